@@ -0,0 +1,77 @@
+// Command smsstub is a local SMS gateway for development and tests. It
+// accepts the messages the backend posts when SMS_GATEWAY_URL points at
+// http://localhost:8081/messages, logs them instead of texting anyone, and
+// lists them at GET /messages.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type message struct {
+	To   string    `json:"to"`
+	Body string    `json:"body"`
+	At   time.Time `json:"at"`
+}
+
+// stub keeps the messages it receives in memory.
+type stub struct {
+	token    string
+	mu       sync.Mutex
+	messages []message
+}
+
+func newStub(token string) *stub {
+	return &stub{token: token}
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/messages" {
+		http.NotFound(w, r)
+		return
+	}
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		var m message
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil || !strings.HasPrefix(m.To, "+") || m.Body == "" {
+			http.Error(w, "expected {\"to\": \"+...\", \"body\": \"...\"}", http.StatusBadRequest)
+			return
+		}
+		m.At = time.Now()
+		s.mu.Lock()
+		s.messages = append(s.messages, m)
+		s.mu.Unlock()
+		log.Printf("sms to %s: %s", m.To, m.Body)
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodGet:
+		s.mu.Lock()
+		list := append([]message{}, s.messages...)
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func main() {
+	addr := flag.String("addr", ":8081", "address to listen on")
+	token := flag.String("token", "", "bearer token senders must present (SMS_GATEWAY_TOKEN)")
+	flag.Parse()
+
+	log.Printf("SMS stub listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, newStub(*token)); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"vesuvio/internal/client"
+)
+
+func TestStub(t *testing.T) {
+	srv := httptest.NewServer(newStub("secret"))
+	defer srv.Close()
+	ctx := context.Background()
+
+	if err := client.NewHTTPSMSSender(srv.URL+"/messages", "secret").SendSMS(ctx, "+34600123456", "Vesuvio: hi"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if err := client.NewHTTPSMSSender(srv.URL+"/messages", "wrong").SendSMS(ctx, "+34600123456", "hi"); err == nil {
+		t.Fatal("expected a wrong token to be rejected")
+	}
+	if err := client.NewHTTPSMSSender(srv.URL+"/messages", "secret").SendSMS(ctx, "600123456", "hi"); err == nil {
+		t.Fatal("expected a number without a country code to be rejected")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/messages", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	defer resp.Body.Close()
+	var list []message
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list) != 1 || list[0].To != "+34600123456" || list[0].Body != "Vesuvio: hi" {
+		t.Fatalf("unexpected messages: %+v", list)
+	}
+}
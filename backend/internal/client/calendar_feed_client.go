@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vesuvio/internal/dto/service"
+	"vesuvio/internal/model"
+)
+
+type GormCalendarFeedClient struct {
+	db *gorm.DB
+}
+
+func NewCalendarFeedClient(db *gorm.DB) *GormCalendarFeedClient {
+	return &GormCalendarFeedClient{db: db}
+}
+
+// GetCalendarFeed returns nil if the user has no feed of the scope.
+func (c *GormCalendarFeedClient) GetCalendarFeed(ctx context.Context, userID uint, scope string) (*servicedto.CalendarFeed, error) {
+	return c.first(c.db.WithContext(ctx).Where("user_id = ? AND scope = ?", userID, scope))
+}
+
+// GetCalendarFeedByToken returns nil for unknown tokens.
+func (c *GormCalendarFeedClient) GetCalendarFeedByToken(ctx context.Context, token string) (*servicedto.CalendarFeed, error) {
+	return c.first(c.db.WithContext(ctx).Where("token = ?", token))
+}
+
+// SaveCalendarFeed creates the feed or replaces its token, which stops the
+// old address from working.
+func (c *GormCalendarFeedClient) SaveCalendarFeed(ctx context.Context, params servicedto.SaveCalendarFeedParams) (*servicedto.CalendarFeed, error) {
+	row := model.CalendarFeedModel{UserID: params.UserID, Scope: params.Scope, Token: params.Token}
+	err := c.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "scope"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	return c.GetCalendarFeed(ctx, params.UserID, params.Scope)
+}
+
+func (c *GormCalendarFeedClient) first(query *gorm.DB) (*servicedto.CalendarFeed, error) {
+	var m model.CalendarFeedModel
+	err := query.First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &servicedto.CalendarFeed{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		Scope:     m.Scope,
+		Token:     m.Token,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestCalendarFeedClient(t *testing.T) {
+	ctx := context.Background()
+	feeds := NewCalendarFeedClient(newTestDB(t))
+
+	if feed, err := feeds.GetCalendarFeed(ctx, 1, servicedto.CalendarFeedScopeUser); feed != nil || err != nil {
+		t.Fatalf("expected no feed yet, got %+v %v", feed, err)
+	}
+	first, err := feeds.SaveCalendarFeed(ctx, servicedto.SaveCalendarFeedParams{UserID: 1, Scope: servicedto.CalendarFeedScopeUser, Token: "first"})
+	if err != nil || first.Token != "first" {
+		t.Fatalf("unexpected feed: %+v %v", first, err)
+	}
+	feeds.SaveCalendarFeed(ctx, servicedto.SaveCalendarFeedParams{UserID: 1, Scope: servicedto.CalendarFeedScopeAdmin, Token: "staff"})
+
+	// Saving again replaces the token of the same feed.
+	second, err := feeds.SaveCalendarFeed(ctx, servicedto.SaveCalendarFeedParams{UserID: 1, Scope: servicedto.CalendarFeedScopeUser, Token: "second"})
+	if err != nil || second.ID != first.ID || second.Token != "second" {
+		t.Fatalf("expected the token to be replaced, got %+v %v", second, err)
+	}
+	if feed, _ := feeds.GetCalendarFeedByToken(ctx, "first"); feed != nil {
+		t.Fatalf("expected the old token to stop working, got %+v", feed)
+	}
+	feed, err := feeds.GetCalendarFeedByToken(ctx, "staff")
+	if err != nil || feed == nil || feed.UserID != 1 || feed.Scope != servicedto.CalendarFeedScopeAdmin {
+		t.Fatalf("unexpected staff feed: %+v %v", feed, err)
+	}
+}
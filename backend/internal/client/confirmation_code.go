@@ -0,0 +1,32 @@
+package client
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+
+	"vesuvio/internal/dto/service"
+)
+
+const (
+	// Letters and digits that are hard to confuse when read out on the phone
+	// (no 0/O, 1/I/L).
+	confirmationCodeAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+	confirmationCodeLength   = 6
+	maxConfirmationCodeTries = 5
+)
+
+// newConfirmationCode returns a random code such as VSV-7K3Q9M.
+func newConfirmationCode() (string, error) {
+	var b strings.Builder
+	b.WriteString(servicedto.ConfirmationCodePrefix)
+	max := big.NewInt(int64(len(confirmationCodeAlphabet)))
+	for i := 0; i < confirmationCodeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(confirmationCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
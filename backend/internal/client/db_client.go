@@ -1,8 +1,6 @@
 package client
 
 import (
-	"vesuvio/internal/model"
-
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -14,12 +12,14 @@ func NewDB(dsn string) (*gorm.DB, error) {
 
 // NewDBWithDialector allows injecting a custom driver (used in tests).
 func NewDBWithDialector(dialector gorm.Dialector) (*gorm.DB, error) {
-	db, err := gorm.Open(dialector, &gorm.Config{})
+	// TranslateError maps driver-specific errors (e.g. unique violations) to
+	// gorm errors so callers can handle them the same way on every database.
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := db.AutoMigrate(&model.UserModel{}, &model.ReservationModel{}); err != nil {
+	if err := Migrate(db); err != nil {
 		return nil, err
 	}
 
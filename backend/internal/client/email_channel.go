@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// SMTPChannel delivers notifications by email through an SMTP server.
+type SMTPChannel struct {
+	addr string
+	from string
+	auth smtp.Auth
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPChannel sends through the server at addr (host:port). Username may
+// be empty for servers without authentication.
+func NewSMTPChannel(addr, from, username, password string) *SMTPChannel {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := strings.Cut(addr, ":")
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPChannel{addr: addr, from: from, auth: auth, send: smtp.SendMail}
+}
+
+func (c *SMTPChannel) Name() string { return "email" }
+
+// Address returns the guest's email. Phone guests only have a placeholder
+// address and cannot be emailed.
+func (c *SMTPChannel) Address(user servicedto.User) (string, bool) {
+	if user.IsGuest || user.Email == "" {
+		return "", false
+	}
+	return user.Email, true
+}
+
+func (c *SMTPChannel) Send(ctx context.Context, n servicedto.Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", c.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", n.To)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	if n.HTML == "" {
+		msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		msg.WriteString(crlf(n.Body))
+		return c.send(c.addr, c.auth, c.from, []string{n.To}, []byte(msg.String()))
+	}
+
+	// Plain text first: clients show the last alternative they understand.
+	parts := multipart.NewWriter(&msg)
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+	for _, alt := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", n.Body},
+		{"text/html; charset=utf-8", n.HTML},
+	} {
+		part, err := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {alt.contentType}})
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write([]byte(crlf(alt.body))); err != nil {
+			return err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return err
+	}
+	return c.send(c.addr, c.auth, c.from, []string{n.To}, []byte(msg.String()))
+}
+
+func crlf(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestSMTPChannel(t *testing.T) {
+	ch := NewSMTPChannel("mail.example.com:587", "reservations@vesuvio.example", "", "")
+	var sent string
+	var to []string
+	ch.send = func(addr string, a smtp.Auth, from string, rcpt []string, msg []byte) error {
+		sent, to = string(msg), rcpt
+		return nil
+	}
+
+	if _, ok := ch.Address(servicedto.User{IsGuest: true, Email: "guest-1@guests.invalid"}); ok {
+		t.Fatal("expected phone guests not to be emailed")
+	}
+
+	n := servicedto.Notification{To: "ana@example.com", Subject: "Your table", Body: "See you\n"}
+	if err := ch.Send(context.Background(), n); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if len(to) != 1 || to[0] != "ana@example.com" || !strings.Contains(sent, "Content-Type: text/plain") || !strings.HasSuffix(sent, "See you\r\n") {
+		t.Fatalf("unexpected plain message to %v: %q", to, sent)
+	}
+
+	n.HTML = "<p>See you</p>"
+	if err := ch.Send(context.Background(), n); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	plain, html := strings.Index(sent, "text/plain"), strings.Index(sent, "text/html")
+	if !strings.Contains(sent, "multipart/alternative; boundary=") || plain < 0 || html < plain || !strings.Contains(sent, "<p>See you</p>") {
+		t.Fatalf("unexpected multipart message: %q", sent)
+	}
+}
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"vesuvio/internal/dto/service"
+)
+
+// Fake webhook event types.
+const (
+	FakePaymentSucceeded = "payment.succeeded"
+	FakePaymentFailed    = "payment.failed"
+)
+
+var (
+	errFakePaymentNotFound = errors.New("fake payment not found")
+	errFakePaymentState    = errors.New("fake payment is not in a state that allows this")
+	errFakeSignature       = errors.New("invalid webhook signature")
+)
+
+// FakePaymentProvider is an in-process payment provider for development and
+// tests. It keeps payments in memory and signs webhooks with HMAC-SHA256
+// like a real provider would.
+type FakePaymentProvider struct {
+	// AutoComplete makes every new payment succeed immediately, as if the
+	// guest had a saved card, so local bookings do not wait for a webhook.
+	AutoComplete bool
+
+	secret   []byte
+	mu       sync.Mutex
+	payments map[string]*FakePayment
+}
+
+// FakePayment is the provider-side state of a fake payment.
+type FakePayment struct {
+	Ref      string
+	Amount   int64
+	Currency string
+	Capture  bool
+	State    string // pending, authorized, captured, voided, refunded or failed
+	Refunded int64
+}
+
+type fakeWebhook struct {
+	Type      string `json:"type"`
+	PaymentID string `json:"payment_id"`
+}
+
+func NewFakePaymentProvider(secret string) *FakePaymentProvider {
+	return &FakePaymentProvider{
+		secret:   []byte(secret),
+		payments: make(map[string]*FakePayment),
+	}
+}
+
+func (p *FakePaymentProvider) CreatePayment(ctx context.Context, params servicedto.ProviderPaymentParams) (*servicedto.ProviderPayment, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	ref := "fake_pay_" + hex.EncodeToString(buf)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payment := &FakePayment{Ref: ref, Amount: params.Amount, Currency: params.Currency, Capture: params.Capture, State: "pending"}
+	if p.AutoComplete {
+		payment.complete()
+	}
+	p.payments[ref] = payment
+	return &servicedto.ProviderPayment{
+		Ref:         ref,
+		CheckoutURL: "https://checkout.fake.invalid/" + ref,
+		Completed:   p.AutoComplete,
+	}, nil
+}
+
+func (p *FakePaymentProvider) Capture(ctx context.Context, ref string) error {
+	return p.transition(ref, "authorized", "captured", nil)
+}
+
+// Void cancels a pending checkout or releases an authorised hold.
+func (p *FakePaymentProvider) Void(ctx context.Context, ref string) error {
+	return p.transition(ref, "", "voided", func(fp *FakePayment) bool {
+		return fp.State == "pending" || fp.State == "authorized"
+	})
+}
+
+func (p *FakePaymentProvider) Refund(ctx context.Context, ref string) error {
+	return p.transition(ref, "captured", "refunded", func(fp *FakePayment) bool {
+		fp.Refunded = fp.Amount
+		return true
+	})
+}
+
+// ParseWebhook verifies the "sha256=<hex>" signature of a webhook body and
+// decodes the payment outcome.
+func (p *FakePaymentProvider) ParseWebhook(payload []byte, signature string) (*servicedto.PaymentEvent, error) {
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || !hmac.Equal(got, p.sign(payload)) {
+		return nil, errFakeSignature
+	}
+	var hook fakeWebhook
+	if err := json.Unmarshal(payload, &hook); err != nil {
+		return nil, err
+	}
+	switch hook.Type {
+	case FakePaymentSucceeded, FakePaymentFailed:
+	default:
+		return nil, fmt.Errorf("unknown fake webhook type %q", hook.Type)
+	}
+	return &servicedto.PaymentEvent{ProviderRef: hook.PaymentID, Succeeded: hook.Type == FakePaymentSucceeded}, nil
+}
+
+// Complete settles a pending payment as the guest's checkout would and
+// returns the signed webhook the provider sends for it.
+func (p *FakePaymentProvider) Complete(ref string, succeeded bool) (payload []byte, signature string, err error) {
+	p.mu.Lock()
+	fp, ok := p.payments[ref]
+	if ok && fp.State == "pending" {
+		if succeeded {
+			fp.complete()
+		} else {
+			fp.State = "failed"
+		}
+	}
+	p.mu.Unlock()
+	if !ok {
+		return nil, "", errFakePaymentNotFound
+	}
+
+	hook := fakeWebhook{Type: FakePaymentFailed, PaymentID: ref}
+	if succeeded {
+		hook.Type = FakePaymentSucceeded
+	}
+	payload, err = json.Marshal(hook)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, "sha256=" + hex.EncodeToString(p.sign(payload)), nil
+}
+
+// Payment returns a copy of the provider-side state of a payment.
+func (p *FakePaymentProvider) Payment(ref string) (FakePayment, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fp, ok := p.payments[ref]
+	if !ok {
+		return FakePayment{}, false
+	}
+	return *fp, true
+}
+
+// transition moves a payment from the given state, or any state allow
+// accepts when from is empty, to the target state.
+func (p *FakePaymentProvider) transition(ref, from, to string, allow func(*FakePayment) bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fp, ok := p.payments[ref]
+	if !ok {
+		return errFakePaymentNotFound
+	}
+	if (from != "" && fp.State != from) || (allow != nil && !allow(fp)) {
+		return errFakePaymentState
+	}
+	fp.State = to
+	return nil
+}
+
+func (fp *FakePayment) complete() {
+	if fp.Capture {
+		fp.State = "captured"
+	} else {
+		fp.State = "authorized"
+	}
+}
+
+func (p *FakePaymentProvider) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestFakePaymentProvider_WebhookAndSettlement(t *testing.T) {
+	provider := NewFakePaymentProvider("secret")
+	ctx := context.Background()
+
+	created, err := provider.CreatePayment(ctx, servicedto.ProviderPaymentParams{Reference: "VSV-000001", Amount: 4000, Currency: "eur"})
+	if err != nil || created.Completed || created.CheckoutURL == "" {
+		t.Fatalf("unexpected payment: %+v (%v)", created, err)
+	}
+	if err := provider.Capture(ctx, created.Ref); err == nil {
+		t.Fatal("expected capture of an unpaid checkout to fail")
+	}
+
+	payload, signature, err := provider.Complete(created.Ref, true)
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	event, err := provider.ParseWebhook(payload, signature)
+	if err != nil || !event.Succeeded || event.ProviderRef != created.Ref {
+		t.Fatalf("unexpected event: %+v (%v)", event, err)
+	}
+	if _, err := provider.ParseWebhook(payload, "sha256=00"); err == nil {
+		t.Fatal("expected a bad signature to be rejected")
+	}
+	if _, err := NewFakePaymentProvider("other").ParseWebhook(payload, signature); err == nil {
+		t.Fatal("expected a different secret to be rejected")
+	}
+
+	if err := provider.Capture(ctx, created.Ref); err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if p, _ := provider.Payment(created.Ref); p.State != "captured" {
+		t.Fatalf("expected captured, got %s", p.State)
+	}
+	if err := provider.Void(ctx, created.Ref); err == nil {
+		t.Fatal("expected void of a captured payment to fail")
+	}
+
+	provider.AutoComplete = true
+	deposit, _ := provider.CreatePayment(ctx, servicedto.ProviderPaymentParams{Amount: 2000, Currency: "eur", Capture: true})
+	if !deposit.Completed {
+		t.Fatal("expected auto-completed payment")
+	}
+	if err := provider.Refund(ctx, deposit.Ref); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if p, _ := provider.Payment(deposit.Ref); p.State != "refunded" || p.Refunded != 2000 {
+		t.Fatalf("unexpected refunded payment: %+v", p)
+	}
+}
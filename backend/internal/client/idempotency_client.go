@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"vesuvio/internal/dto/service"
+	"vesuvio/internal/model"
+)
+
+type GormIdempotencyClient struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyClient(db *gorm.DB) *GormIdempotencyClient {
+	return &GormIdempotencyClient{db: db}
+}
+
+// CreateIdempotencyKey reserves the key. It returns false when the key is
+// already stored for the user; the unique index makes this safe across
+// concurrent requests and instances.
+func (c *GormIdempotencyClient) CreateIdempotencyKey(ctx context.Context, params servicedto.CreateIdempotencyKeyParams) (bool, error) {
+	rec := model.IdempotencyKeyModel{
+		UserID:      params.UserID,
+		Key:         params.Key,
+		RequestHash: params.RequestHash,
+		ExpiresAt:   params.ExpiresAt,
+	}
+	err := c.db.WithContext(ctx).Create(&rec).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *GormIdempotencyClient) GetIdempotencyKey(ctx context.Context, userID uint, key string) (*servicedto.IdempotencyRecord, error) {
+	var rec model.IdempotencyKeyModel
+	err := c.db.WithContext(ctx).Where("user_id = ? AND key = ?", userID, key).First(&rec).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toServiceIdempotencyRecord(&rec), nil
+}
+
+func (c *GormIdempotencyClient) CompleteIdempotencyKey(ctx context.Context, input servicedto.CompleteIdempotentRequestInput) error {
+	return c.db.WithContext(ctx).Model(&model.IdempotencyKeyModel{}).
+		Where("user_id = ? AND key = ?", input.UserID, input.Key).
+		Updates(map[string]interface{}{
+			"status_code":   input.StatusCode,
+			"content_type":  input.ContentType,
+			"response_body": input.ResponseBody,
+		}).Error
+}
+
+func (c *GormIdempotencyClient) DeleteIdempotencyKey(ctx context.Context, userID uint, key string) error {
+	return c.db.WithContext(ctx).
+		Where("user_id = ? AND key = ?", userID, key).
+		Delete(&model.IdempotencyKeyModel{}).Error
+}
+
+// DeleteExpiredIdempotencyKeys removes keys that expired before the given time.
+func (c *GormIdempotencyClient) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) error {
+	return c.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Delete(&model.IdempotencyKeyModel{}).Error
+}
+
+func toServiceIdempotencyRecord(m *model.IdempotencyKeyModel) *servicedto.IdempotencyRecord {
+	return &servicedto.IdempotencyRecord{
+		UserID:       m.UserID,
+		Key:          m.Key,
+		RequestHash:  m.RequestHash,
+		StatusCode:   m.StatusCode,
+		ContentType:  m.ContentType,
+		ResponseBody: m.ResponseBody,
+		ExpiresAt:    m.ExpiresAt,
+		CreatedAt:    m.CreatedAt,
+	}
+}
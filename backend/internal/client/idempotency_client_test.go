@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestIdempotencyClient_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := NewDBWithDialector(sqlite.Open(dsn))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	client := NewIdempotencyClient(db)
+	expires := time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+
+	params := servicedto.CreateIdempotencyKeyParams{UserID: 1, Key: "abc", RequestHash: "h1", ExpiresAt: expires}
+	created, err := client.CreateIdempotencyKey(ctx, params)
+	if err != nil || !created {
+		t.Fatalf("expected key to be created, got %v, %v", created, err)
+	}
+	created, err = client.CreateIdempotencyKey(ctx, params)
+	if err != nil || created {
+		t.Fatalf("expected duplicate key to be rejected, got %v, %v", created, err)
+	}
+
+	err = client.CompleteIdempotencyKey(ctx, servicedto.CompleteIdempotentRequestInput{
+		UserID: 1, Key: "abc", StatusCode: 201, ContentType: "application/json", ResponseBody: []byte(`{"id":1}`),
+	})
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	rec, err := client.GetIdempotencyKey(ctx, 1, "abc")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if rec == nil || !rec.Completed() || string(rec.ResponseBody) != `{"id":1}` || rec.RequestHash != "h1" {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+
+	if err := client.DeleteExpiredIdempotencyKeys(ctx, expires.Add(time.Minute)); err != nil {
+		t.Fatalf("delete expired: %v", err)
+	}
+	rec, err = client.GetIdempotencyKey(ctx, 1, "abc")
+	if err != nil || rec != nil {
+		t.Fatalf("expected expired key to be removed, got %+v, %v", rec, err)
+	}
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"vesuvio/internal/model"
+)
+
+type GormJobLeaseClient struct {
+	db *gorm.DB
+}
+
+func NewJobLeaseClient(db *gorm.DB) *GormJobLeaseClient {
+	return &GormJobLeaseClient{db: db}
+}
+
+// AcquireLease takes or renews the named lease for holder until the given
+// time. It returns false while another holder's lease has not expired; the
+// primary key and conditional update make this safe across instances.
+func (c *GormJobLeaseClient) AcquireLease(ctx context.Context, name, holder string, now, until time.Time) (bool, error) {
+	err := c.db.WithContext(ctx).Create(&model.JobLeaseModel{Name: name, Holder: holder, ExpiresAt: until}).Error
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return false, err
+	}
+	result := c.db.WithContext(ctx).Model(&model.JobLeaseModel{}).
+		Where("name = ? AND (holder = ? OR expires_at <= ?)", name, holder, now).
+		Updates(map[string]interface{}{"holder": holder, "expires_at": until})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseLease gives up the named lease if holder still has it.
+func (c *GormJobLeaseClient) ReleaseLease(ctx context.Context, name, holder string) error {
+	return c.db.WithContext(ctx).
+		Where("name = ? AND holder = ?", name, holder).
+		Delete(&model.JobLeaseModel{}).Error
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestJobLeaseClient(t *testing.T) {
+	ctx := context.Background()
+	client := NewJobLeaseClient(newTestDB(t))
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	if ok, err := client.AcquireLease(ctx, "expire", "a", now, now.Add(2*time.Minute)); err != nil || !ok {
+		t.Fatalf("expected a to take the free lease, got %t %v", ok, err)
+	}
+	if ok, err := client.AcquireLease(ctx, "expire", "b", now.Add(time.Minute), now.Add(3*time.Minute)); err != nil || ok {
+		t.Fatalf("expected b to be refused, got %t %v", ok, err)
+	}
+	if ok, err := client.AcquireLease(ctx, "expire", "a", now.Add(time.Minute), now.Add(3*time.Minute)); err != nil || !ok {
+		t.Fatalf("expected a to renew, got %t %v", ok, err)
+	}
+	if ok, err := client.AcquireLease(ctx, "other", "b", now, now.Add(time.Minute)); err != nil || !ok {
+		t.Fatalf("expected leases to be per job, got %t %v", ok, err)
+	}
+
+	if ok, err := client.AcquireLease(ctx, "expire", "b", now.Add(3*time.Minute), now.Add(5*time.Minute)); err != nil || !ok {
+		t.Fatalf("expected b to take over the expired lease, got %t %v", ok, err)
+	}
+	if err := client.ReleaseLease(ctx, "expire", "a"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if ok, _ := client.AcquireLease(ctx, "expire", "a", now.Add(4*time.Minute), now.Add(6*time.Minute)); ok {
+		t.Fatal("expected a's release not to free b's lease")
+	}
+	if err := client.ReleaseLease(ctx, "expire", "b"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if ok, _ := client.AcquireLease(ctx, "expire", "a", now.Add(4*time.Minute), now.Add(6*time.Minute)); !ok {
+		t.Fatal("expected the released lease to be free")
+	}
+}
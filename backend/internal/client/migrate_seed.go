@@ -8,12 +8,86 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
+	"vesuvio/internal/dto/service"
 	"vesuvio/internal/model"
 )
 
 // Migrate ensures database tables exist for all models.
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(&model.UserModel{}, &model.ReservationModel{})
+	if err := db.AutoMigrate(
+		&model.UserModel{},
+		&model.TableModel{},
+		&model.ReservationModel{},
+		&model.ReservationStatusChangeModel{},
+		&model.ReservationNoteModel{},
+		&model.ReservationTagModel{},
+		&model.GuestIncidentModel{},
+		&model.GuestRestrictionModel{},
+		&model.PaymentModel{},
+		&model.ReminderModel{},
+		&model.NotificationPreferenceModel{},
+		&model.WebhookModel{},
+		&model.WebhookDeliveryModel{},
+		&model.OutboxEventModel{},
+		&model.CalendarFeedModel{},
+		&model.IdempotencyKeyModel{},
+		&model.JobLeaseModel{},
+	); err != nil {
+		return err
+	}
+	return backfillConfirmationCodes(db)
+}
+
+// backfillConfirmationCodes assigns codes to reservations created before
+// confirmation codes existed.
+func backfillConfirmationCodes(db *gorm.DB) error {
+	var ids []uint
+	if err := db.Model(&model.ReservationModel{}).Where("code IS NULL OR code = ''").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		for attempt := 1; ; attempt++ {
+			code, err := newConfirmationCode()
+			if err != nil {
+				return err
+			}
+			err = db.Model(&model.ReservationModel{}).Where("id = ?", id).Update("code", code).Error
+			if errors.Is(err, gorm.ErrDuplicatedKey) && attempt < maxConfirmationCodeTries {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// NormalizeStoredPhones rewrites the phone numbers saved before they were
+// normalized, or before format's country code was configured, in E.164 form
+// so that lookups by phone find them. Numbers format cannot read are left
+// alone and counted in skipped.
+func NormalizeStoredPhones(ctx context.Context, db *gorm.DB, format servicedto.PhoneFormat) (updated, skipped int, err error) {
+	var users []model.UserModel
+	if err := db.WithContext(ctx).Select("id", "phone").Where("phone IS NOT NULL AND phone <> ''").Find(&users).Error; err != nil {
+		return 0, 0, err
+	}
+	for _, u := range users {
+		phone, ok := format.Normalize(*u.Phone)
+		if !ok {
+			skipped++
+			continue
+		}
+		if phone == *u.Phone {
+			continue
+		}
+		if err := db.WithContext(ctx).Model(&model.UserModel{}).Where("id = ?", u.ID).Update("phone", phone).Error; err != nil {
+			return updated, skipped, err
+		}
+		updated++
+	}
+	return updated, skipped, nil
 }
 
 // SeedUser describes a user to insert.
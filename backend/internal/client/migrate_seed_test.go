@@ -4,8 +4,13 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/model"
 )
 
 func TestMigrateAndSeedUsers(t *testing.T) {
@@ -63,3 +68,68 @@ func TestSeedUsersValidation(t *testing.T) {
 		t.Fatalf("expected error for missing password")
 	}
 }
+
+func TestMigrateBackfillsConfirmationCodes(t *testing.T) {
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := NewDBWithDialector(sqlite.Open(dsn))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+
+	// Simulate rows written before confirmation codes existed.
+	legacy := model.ReservationModel{UserID: 1, Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 2, Status: "pending"}
+	if err := db.Create(&legacy).Error; err != nil {
+		t.Fatalf("create legacy reservation: %v", err)
+	}
+	if err := db.Model(&legacy).Update("code", gorm.Expr("NULL")).Error; err != nil {
+		t.Fatalf("clear code: %v", err)
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	var reloaded model.ReservationModel
+	if err := db.First(&reloaded, legacy.ID).Error; err != nil {
+		t.Fatalf("reload reservation: %v", err)
+	}
+	if reloaded.Code == "" {
+		t.Fatalf("expected backfilled confirmation code")
+	}
+}
+
+func TestNormalizeStoredPhones(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	phone := func(p string) *string { return &p }
+	users := []model.UserModel{
+		{Name: "A", Email: "a@example.com", Phone: phone("333 123 4567")},
+		{Name: "B", Email: "b@example.com", Phone: phone("0034 600-123-456")},
+		{Name: "C", Email: "c@example.com", Phone: phone("+393331234567")},
+		{Name: "D", Email: "d@example.com", Phone: phone("call me")},
+		{Name: "E", Email: "e@example.com"},
+	}
+	for i := range users {
+		if err := db.Create(&users[i]).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+
+	updated, skipped, err := NormalizeStoredPhones(ctx, db, servicedto.PhoneFormat{CountryCode: "39"})
+	if err != nil || updated != 2 || skipped != 1 {
+		t.Fatalf("expected two updated and one skipped, got %d %d %v", updated, skipped, err)
+	}
+	want := []string{"+393331234567", "+34600123456", "+393331234567", "call me"}
+	for i, w := range want {
+		var got model.UserModel
+		db.First(&got, users[i].ID)
+		if got.Phone == nil || *got.Phone != w {
+			t.Fatalf("user %d: expected %s, got %v", i, w, got.Phone)
+		}
+	}
+
+	// Running it again changes nothing.
+	if updated, _, err := NormalizeStoredPhones(ctx, db, servicedto.PhoneFormat{CountryCode: "39"}); err != nil || updated != 0 {
+		t.Fatalf("expected nothing left to update, got %d %v", updated, err)
+	}
+}
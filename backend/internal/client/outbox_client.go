@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"vesuvio/internal/dto/service"
+	"vesuvio/internal/model"
+)
+
+type GormOutboxClient struct {
+	db *gorm.DB
+}
+
+func NewOutboxClient(db *gorm.DB) *GormOutboxClient {
+	return &GormOutboxClient{db: db}
+}
+
+// recordEvent adds a domain event to the outbox. It must run in the
+// transaction that makes the change.
+func recordEvent(tx *gorm.DB, eventType string, res *model.ReservationModel, from *string) error {
+	return tx.Create(&model.OutboxEventModel{
+		Type:          eventType,
+		ReservationID: res.ID,
+		FromStatus:    from,
+		ToStatus:      res.Status,
+		Status:        servicedto.OutboxPending,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// ClaimDueEvents claims up to params.Limit pending events whose next attempt
+// is due, oldest first, and returns them. The claim is a conditional update,
+// so when two runs pick the same events each event goes to one of them.
+func (c *GormOutboxClient) ClaimDueEvents(ctx context.Context, params servicedto.ClaimEventsParams) ([]servicedto.DomainEvent, error) {
+	db := c.db.WithContext(ctx)
+	var ids []uint
+	err := db.Model(&model.OutboxEventModel{}).
+		Where("status = ? AND next_attempt_at <= ?", servicedto.OutboxPending, params.Now).
+		Order("id").
+		Limit(params.Limit).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	err = db.Model(&model.OutboxEventModel{}).
+		Where("id IN ? AND status = ? AND next_attempt_at <= ?", ids, servicedto.OutboxPending, params.Now).
+		Updates(map[string]interface{}{"claim_token": params.Claim, "next_attempt_at": params.Until}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var models []model.OutboxEventModel
+	if err := db.Where("claim_token = ? AND id IN ?", params.Claim, ids).Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	events := make([]servicedto.DomainEvent, 0, len(models))
+	for _, m := range models {
+		event := toServiceDomainEvent(m)
+		event.Claim = params.Claim
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ListEvents reads recent events without claiming them, for the live views
+// every instance feeds on its own.
+func (c *GormOutboxClient) ListEvents(ctx context.Context, params servicedto.ListEventsParams) ([]servicedto.DomainEvent, error) {
+	var models []model.OutboxEventModel
+	err := c.db.WithContext(ctx).
+		Where("created_at >= ? AND id > ?", params.Since, params.AfterID).
+		Order("id").
+		Limit(params.Limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	events := make([]servicedto.DomainEvent, 0, len(models))
+	for _, m := range models {
+		events = append(events, toServiceDomainEvent(m))
+	}
+	return events, nil
+}
+
+func toServiceDomainEvent(m model.OutboxEventModel) servicedto.DomainEvent {
+	event := servicedto.DomainEvent{
+		ID:            m.ID,
+		Type:          m.Type,
+		ReservationID: m.ReservationID,
+		ToStatus:      m.ToStatus,
+		Attempts:      m.Attempts,
+		Handled:       m.Handled,
+		CreatedAt:     m.CreatedAt,
+	}
+	if m.FromStatus != nil {
+		event.FromStatus = *m.FromStatus
+	}
+	return event
+}
+
+// CompleteEvent marks an event as handled by every handler. Nothing changes
+// if the claim has lapsed and another run holds the event.
+func (c *GormOutboxClient) CompleteEvent(ctx context.Context, id uint, claim string, at time.Time) error {
+	return c.db.WithContext(ctx).Model(&model.OutboxEventModel{}).Where("id = ? AND claim_token = ?", id, claim).
+		Updates(map[string]interface{}{
+			"status":        servicedto.OutboxDispatched,
+			"attempts":      gorm.Expr("attempts + 1"),
+			"error":         nil,
+			"claim_token":   nil,
+			"dispatched_at": at,
+		}).Error
+}
+
+// RetryEvent records a failed dispatch and when to try again, or gives up
+// on the event when params.NextAttemptAt is nil. Like CompleteEvent it needs
+// the claim to still hold.
+func (c *GormOutboxClient) RetryEvent(ctx context.Context, params servicedto.RetryEventParams) error {
+	msg := params.Error
+	if len(msg) > 255 {
+		msg = msg[:255]
+	}
+	// Map updates skip the model's serializer, so encode the list here.
+	handled, err := json.Marshal(params.Handled)
+	if err != nil {
+		return err
+	}
+	updates := map[string]interface{}{
+		"status":      servicedto.OutboxFailed,
+		"attempts":    gorm.Expr("attempts + 1"),
+		"handled":     string(handled),
+		"error":       msg,
+		"claim_token": nil,
+	}
+	if params.NextAttemptAt != nil {
+		updates["status"] = servicedto.OutboxPending
+		updates["next_attempt_at"] = *params.NextAttemptAt
+	}
+	return c.db.WithContext(ctx).Model(&model.OutboxEventModel{}).Where("id = ? AND claim_token = ?", params.ID, params.Claim).Updates(updates).Error
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/model"
+)
+
+func TestOutboxClient_RecordsReservationEvents(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	reservations := NewReservationClient(db)
+	outbox := NewOutboxClient(db)
+	date := time.Date(2030, 1, 11, 0, 0, 0, 0, time.UTC)
+
+	res, _ := reservations.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	reservations.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{ID: res.ID, Status: servicedto.StatusConfirmed})
+	reservations.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{ID: res.ID, Status: servicedto.StatusConfirmed}) // no change
+	reservations.ModifyReservation(ctx, servicedto.ModifyReservationParams{
+		ID: res.ID, From: servicedto.StatusConfirmed, Date: date, Time: "21:00", People: 3, Status: servicedto.StatusPending,
+	})
+	// A batch that fails leaves no events behind.
+	reservations.UpdateReservationStatuses(ctx, []servicedto.UpdateReservationStatusParams{
+		{ID: res.ID, Status: servicedto.StatusCancelled},
+		{ID: 999, Status: servicedto.StatusCancelled},
+	})
+
+	events, err := outbox.ClaimDueEvents(ctx, servicedto.ClaimEventsParams{Claim: "run", Now: time.Now(), Until: time.Now().Add(time.Minute), Limit: 10})
+	if err != nil || len(events) != 3 {
+		t.Fatalf("expected three events, got %+v %v", events, err)
+	}
+	for i, want := range []servicedto.DomainEvent{
+		{Type: servicedto.DomainEventReservationCreated, ToStatus: servicedto.StatusPending},
+		{Type: servicedto.DomainEventStatusChanged, FromStatus: servicedto.StatusPending, ToStatus: servicedto.StatusConfirmed},
+		{Type: servicedto.DomainEventReservationModified, FromStatus: servicedto.StatusConfirmed, ToStatus: servicedto.StatusPending},
+	} {
+		e := events[i]
+		if e.Type != want.Type || e.ReservationID != res.ID || e.FromStatus != want.FromStatus || e.ToStatus != want.ToStatus {
+			t.Fatalf("event %d: expected %+v, got %+v", i, want, e)
+		}
+	}
+
+	// Live views read events whatever their dispatch status.
+	listed, err := outbox.ListEvents(ctx, servicedto.ListEventsParams{Since: events[0].CreatedAt, AfterID: events[0].ID, Limit: 10})
+	if err != nil || len(listed) != 2 || listed[0].ID != events[1].ID || listed[0].Claim != "" {
+		t.Fatalf("expected the claimed events after the first, got %+v %v", listed, err)
+	}
+	if later, _ := outbox.ListEvents(ctx, servicedto.ListEventsParams{Since: time.Now().Add(time.Minute), Limit: 10}); len(later) != 0 {
+		t.Fatalf("expected no events recorded after since, got %+v", later)
+	}
+}
+
+func TestOutboxClient_RecordsStaffEvents(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	reservations := NewReservationClient(db)
+	outbox := NewOutboxClient(db)
+	users := NewUserClient(db)
+	staff, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Host", Email: "host@example.com", IsAdmin: true})
+	date := time.Date(2030, 1, 11, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+
+	pending, _ := reservations.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "19:00", People: 2, Status: servicedto.StatusPending,
+	})
+	confirmed, _ := reservations.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "21:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+	reservations.EscalateReservation(ctx, pending.ID, "waiting too long", now)
+	reservations.EscalateReservation(ctx, pending.ID, "waiting too long", now) // already escalated
+	reservations.ConfirmAttendance(ctx, confirmed.ID, now)
+	reservations.ConfirmAttendance(ctx, pending.ID, now) // not confirmed
+	reservations.AddStaffNote(ctx, servicedto.CreateStaffNoteParams{ReservationID: confirmed.ID, AuthorID: staff.ID, Body: "regular"})
+	reservations.SetReservationTags(ctx, confirmed.ID, []string{"vip"})
+	reservations.SetReservationTags(ctx, confirmed.ID, []string{"vip"}) // unchanged
+	reservations.SetReservationTags(ctx, confirmed.ID, nil)
+
+	events, err := outbox.ClaimDueEvents(ctx, servicedto.ClaimEventsParams{Claim: "run", Now: now.Add(time.Second), Until: now.Add(time.Minute), Limit: 20})
+	if err != nil || len(events) != 7 {
+		t.Fatalf("expected seven events, got %+v %v", events, err)
+	}
+	for i, want := range []servicedto.DomainEvent{
+		{Type: servicedto.DomainEventEscalated, ReservationID: pending.ID, ToStatus: servicedto.StatusPending},
+		{Type: servicedto.DomainEventAttendanceConfirmed, ReservationID: confirmed.ID, ToStatus: servicedto.StatusConfirmed},
+		{Type: servicedto.DomainEventStaffNoteAdded, ReservationID: confirmed.ID, ToStatus: servicedto.StatusConfirmed},
+		{Type: servicedto.DomainEventTagsChanged, ReservationID: confirmed.ID, ToStatus: servicedto.StatusConfirmed},
+		{Type: servicedto.DomainEventTagsChanged, ReservationID: confirmed.ID, ToStatus: servicedto.StatusConfirmed},
+	} {
+		e := events[i+2] // after the two created events
+		if e.Type != want.Type || e.ReservationID != want.ReservationID || e.ToStatus != want.ToStatus {
+			t.Fatalf("event %d: expected %+v, got %+v", i, want, e)
+		}
+	}
+}
+
+func TestOutboxClient_Dispatch(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	reservations := NewReservationClient(db)
+	outbox := NewOutboxClient(db)
+
+	for _, slot := range []string{"19:00", "20:00", "21:00"} {
+		reservations.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: 1, Date: time.Date(2030, 1, 11, 0, 0, 0, 0, time.UTC), Time: slot, People: 2, Status: servicedto.StatusPending,
+		})
+	}
+	now := time.Now()
+	claim := func(run string, at time.Time, limit int) []servicedto.DomainEvent {
+		events, err := outbox.ClaimDueEvents(ctx, servicedto.ClaimEventsParams{Claim: run, Now: at, Until: at.Add(5 * time.Minute), Limit: limit})
+		if err != nil {
+			t.Fatalf("claim: %v", err)
+		}
+		return events
+	}
+	first := claim("a", now, 2)
+	if len(first) != 2 || first[0].Claim != "a" {
+		t.Fatalf("expected the oldest two events claimed, got %+v", first)
+	}
+	// A second run only gets what the first did not claim.
+	second := claim("b", now, 10)
+	if len(second) != 1 || second[0].ID == first[0].ID || second[0].ID == first[1].ID {
+		t.Fatalf("expected only the unclaimed event, got %+v", second)
+	}
+	events := append(first, second...)
+
+	later := now.Add(time.Minute)
+	// A run that no longer holds an event cannot complete it.
+	if err := outbox.CompleteEvent(ctx, events[0].ID, "b", now); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if err := outbox.CompleteEvent(ctx, events[0].ID, "a", now); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if err := outbox.RetryEvent(ctx, servicedto.RetryEventParams{ID: events[1].ID, Claim: "a", Handled: []string{"webhooks"}, Error: "smtp down", NextAttemptAt: &later}); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if due := claim("c", now, 10); len(due) != 0 {
+		t.Fatalf("expected nothing due, got %+v", due)
+	}
+
+	due := claim("c", later, 10)
+	if len(due) != 1 {
+		t.Fatalf("expected the retried event to come back, got %+v", due)
+	}
+	if e := due[0]; e.ID != events[1].ID || e.Attempts != 1 || len(e.Handled) != 1 || e.Handled[0] != "webhooks" {
+		t.Fatalf("unexpected retried event: %+v", e)
+	}
+
+	// Run b died holding its event; it is due again once the claim lapses.
+	lapsed := claim("d", now.Add(6*time.Minute), 10)
+	if len(lapsed) != 2 || lapsed[0].ID != events[1].ID || lapsed[1].ID != events[2].ID {
+		t.Fatalf("expected lapsed claims to be taken over, got %+v", lapsed)
+	}
+	if err := outbox.RetryEvent(ctx, servicedto.RetryEventParams{ID: events[2].ID, Claim: "d", Error: "gave up"}); err != nil {
+		t.Fatalf("give up: %v", err)
+	}
+	var failed model.OutboxEventModel
+	db.First(&failed, events[2].ID)
+	if failed.Status != servicedto.OutboxFailed || failed.ClaimToken != nil {
+		t.Fatalf("expected the event given up on and released, got %+v", failed)
+	}
+}
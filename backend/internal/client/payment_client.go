@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"vesuvio/internal/dto/service"
+	"vesuvio/internal/model"
+)
+
+type GormPaymentClient struct {
+	db *gorm.DB
+}
+
+func NewPaymentClient(db *gorm.DB) *GormPaymentClient {
+	return &GormPaymentClient{db: db}
+}
+
+func (c *GormPaymentClient) CreatePayment(ctx context.Context, params servicedto.CreatePaymentParams) (*servicedto.Payment, error) {
+	payment := model.PaymentModel{
+		ReservationID: params.ReservationID,
+		Kind:          params.Kind,
+		Amount:        params.Amount,
+		Currency:      params.Currency,
+		Status:        params.Status,
+		ProviderRef:   params.ProviderRef,
+		CheckoutURL:   params.CheckoutURL,
+	}
+	if err := c.db.WithContext(ctx).Create(&payment).Error; err != nil {
+		return nil, err
+	}
+	return toServicePayment(&payment), nil
+}
+
+func (c *GormPaymentClient) GetPaymentByReservation(ctx context.Context, reservationID uint) (*servicedto.Payment, error) {
+	return c.first(ctx, "reservation_id = ?", reservationID)
+}
+
+func (c *GormPaymentClient) GetPaymentByProviderRef(ctx context.Context, ref string) (*servicedto.Payment, error) {
+	return c.first(ctx, "provider_ref = ?", ref)
+}
+
+// UpdatePaymentStatus moves a payment to status only if it is still in
+// from, so concurrent webhooks and settlements apply once. It returns nil
+// when the payment was not in from.
+func (c *GormPaymentClient) UpdatePaymentStatus(ctx context.Context, id uint, from, status string) (*servicedto.Payment, error) {
+	result := c.db.WithContext(ctx).Model(&model.PaymentModel{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", status)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return c.first(ctx, "id = ?", id)
+}
+
+// MarkSettlementDue records that the reservation's payment is owed the
+// outcome and returns the payment, or nil when the reservation has none.
+func (c *GormPaymentClient) MarkSettlementDue(ctx context.Context, reservationID uint, outcome string) (*servicedto.Payment, error) {
+	result := c.db.WithContext(ctx).Model(&model.PaymentModel{}).
+		Where("reservation_id = ?", reservationID).
+		Update("settlement", outcome)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return c.first(ctx, "reservation_id = ?", reservationID)
+}
+
+// ClearSettlement marks the outcome as done. A settlement recorded since,
+// for a later status change, is kept.
+func (c *GormPaymentClient) ClearSettlement(ctx context.Context, id uint, outcome string) error {
+	return c.db.WithContext(ctx).Model(&model.PaymentModel{}).
+		Where("id = ? AND settlement = ?", id, outcome).
+		Update("settlement", nil).Error
+}
+
+// ListDueSettlements returns up to limit payments still owed a settlement,
+// oldest first.
+func (c *GormPaymentClient) ListDueSettlements(ctx context.Context, limit int) ([]servicedto.Payment, error) {
+	var models []model.PaymentModel
+	if err := c.db.WithContext(ctx).Where("settlement IS NOT NULL").Order("id").Limit(limit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	payments := make([]servicedto.Payment, 0, len(models))
+	for i := range models {
+		payments = append(payments, *toServicePayment(&models[i]))
+	}
+	return payments, nil
+}
+
+func (c *GormPaymentClient) first(ctx context.Context, query string, arg interface{}) (*servicedto.Payment, error) {
+	var payment model.PaymentModel
+	err := c.db.WithContext(ctx).Where(query, arg).First(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toServicePayment(&payment), nil
+}
+
+func toServicePayment(m *model.PaymentModel) *servicedto.Payment {
+	if m == nil {
+		return nil
+	}
+	payment := &servicedto.Payment{
+		ID:            m.ID,
+		ReservationID: m.ReservationID,
+		Kind:          m.Kind,
+		Amount:        m.Amount,
+		Currency:      m.Currency,
+		Status:        m.Status,
+		ProviderRef:   m.ProviderRef,
+		CheckoutURL:   m.CheckoutURL,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+	if m.Settlement != nil {
+		payment.Settlement = *m.Settlement
+	}
+	return payment
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestPaymentClient_CreateAndUpdate(t *testing.T) {
+	db := newTestDB(t)
+	client := NewPaymentClient(db)
+	reservations := NewReservationClient(db)
+	ctx := context.Background()
+
+	res, _ := reservations.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 8, Status: servicedto.StatusAwaitingPayment,
+	})
+	created, err := client.CreatePayment(ctx, servicedto.CreatePaymentParams{
+		ReservationID: res.ID,
+		Kind:          servicedto.PaymentKindGuarantee,
+		Amount:        16000,
+		Currency:      "eur",
+		Status:        servicedto.PaymentPending,
+		ProviderRef:   "pay_1",
+		CheckoutURL:   "https://pay.example.com/pay_1",
+	})
+	if err != nil || created.ID == 0 {
+		t.Fatalf("create payment: %+v (%v)", created, err)
+	}
+
+	byRef, err := client.GetPaymentByProviderRef(ctx, "pay_1")
+	if err != nil || byRef == nil || byRef.ReservationID != res.ID {
+		t.Fatalf("unexpected payment by ref: %+v (%v)", byRef, err)
+	}
+	if none, err := client.GetPaymentByReservation(ctx, 999); err != nil || none != nil {
+		t.Fatalf("expected nil for missing payment, got %+v (%v)", none, err)
+	}
+
+	updated, err := client.UpdatePaymentStatus(ctx, created.ID, servicedto.PaymentPending, servicedto.PaymentAuthorized)
+	if err != nil || updated == nil || updated.Status != servicedto.PaymentAuthorized {
+		t.Fatalf("unexpected update: %+v (%v)", updated, err)
+	}
+	stale, err := client.UpdatePaymentStatus(ctx, created.ID, servicedto.PaymentPending, servicedto.PaymentFailed)
+	if err != nil || stale != nil {
+		t.Fatalf("expected nil when the payment moved on, got %+v (%v)", stale, err)
+	}
+
+	withPayment, _ := reservations.GetReservationByCode(ctx, res.Code)
+	if withPayment.Payment == nil || withPayment.Payment.Status != servicedto.PaymentAuthorized {
+		t.Fatalf("expected payment preloaded on reservation, got %+v", withPayment.Payment)
+	}
+}
+
+func TestPaymentClient_Settlements(t *testing.T) {
+	db := newTestDB(t)
+	client := NewPaymentClient(db)
+	reservations := NewReservationClient(db)
+	ctx := context.Background()
+
+	res, _ := reservations.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 8, Status: servicedto.StatusPending,
+	})
+	created, _ := client.CreatePayment(ctx, servicedto.CreatePaymentParams{
+		ReservationID: res.ID, Kind: servicedto.PaymentKindGuarantee, Amount: 16000, Currency: "eur",
+		Status: servicedto.PaymentAuthorized, ProviderRef: "pay_1",
+	})
+
+	if none, err := client.MarkSettlementDue(ctx, 999, "release"); err != nil || none != nil {
+		t.Fatalf("expected nil without a payment, got %+v (%v)", none, err)
+	}
+	due, err := client.MarkSettlementDue(ctx, res.ID, "forfeit")
+	if err != nil || due == nil || due.ID != created.ID || due.Settlement != "forfeit" {
+		t.Fatalf("unexpected due payment: %+v (%v)", due, err)
+	}
+	listed, err := client.ListDueSettlements(ctx, 10)
+	if err != nil || len(listed) != 1 || listed[0].Settlement != "forfeit" {
+		t.Fatalf("unexpected due settlements: %+v (%v)", listed, err)
+	}
+
+	// Clearing an outcome that was replaced meanwhile keeps the new one.
+	if err := client.ClearSettlement(ctx, created.ID, "release"); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if listed, _ := client.ListDueSettlements(ctx, 10); len(listed) != 1 {
+		t.Fatalf("expected the settlement still due, got %+v", listed)
+	}
+	if err := client.ClearSettlement(ctx, created.ID, "forfeit"); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if listed, _ := client.ListDueSettlements(ctx, 10); len(listed) != 0 {
+		t.Fatalf("expected nothing due, got %+v", listed)
+	}
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"vesuvio/internal/dto/service"
+	"vesuvio/internal/model"
+)
+
+type GormReminderClient struct {
+	db *gorm.DB
+}
+
+func NewReminderClient(db *gorm.DB) *GormReminderClient {
+	return &GormReminderClient{db: db}
+}
+
+// ClaimReminder marks a reminder as being sent. It returns nil when the
+// reminder was already sent, is being sent by another instance or has
+// failed MaxAttempts times.
+func (c *GormReminderClient) ClaimReminder(ctx context.Context, params servicedto.ClaimReminderParams) (*servicedto.Reminder, error) {
+	rec := model.ReminderModel{
+		ReservationID: params.ReservationID,
+		OffsetMinutes: int(params.Offset / time.Minute),
+		Channel:       params.Channel,
+		Recipient:     params.Recipient,
+		Status:        servicedto.ReminderSending,
+		Attempts:      1,
+	}
+	err := c.db.WithContext(ctx).Create(&rec).Error
+	if err == nil {
+		return toServiceReminder(&rec), nil
+	}
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, err
+	}
+
+	// Retry a failed reminder, or one left sending by an instance that died
+	// before completing it; the conditional update stops two instances from
+	// retrying it at once.
+	scope := c.db.WithContext(ctx).Where("reservation_id = ? AND offset_minutes = ? AND channel = ?",
+		rec.ReservationID, rec.OffsetMinutes, rec.Channel)
+	retry := scope.Session(&gorm.Session{}).Model(&model.ReminderModel{}).
+		Where("attempts < ? AND (status = ? OR (status = ? AND updated_at < ?))",
+			params.MaxAttempts, servicedto.ReminderFailed, servicedto.ReminderSending, params.StaleBefore).
+		Updates(map[string]interface{}{
+			"status":    servicedto.ReminderSending,
+			"recipient": params.Recipient,
+			"attempts":  gorm.Expr("attempts + 1"),
+		})
+	if retry.Error != nil {
+		return nil, retry.Error
+	}
+	if retry.RowsAffected == 0 {
+		return nil, nil
+	}
+	if err := scope.Session(&gorm.Session{}).First(&rec).Error; err != nil {
+		return nil, err
+	}
+	return toServiceReminder(&rec), nil
+}
+
+// CompleteReminder records the outcome of sending a claimed reminder; a nil
+// sendErr means it was delivered.
+func (c *GormReminderClient) CompleteReminder(ctx context.Context, id uint, sendErr *string, at time.Time) error {
+	updates := map[string]interface{}{"status": servicedto.ReminderSent, "sent_at": at, "error": nil}
+	if sendErr != nil {
+		msg := *sendErr
+		if len(msg) > 255 {
+			msg = msg[:255]
+		}
+		updates = map[string]interface{}{"status": servicedto.ReminderFailed, "error": msg}
+	}
+	return c.db.WithContext(ctx).Model(&model.ReminderModel{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ListRemindersByReservation returns the reminder log of a reservation,
+// oldest first.
+func (c *GormReminderClient) ListRemindersByReservation(ctx context.Context, reservationID uint) ([]servicedto.Reminder, error) {
+	var models []model.ReminderModel
+	if err := c.db.WithContext(ctx).Where("reservation_id = ?", reservationID).Order("created_at, id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	reminders := make([]servicedto.Reminder, 0, len(models))
+	for _, m := range models {
+		reminders = append(reminders, *toServiceReminder(&m))
+	}
+	return reminders, nil
+}
+
+func toServiceReminder(m *model.ReminderModel) *servicedto.Reminder {
+	return &servicedto.Reminder{
+		ID:            m.ID,
+		ReservationID: m.ReservationID,
+		Offset:        time.Duration(m.OffsetMinutes) * time.Minute,
+		Channel:       m.Channel,
+		Recipient:     m.Recipient,
+		Status:        m.Status,
+		Attempts:      m.Attempts,
+		Error:         m.Error,
+		SentAt:        m.SentAt,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestReminderClient(t *testing.T) {
+	ctx := context.Background()
+	client := NewReminderClient(newTestDB(t))
+	params := servicedto.ClaimReminderParams{
+		ReservationID: 1, Offset: 3 * time.Hour, Channel: "sms", Recipient: "+391234567", MaxAttempts: 2,
+	}
+
+	first, err := client.ClaimReminder(ctx, params)
+	if err != nil || first == nil || first.Status != servicedto.ReminderSending || first.Offset != 3*time.Hour {
+		t.Fatalf("expected a claimed reminder, got %+v %v", first, err)
+	}
+	if again, err := client.ClaimReminder(ctx, params); err != nil || again != nil {
+		t.Fatalf("expected a reminder being sent not to be claimed twice, got %+v %v", again, err)
+	}
+
+	sendErr := "gateway down"
+	if err := client.CompleteReminder(ctx, first.ID, &sendErr, time.Now()); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	retry, err := client.ClaimReminder(ctx, params)
+	if err != nil || retry == nil || retry.ID != first.ID || retry.Attempts != 2 {
+		t.Fatalf("expected the failed reminder to be retried, got %+v %v", retry, err)
+	}
+	if err := client.CompleteReminder(ctx, retry.ID, &sendErr, time.Now()); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if exhausted, _ := client.ClaimReminder(ctx, params); exhausted != nil {
+		t.Fatalf("expected no retry after MaxAttempts, got %+v", exhausted)
+	}
+
+	params.Channel = "email"
+	email, _ := client.ClaimReminder(ctx, params)
+	at := time.Date(2030, 1, 10, 17, 0, 0, 0, time.UTC)
+	if err := client.CompleteReminder(ctx, email.ID, nil, at); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if sent, _ := client.ClaimReminder(ctx, params); sent != nil {
+		t.Fatalf("expected a sent reminder not to be claimed again, got %+v", sent)
+	}
+
+	// A reminder left sending by a crashed instance is retried once stale.
+	params.Channel = "push"
+	stuck, _ := client.ClaimReminder(ctx, params)
+	params.StaleBefore = stuck.UpdatedAt.Add(-time.Minute)
+	if again, _ := client.ClaimReminder(ctx, params); again != nil {
+		t.Fatalf("expected a reminder sending recently not to be claimed, got %+v", again)
+	}
+	params.StaleBefore = stuck.UpdatedAt.Add(time.Minute)
+	if reclaimed, err := client.ClaimReminder(ctx, params); err != nil || reclaimed == nil || reclaimed.ID != stuck.ID || reclaimed.Attempts != 2 {
+		t.Fatalf("expected the stale reminder to be reclaimed, got %+v %v", reclaimed, err)
+	}
+	if again, _ := client.ClaimReminder(ctx, params); again != nil {
+		t.Fatalf("expected no retry after MaxAttempts, got %+v", again)
+	}
+
+	log, err := client.ListRemindersByReservation(ctx, 1)
+	if err != nil || len(log) != 3 {
+		t.Fatalf("expected three reminders, got %+v %v", log, err)
+	}
+	if log[0].Status != servicedto.ReminderFailed || log[0].Error == nil || *log[0].Error != sendErr {
+		t.Fatalf("unexpected failed reminder: %+v", log[0])
+	}
+	if log[1].Status != servicedto.ReminderSent || log[1].SentAt == nil || !log[1].SentAt.Equal(at) || log[1].Error != nil {
+		t.Fatalf("unexpected sent reminder: %+v", log[1])
+	}
+}
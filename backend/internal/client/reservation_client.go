@@ -3,9 +3,12 @@ package client
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"vesuvio/internal/dto/service"
 	"vesuvio/internal/model"
@@ -21,23 +24,104 @@ func NewReservationClient(db *gorm.DB) *GormReservationClient {
 
 func (c *GormReservationClient) CreateReservation(ctx context.Context, params servicedto.CreateReservationParams) (*servicedto.Reservation, error) {
 	res := model.ReservationModel{
-		UserID:  params.UserID,
-		Date:    params.Date,
-		Time:    params.Time,
-		People:  params.People,
-		Comment: params.Comment,
-		Status:  params.Status,
+		UserID:        params.UserID,
+		Date:          params.Date,
+		Time:          params.Time,
+		People:        params.People,
+		TableID:       params.TableID,
+		Comment:       params.Comment,
+		Allergens:     params.Requirements.Allergens,
+		DietaryStyle:  params.Requirements.DietaryStyle,
+		Accessibility: params.Requirements.Accessibility,
+		HighChairs:    params.Requirements.HighChairs,
+		Status:        params.Status,
+		Channel:       params.Channel,
+		ReviewReason:  params.ReviewReason,
+		SeatedAt:      params.SeatedAt,
+	}
+	if params.Decision != nil {
+		res.DecisionRule = &params.Decision.Rule
+		res.DecisionNote = &params.Decision.Note
 	}
 
-	if err := c.db.WithContext(ctx).Create(&res).Error; err != nil {
-		return nil, err
+	// Codes are random, so retry on the rare collision with an existing one.
+	for attempt := 1; ; attempt++ {
+		code, err := newConfirmationCode()
+		if err != nil {
+			return nil, err
+		}
+		res.ID = 0
+		res.Code = code
+
+		err = c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if params.Overlap != nil {
+				if err := guardOverlap(tx, &res, *params.Overlap); err != nil {
+					return err
+				}
+			}
+			if err := tx.Create(&res).Error; err != nil {
+				return err
+			}
+			return recordEvent(tx, servicedto.DomainEventReservationCreated, &res, nil)
+		})
+		if errors.Is(err, gorm.ErrDuplicatedKey) && attempt < maxConfirmationCodeTries {
+			continue
+		}
+		if errors.Is(err, errOverlapRejected) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return toServiceReservation(&res, nil), nil
 	}
-	return toServiceReservation(&res, nil), nil
+}
+
+// errOverlapRejected rolls back a booking refused by its overlap guard.
+var errOverlapRejected = errors.New("overlapping reservation")
+
+// guardOverlap locks the guest and re-checks the overlap rule against their
+// bookings on the surrounding dates, so concurrent bookings by one guest are
+// checked one after the other. Under the flag policy an overlapping booking
+// is held for review instead of refused.
+func guardOverlap(tx *gorm.DB, res *model.ReservationModel, guard servicedto.OverlapGuard) error {
+	var user model.UserModel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, res.UserID).Error; err != nil {
+		return err
+	}
+
+	var models []model.ReservationModel
+	day := 24 * time.Hour
+	if err := tx.Where("user_id = ? AND date BETWEEN ? AND ?", res.UserID, res.Date.Add(-day), res.Date.Add(day)).
+		Find(&models).Error; err != nil {
+		return err
+	}
+	existing := make([]servicedto.Reservation, len(models))
+	for i := range models {
+		existing[i] = *toServiceReservation(&models[i], nil)
+	}
+
+	conflict := guard.Conflict(existing, res.Date, res.Time, 0)
+	if conflict == nil {
+		return nil
+	}
+	if guard.Reject {
+		return errOverlapRejected
+	}
+	if res.ReviewReason == nil {
+		reason := guard.Reason(*conflict)
+		res.ReviewReason = &reason
+	}
+	if res.Status == servicedto.StatusConfirmed {
+		rule, note := servicedto.RuleFlagged, "flagged for review"
+		res.Status, res.DecisionRule, res.DecisionNote = servicedto.StatusPending, &rule, &note
+	}
+	return nil
 }
 
 func (c *GormReservationClient) ListReservationsByUser(ctx context.Context, userID uint, status *string) ([]servicedto.Reservation, error) {
 	var models []model.ReservationModel
-	query := c.db.WithContext(ctx).Where("user_id = ?", userID)
+	query := c.db.WithContext(ctx).Preload("Payment").Where("user_id = ?", userID)
 	if status != nil {
 		query = query.Where("status = ?", *status)
 	}
@@ -47,6 +131,111 @@ func (c *GormReservationClient) ListReservationsByUser(ctx context.Context, user
 	return mapReservations(models, nil), nil
 }
 
+// ListReservationsByUsers returns every reservation of the given users with
+// their staff tags, ordered by slot.
+func (c *GormReservationClient) ListReservationsByUsers(ctx context.Context, userIDs []uint) ([]servicedto.Reservation, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var models []model.ReservationModel
+	err := c.db.WithContext(ctx).
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("tag") }).
+		Where("user_id IN ?", userIDs).
+		Order("date, time, id").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return mapReservations(models, nil), nil
+}
+
+// SummarizeGuests returns the guest summary of each reservation in the query,
+// keyed by reservation ID. Visits, no-shows and visit numbers are counted in
+// the database, so the cost does not grow with each guest's history.
+func (c *GormReservationClient) SummarizeGuests(ctx context.Context, q servicedto.GuestSummaryQuery) (map[uint]servicedto.GuestSummary, error) {
+	summaries := make(map[uint]servicedto.GuestSummary, len(q.ReservationIDs))
+	if len(q.ReservationIDs) == 0 {
+		return summaries, nil
+	}
+	db := c.db.WithContext(ctx)
+
+	var owners []struct{ ID, UserID uint }
+	if err := db.Model(&model.ReservationModel{}).Select("id, user_id").
+		Where("id IN ?", q.ReservationIDs).Scan(&owners).Error; err != nil {
+		return nil, err
+	}
+	userIDs := make([]uint, 0, len(owners))
+	for _, o := range owners {
+		userIDs = append(userIDs, o.UserID)
+	}
+
+	visit := `(v.status = ? OR (v.status = ? AND (v.date < ? OR (v.date = ? AND v.time < ?))))`
+	visitArgs := []any{servicedto.StatusSeated, servicedto.StatusConfirmed, q.Today, q.Today, q.Clock}
+
+	var stats []struct {
+		UserID  uint
+		Visits  int
+		NoShows int
+	}
+	if err := db.Table("reservation_models AS v").
+		Select("v.user_id AS user_id, SUM(CASE WHEN "+visit+" THEN 1 ELSE 0 END) AS visits, SUM(CASE WHEN v.status = ? THEN 1 ELSE 0 END) AS no_shows",
+			append(visitArgs, servicedto.StatusNoShow)...).
+		Where("v.user_id IN ?", userIDs).
+		Group("v.user_id").
+		Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	// Visits by the same guest with an earlier slot than each reservation.
+	var earlier []struct {
+		ID     uint
+		Visits int
+	}
+	if err := db.Table("reservation_models AS r").
+		Select("r.id AS id, COUNT(v.id) AS visits").
+		Joins(`JOIN reservation_models AS v ON v.user_id = r.user_id AND v.id <> r.id AND
+			(v.date < r.date OR (v.date = r.date AND (v.time < r.time OR (v.time = r.time AND v.id < r.id))))`).
+		Where("r.id IN ?", q.ReservationIDs).
+		Where(visit, visitArgs...).
+		Group("r.id").
+		Scan(&earlier).Error; err != nil {
+		return nil, err
+	}
+
+	var tags []struct {
+		UserID uint
+		Tag    string
+	}
+	if err := db.Table("reservation_tag_models AS t").
+		Select("DISTINCT r.user_id AS user_id, t.tag AS tag").
+		Joins("JOIN reservation_models AS r ON r.id = t.reservation_id").
+		Where("r.user_id IN ?", userIDs).
+		Order("t.tag").
+		Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[uint]servicedto.GuestSummary, len(stats))
+	for _, s := range stats {
+		byUser[s.UserID] = servicedto.GuestSummary{Visits: s.Visits, NoShows: s.NoShows}
+	}
+	for _, t := range tags {
+		g := byUser[t.UserID]
+		g.Tags = append(g.Tags, t.Tag)
+		byUser[t.UserID] = g
+	}
+	visitsBefore := make(map[uint]int, len(earlier))
+	for _, e := range earlier {
+		visitsBefore[e.ID] = e.Visits
+	}
+	for _, o := range owners {
+		g := byUser[o.UserID]
+		g.VisitNumber = visitsBefore[o.ID] + 1
+		summaries[o.ID] = g
+	}
+	return summaries, nil
+}
+
 func (c *GormReservationClient) GetReservationByID(ctx context.Context, id uint) (*servicedto.Reservation, error) {
 	var res model.ReservationModel
 	err := c.db.WithContext(ctx).First(&res, id).Error
@@ -59,14 +248,179 @@ func (c *GormReservationClient) GetReservationByID(ctx context.Context, id uint)
 	return toServiceReservation(&res, nil), nil
 }
 
-func (c *GormReservationClient) UpdateReservationStatus(ctx context.Context, id uint, status string) (*servicedto.Reservation, error) {
+func (c *GormReservationClient) GetReservationByCode(ctx context.Context, code string) (*servicedto.Reservation, error) {
+	var res model.ReservationModel
+	err := withStaffDetails(c.db.WithContext(ctx).Preload("User")).Where("code = ?", code).First(&res).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toServiceReservation(&res, toServiceUser(&res.User)), nil
+}
+
+// UpdateReservationStatus sets a reservation's status and records the change
+// in its history. It returns nil when the reservation does not exist or, if
+// params.From is set, is no longer in that status.
+func (c *GormReservationClient) UpdateReservationStatus(ctx context.Context, params servicedto.UpdateReservationStatusParams) (*servicedto.Reservation, error) {
+	var res model.ReservationModel
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if params.From != "" {
+			// Claim the row with a conditional update so only one caller
+			// sees the transition from the expected status.
+			claim := tx.Model(&model.ReservationModel{}).
+				Where("id = ? AND status = ?", params.ID, params.From).
+				Update("status", params.Status)
+			if claim.Error != nil {
+				return claim.Error
+			}
+			if claim.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		if err := tx.First(&res, params.ID).Error; err != nil {
+			return err
+		}
+		if params.From != "" {
+			res.Status = params.From
+		}
+		return changeStatus(tx, &res, params.Status, params.Actor)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toServiceReservation(&res, nil), nil
+}
+
+// UpdateReservationStatuses applies all changes in one transaction. It
+// returns nil without changing anything if any reservation is missing.
+func (c *GormReservationClient) UpdateReservationStatuses(ctx context.Context, changes []servicedto.UpdateReservationStatusParams) ([]servicedto.Reservation, error) {
+	updated := make([]servicedto.Reservation, 0, len(changes))
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, params := range changes {
+			var res model.ReservationModel
+			if err := tx.First(&res, params.ID).Error; err != nil {
+				return err
+			}
+			if err := changeStatus(tx, &res, params.Status, params.Actor); err != nil {
+				return err
+			}
+			updated = append(updated, *toServiceReservation(&res, nil))
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ModifyReservation stores changed booking details and records any status
+// change in the history. It returns nil if the reservation is missing or no
+// longer in status From.
+func (c *GormReservationClient) ModifyReservation(ctx context.Context, params servicedto.ModifyReservationParams) (*servicedto.Reservation, error) {
+	var res model.ReservationModel
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"date":          params.Date,
+			"time":          params.Time,
+			"people":        params.People,
+			"comment":       params.Comment,
+			"status":        params.Status,
+			"review_reason": params.ReviewReason,
+			"decision_rule": nil,
+			"decision_note": nil,
+		}
+		if params.Decision != nil {
+			updates["decision_rule"] = params.Decision.Rule
+			updates["decision_note"] = params.Decision.Note
+		}
+		claim := tx.Model(&model.ReservationModel{}).
+			Where("id = ? AND status = ?", params.ID, params.From).
+			Updates(updates)
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.First(&res, params.ID).Error; err != nil {
+			return err
+		}
+		res.Status = params.From
+		// One modified event covers any status change that came with it.
+		if err := saveStatus(tx, &res, params.Status, params.Actor); err != nil {
+			return err
+		}
+		return recordEvent(tx, servicedto.DomainEventReservationModified, &res, &params.From)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toServiceReservation(&res, nil), nil
+}
+
+// EscalateReservation flags a pending reservation for staff with the given
+// review reason. It returns false if the reservation is no longer pending
+// or was already escalated.
+func (c *GormReservationClient) EscalateReservation(ctx context.Context, id uint, reason string, at time.Time) (bool, error) {
+	return c.updateAndRecord(ctx, id, servicedto.DomainEventEscalated, servicedto.StatusPending,
+		"escalated_at IS NULL", map[string]interface{}{"review_reason": reason, "escalated_at": at})
+}
+
+// ConfirmAttendance records that the guest confirmed they are coming. It
+// returns false if the reservation is not confirmed.
+func (c *GormReservationClient) ConfirmAttendance(ctx context.Context, id uint, at time.Time) (bool, error) {
+	return c.updateAndRecord(ctx, id, servicedto.DomainEventAttendanceConfirmed, servicedto.StatusConfirmed,
+		"", map[string]interface{}{"attending_at": at})
+}
+
+// updateAndRecord applies updates to a reservation in status that also
+// meets cond, if set, and records eventType when it did.
+func (c *GormReservationClient) updateAndRecord(ctx context.Context, id uint, eventType, status, cond string, updates map[string]interface{}) (bool, error) {
+	updated := false
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		q := tx.Model(&model.ReservationModel{}).Where("id = ? AND status = ?", id, status)
+		if cond != "" {
+			q = q.Where(cond)
+		}
+		result := q.Updates(updates)
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+		updated = true
+		return recordEvent(tx, eventType, &model.ReservationModel{ID: id, Status: status}, nil)
+	})
+	if err != nil {
+		return false, err
+	}
+	return updated, nil
+}
+
+// SeatReservation marks the party as seated, optionally at the given table.
+func (c *GormReservationClient) SeatReservation(ctx context.Context, id uint, tableID *uint, at time.Time, actor servicedto.StatusActor) (*servicedto.Reservation, error) {
 	var res model.ReservationModel
 	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.First(&res, id).Error; err != nil {
 			return err
 		}
-		res.Status = status
-		return tx.Save(&res).Error
+		if tableID != nil {
+			res.TableID = tableID
+		}
+		if res.SeatedAt == nil {
+			res.SeatedAt = &at
+		}
+		return changeStatus(tx, &res, servicedto.StatusSeated, actor)
 	})
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
@@ -77,20 +431,281 @@ func (c *GormReservationClient) UpdateReservationStatus(ctx context.Context, id
 	return toServiceReservation(&res, nil), nil
 }
 
-func (c *GormReservationClient) ListReservationsByDate(ctx context.Context, date time.Time, status *string) ([]servicedto.Reservation, error) {
+// ListStatusChanges returns a reservation's status history, oldest first.
+func (c *GormReservationClient) ListStatusChanges(ctx context.Context, reservationID uint) ([]servicedto.StatusChange, error) {
+	return c.ListStatusChangesByReservations(ctx, []uint{reservationID})
+}
+
+// ListStatusChangesByReservations returns the status history of several
+// reservations, oldest first.
+func (c *GormReservationClient) ListStatusChangesByReservations(ctx context.Context, reservationIDs []uint) ([]servicedto.StatusChange, error) {
+	if len(reservationIDs) == 0 {
+		return nil, nil
+	}
+	var models []model.ReservationStatusChangeModel
+	if err := c.db.WithContext(ctx).Where("reservation_id IN ?", reservationIDs).Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	changes := make([]servicedto.StatusChange, 0, len(models))
+	for _, m := range models {
+		changes = append(changes, servicedto.StatusChange{
+			ID:            m.ID,
+			ReservationID: m.ReservationID,
+			FromStatus:    m.FromStatus,
+			ToStatus:      m.ToStatus,
+			ActorID:       m.ActorID,
+			Source:        m.Source,
+			Reason:        m.Reason,
+			CreatedAt:     m.CreatedAt,
+		})
+	}
+	return changes, nil
+}
+
+// AddStaffNote stores an internal note on a reservation.
+func (c *GormReservationClient) AddStaffNote(ctx context.Context, params servicedto.CreateStaffNoteParams) (*servicedto.StaffNote, error) {
+	note := model.ReservationNoteModel{
+		ReservationID: params.ReservationID,
+		AuthorID:      params.AuthorID,
+		Body:          params.Body,
+	}
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&note).Error; err != nil {
+			return err
+		}
+		if err := recordStaffEvent(tx, servicedto.DomainEventStaffNoteAdded, params.ReservationID); err != nil {
+			return err
+		}
+		if err := tx.First(&note.Author, note.AuthorID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := toServiceNote(note)
+	return &out, nil
+}
+
+// SetReservationTags replaces the tags of a reservation, recording an event
+// when they changed.
+func (c *GormReservationClient) SetReservationTags(ctx context.Context, reservationID uint, tags []string) error {
+	return c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []string
+		if err := tx.Model(&model.ReservationTagModel{}).Where("reservation_id = ?", reservationID).Pluck("tag", &existing).Error; err != nil {
+			return err
+		}
+		remove := tx.Where("reservation_id = ?", reservationID)
+		if len(tags) > 0 {
+			remove = remove.Where("tag NOT IN ?", tags)
+		}
+		result := remove.Delete(&model.ReservationTagModel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		changed := result.RowsAffected > 0
+		for _, tag := range tags {
+			if slices.Contains(existing, tag) {
+				continue
+			}
+			if err := tx.Create(&model.ReservationTagModel{ReservationID: reservationID, Tag: tag}).Error; err != nil {
+				return err
+			}
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return recordStaffEvent(tx, servicedto.DomainEventTagsChanged, reservationID)
+	})
+}
+
+// recordStaffEvent records a change to a reservation's staff details, which
+// leaves its status as it is.
+func recordStaffEvent(tx *gorm.DB, eventType string, reservationID uint) error {
+	var res model.ReservationModel
+	if err := tx.Select("id", "status").First(&res, reservationID).Error; err != nil {
+		return err
+	}
+	return recordEvent(tx, eventType, &res, nil)
+}
+
+// withStaffDetails preloads the staff-only tags and notes of reservations,
+// and their payment.
+func withStaffDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("tag") }).
+		Preload("Notes", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Notes.Author").
+		Preload("Payment")
+}
+
+func toServiceNote(m model.ReservationNoteModel) servicedto.StaffNote {
+	return servicedto.StaffNote{
+		ID:            m.ID,
+		ReservationID: m.ReservationID,
+		AuthorID:      m.AuthorID,
+		AuthorName:    m.Author.Name,
+		Body:          m.Body,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+// changeStatus saves res with the new status and, if the status actually
+// changed, appends a history entry and a status change event. It must run
+// inside a transaction.
+func changeStatus(tx *gorm.DB, res *model.ReservationModel, status string, actor servicedto.StatusActor) error {
+	from := res.Status
+	if err := saveStatus(tx, res, status, actor); err != nil || from == status {
+		return err
+	}
+	return recordEvent(tx, servicedto.DomainEventStatusChanged, res, &from)
+}
+
+// saveStatus is changeStatus without the event.
+func saveStatus(tx *gorm.DB, res *model.ReservationModel, status string, actor servicedto.StatusActor) error {
+	from := res.Status
+	res.Status = status
+	if err := tx.Save(res).Error; err != nil {
+		return err
+	}
+	if from == status {
+		return nil
+	}
+	return tx.Create(&model.ReservationStatusChangeModel{
+		ReservationID: res.ID,
+		FromStatus:    from,
+		ToStatus:      status,
+		ActorID:       actor.UserID,
+		Source:        actor.Source,
+		Reason:        actor.Reason,
+	}).Error
+}
+
+// QueryReservations returns one page of reservations matching the query,
+// ordered by the sort key and ID, plus the total number of matches.
+// Pagination is keyset-based: the next page starts after the cursor row.
+func (c *GormReservationClient) QueryReservations(ctx context.Context, q servicedto.ReservationQuery) (*servicedto.ReservationPage, error) {
+	filtered := c.db.WithContext(ctx).Model(&model.ReservationModel{})
+	if q.From != nil {
+		filtered = filtered.Where("date >= ?", *q.From)
+	}
+	if q.To != nil {
+		filtered = filtered.Where("date <= ?", *q.To)
+	}
+	if len(q.Statuses) > 0 {
+		filtered = filtered.Where("status IN ?", q.Statuses)
+	}
+	if len(q.Tags) > 0 {
+		filtered = filtered.Where("id IN (?)",
+			c.db.Model(&model.ReservationTagModel{}).Select("reservation_id").Where("tag IN ?", q.Tags))
+	}
+	if q.MinPeople > 0 {
+		filtered = filtered.Where("people >= ?", q.MinPeople)
+	}
+	if q.MaxPeople > 0 {
+		filtered = filtered.Where("people <= ?", q.MaxPeople)
+	}
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	dir, cmp := "ASC", ">"
+	if q.Descending {
+		dir, cmp = "DESC", "<"
+	}
+	page := withStaffDetails(filtered.Session(&gorm.Session{}).Preload("User"))
+	switch q.Sort {
+	case servicedto.SortByCreatedAt:
+		if q.After != nil {
+			page = page.Where("created_at "+cmp+" ? OR (created_at = ? AND id "+cmp+" ?)",
+				q.After.CreatedAt, q.After.CreatedAt, q.After.ID)
+		}
+		page = page.Order("created_at " + dir).Order("id " + dir)
+	case servicedto.SortByPeople:
+		if q.After != nil {
+			page = page.Where("people "+cmp+" ? OR (people = ? AND id "+cmp+" ?)",
+				q.After.People, q.After.People, q.After.ID)
+		}
+		page = page.Order("people " + dir).Order("id " + dir)
+	default:
+		if q.After != nil {
+			page = page.Where("date "+cmp+" ? OR (date = ? AND time "+cmp+" ?) OR (date = ? AND time = ? AND id "+cmp+" ?)",
+				q.After.Date, q.After.Date, q.After.Time, q.After.Date, q.After.Time, q.After.ID)
+		}
+		page = page.Order("date " + dir).Order("time " + dir).Order("id " + dir)
+	}
+	// Fetch one extra row to learn whether another page follows.
+	if q.Limit > 0 {
+		page = page.Limit(q.Limit + 1)
+	}
+
 	var models []model.ReservationModel
-	query := c.db.WithContext(ctx).Preload("User").Where("date = ?", date)
-	if status != nil {
-		query = query.Where("status = ?", *status)
+	if err := page.Find(&models).Error; err != nil {
+		return nil, err
 	}
-	if err := query.Order("time").Find(&models).Error; err != nil {
+
+	result := &servicedto.ReservationPage{Total: total}
+	if q.Limit > 0 && len(models) > q.Limit {
+		models = models[:q.Limit]
+		last := models[len(models)-1]
+		result.Next = &servicedto.ReservationCursor{
+			Date:      last.Date,
+			Time:      last.Time,
+			CreatedAt: last.CreatedAt,
+			People:    last.People,
+			ID:        last.ID,
+		}
+	}
+	result.Reservations = mapReservations(models, func(m model.ReservationModel) *servicedto.User {
+		return toServiceUser(&m.User)
+	})
+	return result, nil
+}
+
+// SearchReservations finds reservations whose guest name, email or phone,
+// confirmation code or comment contains the search term, ignoring case.
+// Upcoming and past matches are fetched separately so the rows nearest to
+// Around are kept; ranking is left to the caller.
+func (c *GormReservationClient) SearchReservations(ctx context.Context, q servicedto.ReservationSearch) ([]servicedto.Reservation, error) {
+	pattern := "%" + escapeLike(q.Term) + "%"
+	match := c.db.Where(`LOWER("User".name) LIKE ? ESCAPE '\'`, pattern).
+		Or(`LOWER("User".email) LIKE ? ESCAPE '\'`, pattern).
+		Or(`LOWER(reservation_models.code) LIKE ? ESCAPE '\'`, pattern).
+		Or(`LOWER(reservation_models.comment) LIKE ? ESCAPE '\'`, pattern)
+	if q.PhoneDigits != "" {
+		match = match.Or(`"User".phone LIKE ? ESCAPE '\'`, "%"+escapeLike(q.PhoneDigits)+"%")
+	}
+
+	base := withStaffDetails(c.db.WithContext(ctx).Joins("User")).Where(match)
+	var upcoming, past []model.ReservationModel
+	if err := base.Session(&gorm.Session{}).
+		Where("reservation_models.date >= ?", q.Around).
+		Order("reservation_models.date ASC").Order("reservation_models.time ASC").
+		Limit(q.Limit).Find(&upcoming).Error; err != nil {
+		return nil, err
+	}
+	if err := base.Session(&gorm.Session{}).
+		Where("reservation_models.date < ?", q.Around).
+		Order("reservation_models.date DESC").Order("reservation_models.time DESC").
+		Limit(q.Limit).Find(&past).Error; err != nil {
 		return nil, err
 	}
-	return mapReservations(models, func(m model.ReservationModel) *servicedto.User {
+
+	return mapReservations(append(upcoming, past...), func(m model.ReservationModel) *servicedto.User {
 		return toServiceUser(&m.User)
 	}), nil
 }
 
+// escapeLike escapes the LIKE wildcards in s, using backslash as the escape
+// character.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
 func mapReservations(models []model.ReservationModel, userMapper func(model.ReservationModel) *servicedto.User) []servicedto.Reservation {
 	reservations := make([]servicedto.Reservation, 0, len(models))
 	for _, m := range models {
@@ -105,15 +720,65 @@ func mapReservations(models []model.ReservationModel, userMapper func(model.Rese
 
 func toServiceReservation(m *model.ReservationModel, user *servicedto.User) *servicedto.Reservation {
 	return &servicedto.Reservation{
-		ID:        m.ID,
-		UserID:    m.UserID,
-		User:      user,
-		Date:      m.Date,
-		Time:      m.Time,
-		People:    m.People,
-		Comment:   m.Comment,
-		Status:    m.Status,
+		ID:           m.ID,
+		Code:         m.Code,
+		UserID:       m.UserID,
+		User:         user,
+		Date:         m.Date,
+		Time:         m.Time,
+		People:       m.People,
+		TableID:      m.TableID,
+		Comment:      m.Comment,
+		Status:       m.Status,
+		Channel:      m.Channel,
+		ReviewReason: m.ReviewReason,
+		Decision:     toServiceDecision(m),
+		EscalatedAt:  m.EscalatedAt,
+		AttendingAt:  m.AttendingAt,
+		SeatedAt:     m.SeatedAt,
+		Requirements: servicedto.Requirements{
+			Allergens:     m.Allergens,
+			DietaryStyle:  m.DietaryStyle,
+			Accessibility: m.Accessibility,
+			HighChairs:    m.HighChairs,
+		},
+		Tags:      toServiceTags(m.Tags),
+		Notes:     toServiceNotes(m.Notes),
+		Payment:   toServicePayment(m.Payment),
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
 	}
 }
+
+func toServiceDecision(m *model.ReservationModel) *servicedto.Decision {
+	if m.DecisionRule == nil {
+		return nil
+	}
+	d := servicedto.Decision{Rule: *m.DecisionRule}
+	if m.DecisionNote != nil {
+		d.Note = *m.DecisionNote
+	}
+	return &d
+}
+
+func toServiceTags(models []model.ReservationTagModel) []string {
+	if len(models) == 0 {
+		return nil
+	}
+	tags := make([]string, 0, len(models))
+	for _, m := range models {
+		tags = append(tags, m.Tag)
+	}
+	return tags
+}
+
+func toServiceNotes(models []model.ReservationNoteModel) []servicedto.StaffNote {
+	if len(models) == 0 {
+		return nil
+	}
+	notes := make([]servicedto.StaffNote, 0, len(models))
+	for _, m := range models {
+		notes = append(notes, toServiceNote(m))
+	}
+	return notes
+}
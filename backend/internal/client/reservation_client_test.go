@@ -3,12 +3,14 @@ package client
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"gorm.io/driver/sqlite"
 
 	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/model"
 )
 
 func newReservationTestClient(t *testing.T) *GormReservationClient {
@@ -49,7 +51,11 @@ func TestReservationClient_CRUD(t *testing.T) {
 		t.Fatalf("unexpected reservation by id: %+v", byID)
 	}
 
-	updated, err := client.UpdateReservationStatus(ctx, created.ID, servicedto.StatusConfirmed)
+	updated, err := client.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{
+		ID:     created.ID,
+		Status: servicedto.StatusConfirmed,
+		Actor:  servicedto.StatusActor{Source: servicedto.StatusSourceAdmin},
+	})
 	if err != nil {
 		t.Fatalf("update status: %v", err)
 	}
@@ -66,6 +72,356 @@ func TestReservationClient_CRUD(t *testing.T) {
 	}
 }
 
+func TestReservationClient_StatusHistory(t *testing.T) {
+	ctx := context.Background()
+	client := newReservationTestClient(t)
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	first, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	second, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "21:00", People: 2, Status: servicedto.StatusPending,
+	})
+
+	adminID := uint(7)
+	reason := "kitchen closed"
+	actor := servicedto.StatusActor{UserID: &adminID, Source: servicedto.StatusSourceAdminBulk, Reason: &reason}
+	updated, err := client.UpdateReservationStatuses(ctx, []servicedto.UpdateReservationStatusParams{
+		{ID: first.ID, Status: servicedto.StatusCancelled, Actor: actor},
+		{ID: second.ID, Status: servicedto.StatusCancelled, Actor: actor},
+	})
+	if err != nil || len(updated) != 2 {
+		t.Fatalf("bulk update: %+v (%v)", updated, err)
+	}
+
+	// A missing reservation rolls back the whole batch.
+	updated, err = client.UpdateReservationStatuses(ctx, []servicedto.UpdateReservationStatusParams{
+		{ID: first.ID, Status: servicedto.StatusConfirmed, Actor: actor},
+		{ID: 999, Status: servicedto.StatusConfirmed, Actor: actor},
+	})
+	if err != nil || updated != nil {
+		t.Fatalf("expected nil result for missing reservation, got %+v (%v)", updated, err)
+	}
+	if res, _ := client.GetReservationByID(ctx, first.ID); res.Status != servicedto.StatusCancelled {
+		t.Fatalf("expected rollback to keep cancelled, got %s", res.Status)
+	}
+
+	// Setting the same status again is not a change.
+	if _, err := client.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{
+		ID: first.ID, Status: servicedto.StatusCancelled, Actor: actor,
+	}); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+
+	history, err := client.ListStatusChanges(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("list history: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("expected one history entry, got %+v", history)
+	}
+	h := history[0]
+	if h.FromStatus != servicedto.StatusPending || h.ToStatus != servicedto.StatusCancelled ||
+		h.ActorID == nil || *h.ActorID != adminID || h.Source != servicedto.StatusSourceAdminBulk ||
+		h.Reason == nil || *h.Reason != reason {
+		t.Fatalf("unexpected history entry: %+v", h)
+	}
+}
+
+func TestReservationClient_NotesAndTags(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	client := NewReservationClient(db)
+	host, err := NewUserClient(db).CreateUser(ctx, servicedto.CreateUserParams{Name: "Host", Email: "host@example.com", PasswordHash: "x", IsAdmin: true})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	tagged, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: host.ID, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	_, _ = client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: host.ID, Date: date, Time: "21:00", People: 2, Status: servicedto.StatusPending,
+	})
+
+	note, err := client.AddStaffNote(ctx, servicedto.CreateStaffNoteParams{ReservationID: tagged.ID, AuthorID: host.ID, Body: "VIP, seat away from door"})
+	if err != nil {
+		t.Fatalf("add note: %v", err)
+	}
+	if note.ID == 0 || note.AuthorName != "Host" {
+		t.Fatalf("unexpected note: %+v", note)
+	}
+
+	if err := client.SetReservationTags(ctx, tagged.ID, []string{servicedto.TagVIP, servicedto.TagBirthday}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+	if err := client.SetReservationTags(ctx, tagged.ID, []string{servicedto.TagVIP, servicedto.TagAllergy}); err != nil {
+		t.Fatalf("replace tags: %v", err)
+	}
+
+	page, err := client.QueryReservations(ctx, servicedto.ReservationQuery{From: &date, To: &date, Tags: []string{servicedto.TagVIP}})
+	if err != nil {
+		t.Fatalf("query by tag: %v", err)
+	}
+	if page.Total != 1 || len(page.Reservations) != 1 {
+		t.Fatalf("expected one tagged reservation, got %+v", page)
+	}
+	got := page.Reservations[0]
+	if fmt.Sprint(got.Tags) != "[allergy vip]" {
+		t.Fatalf("unexpected tags: %v", got.Tags)
+	}
+	if len(got.Notes) != 1 || got.Notes[0].Body != note.Body || got.Notes[0].AuthorName != "Host" {
+		t.Fatalf("unexpected notes: %+v", got.Notes)
+	}
+
+	if err := client.SetReservationTags(ctx, tagged.ID, nil); err != nil {
+		t.Fatalf("clear tags: %v", err)
+	}
+	byCode, _ := client.GetReservationByCode(ctx, tagged.Code)
+	if len(byCode.Tags) != 0 || len(byCode.Notes) != 1 {
+		t.Fatalf("unexpected staff details after clearing tags: %+v", byCode)
+	}
+}
+
+func TestReservationClient_GuestHistory(t *testing.T) {
+	ctx := context.Background()
+	client := newReservationTestClient(t)
+	day := func(d int) time.Time { return time.Date(2025, 12, d, 0, 0, 0, 0, time.UTC) }
+
+	later, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: day(5), Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	earlier, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: day(1), Time: "21:00", People: 4, Status: servicedto.StatusSeated,
+	})
+	other, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 2, Date: day(1), Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	_, _ = client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 3, Date: day(1), Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	if err := client.SetReservationTags(ctx, earlier.ID, []string{servicedto.TagRegular}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+
+	list, err := client.ListReservationsByUsers(ctx, []uint{1, 2})
+	if err != nil {
+		t.Fatalf("list by users: %v", err)
+	}
+	if len(list) != 3 || list[0].ID != other.ID || list[1].ID != earlier.ID || list[2].ID != later.ID {
+		t.Fatalf("expected reservations of users 1 and 2 in slot order, got %+v", list)
+	}
+	if fmt.Sprint(list[1].Tags) != "[regular]" {
+		t.Fatalf("expected tags preloaded, got %v", list[1].Tags)
+	}
+
+	actor := servicedto.StatusActor{Source: servicedto.StatusSourceGuest}
+	for _, id := range []uint{later.ID, other.ID} {
+		if _, err := client.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{ID: id, Status: servicedto.StatusCancelled, Actor: actor}); err != nil {
+			t.Fatalf("update status: %v", err)
+		}
+	}
+	changes, err := client.ListStatusChangesByReservations(ctx, []uint{later.ID})
+	if err != nil {
+		t.Fatalf("list changes: %v", err)
+	}
+	if len(changes) != 1 || changes[0].ReservationID != later.ID || changes[0].ToStatus != servicedto.StatusCancelled {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestReservationClient_SummarizeGuests(t *testing.T) {
+	ctx := context.Background()
+	client := newReservationTestClient(t)
+	day := func(d int) time.Time { return time.Date(2025, 12, d, 0, 0, 0, 0, time.UTC) }
+	create := func(userID uint, date time.Time, hhmm, status string) servicedto.Reservation {
+		t.Helper()
+		res, err := client.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: userID, Date: date, Time: hhmm, People: 2, Status: status})
+		if err != nil {
+			t.Fatalf("create reservation: %v", err)
+		}
+		return *res
+	}
+	first := create(1, day(1), "20:00", servicedto.StatusSeated)
+	create(1, day(3), "20:00", servicedto.StatusNoShow)
+	second := create(1, day(5), "19:00", servicedto.StatusConfirmed)  // started before the clock
+	tonight := create(1, day(5), "21:00", servicedto.StatusConfirmed) // not yet started
+	create(1, day(6), "20:00", servicedto.StatusCancelled)
+	newcomer := create(2, day(5), "20:00", servicedto.StatusPending)
+	if err := client.SetReservationTags(ctx, first.ID, []string{servicedto.TagVIP, servicedto.TagAllergy}); err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+
+	summaries, err := client.SummarizeGuests(ctx, servicedto.GuestSummaryQuery{
+		ReservationIDs: []uint{first.ID, second.ID, tonight.ID, newcomer.ID},
+		Today:          day(5),
+		Clock:          "20:30",
+	})
+	if err != nil {
+		t.Fatalf("summarize guests: %v", err)
+	}
+	for id, want := range map[uint]servicedto.GuestSummary{
+		first.ID:    {Visits: 2, NoShows: 1, VisitNumber: 1, Tags: []string{servicedto.TagAllergy, servicedto.TagVIP}},
+		second.ID:   {Visits: 2, NoShows: 1, VisitNumber: 2, Tags: []string{servicedto.TagAllergy, servicedto.TagVIP}},
+		tonight.ID:  {Visits: 2, NoShows: 1, VisitNumber: 3, Tags: []string{servicedto.TagAllergy, servicedto.TagVIP}},
+		newcomer.ID: {VisitNumber: 1},
+	} {
+		got := summaries[id]
+		if got.Visits != want.Visits || got.NoShows != want.NoShows || got.VisitNumber != want.VisitNumber || fmt.Sprint(got.Tags) != fmt.Sprint(want.Tags) {
+			t.Fatalf("reservation %d: expected %+v, got %+v", id, want, got)
+		}
+	}
+
+	if empty, err := client.SummarizeGuests(ctx, servicedto.GuestSummaryQuery{}); err != nil || len(empty) != 0 {
+		t.Fatalf("expected no summaries, got %+v (%v)", empty, err)
+	}
+}
+
+func TestReservationClient_Requirements(t *testing.T) {
+	ctx := context.Background()
+	client := newReservationTestClient(t)
+	vegan := "vegan"
+
+	created, err := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 3, Status: servicedto.StatusPending,
+		Requirements: servicedto.Requirements{
+			Allergens:     []string{"gluten", "nuts"},
+			DietaryStyle:  &vegan,
+			Accessibility: []string{"wheelchair"},
+			HighChairs:    1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("create reservation: %v", err)
+	}
+
+	got, err := client.GetReservationByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get reservation: %v", err)
+	}
+	req := got.Requirements
+	if fmt.Sprint(req.Allergens) != "[gluten nuts]" || req.DietaryStyle == nil || *req.DietaryStyle != vegan ||
+		fmt.Sprint(req.Accessibility) != "[wheelchair]" || req.HighChairs != 1 {
+		t.Fatalf("requirements did not round-trip: %+v", req)
+	}
+}
+
+func TestReservationClient_Decision(t *testing.T) {
+	ctx := context.Background()
+	client := newReservationTestClient(t)
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	decided, err := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+		Decision: &servicedto.Decision{Rule: servicedto.RuleAutoConfirm, Note: "party of 2"},
+	})
+	if err != nil {
+		t.Fatalf("create reservation: %v", err)
+	}
+	undecided, err := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "22:00", People: 2, Status: servicedto.StatusPending,
+	})
+	if err != nil {
+		t.Fatalf("create reservation: %v", err)
+	}
+
+	got, _ := client.GetReservationByID(ctx, decided.ID)
+	if got.Decision == nil || *got.Decision != (servicedto.Decision{Rule: servicedto.RuleAutoConfirm, Note: "party of 2"}) {
+		t.Fatalf("decision did not round-trip: %+v", got.Decision)
+	}
+	if got, _ := client.GetReservationByID(ctx, undecided.ID); got.Decision != nil {
+		t.Fatalf("expected no decision, got %+v", got.Decision)
+	}
+}
+
+func TestReservationClient_ExpiryTransitions(t *testing.T) {
+	ctx := context.Background()
+	client := newReservationTestClient(t)
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	res, err := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	if err != nil {
+		t.Fatalf("create reservation: %v", err)
+	}
+
+	at := time.Date(2025, 11, 30, 12, 0, 0, 0, time.UTC)
+	if ok, err := client.EscalateReservation(ctx, res.ID, "not reviewed in time", at); err != nil || !ok {
+		t.Fatalf("expected escalation, got %t %v", ok, err)
+	}
+	if ok, _ := client.EscalateReservation(ctx, res.ID, "again", at); ok {
+		t.Fatal("expected a reservation to be escalated only once")
+	}
+	got, _ := client.GetReservationByID(ctx, res.ID)
+	if got.EscalatedAt == nil || !got.EscalatedAt.Equal(at) || got.ReviewReason == nil || *got.ReviewReason != "not reviewed in time" {
+		t.Fatalf("unexpected escalated reservation: %+v", got)
+	}
+
+	reason := "expired"
+	cancel := servicedto.UpdateReservationStatusParams{
+		ID: res.ID, Status: servicedto.StatusCancelled, From: servicedto.StatusPending,
+		Actor: servicedto.StatusActor{Source: servicedto.StatusSourceSystem, Reason: &reason},
+	}
+	updated, err := client.UpdateReservationStatus(ctx, cancel)
+	if err != nil || updated == nil || updated.Status != servicedto.StatusCancelled {
+		t.Fatalf("expected conditional cancel, got %+v %v", updated, err)
+	}
+	if updated, err := client.UpdateReservationStatus(ctx, cancel); err != nil || updated != nil {
+		t.Fatalf("expected no change once the status moved on, got %+v %v", updated, err)
+	}
+	history, _ := client.ListStatusChanges(ctx, res.ID)
+	if len(history) != 1 || history[0].FromStatus != servicedto.StatusPending || history[0].Reason == nil {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+}
+
+func TestReservationClient_OverlapGuard(t *testing.T) {
+	ctx := context.Background()
+	client := newReservationTestClient(t)
+	user := model.UserModel{Name: "Ana", Email: "ana@example.com"}
+	if err := client.db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	reject := &servicedto.OverlapGuard{Window: 2 * time.Hour, Reject: true}
+	flag := &servicedto.OverlapGuard{Window: 2 * time.Hour}
+
+	if _, err := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: user.ID, Date: date, Time: "23:00", People: 2, Status: servicedto.StatusConfirmed, Overlap: reject,
+	}); err != nil {
+		t.Fatalf("create reservation: %v", err)
+	}
+
+	// The guard sees bookings on the previous date across midnight.
+	next := date.AddDate(0, 0, 1)
+	res, err := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: user.ID, Date: next, Time: "00:30", People: 2, Status: servicedto.StatusConfirmed, Overlap: reject,
+	})
+	if err != nil || res != nil {
+		t.Fatalf("expected the overlapping booking to be refused, got %+v (%v)", res, err)
+	}
+	var count int64
+	client.db.Model(&model.ReservationModel{}).Count(&count)
+	if count != 1 {
+		t.Fatalf("expected the refused booking to be rolled back, got %d rows", count)
+	}
+
+	res, err = client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: user.ID, Date: next, Time: "00:30", People: 2, Status: servicedto.StatusConfirmed, Overlap: flag,
+	})
+	if err != nil {
+		t.Fatalf("create reservation: %v", err)
+	}
+	if res.Status != servicedto.StatusPending || res.ReviewReason == nil || !strings.Contains(*res.ReviewReason, "23:00") {
+		t.Fatalf("expected the overlapping booking to be held for review, got %+v", res)
+	}
+	if res.Decision == nil || res.Decision.Rule != servicedto.RuleFlagged {
+		t.Fatalf("expected the flagged rule to be recorded, got %+v", res.Decision)
+	}
+}
+
 func TestReservationClient_Listing(t *testing.T) {
 	ctx := context.Background()
 	client := newReservationTestClient(t)
@@ -91,12 +447,243 @@ func TestReservationClient_Listing(t *testing.T) {
 		t.Fatalf("unexpected user list: %+v", userList)
 	}
 
-	dateStatus := servicedto.StatusPending
-	dateList, err := client.ListReservationsByDate(ctx, date, &dateStatus)
+	datePage, err := client.QueryReservations(ctx, servicedto.ReservationQuery{
+		From:     &date,
+		To:       &date,
+		Statuses: []string{servicedto.StatusPending},
+	})
 	if err != nil {
 		t.Fatalf("list by date: %v", err)
 	}
-	if len(dateList) != 1 || dateList[0].Status != servicedto.StatusPending {
-		t.Fatalf("unexpected date list: %+v", dateList)
+	if len(datePage.Reservations) != 1 || datePage.Reservations[0].Status != servicedto.StatusPending || datePage.Total != 1 {
+		t.Fatalf("unexpected date list: %+v", datePage)
+	}
+}
+
+func TestReservationClient_QueryReservationsPaging(t *testing.T) {
+	ctx := context.Background()
+	client := newReservationTestClient(t)
+	from := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 3, 0, 0, 0, 0, time.UTC)
+
+	for _, r := range []struct {
+		day    int
+		time   string
+		people int
+	}{{2, "19:00", 4}, {1, "21:00", 2}, {1, "19:30", 6}, {3, "20:00", 2}, {4, "20:00", 8}} {
+		_, err := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: 1,
+			Date:   time.Date(2025, 12, r.day, 0, 0, 0, 0, time.UTC),
+			Time:   r.time,
+			People: r.people,
+			Status: servicedto.StatusPending,
+		})
+		if err != nil {
+			t.Fatalf("create reservation: %v", err)
+		}
+	}
+
+	var got []string
+	query := servicedto.ReservationQuery{From: &from, To: &to, Limit: 2}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("pagination did not terminate")
+		}
+		page, err := client.QueryReservations(ctx, query)
+		if err != nil {
+			t.Fatalf("query reservations: %v", err)
+		}
+		if page.Total != 4 {
+			t.Fatalf("expected total 4, got %d", page.Total)
+		}
+		for _, r := range page.Reservations {
+			got = append(got, fmt.Sprintf("%d %s", r.Date.Day(), r.Time))
+		}
+		if page.Next == nil {
+			break
+		}
+		query.After = page.Next
+	}
+	want := []string{"1 19:30", "1 21:00", "2 19:00", "3 20:00"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	page, err := client.QueryReservations(ctx, servicedto.ReservationQuery{
+		From:       &from,
+		Sort:       servicedto.SortByPeople,
+		Descending: true,
+		MinPeople:  3,
+	})
+	if err != nil {
+		t.Fatalf("query by people: %v", err)
+	}
+	if len(page.Reservations) != 3 || page.Reservations[0].People != 8 || page.Reservations[2].People != 4 {
+		t.Fatalf("unexpected people ordering: %+v", page.Reservations)
+	}
+}
+
+func TestReservationClient_SearchReservations(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	users := NewUserClient(db)
+	client := NewReservationClient(db)
+
+	phone := "+34911222333"
+	garcia, err := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Maria García", Email: "M.Garcia@Example.com", PasswordHash: "x", Phone: &phone})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	other, err := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Luca Bianchi", Email: "luca@example.com", PasswordHash: "x"})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	today := time.Date(2025, 12, 10, 0, 0, 0, 0, time.UTC)
+	comment := "100% gluten free, friend of the garcias"
+	for _, r := range []struct {
+		user    uint
+		day     int
+		comment *string
+	}{{garcia.ID, 12, nil}, {garcia.ID, 2, nil}, {other.ID, 10, &comment}, {other.ID, 11, nil}} {
+		_, err := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID:  r.user,
+			Date:    time.Date(2025, 12, r.day, 0, 0, 0, 0, time.UTC),
+			Time:    "20:00",
+			People:  2,
+			Comment: r.comment,
+			Status:  servicedto.StatusPending,
+		})
+		if err != nil {
+			t.Fatalf("create reservation: %v", err)
+		}
+	}
+
+	found, err := client.SearchReservations(ctx, servicedto.ReservationSearch{Term: "garcia", Around: today, Limit: 10})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(found) != 3 {
+		t.Fatalf("expected 3 matches by email and comment, got %+v", found)
+	}
+	if found[0].Date.Day() != 10 || found[1].Date.Day() != 12 || found[2].Date.Day() != 2 {
+		t.Fatalf("expected upcoming matches then past ones, got %+v", found)
+	}
+	if found[1].User == nil || found[1].User.ID != garcia.ID {
+		t.Fatalf("expected joined user on result, got %+v", found[1].User)
+	}
+
+	found, err = client.SearchReservations(ctx, servicedto.ReservationSearch{Term: "100%", Around: today, Limit: 10})
+	if err != nil || len(found) != 1 {
+		t.Fatalf("expected literal %% match only, got %+v (%v)", found, err)
+	}
+	found, err = client.SearchReservations(ctx, servicedto.ReservationSearch{Term: "0%", Around: today, Limit: 10})
+	if err != nil || len(found) != 1 {
+		t.Fatalf("expected wildcard to be escaped, got %+v (%v)", found, err)
+	}
+
+	found, err = client.SearchReservations(ctx, servicedto.ReservationSearch{Term: "911222", PhoneDigits: "911222", Around: today, Limit: 1})
+	if err != nil || len(found) != 2 {
+		t.Fatalf("expected one upcoming and one past phone match, got %+v (%v)", found, err)
+	}
+}
+
+func TestReservationClient_ConfirmationCode(t *testing.T) {
+	ctx := context.Background()
+	client := newReservationTestClient(t)
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	first, err := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	if err != nil {
+		t.Fatalf("create reservation: %v", err)
+	}
+	second, err := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "21:00", People: 2, Status: servicedto.StatusPending,
+	})
+	if err != nil {
+		t.Fatalf("create reservation: %v", err)
+	}
+	if !strings.HasPrefix(first.Code, servicedto.ConfirmationCodePrefix) || len(first.Code) != len(servicedto.ConfirmationCodePrefix)+confirmationCodeLength {
+		t.Fatalf("unexpected confirmation code: %q", first.Code)
+	}
+	if first.Code == second.Code {
+		t.Fatalf("expected distinct codes, both were %q", first.Code)
+	}
+
+	byCode, err := client.GetReservationByCode(ctx, second.Code)
+	if err != nil {
+		t.Fatalf("get by code: %v", err)
+	}
+	if byCode == nil || byCode.ID != second.ID {
+		t.Fatalf("unexpected reservation by code: %+v", byCode)
+	}
+
+	none, err := client.GetReservationByCode(ctx, "VSV-NOPE00")
+	if err != nil {
+		t.Fatalf("unexpected error for missing code: %v", err)
+	}
+	if none != nil {
+		t.Fatalf("expected nil for missing code, got %+v", none)
+	}
+}
+
+func TestReservationClient_ConfirmAttendance(t *testing.T) {
+	ctx := context.Background()
+	client := newReservationTestClient(t)
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	confirmed, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+	pending, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+
+	at := time.Date(2025, 11, 30, 12, 0, 0, 0, time.UTC)
+	if ok, err := client.ConfirmAttendance(ctx, confirmed.ID, at); err != nil || !ok {
+		t.Fatalf("expected attendance confirmed, got %t %v", ok, err)
+	}
+	if got, _ := client.GetReservationByID(ctx, confirmed.ID); got.AttendingAt == nil || !got.AttendingAt.Equal(at) {
+		t.Fatalf("unexpected attending_at: %+v", got.AttendingAt)
+	}
+	if ok, err := client.ConfirmAttendance(ctx, pending.ID, at); err != nil || ok {
+		t.Fatalf("expected a pending booking to be refused, got %t %v", ok, err)
+	}
+}
+
+func TestReservationClient_ModifyReservation(t *testing.T) {
+	ctx := context.Background()
+	client := newReservationTestClient(t)
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+		Decision: &servicedto.Decision{Rule: servicedto.RuleAutoConfirm, Note: "party of 2"},
+	})
+
+	userID := uint(1)
+	comment := "window seat"
+	params := servicedto.ModifyReservationParams{
+		ID: res.ID, From: servicedto.StatusConfirmed, Date: date.AddDate(0, 0, 1), Time: "21:00", People: 6,
+		Comment: &comment, Status: servicedto.StatusPending,
+		Decision: &servicedto.Decision{Rule: servicedto.RulePartySize, Note: "party of 6 is over 4"},
+		Actor:    servicedto.StatusActor{UserID: &userID, Source: servicedto.StatusSourceGuest},
+	}
+	updated, err := client.ModifyReservation(ctx, params)
+	if err != nil || updated == nil {
+		t.Fatalf("modify: %+v %v", updated, err)
+	}
+	got, _ := client.GetReservationByID(ctx, res.ID)
+	if !got.Date.Equal(params.Date) || got.Time != "21:00" || got.People != 6 || *got.Comment != comment ||
+		got.Status != servicedto.StatusPending || got.Decision.Rule != servicedto.RulePartySize {
+		t.Fatalf("unexpected modified reservation: %+v", got)
+	}
+	history, _ := client.ListStatusChanges(ctx, res.ID)
+	if len(history) != 1 || history[0].FromStatus != servicedto.StatusConfirmed || history[0].Source != servicedto.StatusSourceGuest {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+
+	if stale, err := client.ModifyReservation(ctx, params); err != nil || stale != nil {
+		t.Fatalf("expected no change once the status moved on, got %+v %v", stale, err)
 	}
 }
@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"vesuvio/internal/dto/service"
+	"vesuvio/internal/model"
+)
+
+type GormRestrictionClient struct {
+	db *gorm.DB
+}
+
+func NewRestrictionClient(db *gorm.DB) *GormRestrictionClient {
+	return &GormRestrictionClient{db: db}
+}
+
+// RecordIncident stores an incident and returns nil when the reservation was
+// already counted for that kind.
+func (c *GormRestrictionClient) RecordIncident(ctx context.Context, params servicedto.CreateIncidentParams) (*servicedto.Incident, error) {
+	incident := model.GuestIncidentModel{
+		UserID:        params.UserID,
+		ReservationID: params.ReservationID,
+		Kind:          params.Kind,
+		OccurredAt:    params.OccurredAt,
+	}
+	err := c.db.WithContext(ctx).Create(&incident).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toServiceIncident(&incident), nil
+}
+
+// DeleteIncident forgets the incident of the given kind on a reservation,
+// e.g. when staff correct a mistaken no-show.
+func (c *GormRestrictionClient) DeleteIncident(ctx context.Context, reservationID uint, kind string) error {
+	return c.db.WithContext(ctx).
+		Where("reservation_id = ? AND kind = ?", reservationID, kind).
+		Delete(&model.GuestIncidentModel{}).Error
+}
+
+// ListIncidentsByUser returns a guest's incidents, newest first.
+func (c *GormRestrictionClient) ListIncidentsByUser(ctx context.Context, userID uint) ([]servicedto.Incident, error) {
+	var models []model.GuestIncidentModel
+	if err := c.db.WithContext(ctx).Where("user_id = ?", userID).Order("occurred_at DESC, id DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	incidents := make([]servicedto.Incident, 0, len(models))
+	for _, m := range models {
+		incidents = append(incidents, *toServiceIncident(&m))
+	}
+	return incidents, nil
+}
+
+// CountIncidents counts a guest's incidents of the given kinds that occurred
+// after since.
+func (c *GormRestrictionClient) CountIncidents(ctx context.Context, userID uint, kinds []string, since time.Time) (int, error) {
+	var count int64
+	err := c.db.WithContext(ctx).Model(&model.GuestIncidentModel{}).
+		Where("user_id = ? AND kind IN ? AND occurred_at > ?", userID, kinds, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// ListRestrictionsByUser returns a guest's restrictions, newest first.
+func (c *GormRestrictionClient) ListRestrictionsByUser(ctx context.Context, userID uint) ([]servicedto.Restriction, error) {
+	var models []model.GuestRestrictionModel
+	if err := c.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toServiceRestrictions(models), nil
+}
+
+// ListActiveRestrictions returns every restriction not yet lifted with its
+// guest, oldest first.
+func (c *GormRestrictionClient) ListActiveRestrictions(ctx context.Context) ([]servicedto.Restriction, error) {
+	var models []model.GuestRestrictionModel
+	err := c.db.WithContext(ctx).Preload("User").
+		Where("lifted_at IS NULL").
+		Order("created_at, id").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return toServiceRestrictions(models), nil
+}
+
+// SaveRestriction updates the guest's active restriction, or creates one.
+func (c *GormRestrictionClient) SaveRestriction(ctx context.Context, params servicedto.SaveRestrictionParams) (*servicedto.Restriction, error) {
+	var saved model.GuestRestrictionModel
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND lifted_at IS NULL", params.UserID).First(&saved).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			saved = model.GuestRestrictionModel{UserID: params.UserID}
+		case err != nil:
+			return err
+		}
+		saved.Level = params.Level
+		saved.Reason = params.Reason
+		saved.Incidents = params.Incidents
+		return tx.Save(&saved).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toServiceRestriction(&saved), nil
+}
+
+// LiftRestriction ends the guest's active restriction. It returns nil when
+// there is none.
+func (c *GormRestrictionClient) LiftRestriction(ctx context.Context, params servicedto.LiftRestrictionParams) (*servicedto.Restriction, error) {
+	var restriction model.GuestRestrictionModel
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND lifted_at IS NULL", params.UserID).First(&restriction).Error; err != nil {
+			return err
+		}
+		restriction.LiftedAt = &params.At
+		restriction.LiftedBy = &params.LiftedBy
+		restriction.LiftNote = params.Note
+		return tx.Save(&restriction).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toServiceRestriction(&restriction), nil
+}
+
+func toServiceIncident(m *model.GuestIncidentModel) *servicedto.Incident {
+	return &servicedto.Incident{
+		ID:            m.ID,
+		UserID:        m.UserID,
+		ReservationID: m.ReservationID,
+		Kind:          m.Kind,
+		OccurredAt:    m.OccurredAt,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+func toServiceRestrictions(models []model.GuestRestrictionModel) []servicedto.Restriction {
+	restrictions := make([]servicedto.Restriction, 0, len(models))
+	for _, m := range models {
+		restrictions = append(restrictions, *toServiceRestriction(&m))
+	}
+	return restrictions
+}
+
+func toServiceRestriction(m *model.GuestRestrictionModel) *servicedto.Restriction {
+	r := &servicedto.Restriction{
+		ID:        m.ID,
+		UserID:    m.UserID,
+		Level:     m.Level,
+		Reason:    m.Reason,
+		Incidents: m.Incidents,
+		LiftedAt:  m.LiftedAt,
+		LiftedBy:  m.LiftedBy,
+		LiftNote:  m.LiftNote,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+	if m.User.ID != 0 {
+		r.User = toServiceUser(&m.User)
+	}
+	return r
+}
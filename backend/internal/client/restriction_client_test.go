@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestRestrictionClient_Incidents(t *testing.T) {
+	client := NewRestrictionClient(newTestDB(t))
+	ctx := context.Background()
+	at := time.Date(2025, 12, 1, 21, 0, 0, 0, time.UTC)
+
+	first, err := client.RecordIncident(ctx, servicedto.CreateIncidentParams{UserID: 1, ReservationID: 10, Kind: servicedto.IncidentNoShow, OccurredAt: at})
+	if err != nil || first == nil || first.ID == 0 {
+		t.Fatalf("record incident: %+v (%v)", first, err)
+	}
+	dup, err := client.RecordIncident(ctx, servicedto.CreateIncidentParams{UserID: 1, ReservationID: 10, Kind: servicedto.IncidentNoShow, OccurredAt: at})
+	if err != nil || dup != nil {
+		t.Fatalf("expected nil for duplicate incident, got %+v (%v)", dup, err)
+	}
+	_, _ = client.RecordIncident(ctx, servicedto.CreateIncidentParams{UserID: 1, ReservationID: 11, Kind: servicedto.IncidentLateCancel, OccurredAt: at.Add(time.Hour)})
+	_, _ = client.RecordIncident(ctx, servicedto.CreateIncidentParams{UserID: 2, ReservationID: 12, Kind: servicedto.IncidentNoShow, OccurredAt: at})
+
+	list, err := client.ListIncidentsByUser(ctx, 1)
+	if err != nil || len(list) != 2 || list[0].Kind != servicedto.IncidentLateCancel {
+		t.Fatalf("expected user incidents newest first, got %+v (%v)", list, err)
+	}
+
+	if err := client.DeleteIncident(ctx, 12, servicedto.IncidentNoShow); err != nil {
+		t.Fatalf("delete incident: %v", err)
+	}
+	if list, _ := client.ListIncidentsByUser(ctx, 2); len(list) != 0 {
+		t.Fatalf("expected deleted incident to be gone, got %+v", list)
+	}
+
+	count, err := client.CountIncidents(ctx, 1, []string{servicedto.IncidentNoShow}, at.Add(-time.Hour))
+	if err != nil || count != 1 {
+		t.Fatalf("expected one no-show, got %d (%v)", count, err)
+	}
+	if count, _ := client.CountIncidents(ctx, 1, []string{servicedto.IncidentNoShow, servicedto.IncidentLateCancel}, at); count != 1 {
+		t.Fatalf("expected only incidents after since, got %d", count)
+	}
+}
+
+func TestRestrictionClient_SaveAndLift(t *testing.T) {
+	db := newTestDB(t)
+	client := NewRestrictionClient(db)
+	ctx := context.Background()
+	user, err := NewUserClient(db).CreateUser(ctx, servicedto.CreateUserParams{Name: "Flaky", Email: "flaky@example.com", PasswordHash: "x"})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	saved, err := client.SaveRestriction(ctx, servicedto.SaveRestrictionParams{UserID: user.ID, Level: servicedto.RestrictionApproval, Reason: "2 incidents", Incidents: 2})
+	if err != nil || saved.ID == 0 || !saved.Active() {
+		t.Fatalf("save restriction: %+v (%v)", saved, err)
+	}
+	upgraded, err := client.SaveRestriction(ctx, servicedto.SaveRestrictionParams{UserID: user.ID, Level: servicedto.RestrictionBlock, Reason: "4 incidents", Incidents: 4})
+	if err != nil || upgraded.ID != saved.ID || upgraded.Level != servicedto.RestrictionBlock {
+		t.Fatalf("expected active restriction updated in place, got %+v (%v)", upgraded, err)
+	}
+
+	active, err := client.ListActiveRestrictions(ctx)
+	if err != nil || len(active) != 1 || active[0].User == nil || active[0].User.Name != "Flaky" {
+		t.Fatalf("unexpected active restrictions: %+v (%v)", active, err)
+	}
+
+	note := "settled"
+	lifted, err := client.LiftRestriction(ctx, servicedto.LiftRestrictionParams{UserID: user.ID, LiftedBy: 9, Note: &note, At: time.Now()})
+	if err != nil || lifted == nil || lifted.Active() || *lifted.LiftedBy != 9 || *lifted.LiftNote != note {
+		t.Fatalf("unexpected lifted restriction: %+v (%v)", lifted, err)
+	}
+	none, err := client.LiftRestriction(ctx, servicedto.LiftRestrictionParams{UserID: user.ID, LiftedBy: 9, At: time.Now()})
+	if err != nil || none != nil {
+		t.Fatalf("expected nil with nothing to lift, got %+v (%v)", none, err)
+	}
+
+	again, _ := client.SaveRestriction(ctx, servicedto.SaveRestrictionParams{UserID: user.ID, Level: servicedto.RestrictionApproval, Reason: "2 incidents", Incidents: 2})
+	history, err := client.ListRestrictionsByUser(ctx, user.ID)
+	if err != nil || len(history) != 2 || history[0].ID != again.ID || history[1].Active() {
+		t.Fatalf("expected new restriction after lift, newest first, got %+v (%v)", history, err)
+	}
+}
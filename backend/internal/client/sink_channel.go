@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// sinkAddress reaches guests by email, or by phone for phone guests.
+func sinkAddress(user servicedto.User) (string, bool) {
+	if !user.IsGuest && user.Email != "" {
+		return user.Email, true
+	}
+	if user.Phone != nil && *user.Phone != "" {
+		return *user.Phone, true
+	}
+	return "", false
+}
+
+// FileSink appends notifications as JSON lines to a file instead of
+// delivering them, for local runs.
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (s *FileSink) Name() string { return "file" }
+
+func (s *FileSink) Address(user servicedto.User) (string, bool) { return sinkAddress(user) }
+
+func (s *FileSink) Send(ctx context.Context, n servicedto.Notification) error {
+	line, err := json.Marshal(struct {
+		At time.Time `json:"at"`
+		servicedto.Notification
+	}{time.Now(), n})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// MemorySink keeps notifications in memory, for local runs and tests.
+type MemorySink struct {
+	mu       sync.Mutex
+	messages []servicedto.Notification
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Name() string { return "memory" }
+
+func (s *MemorySink) Address(user servicedto.User) (string, bool) { return sinkAddress(user) }
+
+func (s *MemorySink) Send(ctx context.Context, n servicedto.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, n)
+	return nil
+}
+
+// Messages returns the notifications sent so far.
+func (s *MemorySink) Messages() []servicedto.Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]servicedto.Notification(nil), s.messages...)
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestNotificationSinks(t *testing.T) {
+	ctx := context.Background()
+	n := servicedto.Notification{ReservationID: 7, To: "ana@example.com", Subject: "Reminder", Body: "See you"}
+
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	file := NewFileSink(path)
+	for range 2 {
+		if err := file.Send(ctx, n); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"To":"ana@example.com"`) {
+		t.Fatalf("unexpected sink file: %s", data)
+	}
+
+	memory := NewMemorySink()
+	memory.Send(ctx, n)
+	if msgs := memory.Messages(); len(msgs) != 1 || msgs[0] != n {
+		t.Fatalf("unexpected messages: %+v", msgs)
+	}
+
+	phone := "+391234567"
+	if to, ok := memory.Address(servicedto.User{IsGuest: true, Email: "guest-1@guests.invalid", Phone: &phone}); !ok || to != phone {
+		t.Fatalf("expected phone guests to be reached by phone, got %q %t", to, ok)
+	}
+}
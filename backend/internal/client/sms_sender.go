@@ -0,0 +1,49 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTPSMSSender sends text messages through an HTTP SMS gateway that accepts
+// {"to": ..., "body": ...} as JSON. cmd/smsstub is such a gateway for local
+// runs.
+type HTTPSMSSender struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewHTTPSMSSender posts messages to url, authenticating with a bearer token
+// when one is given.
+func NewHTTPSMSSender(url, token string) *HTTPSMSSender {
+	return &HTTPSMSSender{url: url, token: token, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *HTTPSMSSender) SendSMS(ctx context.Context, to, body string) error {
+	payload, err := json.Marshal(map[string]string{"to": to, "body": body})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("sms gateway returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPSMSSender(t *testing.T) {
+	var got map[string]string
+	var auth string
+	status := http.StatusAccepted
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer gateway.Close()
+
+	sender := NewHTTPSMSSender(gateway.URL, "token")
+	if err := sender.SendSMS(context.Background(), "+391234567", "See you tonight"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got["to"] != "+391234567" || got["body"] != "See you tonight" || auth != "Bearer token" {
+		t.Fatalf("unexpected request: %v %q", got, auth)
+	}
+
+	status = http.StatusBadGateway
+	if err := sender.SendSMS(context.Background(), "+391234567", "x"); err == nil {
+		t.Fatal("expected an error for a failed delivery")
+	}
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"vesuvio/internal/dto/service"
+	"vesuvio/internal/model"
+)
+
+type GormTableClient struct {
+	db *gorm.DB
+}
+
+func NewTableClient(db *gorm.DB) *GormTableClient {
+	return &GormTableClient{db: db}
+}
+
+func (c *GormTableClient) CreateTable(ctx context.Context, params servicedto.CreateTableParams) (*servicedto.Table, error) {
+	table := model.TableModel{
+		Name:  params.Name,
+		Area:  params.Area,
+		Seats: params.Seats,
+	}
+	if err := c.db.WithContext(ctx).Create(&table).Error; err != nil {
+		return nil, err
+	}
+	return toServiceTable(&table), nil
+}
+
+func (c *GormTableClient) ListTables(ctx context.Context) ([]servicedto.Table, error) {
+	var models []model.TableModel
+	if err := c.db.WithContext(ctx).Order("area, name").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	tables := make([]servicedto.Table, 0, len(models))
+	for _, m := range models {
+		tables = append(tables, *toServiceTable(&m))
+	}
+	return tables, nil
+}
+
+func (c *GormTableClient) GetTableByID(ctx context.Context, id uint) (*servicedto.Table, error) {
+	var table model.TableModel
+	err := c.db.WithContext(ctx).First(&table, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toServiceTable(&table), nil
+}
+
+func toServiceTable(m *model.TableModel) *servicedto.Table {
+	return &servicedto.Table{
+		ID:    m.ID,
+		Name:  m.Name,
+		Area:  m.Area,
+		Seats: m.Seats,
+	}
+}
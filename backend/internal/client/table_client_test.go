@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestTableClient_CreateAndList(t *testing.T) {
+	db := newTestDB(t)
+	client := NewTableClient(db)
+	ctx := context.Background()
+
+	terrace, err := client.CreateTable(ctx, servicedto.CreateTableParams{Name: "P1", Area: "terrace", Seats: 6})
+	if err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if _, err := client.CreateTable(ctx, servicedto.CreateTableParams{Name: "T1", Area: "main", Seats: 4}); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	tables, err := client.ListTables(ctx)
+	if err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	if len(tables) != 2 || tables[0].Area != "main" || tables[1].Name != "P1" {
+		t.Fatalf("expected tables ordered by area, got %+v", tables)
+	}
+
+	found, err := client.GetTableByID(ctx, terrace.ID)
+	if err != nil || found == nil || found.Seats != 6 {
+		t.Fatalf("unexpected table by id: %+v, %v", found, err)
+	}
+	none, err := client.GetTableByID(ctx, 999)
+	if err != nil || none != nil {
+		t.Fatalf("expected nil for missing table, got %+v, %v", none, err)
+	}
+}
+
+func TestReservationClient_SeatReservation(t *testing.T) {
+	db := newTestDB(t)
+	tables := NewTableClient(db)
+	reservations := NewReservationClient(db)
+	ctx := context.Background()
+
+	table, _ := tables.CreateTable(ctx, servicedto.CreateTableParams{Name: "T1", Area: "main", Seats: 4})
+	res, err := reservations.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+	if err != nil {
+		t.Fatalf("create reservation: %v", err)
+	}
+
+	seatedAt := time.Date(2025, 12, 1, 20, 10, 0, 0, time.UTC)
+	seated, err := reservations.SeatReservation(ctx, res.ID, &table.ID, seatedAt, servicedto.StatusActor{Source: servicedto.StatusSourceAdmin})
+	if err != nil {
+		t.Fatalf("seat reservation: %v", err)
+	}
+	if seated.Status != servicedto.StatusSeated || seated.TableID == nil || *seated.TableID != table.ID {
+		t.Fatalf("unexpected seated reservation: %+v", seated)
+	}
+	if got, _ := reservations.GetReservationByID(ctx, res.ID); got.SeatedAt == nil || !got.SeatedAt.Equal(seatedAt) {
+		t.Fatalf("expected seated at %s, got %v", seatedAt, got.SeatedAt)
+	}
+
+	missing, err := reservations.SeatReservation(ctx, 999, nil, seatedAt, servicedto.StatusActor{Source: servicedto.StatusSourceAdmin})
+	if err != nil || missing != nil {
+		t.Fatalf("expected nil for missing reservation, got %+v, %v", missing, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"vesuvio/internal/dto/service"
 	"vesuvio/internal/model"
@@ -24,6 +25,8 @@ func (c *GormUserClient) CreateUser(ctx context.Context, params servicedto.Creat
 		Email:        params.Email,
 		PasswordHash: params.PasswordHash,
 		IsAdmin:      params.IsAdmin,
+		Phone:        params.Phone,
+		IsGuest:      params.IsGuest,
 	}
 
 	if err := c.db.WithContext(ctx).Create(&user).Error; err != nil {
@@ -59,12 +62,52 @@ func (c *GormUserClient) GetUserByID(ctx context.Context, id uint) (*servicedto.
 	return toServiceUser(&user), nil
 }
 
+func (c *GormUserClient) GetUserByPhone(ctx context.Context, phone string) (*servicedto.User, error) {
+	var user model.UserModel
+	err := c.db.WithContext(ctx).Where("phone = ?", phone).Order("id").First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toServiceUser(&user), nil
+}
+
+// UpdateUserPhone sets or, with nil, removes a user's phone number.
+func (c *GormUserClient) UpdateUserPhone(ctx context.Context, userID uint, phone *string) error {
+	return c.db.WithContext(ctx).Model(&model.UserModel{}).Where("id = ?", userID).Update("phone", phone).Error
+}
+
+// GetNotificationPreferences returns nil for users who never set any.
+func (c *GormUserClient) GetNotificationPreferences(ctx context.Context, userID uint) (*servicedto.NotificationPreferences, error) {
+	var prefs model.NotificationPreferenceModel
+	err := c.db.WithContext(ctx).Where("user_id = ?", userID).First(&prefs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &servicedto.NotificationPreferences{Email: prefs.Email, SMS: prefs.SMS, Reminders: prefs.Reminders}, nil
+}
+
+func (c *GormUserClient) SaveNotificationPreferences(ctx context.Context, userID uint, prefs servicedto.NotificationPreferences) error {
+	row := model.NotificationPreferenceModel{UserID: userID, Email: prefs.Email, SMS: prefs.SMS, Reminders: prefs.Reminders}
+	return c.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email", "sms", "reminders", "updated_at"}),
+	}).Create(&row).Error
+}
+
 func toServiceUser(u *model.UserModel) *servicedto.User {
 	return &servicedto.User{
 		ID:        u.ID,
 		Name:      u.Name,
 		Email:     u.Email,
 		IsAdmin:   u.IsAdmin,
+		Phone:     u.Phone,
+		IsGuest:   u.IsGuest,
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 	}
@@ -95,3 +95,74 @@ func TestUserClient_DuplicateEmailAndNotFound(t *testing.T) {
 		t.Fatalf("expected nil for missing id, got %+v", userByID)
 	}
 }
+
+func TestUserClient_GuestByPhone(t *testing.T) {
+	db := newTestDB(t)
+	client := NewUserClient(db)
+	ctx := context.Background()
+	phone := "+34600123456"
+
+	created, err := client.CreateUser(ctx, servicedto.CreateUserParams{
+		Name:    "Luis",
+		Email:   "guest-34600123456@guests.invalid",
+		Phone:   &phone,
+		IsGuest: true,
+	})
+	if err != nil {
+		t.Fatalf("create guest: %v", err)
+	}
+
+	found, err := client.GetUserByPhone(ctx, phone)
+	if err != nil {
+		t.Fatalf("get by phone: %v", err)
+	}
+	if found == nil || found.ID != created.ID || !found.IsGuest || found.Phone == nil || *found.Phone != phone {
+		t.Fatalf("unexpected guest: %+v", found)
+	}
+
+	none, err := client.GetUserByPhone(ctx, "+10000000000")
+	if err != nil || none != nil {
+		t.Fatalf("expected no user for unknown phone, got %+v, %v", none, err)
+	}
+}
+
+func TestUserClient_NotificationSettings(t *testing.T) {
+	db := newTestDB(t)
+	client := NewUserClient(db)
+	ctx := context.Background()
+
+	user, err := client.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if prefs, err := client.GetNotificationPreferences(ctx, user.ID); err != nil || prefs != nil {
+		t.Fatalf("expected no preferences yet, got %+v, %v", prefs, err)
+	}
+
+	for _, want := range []servicedto.NotificationPreferences{
+		{Email: true, SMS: false, Reminders: true},
+		{Email: false, SMS: true, Reminders: false},
+	} {
+		if err := client.SaveNotificationPreferences(ctx, user.ID, want); err != nil {
+			t.Fatalf("save preferences: %v", err)
+		}
+		got, err := client.GetNotificationPreferences(ctx, user.ID)
+		if err != nil || got == nil || *got != want {
+			t.Fatalf("expected %+v, got %+v, %v", want, got, err)
+		}
+	}
+
+	phone := "+34600123456"
+	if err := client.UpdateUserPhone(ctx, user.ID, &phone); err != nil {
+		t.Fatalf("set phone: %v", err)
+	}
+	if got, _ := client.GetUserByID(ctx, user.ID); got.Phone == nil || *got.Phone != phone {
+		t.Fatalf("expected phone to be set, got %+v", got)
+	}
+	if err := client.UpdateUserPhone(ctx, user.ID, nil); err != nil {
+		t.Fatalf("remove phone: %v", err)
+	}
+	if got, _ := client.GetUserByID(ctx, user.ID); got.Phone != nil {
+		t.Fatalf("expected phone to be removed, got %q", *got.Phone)
+	}
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"vesuvio/internal/dto/service"
+	"vesuvio/internal/model"
+)
+
+type GormWebhookClient struct {
+	db *gorm.DB
+}
+
+func NewWebhookClient(db *gorm.DB) *GormWebhookClient {
+	return &GormWebhookClient{db: db}
+}
+
+func (c *GormWebhookClient) CreateWebhook(ctx context.Context, params servicedto.CreateWebhookParams) (*servicedto.Webhook, error) {
+	webhook := model.WebhookModel{
+		URL:         params.URL,
+		Secret:      params.Secret,
+		Events:      params.Events,
+		Active:      true,
+		Description: params.Description,
+	}
+	if err := c.db.WithContext(ctx).Create(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return toServiceWebhook(&webhook), nil
+}
+
+func (c *GormWebhookClient) GetWebhookByID(ctx context.Context, id uint) (*servicedto.Webhook, error) {
+	var webhook model.WebhookModel
+	err := c.db.WithContext(ctx).First(&webhook, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toServiceWebhook(&webhook), nil
+}
+
+// ListWebhooks returns every subscription, oldest first.
+func (c *GormWebhookClient) ListWebhooks(ctx context.Context) ([]servicedto.Webhook, error) {
+	var models []model.WebhookModel
+	if err := c.db.WithContext(ctx).Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	webhooks := make([]servicedto.Webhook, 0, len(models))
+	for _, m := range models {
+		webhooks = append(webhooks, *toServiceWebhook(&m))
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook saves the URL, events, active flag and description.
+func (c *GormWebhookClient) UpdateWebhook(ctx context.Context, webhook servicedto.Webhook) (*servicedto.Webhook, error) {
+	var saved model.WebhookModel
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&saved, webhook.ID).Error; err != nil {
+			return err
+		}
+		saved.URL = webhook.URL
+		saved.Events = webhook.Events
+		saved.Active = webhook.Active
+		saved.Description = webhook.Description
+		return tx.Save(&saved).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toServiceWebhook(&saved), nil
+}
+
+// DeleteWebhook removes a subscription and its delivery log. It returns
+// false when there was no such webhook.
+func (c *GormWebhookClient) DeleteWebhook(ctx context.Context, id uint) (bool, error) {
+	var deleted int64
+	err := c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&model.WebhookDeliveryModel{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&model.WebhookModel{}, id)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted > 0, err
+}
+
+func (c *GormWebhookClient) CreateWebhookDeliveries(ctx context.Context, params []servicedto.CreateWebhookDeliveryParams) error {
+	if len(params) == 0 {
+		return nil
+	}
+	models := make([]model.WebhookDeliveryModel, 0, len(params))
+	for _, p := range params {
+		next := p.NextAttemptAt
+		models = append(models, model.WebhookDeliveryModel{
+			WebhookID:     p.WebhookID,
+			EventID:       p.EventID,
+			Event:         p.Event,
+			Payload:       p.Payload,
+			Status:        servicedto.WebhookDeliveryPending,
+			NextAttemptAt: &next,
+		})
+	}
+	return c.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "webhook_id"}, {Name: "event_id"}},
+		DoNothing: true,
+	}).Create(&models).Error
+}
+
+// ListDueWebhookDeliveries returns up to limit pending deliveries of active
+// webhooks whose next attempt is due, oldest first.
+func (c *GormWebhookClient) ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]servicedto.WebhookDelivery, error) {
+	active := c.db.Model(&model.WebhookModel{}).Select("id").Where("active = ?", true)
+	var models []model.WebhookDeliveryModel
+	err := c.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ? AND webhook_id IN (?)", servicedto.WebhookDeliveryPending, now, active).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return toServiceWebhookDeliveries(models), nil
+}
+
+// RecordWebhookAttempt stores the outcome of an attempt and counts it.
+func (c *GormWebhookClient) RecordWebhookAttempt(ctx context.Context, params servicedto.WebhookAttemptParams) error {
+	updates := map[string]interface{}{
+		"status":          params.Status,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"next_attempt_at": params.NextAttemptAt,
+		"status_code":     params.StatusCode,
+		"error":           nil,
+	}
+	if params.Error != nil {
+		msg := *params.Error
+		if len(msg) > 255 {
+			msg = msg[:255]
+		}
+		updates["error"] = msg
+	}
+	if params.Status == servicedto.WebhookDeliveryDelivered {
+		updates["delivered_at"] = params.At
+	}
+	return c.db.WithContext(ctx).Model(&model.WebhookDeliveryModel{}).Where("id = ?", params.ID).Updates(updates).Error
+}
+
+// ListWebhookDeliveries returns up to limit deliveries of a webhook, newest
+// first, optionally only those with status.
+func (c *GormWebhookClient) ListWebhookDeliveries(ctx context.Context, webhookID uint, status *string, limit int) ([]servicedto.WebhookDelivery, error) {
+	query := c.db.WithContext(ctx).Where("webhook_id = ?", webhookID)
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	var models []model.WebhookDeliveryModel
+	if err := query.Order("id DESC").Limit(limit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return toServiceWebhookDeliveries(models), nil
+}
+
+// ReplayWebhookDeliveries queues failed deliveries of a webhook for a fresh
+// round of attempts from at, only those in ids when any are given. It
+// returns how many were queued.
+func (c *GormWebhookClient) ReplayWebhookDeliveries(ctx context.Context, webhookID uint, ids []uint, at time.Time) (int, error) {
+	query := c.db.WithContext(ctx).Model(&model.WebhookDeliveryModel{}).
+		Where("webhook_id = ? AND status = ?", webhookID, servicedto.WebhookDeliveryFailed)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	result := query.Updates(map[string]interface{}{
+		"status":          servicedto.WebhookDeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": at,
+	})
+	return int(result.RowsAffected), result.Error
+}
+
+func toServiceWebhook(m *model.WebhookModel) *servicedto.Webhook {
+	return &servicedto.Webhook{
+		ID:          m.ID,
+		URL:         m.URL,
+		Secret:      m.Secret,
+		Events:      m.Events,
+		Active:      m.Active,
+		Description: m.Description,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+func toServiceWebhookDeliveries(models []model.WebhookDeliveryModel) []servicedto.WebhookDelivery {
+	deliveries := make([]servicedto.WebhookDelivery, 0, len(models))
+	for _, m := range models {
+		deliveries = append(deliveries, servicedto.WebhookDelivery{
+			ID:            m.ID,
+			WebhookID:     m.WebhookID,
+			EventID:       m.EventID,
+			Event:         m.Event,
+			Payload:       m.Payload,
+			Status:        m.Status,
+			Attempts:      m.Attempts,
+			NextAttemptAt: m.NextAttemptAt,
+			StatusCode:    m.StatusCode,
+			Error:         m.Error,
+			DeliveredAt:   m.DeliveredAt,
+			CreatedAt:     m.CreatedAt,
+			UpdatedAt:     m.UpdatedAt,
+		})
+	}
+	return deliveries
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestWebhookClient_Subscriptions(t *testing.T) {
+	client := NewWebhookClient(newTestDB(t))
+	ctx := context.Background()
+
+	created, err := client.CreateWebhook(ctx, servicedto.CreateWebhookParams{
+		URL: "https://crm.example/hooks", Secret: "whsec_1", Events: []string{servicedto.WebhookReservationCreated},
+	})
+	if err != nil || created.ID == 0 || !created.Active || created.Secret != "whsec_1" {
+		t.Fatalf("unexpected webhook: %+v %v", created, err)
+	}
+
+	created.Active = false
+	created.Events = []string{servicedto.WebhookReservationCancelled, servicedto.WebhookReservationUpdated}
+	updated, err := client.UpdateWebhook(ctx, *created)
+	if err != nil || updated.Active || len(updated.Events) != 2 {
+		t.Fatalf("unexpected update: %+v %v", updated, err)
+	}
+	if missing, err := client.UpdateWebhook(ctx, servicedto.Webhook{ID: 999}); err != nil || missing != nil {
+		t.Fatalf("expected nil for a missing webhook, got %+v %v", missing, err)
+	}
+
+	list, err := client.ListWebhooks(ctx)
+	if err != nil || len(list) != 1 || list[0].Events[1] != servicedto.WebhookReservationUpdated {
+		t.Fatalf("unexpected list: %+v %v", list, err)
+	}
+
+	if deleted, err := client.DeleteWebhook(ctx, created.ID); err != nil || !deleted {
+		t.Fatalf("expected delete, got %t %v", deleted, err)
+	}
+	if deleted, _ := client.DeleteWebhook(ctx, created.ID); deleted {
+		t.Fatal("expected a second delete to find nothing")
+	}
+	if got, err := client.GetWebhookByID(ctx, created.ID); err != nil || got != nil {
+		t.Fatalf("expected webhook gone, got %+v %v", got, err)
+	}
+}
+
+func TestWebhookClient_Deliveries(t *testing.T) {
+	client := NewWebhookClient(newTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	active, _ := client.CreateWebhook(ctx, servicedto.CreateWebhookParams{URL: "https://a.example", Secret: "a", Events: []string{servicedto.WebhookReservationCreated}})
+	paused, _ := client.CreateWebhook(ctx, servicedto.CreateWebhookParams{URL: "https://b.example", Secret: "b", Events: []string{servicedto.WebhookReservationCreated}})
+	paused.Active = false
+	client.UpdateWebhook(ctx, *paused)
+
+	payload := []byte(`{"id":"e1"}`)
+	err := client.CreateWebhookDeliveries(ctx, []servicedto.CreateWebhookDeliveryParams{
+		{WebhookID: active.ID, EventID: "e1", Event: servicedto.WebhookReservationCreated, Payload: payload, NextAttemptAt: now},
+		{WebhookID: active.ID, EventID: "e2", Event: servicedto.WebhookReservationCreated, Payload: payload, NextAttemptAt: now.Add(time.Hour)},
+		{WebhookID: paused.ID, EventID: "e1", Event: servicedto.WebhookReservationCreated, Payload: payload, NextAttemptAt: now},
+	})
+	if err != nil {
+		t.Fatalf("create deliveries: %v", err)
+	}
+	// Queuing an event a webhook already has adds nothing.
+	err = client.CreateWebhookDeliveries(ctx, []servicedto.CreateWebhookDeliveryParams{
+		{WebhookID: active.ID, EventID: "e1", Event: servicedto.WebhookReservationCreated, Payload: payload, NextAttemptAt: now},
+	})
+	if err != nil {
+		t.Fatalf("create duplicate delivery: %v", err)
+	}
+
+	due, err := client.ListDueWebhookDeliveries(ctx, now, 10)
+	if err != nil || len(due) != 1 || due[0].EventID != "e1" || due[0].WebhookID != active.ID || string(due[0].Payload) != string(payload) {
+		t.Fatalf("expected only the due delivery of the active webhook, got %+v %v", due, err)
+	}
+
+	code, msg := 500, "subscriber answered 500"
+	if err := client.RecordWebhookAttempt(ctx, servicedto.WebhookAttemptParams{
+		ID: due[0].ID, Status: servicedto.WebhookDeliveryFailed, StatusCode: &code, Error: &msg, At: now,
+	}); err != nil {
+		t.Fatalf("record attempt: %v", err)
+	}
+	failed := servicedto.WebhookDeliveryFailed
+	log, err := client.ListWebhookDeliveries(ctx, active.ID, &failed, 10)
+	if err != nil || len(log) != 1 || log[0].Attempts != 1 || *log[0].StatusCode != 500 || log[0].NextAttemptAt != nil {
+		t.Fatalf("unexpected failed deliveries: %+v %v", log, err)
+	}
+
+	if n, err := client.ReplayWebhookDeliveries(ctx, active.ID, []uint{999}, now); err != nil || n != 0 {
+		t.Fatalf("expected unknown ids to replay nothing, got %d %v", n, err)
+	}
+	if n, err := client.ReplayWebhookDeliveries(ctx, active.ID, nil, now); err != nil || n != 1 {
+		t.Fatalf("expected one replayed delivery, got %d %v", n, err)
+	}
+	due, _ = client.ListDueWebhookDeliveries(ctx, now, 10)
+	if len(due) != 1 || due[0].Attempts != 0 || due[0].Status != servicedto.WebhookDeliveryPending {
+		t.Fatalf("expected the replayed delivery to be due again, got %+v", due)
+	}
+
+	if err := client.RecordWebhookAttempt(ctx, servicedto.WebhookAttemptParams{ID: due[0].ID, Status: servicedto.WebhookDeliveryDelivered, StatusCode: &code, At: now}); err != nil {
+		t.Fatalf("record attempt: %v", err)
+	}
+	all, _ := client.ListWebhookDeliveries(ctx, active.ID, nil, 10)
+	if len(all) != 2 || all[1].Status != servicedto.WebhookDeliveryDelivered || all[1].DeliveredAt == nil || all[1].Error != nil {
+		t.Fatalf("unexpected log: %+v", all)
+	}
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// errPrivateAddress is returned for subscribers that resolve to an address
+// deliveries may not be sent to.
+var errPrivateAddress = errors.New("subscriber resolves to a loopback, link-local or private address")
+
+// HTTPWebhookSender posts webhook deliveries to subscribers.
+type HTTPWebhookSender struct {
+	client *http.Client
+}
+
+// NewHTTPWebhookSender gives each delivery at most timeout to be answered.
+// It only connects to addresses servicedto.PublicAddress allows, checked on
+// the address actually dialled so that a host re-pointed after it was
+// registered, or a redirect, cannot reach internal services. Proxies from
+// the environment are not used, as they would hide that address.
+func NewHTTPWebhookSender(timeout time.Duration) *HTTPWebhookSender {
+	return newHTTPWebhookSender(timeout, servicedto.PublicAddress)
+}
+
+func newHTTPWebhookSender(timeout time.Duration, allowed func(netip.Addr) bool) *HTTPWebhookSender {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil || !allowed(addr.Addr()) {
+				return errPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: timeout,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &HTTPWebhookSender{client: &http.Client{Timeout: timeout, Transport: transport}}
+}
+
+func (s *HTTPWebhookSender) Post(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestHTTPWebhookSender(t *testing.T) {
+	var gotBody, gotSignature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody, gotSignature = string(body), r.Header.Get("X-Vesuvio-Signature")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	// The test server listens on loopback, which deliveries normally refuse.
+	if _, err := NewHTTPWebhookSender(time.Second).Post(context.Background(), srv.URL, nil, nil); !errors.Is(err, errPrivateAddress) {
+		t.Fatalf("expected a loopback subscriber refused, got %v", err)
+	}
+	if gotBody != "" {
+		t.Fatal("expected nothing sent to a loopback subscriber")
+	}
+
+	sender := newHTTPWebhookSender(time.Second, func(netip.Addr) bool { return true })
+	code, err := sender.Post(context.Background(), srv.URL, map[string]string{"X-Vesuvio-Signature": "sha256=abc"}, []byte(`{"id":"e1"}`))
+	if err != nil || code != http.StatusTeapot {
+		t.Fatalf("expected the subscriber's status, got %d %v", code, err)
+	}
+	if gotBody != `{"id":"e1"}` || gotSignature != "sha256=abc" {
+		t.Fatalf("unexpected request: %q %q", gotBody, gotSignature)
+	}
+
+	srv.Close()
+	if _, err := sender.Post(context.Background(), srv.URL, nil, nil); err == nil {
+		t.Fatal("expected an error when the subscriber is down")
+	}
+}
@@ -1,6 +1,10 @@
 package config
 
-import "testing"
+import (
+	"slices"
+	"testing"
+	"time"
+)
 
 // Ensures Load returns defaults when environment variables are empty or unset.
 func TestLoadDefaults(t *testing.T) {
@@ -29,3 +33,278 @@ func TestLoadFromEnv(t *testing.T) {
 		t.Fatalf("unexpected dsn: %s", cfg.DatabaseDSN)
 	}
 }
+
+// Ensures durations are parsed and invalid values fall back to the default.
+func TestLoadIdempotencyTTL(t *testing.T) {
+	t.Setenv("IDEMPOTENCY_TTL", "")
+	if cfg := Load(); cfg.IdempotencyTTL != 24*time.Hour {
+		t.Fatalf("expected default ttl 24h, got %s", cfg.IdempotencyTTL)
+	}
+
+	t.Setenv("IDEMPOTENCY_TTL", "90m")
+	if cfg := Load(); cfg.IdempotencyTTL != 90*time.Minute {
+		t.Fatalf("expected ttl 90m, got %s", cfg.IdempotencyTTL)
+	}
+
+	t.Setenv("IDEMPOTENCY_TTL", "soon")
+	if cfg := Load(); cfg.IdempotencyTTL != 24*time.Hour {
+		t.Fatalf("expected fallback ttl for invalid value, got %s", cfg.IdempotencyTTL)
+	}
+}
+
+// Ensures unknown overlap policies fall back to the default.
+func TestLoadOverlapPolicy(t *testing.T) {
+	t.Setenv("OVERLAP_POLICY", "REJECT")
+	if cfg := Load(); cfg.OverlapPolicy != "reject" {
+		t.Fatalf("expected reject policy, got %s", cfg.OverlapPolicy)
+	}
+
+	t.Setenv("OVERLAP_POLICY", "sometimes")
+	if cfg := Load(); cfg.OverlapPolicy != "flag" {
+		t.Fatalf("expected fallback flag policy, got %s", cfg.OverlapPolicy)
+	}
+}
+
+// Ensures requirement catalogues and service periods are parsed from env.
+func TestLoadRequirementsAndPeriods(t *testing.T) {
+	t.Setenv("ALLERGENS", " Gluten, nuts ,,")
+	t.Setenv("MAX_HIGH_CHAIRS", "2")
+	t.Setenv("SERVICE_PERIODS", "lunch=12:00-15:00, Dinner=19:00-23:30")
+	cfg := Load()
+	if len(cfg.Allergens) != 2 || cfg.Allergens[0] != "gluten" || cfg.Allergens[1] != "nuts" {
+		t.Fatalf("unexpected allergens: %v", cfg.Allergens)
+	}
+	if cfg.MaxHighChairs != 2 {
+		t.Fatalf("expected 2 high chairs, got %d", cfg.MaxHighChairs)
+	}
+	if len(cfg.ServicePeriods) != 2 || cfg.ServicePeriods[1] != (ServicePeriod{Name: "dinner", Start: "19:00", End: "23:30"}) {
+		t.Fatalf("unexpected periods: %+v", cfg.ServicePeriods)
+	}
+
+	t.Setenv("SERVICE_PERIODS", "lunch=15:00-12:00")
+	if cfg := Load(); cfg.ServicePeriods != nil {
+		t.Fatalf("expected invalid periods to be discarded, got %+v", cfg.ServicePeriods)
+	}
+}
+
+// Ensures sanction rules are parsed, "off" disables them and invalid values keep the defaults.
+func TestLoadSanctions(t *testing.T) {
+	t.Setenv("SANCTIONS", "")
+	t.Setenv("SANCTION_COUNT_LATE_CANCELS", "")
+	cfg := Load()
+	if cfg.Sanctions != nil || cfg.SanctionWindow != 180*24*time.Hour || cfg.SanctionCountLateCancels {
+		t.Fatalf("unexpected sanction defaults: %+v %s %t", cfg.Sanctions, cfg.SanctionWindow, cfg.SanctionCountLateCancels)
+	}
+
+	t.Setenv("SANCTIONS", "2=approval, 5=BLOCK")
+	t.Setenv("SANCTION_COUNT_LATE_CANCELS", "true")
+	cfg = Load()
+	if len(cfg.Sanctions) != 2 || cfg.Sanctions[1] != (SanctionRule{Incidents: 5, Level: "block"}) || !cfg.SanctionCountLateCancels {
+		t.Fatalf("unexpected sanctions: %+v %t", cfg.Sanctions, cfg.SanctionCountLateCancels)
+	}
+
+	t.Setenv("SANCTIONS", "off")
+	if cfg := Load(); cfg.Sanctions == nil || len(cfg.Sanctions) != 0 {
+		t.Fatalf("expected empty sanctions for off, got %+v", cfg.Sanctions)
+	}
+
+	t.Setenv("SANCTIONS", "2=fine")
+	if cfg := Load(); cfg.Sanctions != nil {
+		t.Fatalf("expected defaults for invalid level, got %+v", cfg.Sanctions)
+	}
+}
+
+// Ensures deposit settings are parsed and invalid peak days are ignored.
+func TestLoadDeposits(t *testing.T) {
+	t.Setenv("PAYMENT_PROVIDER", "")
+	t.Setenv("DEPOSIT_PEAK_DAYS", "Fri, saturday")
+	t.Setenv("DEPOSIT_PEAK_DATES", "2025-12-31")
+	cfg := Load()
+	if cfg.PaymentProvider != "off" || cfg.DepositKind != "guarantee" || cfg.DepositMinPeople != 8 || !cfg.DepositChargeLateCancels ||
+		cfg.SettlementInterval != time.Minute {
+		t.Fatalf("unexpected deposit defaults: %+v", cfg)
+	}
+	if len(cfg.DepositPeakDays) != 2 || cfg.DepositPeakDays[0] != time.Friday || cfg.DepositPeakDays[1] != time.Saturday {
+		t.Fatalf("unexpected peak days: %v", cfg.DepositPeakDays)
+	}
+	if len(cfg.DepositPeakDates) != 1 {
+		t.Fatalf("unexpected peak dates: %v", cfg.DepositPeakDates)
+	}
+
+	t.Setenv("DEPOSIT_PEAK_DAYS", "fri,someday")
+	t.Setenv("DEPOSIT_PEAK_DATES", "31/12/2025")
+	if cfg := Load(); cfg.DepositPeakDays != nil || cfg.DepositPeakDates != nil {
+		t.Fatalf("expected invalid values ignored, got %v %v", cfg.DepositPeakDays, cfg.DepositPeakDates)
+	}
+
+	t.Setenv("SETTLEMENT_INTERVAL", "5m")
+	if cfg := Load(); cfg.SettlementInterval != 5*time.Minute {
+		t.Fatalf("expected 5m settlement interval, got %s", cfg.SettlementInterval)
+	}
+}
+
+// Ensures auto-confirm is off by default and its conditions are parsed.
+func TestLoadAutoConfirm(t *testing.T) {
+	t.Setenv("AUTO_CONFIRM", "")
+	t.Setenv("AUTO_CONFIRM_BLACKOUT_DATES", "")
+	cfg := Load()
+	if cfg.AutoConfirm || cfg.AutoConfirmMaxPeople != 4 || cfg.AutoConfirmMaxLoad != 80 || cfg.AutoConfirmAllowNoShows {
+		t.Fatalf("unexpected auto-confirm defaults: %+v", cfg)
+	}
+
+	t.Setenv("AUTO_CONFIRM", "true")
+	t.Setenv("AUTO_CONFIRM_MAX_PEOPLE", "6")
+	t.Setenv("AUTO_CONFIRM_BLACKOUT_DATES", "2025-12-24,2025-12-31")
+	cfg = Load()
+	if !cfg.AutoConfirm || cfg.AutoConfirmMaxPeople != 6 || len(cfg.AutoConfirmBlackoutDates) != 2 {
+		t.Fatalf("unexpected auto-confirm settings: %+v", cfg)
+	}
+}
+
+// Ensures the expiry worker settings default sensibly and 0 disables a rule.
+func TestLoadExpiry(t *testing.T) {
+	t.Setenv("PENDING_MAX_AGE", "")
+	t.Setenv("PENDING_EXPIRY_ACTION", "")
+	cfg := Load()
+	if cfg.ExpiryInterval != time.Minute || cfg.PendingMaxAge != 48*time.Hour || cfg.PendingMinLead != 2*time.Hour ||
+		cfg.PendingExpiryAction != "escalate" || cfg.PaymentTimeout != 30*time.Minute {
+		t.Fatalf("unexpected expiry defaults: %+v", cfg)
+	}
+
+	t.Setenv("PENDING_MAX_AGE", "0")
+	t.Setenv("PENDING_EXPIRY_ACTION", "Cancel")
+	if cfg := Load(); cfg.PendingMaxAge != 0 || cfg.PendingExpiryAction != "cancel" {
+		t.Fatalf("unexpected expiry settings: %s %s", cfg.PendingMaxAge, cfg.PendingExpiryAction)
+	}
+}
+
+// Ensures automatic no-show marking is on by default and 0 disables it.
+func TestLoadNoShowGrace(t *testing.T) {
+	t.Setenv("NO_SHOW_GRACE", "")
+	if cfg := Load(); cfg.NoShowGrace != 45*time.Minute || cfg.NoShowInterval != 15*time.Minute {
+		t.Fatalf("unexpected no-show defaults: %s %s", cfg.NoShowGrace, cfg.NoShowInterval)
+	}
+	t.Setenv("NO_SHOW_GRACE", "0")
+	if cfg := Load(); cfg.NoShowGrace != 0 {
+		t.Fatalf("expected no-show marking disabled, got %s", cfg.NoShowGrace)
+	}
+}
+
+// Ensures reminder offsets and channels are parsed and invalid values are dropped.
+func TestLoadReminders(t *testing.T) {
+	t.Setenv("REMINDER_OFFSETS", "")
+	t.Setenv("NOTIFICATION_CHANNELS", "")
+	cfg := Load()
+	if cfg.ReminderOffsets != nil || cfg.ReminderInterval != 5*time.Minute || len(cfg.NotificationChannels) != 1 || cfg.NotificationChannels[0] != "file" {
+		t.Fatalf("unexpected reminder defaults: %v %s %v", cfg.ReminderOffsets, cfg.ReminderInterval, cfg.NotificationChannels)
+	}
+
+	t.Setenv("REMINDER_OFFSETS", "48h, 90m")
+	t.Setenv("NOTIFICATION_CHANNELS", "Email,pigeon,sms")
+	cfg = Load()
+	if len(cfg.ReminderOffsets) != 2 || cfg.ReminderOffsets[1] != 90*time.Minute {
+		t.Fatalf("unexpected offsets: %v", cfg.ReminderOffsets)
+	}
+	if len(cfg.NotificationChannels) != 2 || cfg.NotificationChannels[0] != "email" || cfg.NotificationChannels[1] != "sms" {
+		t.Fatalf("unexpected channels: %v", cfg.NotificationChannels)
+	}
+
+	t.Setenv("REMINDER_OFFSETS", "24h,-3h")
+	if cfg := Load(); cfg.ReminderOffsets != nil {
+		t.Fatalf("expected invalid offsets discarded, got %v", cfg.ReminderOffsets)
+	}
+}
+
+// Ensures lifecycle emails are on by default and the locale is normalised.
+func TestLoadLifecycleEmails(t *testing.T) {
+	t.Setenv("LIFECYCLE_EMAILS", "")
+	t.Setenv("EMAIL_LOCALE", "")
+	cfg := Load()
+	if !cfg.LifecycleEmails || cfg.EmailTemplatesDir != "templates/email" || cfg.EmailLocale != "en" {
+		t.Fatalf("unexpected email defaults: %t %s %s", cfg.LifecycleEmails, cfg.EmailTemplatesDir, cfg.EmailLocale)
+	}
+
+	t.Setenv("LIFECYCLE_EMAILS", "false")
+	t.Setenv("EMAIL_LOCALE", "IT")
+	if cfg := Load(); cfg.LifecycleEmails || cfg.EmailLocale != "it" {
+		t.Fatalf("unexpected email settings: %t %s", cfg.LifecycleEmails, cfg.EmailLocale)
+	}
+}
+
+// Ensures local phone numbers are rejected by default and the country code
+// may be written with a plus.
+func TestLoadPhoneFormat(t *testing.T) {
+	t.Setenv("PHONE_COUNTRY_CODE", "")
+	t.Setenv("PHONE_TRUNK_PREFIX", "")
+	if cfg := Load(); cfg.PhoneCountryCode != "" || cfg.PhoneTrunkPrefix != "" {
+		t.Fatalf("unexpected phone defaults: %q %q", cfg.PhoneCountryCode, cfg.PhoneTrunkPrefix)
+	}
+
+	t.Setenv("PHONE_COUNTRY_CODE", " +44 ")
+	t.Setenv("PHONE_TRUNK_PREFIX", "0")
+	if cfg := Load(); cfg.PhoneCountryCode != "44" || cfg.PhoneTrunkPrefix != "0" {
+		t.Fatalf("unexpected phone settings: %q %q", cfg.PhoneCountryCode, cfg.PhoneTrunkPrefix)
+	}
+}
+
+// Ensures webhook delivery defaults and overrides are loaded.
+func TestLoadWebhooks(t *testing.T) {
+	for _, key := range []string{"WEBHOOK_INTERVAL", "WEBHOOK_MAX_ATTEMPTS", "WEBHOOK_BACKOFF", "WEBHOOK_MAX_BACKOFF", "WEBHOOK_TIMEOUT"} {
+		t.Setenv(key, "")
+	}
+	cfg := Load()
+	if cfg.WebhookInterval != 10*time.Second || cfg.WebhookMaxAttempts != 8 || cfg.WebhookBackoff != 30*time.Second ||
+		cfg.WebhookMaxBackoff != 30*time.Minute || cfg.WebhookTimeout != 10*time.Second {
+		t.Fatalf("unexpected webhook defaults: %+v", cfg)
+	}
+
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "3")
+	t.Setenv("WEBHOOK_BACKOFF", "1m")
+	if cfg := Load(); cfg.WebhookMaxAttempts != 3 || cfg.WebhookBackoff != time.Minute {
+		t.Fatalf("unexpected webhook settings: %d %s", cfg.WebhookMaxAttempts, cfg.WebhookBackoff)
+	}
+}
+
+// Ensures the outbox interval defaults to two seconds and can be overridden.
+func TestLoadOutbox(t *testing.T) {
+	t.Setenv("OUTBOX_INTERVAL", "")
+	if cfg := Load(); cfg.OutboxInterval != 2*time.Second {
+		t.Fatalf("expected 2s outbox interval, got %s", cfg.OutboxInterval)
+	}
+
+	t.Setenv("OUTBOX_INTERVAL", "500ms")
+	if cfg := Load(); cfg.OutboxInterval != 500*time.Millisecond {
+		t.Fatalf("expected 500ms outbox interval, got %s", cfg.OutboxInterval)
+	}
+}
+
+// Ensures live stream defaults and overrides are loaded.
+func TestLoadLive(t *testing.T) {
+	t.Setenv("LIVE_HEARTBEAT", "")
+	t.Setenv("LIVE_HISTORY", "")
+	t.Setenv("LIVE_INTERVAL", "")
+	if cfg := Load(); cfg.LiveHeartbeat != 15*time.Second || cfg.LiveHistory != 1000 || cfg.LiveInterval != time.Second {
+		t.Fatalf("unexpected live defaults: %s %d %s", cfg.LiveHeartbeat, cfg.LiveHistory, cfg.LiveInterval)
+	}
+
+	t.Setenv("LIVE_HEARTBEAT", "5s")
+	t.Setenv("LIVE_HISTORY", "50")
+	t.Setenv("LIVE_INTERVAL", "250ms")
+	if cfg := Load(); cfg.LiveHeartbeat != 5*time.Second || cfg.LiveHistory != 50 || cfg.LiveInterval != 250*time.Millisecond {
+		t.Fatalf("unexpected live settings: %s %d %s", cfg.LiveHeartbeat, cfg.LiveHistory, cfg.LiveInterval)
+	}
+}
+
+// Ensures allowed origins default to the frontend dev server and are
+// compared without case or trailing slashes.
+func TestLoadAllowedOrigins(t *testing.T) {
+	t.Setenv("ALLOWED_ORIGINS", "")
+	if cfg := Load(); !slices.Equal(cfg.AllowedOrigins, []string{"http://localhost:5173"}) {
+		t.Fatalf("unexpected default origins: %v", cfg.AllowedOrigins)
+	}
+
+	t.Setenv("ALLOWED_ORIGINS", " https://Book.example.com/ , ,https://admin.example.com")
+	if cfg := Load(); !slices.Equal(cfg.AllowedOrigins, []string{"https://book.example.com", "https://admin.example.com"}) {
+		t.Fatalf("unexpected origins: %v", cfg.AllowedOrigins)
+	}
+}
@@ -1,8 +1,10 @@
 package controller
 
 import (
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -22,19 +24,36 @@ func NewAdminController(reservationService *service.ReservationService) *AdminCo
 }
 
 func (ctl *AdminController) ListReservations(c *gin.Context) {
-	date := c.Query("date")
-	var status *string
-	if s := c.Query("status"); s != "" {
-		status = &s
+	input := servicedto.AdminListReservationsInput{
+		Date:   c.Query("date"),
+		From:   c.Query("from"),
+		To:     c.Query("to"),
+		Sort:   c.Query("sort"),
+		Order:  c.Query("order"),
+		Cursor: c.Query("cursor"),
+	}
+	input.Statuses = queryList(c, "status")
+	input.Tags = queryList(c, "tag")
+	for _, p := range []struct {
+		name string
+		dest *int
+	}{{"min_people", &input.MinPeople}, {"max_people", &input.MaxPeople}, {"limit", &input.Limit}} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + p.name})
+			return
+		}
+		*p.dest = n
 	}
 
-	res, err := ctl.reservationService.AdminListReservations(c.Request.Context(), servicedto.AdminListReservationsInput{
-		Date:   date,
-		Status: status,
-	})
+	res, err := ctl.reservationService.AdminListReservations(c.Request.Context(), input)
 	if err != nil {
 		switch err {
-		case service.ErrInvalidInput, service.ErrInvalidStatus:
+		case service.ErrInvalidInput, service.ErrInvalidStatus, service.ErrInvalidCursor, service.ErrInvalidTag:
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list reservations"})
@@ -42,27 +61,214 @@ func (ctl *AdminController) ListReservations(c *gin.Context) {
 		return
 	}
 
-	resp := make([]controllerdto.AdminReservationResponse, 0, len(res))
-	for _, r := range res {
-		user := controllerdto.AdminUserInfo{
-			ID:    r.User.ID,
-			Name:  r.User.Name,
-			Email: r.User.Email,
-		}
-		resp = append(resp, controllerdto.AdminReservationResponse{
-			ID:        r.ID,
-			User:      user,
-			Date:      r.Date.Format("2006-01-02"),
-			Time:      r.Time,
-			People:    r.People,
-			Comment:   r.Comment,
-			Status:    r.Status,
-			CreatedAt: r.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: r.UpdatedAt.Format(time.RFC3339),
-		})
+	c.Header("X-Total-Count", strconv.FormatInt(res.Total, 10))
+	if res.NextCursor != "" {
+		c.Header("X-Next-Cursor", res.NextCursor)
+	}
+	c.JSON(http.StatusOK, toAdminReservationResponses(res.Reservations))
+}
+
+// ExportReservations streams the admin listing as CSV or a spreadsheet.
+// Once rows are being sent a failure can only cut the download short.
+func (ctl *AdminController) ExportReservations(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	input := servicedto.ExportReservationsInput{
+		Format:   c.Query("format"),
+		Date:     c.Query("date"),
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		Statuses: queryList(c, "status"),
+		Tags:     queryList(c, "tag"),
+		Columns:  queryList(c, "columns"),
+		Locale:   c.Query("locale"),
 	}
 
-	c.JSON(http.StatusOK, resp)
+	export, err := ctl.reservationService.ExportReservations(c.Request.Context(), currentUser, input)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput, service.ErrInvalidStatus, service.ErrInvalidTag:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export reservations"})
+		}
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType)
+	c.Header("Content-Disposition", `attachment; filename="`+export.Filename+`"`)
+	c.Status(http.StatusOK)
+	if err := export.Write(c.Request.Context(), c.Writer); err != nil {
+		log.Printf("export reservations: %v", err)
+		c.Abort()
+	}
+}
+
+func (ctl *AdminController) CreateReservation(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+
+	var req controllerdto.AdminCreateReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := servicedto.AdminCreateReservationInput{
+		UserID:       req.UserID,
+		Date:         req.Date,
+		Time:         req.Time,
+		People:       req.People,
+		Comment:      req.Comment,
+		Requirements: toServiceRequirements(req.Requirements),
+		Channel:      req.Channel,
+	}
+	if req.Guest != nil {
+		input.Guest = &servicedto.GuestInput{Name: req.Guest.Name, Phone: req.Guest.Phone}
+	}
+
+	out, err := ctl.reservationService.AdminCreateReservation(c.Request.Context(), currentUser, input)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput, service.ErrInvalidRequirement, service.ErrInvalidPhone:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case service.ErrOverlappingReservation:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create reservation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, toAdminReservationResponse(out.Reservation))
+}
+
+func (ctl *AdminController) WalkIn(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+
+	var req controllerdto.WalkInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := servicedto.WalkInInput{
+		UserID:  req.UserID,
+		People:  req.People,
+		TableID: req.TableID,
+		Comment: req.Comment,
+	}
+	if req.Guest != nil {
+		input.Guest = &servicedto.GuestInput{Name: req.Guest.Name, Phone: req.Guest.Phone}
+	}
+
+	res, err := ctl.reservationService.WalkIn(c.Request.Context(), currentUser, input)
+	if err != nil {
+		writeSeatingError(c, err, "failed to register walk-in")
+		return
+	}
+
+	c.JSON(http.StatusCreated, toAdminReservationResponse(*res))
+}
+
+func (ctl *AdminController) SeatReservation(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	reservationID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reservation id"})
+		return
+	}
+
+	// The body is optional: seating without a table is allowed.
+	var req controllerdto.SeatReservationRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	res, err := ctl.reservationService.SeatReservation(c.Request.Context(), currentUser, servicedto.SeatReservationInput{
+		ReservationID: reservationID,
+		TableID:       req.TableID,
+	})
+	if err != nil {
+		writeSeatingError(c, err, "failed to seat reservation")
+		return
+	}
+
+	c.JSON(http.StatusOK, toAdminReservationResponse(*res))
+}
+
+func writeSeatingError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case service.ErrInvalidInput, service.ErrInvalidStatus, service.ErrInvalidPhone, service.ErrNotToday:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.ErrReservationNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+	case service.ErrUserNotFound, service.ErrTableNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case service.ErrTableOccupied:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case service.ErrUnauthorized:
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func (ctl *AdminController) GetReservationByCode(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+
+	res, err := ctl.reservationService.AdminGetReservationByCode(c.Request.Context(), currentUser, c.Param("code"))
+	if err != nil {
+		switch err {
+		case service.ErrReservationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		case service.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get reservation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toAdminReservationResponse(*res))
+}
+
+func (ctl *AdminController) SearchReservations(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+
+	input := servicedto.SearchReservationsInput{Query: c.Query("q")}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		input.Limit = limit
+	}
+
+	res, err := ctl.reservationService.AdminSearchReservations(c.Request.Context(), currentUser, input)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search reservations"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toAdminReservationResponses(res))
 }
 
 func (ctl *AdminController) ConfirmReservation(c *gin.Context) {
@@ -80,6 +286,8 @@ func (ctl *AdminController) ConfirmReservation(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
 		case service.ErrInvalidInput, service.ErrInvalidStatus:
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrStatusConflict:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		case service.ErrUnauthorized:
 			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 		default:
@@ -106,6 +314,8 @@ func (ctl *AdminController) CancelReservation(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
 		case service.ErrInvalidInput, service.ErrInvalidStatus:
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrStatusConflict:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		case service.ErrUnauthorized:
 			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
 		default:
@@ -117,6 +327,373 @@ func (ctl *AdminController) CancelReservation(c *gin.Context) {
 	c.JSON(http.StatusOK, toReservationResponse(*res))
 }
 
+func (ctl *AdminController) MarkNoShow(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	reservationID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reservation id"})
+		return
+	}
+
+	res, err := ctl.reservationService.MarkNoShow(c.Request.Context(), currentUser, reservationID)
+	if err != nil {
+		switch err {
+		case service.ErrReservationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		case service.ErrInvalidInput, service.ErrInvalidStatus:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrStatusConflict:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to mark no-show"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toReservationResponse(*res))
+}
+
+// RevertNoShow corrects a no-show marked by mistake, e.g. by the automatic
+// no-show job for a party the host forgot to seat.
+func (ctl *AdminController) RevertNoShow(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	reservationID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reservation id"})
+		return
+	}
+
+	var req controllerdto.RevertNoShowRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	res, err := ctl.reservationService.RevertNoShow(c.Request.Context(), currentUser, servicedto.RevertNoShowInput{
+		ReservationID: reservationID,
+		Status:        req.Status,
+	})
+	if err != nil {
+		switch err {
+		case service.ErrReservationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		case service.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrInvalidStatus:
+			c.JSON(http.StatusConflict, gin.H{"error": "reservation is not a no-show"})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revert no-show"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toReservationResponse(*res))
+}
+
+// NoShowSummary lists a day's no-shows for review; date defaults to today.
+func (ctl *AdminController) NoShowSummary(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	summary, err := ctl.reservationService.NoShowSummary(c.Request.Context(), currentUser, c.Query("date"))
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build no-show summary"})
+		}
+		return
+	}
+
+	resp := controllerdto.NoShowSummaryResponse{
+		Date:     summary.Date.Format("2006-01-02"),
+		Auto:     summary.Auto,
+		Manual:   summary.Manual,
+		Reverted: summary.Reverted,
+		Covers:   summary.Covers,
+		Entries:  make([]controllerdto.NoShowEntryResponse, 0, len(summary.Entries)),
+	}
+	for _, e := range summary.Entries {
+		resp.Entries = append(resp.Entries, controllerdto.NoShowEntryResponse{
+			Reservation: toAdminReservationResponse(e.Reservation),
+			MarkedAt:    e.MarkedAt.Format(time.RFC3339),
+			Auto:        e.Auto,
+			RevertedTo:  e.RevertedTo,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (ctl *AdminController) BulkConfirm(c *gin.Context) {
+	ctl.bulkUpdateStatus(c, servicedto.StatusConfirmed)
+}
+
+func (ctl *AdminController) BulkCancel(c *gin.Context) {
+	ctl.bulkUpdateStatus(c, servicedto.StatusCancelled)
+}
+
+func (ctl *AdminController) BulkUpdateStatus(c *gin.Context) {
+	ctl.bulkUpdateStatus(c, "")
+}
+
+// bulkUpdateStatus handles the bulk endpoints; an empty status means the
+// target status comes from the request body.
+func (ctl *AdminController) bulkUpdateStatus(c *gin.Context, status string) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+
+	var req controllerdto.BulkStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if status == "" {
+		status = req.Status
+	}
+
+	input := servicedto.BulkStatusInput{
+		IDs:    req.IDs,
+		Status: status,
+		Atomic: req.Atomic,
+		Reason: req.Reason,
+	}
+	if req.Filter != nil {
+		input.Filter = &servicedto.BulkReservationFilter{
+			Date:      req.Filter.Date,
+			From:      req.Filter.From,
+			To:        req.Filter.To,
+			Statuses:  req.Filter.Statuses,
+			Tags:      req.Filter.Tags,
+			MinPeople: req.Filter.MinPeople,
+			MaxPeople: req.Filter.MaxPeople,
+		}
+	}
+
+	out, err := ctl.reservationService.BulkUpdateStatus(c.Request.Context(), currentUser, input)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput, service.ErrInvalidStatus, service.ErrInvalidTag, service.ErrTooManyReservations:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrReservationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update reservations"})
+		}
+		return
+	}
+
+	resp := controllerdto.BulkStatusResponse{
+		Updated:   out.Updated,
+		Unchanged: out.Unchanged,
+		Failed:    out.Failed,
+		Results:   make([]controllerdto.BulkStatusResult, 0, len(out.Results)),
+	}
+	for _, r := range out.Results {
+		result := controllerdto.BulkStatusResult{ID: r.ID, Outcome: r.Outcome}
+		if r.Reservation != nil {
+			result.Status = r.Reservation.Status
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (ctl *AdminController) ReservationHistory(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	reservationID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reservation id"})
+		return
+	}
+
+	changes, err := ctl.reservationService.AdminReservationHistory(c.Request.Context(), currentUser, reservationID)
+	if err != nil {
+		switch err {
+		case service.ErrReservationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get reservation history"})
+		}
+		return
+	}
+
+	resp := make([]controllerdto.StatusChangeResponse, 0, len(changes))
+	for _, ch := range changes {
+		resp = append(resp, controllerdto.StatusChangeResponse{
+			FromStatus: ch.FromStatus,
+			ToStatus:   ch.ToStatus,
+			ActorID:    ch.ActorID,
+			Source:     ch.Source,
+			Reason:     ch.Reason,
+			CreatedAt:  ch.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (ctl *AdminController) AddStaffNote(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	reservationID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reservation id"})
+		return
+	}
+
+	var req controllerdto.AddStaffNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	note, err := ctl.reservationService.AddStaffNote(c.Request.Context(), currentUser, reservationID, req.Body)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrReservationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add note"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, toStaffNoteResponse(*note))
+}
+
+func (ctl *AdminController) SetTags(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	reservationID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reservation id"})
+		return
+	}
+
+	var req controllerdto.SetTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, err := ctl.reservationService.SetReservationTags(c.Request.Context(), currentUser, reservationID, req.Tags)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput, service.ErrInvalidTag:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrReservationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set tags"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, controllerdto.TagsResponse{Tags: tags})
+}
+
+// queryList collects a query parameter given repeatedly and/or as a
+// comma-separated list.
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+func toStaffNoteResponse(n servicedto.StaffNote) controllerdto.StaffNoteResponse {
+	return controllerdto.StaffNoteResponse{
+		ID:         n.ID,
+		AuthorID:   n.AuthorID,
+		AuthorName: n.AuthorName,
+		Body:       n.Body,
+		CreatedAt:  n.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toAdminReservationResponses(res []servicedto.Reservation) []controllerdto.AdminReservationResponse {
+	resp := make([]controllerdto.AdminReservationResponse, 0, len(res))
+	for _, r := range res {
+		resp = append(resp, toAdminReservationResponse(r))
+	}
+	return resp
+}
+
+func toAdminReservationResponse(r servicedto.Reservation) controllerdto.AdminReservationResponse {
+	var user controllerdto.AdminUserInfo
+	if r.User != nil {
+		user = controllerdto.AdminUserInfo{
+			ID:      r.User.ID,
+			Name:    r.User.Name,
+			Email:   r.User.Email,
+			Phone:   r.User.Phone,
+			IsGuest: r.User.IsGuest,
+		}
+	}
+	notes := make([]controllerdto.StaffNoteResponse, 0, len(r.Notes))
+	for _, n := range r.Notes {
+		notes = append(notes, toStaffNoteResponse(n))
+	}
+	return controllerdto.AdminReservationResponse{
+		ID:           r.ID,
+		Code:         r.Code,
+		User:         user,
+		Date:         r.Date.Format("2006-01-02"),
+		Time:         r.Time,
+		People:       r.People,
+		TableID:      r.TableID,
+		Comment:      r.Comment,
+		Requirements: toRequirementsResponse(r.Requirements),
+		Status:       r.Status,
+		Channel:      r.Channel,
+		NeedsReview:  r.ReviewReason != nil,
+		ReviewReason: r.ReviewReason,
+		Decision:     toDecisionResponse(r.Decision),
+		EscalatedAt:  formatOptionalTime(r.EscalatedAt),
+		AttendingAt:  formatOptionalTime(r.AttendingAt),
+		SeatedAt:     formatOptionalTime(r.SeatedAt),
+		Tags:         nonNilStrings(r.Tags),
+		Notes:        notes,
+		Guest:        toGuestSummaryResponse(r.Guest),
+		Payment:      toPaymentResponse(r.Payment),
+		CreatedAt:    r.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    r.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toDecisionResponse(d *servicedto.Decision) *controllerdto.DecisionResponse {
+	if d == nil {
+		return nil
+	}
+	return &controllerdto.DecisionResponse{Rule: d.Rule, Note: d.Note}
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
 func parseIDParam(param string) (uint, bool) {
 	id, err := strconv.ParseUint(param, 10, 64)
 	if err != nil || id == 0 {
@@ -29,10 +29,11 @@ func (ctl *AuthController) Register(c *gin.Context) {
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: req.Password,
+		Phone:    req.Phone,
 	})
 	if err != nil {
 		switch err {
-		case service.ErrEmailAlreadyExists, service.ErrInvalidInput:
+		case service.ErrEmailAlreadyExists, service.ErrInvalidInput, service.ErrInvalidPhone:
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to register"})
@@ -44,6 +45,7 @@ func (ctl *AuthController) Register(c *gin.Context) {
 		ID:      out.User.ID,
 		Name:    out.User.Name,
 		Email:   out.User.Email,
+		Phone:   out.User.Phone,
 		IsAdmin: out.User.IsAdmin,
 	})
 }
@@ -73,6 +75,7 @@ func (ctl *AuthController) Login(c *gin.Context) {
 		ID:      out.User.ID,
 		Name:    out.User.Name,
 		Email:   out.User.Email,
+		Phone:   out.User.Phone,
 		IsAdmin: out.User.IsAdmin,
 	})
 }
@@ -20,7 +20,7 @@ import (
 func TestAuthController_RegisterAndLogin(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	authSvc := service.NewAuthService(newControllerFakeUserClient())
+	authSvc := service.NewAuthService(newControllerFakeUserClient(), servicedto.PhoneFormat{})
 	ctl := NewAuthController(authSvc)
 
 	// Register
@@ -73,7 +73,7 @@ func TestAuthController_RegisterAndLogin(t *testing.T) {
 func TestAuthController_DuplicateEmail(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	userClient := newControllerFakeUserClient()
-	authSvc := service.NewAuthService(userClient)
+	authSvc := service.NewAuthService(userClient, servicedto.PhoneFormat{})
 	ctl := NewAuthController(authSvc)
 
 	// Seed existing
@@ -101,7 +101,7 @@ func TestAuthController_DuplicateEmail(t *testing.T) {
 func TestAuthController_LoginInvalid(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	userClient := newControllerFakeUserClient()
-	authSvc := service.NewAuthService(userClient)
+	authSvc := service.NewAuthService(userClient, servicedto.PhoneFormat{})
 	ctl := NewAuthController(authSvc)
 
 	hash, _ := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.DefaultCost)
@@ -134,14 +134,16 @@ func newTestContext(req *http.Request, w http.ResponseWriter) *gin.Context {
 }
 
 type controllerFakeUserClient struct {
-	users  map[uint]servicedto.UserWithPassword
-	nextID uint
+	users       map[uint]servicedto.UserWithPassword
+	preferences map[uint]servicedto.NotificationPreferences
+	nextID      uint
 }
 
 func newControllerFakeUserClient() *controllerFakeUserClient {
 	return &controllerFakeUserClient{
-		users:  make(map[uint]servicedto.UserWithPassword),
-		nextID: 1,
+		users:       make(map[uint]servicedto.UserWithPassword),
+		preferences: make(map[uint]servicedto.NotificationPreferences),
+		nextID:      1,
 	}
 }
 
@@ -160,6 +162,8 @@ func (f *controllerFakeUserClient) CreateUser(ctx context.Context, params servic
 			Name:      params.Name,
 			Email:     params.Email,
 			IsAdmin:   params.IsAdmin,
+			Phone:     params.Phone,
+			IsGuest:   params.IsGuest,
 			CreatedAt: now,
 			UpdatedAt: now,
 		},
@@ -187,3 +191,33 @@ func (f *controllerFakeUserClient) GetUserByID(ctx context.Context, id uint) (*s
 	copy := u.User
 	return &copy, nil
 }
+
+func (f *controllerFakeUserClient) GetUserByPhone(ctx context.Context, phone string) (*servicedto.User, error) {
+	for _, u := range f.users {
+		if u.Phone != nil && *u.Phone == phone {
+			copy := u.User
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *controllerFakeUserClient) UpdateUserPhone(ctx context.Context, userID uint, phone *string) error {
+	u := f.users[userID]
+	u.Phone = phone
+	f.users[userID] = u
+	return nil
+}
+
+func (f *controllerFakeUserClient) GetNotificationPreferences(ctx context.Context, userID uint) (*servicedto.NotificationPreferences, error) {
+	prefs, ok := f.preferences[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &prefs, nil
+}
+
+func (f *controllerFakeUserClient) SaveNotificationPreferences(ctx context.Context, userID uint, prefs servicedto.NotificationPreferences) error {
+	f.preferences[userID] = prefs
+	return nil
+}
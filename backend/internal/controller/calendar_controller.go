@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+type CalendarController struct {
+	calendarService *service.CalendarService
+}
+
+func NewCalendarController(calendarService *service.CalendarService) *CalendarController {
+	return &CalendarController{calendarService: calendarService}
+}
+
+// ReservationCalendar downloads one booking as an .ics file, for its guest
+// or for staff.
+func (ctl *CalendarController) ReservationCalendar(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reservation id"})
+		return
+	}
+
+	file, err := ctl.calendarService.ReservationCalendar(c.Request.Context(), currentUser, id)
+	if err != nil {
+		switch err {
+		case service.ErrForbiddenReservation:
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allowed to view this reservation"})
+		case service.ErrReservationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build calendar"})
+		}
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+file.Filename+`"`)
+	c.Data(http.StatusOK, calendarContentType, file.Body)
+}
+
+// Feed serves /feeds/:token.ics to calendar apps. The token is the only
+// credential.
+func (ctl *CalendarController) Feed(c *gin.Context) {
+	token, ok := strings.CutSuffix(c.Param("token"), ".ics")
+	if !ok || token == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.ErrCalendarFeedNotFound.Error()})
+		return
+	}
+
+	file, err := ctl.calendarService.RenderFeed(c.Request.Context(), token)
+	if err != nil {
+		switch err {
+		case service.ErrCalendarFeedNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build calendar"})
+		}
+		return
+	}
+	c.Header("Content-Disposition", `inline; filename="`+file.Filename+`"`)
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Data(http.StatusOK, calendarContentType, file.Body)
+}
+
+// MyFeed returns the address of the current user's booking feed.
+func (ctl *CalendarController) MyFeed(c *gin.Context) {
+	ctl.feed(c, servicedto.CalendarFeedScopeUser, false)
+}
+
+// ResetMyFeed moves the current user's feed to a new address.
+func (ctl *CalendarController) ResetMyFeed(c *gin.Context) {
+	ctl.feed(c, servicedto.CalendarFeedScopeUser, true)
+}
+
+// StaffFeed returns the address of the current admin's feed of all
+// bookings.
+func (ctl *CalendarController) StaffFeed(c *gin.Context) {
+	ctl.feed(c, servicedto.CalendarFeedScopeAdmin, false)
+}
+
+// ResetStaffFeed moves the current admin's staff feed to a new address.
+func (ctl *CalendarController) ResetStaffFeed(c *gin.Context) {
+	ctl.feed(c, servicedto.CalendarFeedScopeAdmin, true)
+}
+
+func (ctl *CalendarController) feed(c *gin.Context, scope string, reset bool) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	get := ctl.calendarService.CalendarFeed
+	if reset {
+		get = ctl.calendarService.ResetCalendarFeed
+	}
+
+	feed, err := get(c.Request.Context(), currentUser, scope)
+	if err != nil {
+		switch err {
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load calendar feed"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, controllerdto.CalendarFeedResponse{
+		URL:       feed.URL,
+		Scope:     feed.Scope,
+		UpdatedAt: feed.UpdatedAt.Format(time.RFC3339),
+	})
+}
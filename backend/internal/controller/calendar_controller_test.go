@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+func TestCalendarController(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	userClient := newControllerFakeUserClient()
+	guest, _ := userClient.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com", PasswordHash: "hash"})
+	other, _ := userClient.CreateUser(ctx, servicedto.CreateUserParams{Name: "Bo", Email: "bo@example.com", PasswordHash: "hash"})
+	resClient := newControllerFakeReservationClient()
+	res, _ := resClient.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: guest.ID, Date: time.Now().AddDate(0, 0, 3), Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+
+	calendarService := service.NewCalendarService(resClient, &controllerFakeCalendarFeedClient{}, userClient, "https://book.vesuvio.example", 2*time.Hour, time.UTC)
+	ctl := NewCalendarController(calendarService)
+	router := gin.New()
+	router.GET("/feeds/:token", ctl.Feed)
+	auth := router.Group("/", middleware.AuthMiddleware(service.NewAuthService(userClient, servicedto.PhoneFormat{})))
+	auth.GET("/reservations/:id/calendar.ics", ctl.ReservationCalendar)
+	auth.GET("/my/calendar-feed", ctl.MyFeed)
+	auth.GET("/admin/calendar-feed", ctl.StaffFeed)
+
+	call := func(path string, userID uint) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if userID != 0 {
+			req.Header.Set("X-User-ID", fmt.Sprintf("%d", userID))
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := call(fmt.Sprintf("/reservations/%d/calendar.ics", res.ID), guest.ID)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != calendarContentType ||
+		!strings.Contains(w.Header().Get("Content-Disposition"), "vsv-") || !strings.Contains(w.Body.String(), "BEGIN:VEVENT") {
+		t.Fatalf("unexpected download: %d %v %s", w.Code, w.Header(), w.Body.String())
+	}
+	if w := call(fmt.Sprintf("/reservations/%d/calendar.ics", res.ID), other.ID); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for another guest, got %d", w.Code)
+	}
+	if w := call("/admin/calendar-feed", guest.ID); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a guest's staff feed, got %d", w.Code)
+	}
+
+	w = call("/my/calendar-feed", guest.ID)
+	var feed controllerdto.CalendarFeedResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &feed); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("unexpected feed response: %d %s", w.Code, w.Body.String())
+	}
+	path := strings.TrimPrefix(feed.URL, "https://book.vesuvio.example")
+	if w := call(path, 0); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "X-WR-CALNAME:Vesuvio") {
+		t.Fatalf("unexpected feed: %d %s", w.Code, w.Body.String())
+	}
+	if w := call(strings.TrimSuffix(path, ".ics"), 0); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without the .ics suffix, got %d", w.Code)
+	}
+	if w := call("/feeds/unknown.ics", 0); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown token, got %d", w.Code)
+	}
+}
+
+// controllerFakeCalendarFeedClient keeps feeds in memory.
+type controllerFakeCalendarFeedClient struct {
+	feeds []servicedto.CalendarFeed
+}
+
+func (f *controllerFakeCalendarFeedClient) GetCalendarFeed(ctx context.Context, userID uint, scope string) (*servicedto.CalendarFeed, error) {
+	for _, feed := range f.feeds {
+		if feed.UserID == userID && feed.Scope == scope {
+			return &feed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *controllerFakeCalendarFeedClient) GetCalendarFeedByToken(ctx context.Context, token string) (*servicedto.CalendarFeed, error) {
+	for _, feed := range f.feeds {
+		if feed.Token == token {
+			return &feed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *controllerFakeCalendarFeedClient) SaveCalendarFeed(ctx context.Context, params servicedto.SaveCalendarFeedParams) (*servicedto.CalendarFeed, error) {
+	feed := servicedto.CalendarFeed{ID: uint(len(f.feeds) + 1), UserID: params.UserID, Scope: params.Scope, Token: params.Token, UpdatedAt: time.Now()}
+	f.feeds = append(f.feeds, feed)
+	return &feed, nil
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+type FloorController struct {
+	floorService *service.FloorService
+}
+
+func NewFloorController(floorService *service.FloorService) *FloorController {
+	return &FloorController{floorService: floorService}
+}
+
+func (ctl *FloorController) ListTables(c *gin.Context) {
+	tables, err := ctl.floorService.ListTables(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list tables"})
+		return
+	}
+
+	resp := make([]controllerdto.TableResponse, 0, len(tables))
+	for _, t := range tables {
+		resp = append(resp, toTableResponse(t))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (ctl *FloorController) CreateTable(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+
+	var req controllerdto.CreateTableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	table, err := ctl.floorService.CreateTable(c.Request.Context(), currentUser, servicedto.CreateTableParams{
+		Name:  req.Name,
+		Area:  req.Area,
+		Seats: req.Seats,
+	})
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrTableNameTaken:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create table"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTableResponse(*table))
+}
+
+func (ctl *FloorController) FloorStatus(c *gin.Context) {
+	status, err := ctl.floorService.FloorStatus(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load floor status"})
+		return
+	}
+
+	resp := controllerdto.FloorStatusResponse{
+		At:         status.At.Format(time.RFC3339),
+		Covers:     status.Covers,
+		Areas:      make([]controllerdto.FloorAreaResponse, 0, len(status.Areas)),
+		Unassigned: toAdminReservationResponses(status.Unassigned),
+		Upcoming:   toAdminReservationResponses(status.Upcoming),
+	}
+	for _, a := range status.Areas {
+		area := controllerdto.FloorAreaResponse{
+			Name:     a.Name,
+			Seats:    a.Seats,
+			Covers:   a.Covers,
+			Occupied: a.Occupied,
+			Tables:   make([]controllerdto.FloorTableResponse, 0, len(a.Tables)),
+		}
+		for _, t := range a.Tables {
+			table := controllerdto.FloorTableResponse{
+				TableResponse: toTableResponse(t.Table),
+				Occupied:      t.Reservation != nil,
+			}
+			if t.Reservation != nil {
+				r := toAdminReservationResponse(*t.Reservation)
+				table.Reservation = &r
+			}
+			if t.FreeAt != nil {
+				freeAt := t.FreeAt.Format(time.RFC3339)
+				table.FreeAt = &freeAt
+			}
+			area.Tables = append(area.Tables, table)
+		}
+		resp.Areas = append(resp.Areas, area)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func toTableResponse(t servicedto.Table) controllerdto.TableResponse {
+	return controllerdto.TableResponse{
+		ID:    t.ID,
+		Name:  t.Name,
+		Area:  t.Area,
+		Seats: t.Seats,
+	}
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+func TestFloorController_TablesWalkInAndFloor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tableClient := newControllerFakeTableClient()
+	resClient := newControllerFakeReservationClient()
+	resSvc := service.NewReservationService(resClient,
+		service.WithGuestClient(newControllerFakeUserClient()),
+		service.WithTableClient(tableClient),
+	)
+	floorCtl := NewFloorController(service.NewFloorService(tableClient, resClient, 2*time.Hour, time.UTC))
+	adminCtl := NewAdminController(resSvc)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextUserKey, servicedto.User{ID: 1, IsAdmin: true})
+	})
+	router.POST("/admin/tables", floorCtl.CreateTable)
+	router.GET("/admin/tables", floorCtl.ListTables)
+	router.POST("/admin/walk-ins", adminCtl.WalkIn)
+	router.GET("/admin/floor", floorCtl.FloorStatus)
+
+	post := func(path string, payload interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/admin/tables", controllerdto.CreateTableRequest{Name: "T1", Area: "terrace", Seats: 4})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 creating table, got %d", w.Code)
+	}
+	var table controllerdto.TableResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &table)
+
+	if w := post("/admin/tables", controllerdto.CreateTableRequest{Name: "T1", Seats: 2}); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for duplicate table name, got %d", w.Code)
+	}
+
+	w = post("/admin/walk-ins", controllerdto.WalkInRequest{
+		Guest:   &controllerdto.GuestRequest{Name: "Walk In", Phone: "+34600123456"},
+		People:  3,
+		TableID: &table.ID,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for walk-in, got %d: %s", w.Code, w.Body.String())
+	}
+	var walkIn controllerdto.AdminReservationResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &walkIn)
+	if walkIn.Status != servicedto.StatusSeated || walkIn.Channel != servicedto.ChannelWalkIn {
+		t.Fatalf("unexpected walk-in: %+v", walkIn)
+	}
+
+	if w := post("/admin/walk-ins", controllerdto.WalkInRequest{UserID: walkIn.User.ID, People: 2, TableID: &table.ID}); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for occupied table, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/floor", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for floor, got %d", w.Code)
+	}
+	var floor controllerdto.FloorStatusResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &floor)
+	if floor.Covers != 3 || len(floor.Areas) != 1 || !floor.Areas[0].Tables[0].Occupied {
+		t.Fatalf("unexpected floor status: %+v", floor)
+	}
+}
+
+type controllerFakeTableClient struct {
+	tables []servicedto.Table
+}
+
+func newControllerFakeTableClient() *controllerFakeTableClient {
+	return &controllerFakeTableClient{}
+}
+
+func (f *controllerFakeTableClient) CreateTable(ctx context.Context, params servicedto.CreateTableParams) (*servicedto.Table, error) {
+	table := servicedto.Table{ID: uint(len(f.tables) + 1), Name: params.Name, Area: params.Area, Seats: params.Seats}
+	f.tables = append(f.tables, table)
+	return &table, nil
+}
+
+func (f *controllerFakeTableClient) ListTables(ctx context.Context) ([]servicedto.Table, error) {
+	return append([]servicedto.Table(nil), f.tables...), nil
+}
+
+func (f *controllerFakeTableClient) GetTableByID(ctx context.Context, id uint) (*servicedto.Table, error) {
+	for _, t := range f.tables {
+		if t.ID == id {
+			copy := t
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+type GuestController struct {
+	guestService *service.GuestService
+}
+
+func NewGuestController(guestService *service.GuestService) *GuestController {
+	return &GuestController{guestService: guestService}
+}
+
+func (ctl *GuestController) Profile(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid guest id"})
+		return
+	}
+
+	profile, err := ctl.guestService.GuestProfile(c.Request.Context(), currentUser, id)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		case service.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load guest profile"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toGuestProfileResponse(profile))
+}
+
+func (ctl *GuestController) ListRestrictions(c *gin.Context) {
+	restrictions, err := ctl.guestService.ListRestrictions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list restrictions"})
+		return
+	}
+
+	resp := make([]controllerdto.RestrictionResponse, 0, len(restrictions))
+	for _, r := range restrictions {
+		resp = append(resp, toRestrictionResponse(r))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (ctl *GuestController) LiftRestriction(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid guest id"})
+		return
+	}
+
+	var req controllerdto.LiftRestrictionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	lifted, err := ctl.guestService.LiftRestriction(c.Request.Context(), currentUser, servicedto.LiftRestrictionInput{
+		UserID: id,
+		Note:   req.Note,
+	})
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrRestrictionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to lift restriction"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toRestrictionResponse(*lifted))
+}
+
+func toGuestProfileResponse(p *servicedto.GuestProfile) controllerdto.GuestProfileResponse {
+	resp := controllerdto.GuestProfileResponse{
+		User: controllerdto.AdminUserInfo{
+			ID:      p.User.ID,
+			Name:    p.User.Name,
+			Email:   p.User.Email,
+			Phone:   p.User.Phone,
+			IsGuest: p.User.IsGuest,
+		},
+		Visits:           p.Visits,
+		NoShows:          p.NoShows,
+		Cancellations:    p.Cancellations,
+		LateCancels:      p.LateCancels,
+		Upcoming:         p.Upcoming,
+		AveragePartySize: p.AveragePartySize,
+		Preferences: controllerdto.GuestPreferencesResponse{
+			Allergens:     nonNilStrings(p.Preferences.Allergens),
+			DietaryStyles: nonNilStrings(p.Preferences.DietaryStyles),
+			Accessibility: nonNilStrings(p.Preferences.Accessibility),
+			HighChairs:    p.Preferences.HighChairs,
+		},
+		Tags:   nonNilStrings(p.Tags),
+		Recent: toAdminReservationResponses(p.Recent),
+	}
+	if p.Restriction != nil {
+		restriction := toRestrictionResponse(*p.Restriction)
+		resp.Restriction = &restriction
+	}
+	resp.Incidents = make([]controllerdto.IncidentResponse, 0, len(p.Incidents))
+	for _, i := range p.Incidents {
+		resp.Incidents = append(resp.Incidents, controllerdto.IncidentResponse{
+			ID:            i.ID,
+			ReservationID: i.ReservationID,
+			Kind:          i.Kind,
+			OccurredAt:    i.OccurredAt.Format(time.RFC3339),
+		})
+	}
+	if p.FirstVisit != nil {
+		first := p.FirstVisit.Format("2006-01-02")
+		resp.FirstVisit = &first
+	}
+	if p.LastVisit != nil {
+		last := p.LastVisit.Format("2006-01-02")
+		resp.LastVisit = &last
+	}
+	return resp
+}
+
+func toRestrictionResponse(r servicedto.Restriction) controllerdto.RestrictionResponse {
+	resp := controllerdto.RestrictionResponse{
+		ID:        r.ID,
+		UserID:    r.UserID,
+		Level:     r.Level,
+		Reason:    r.Reason,
+		Incidents: r.Incidents,
+		CreatedAt: r.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: r.UpdatedAt.Format(time.RFC3339),
+		LiftedBy:  r.LiftedBy,
+		LiftNote:  r.LiftNote,
+	}
+	if r.User != nil {
+		resp.User = &controllerdto.AdminUserInfo{
+			ID:      r.User.ID,
+			Name:    r.User.Name,
+			Email:   r.User.Email,
+			Phone:   r.User.Phone,
+			IsGuest: r.User.IsGuest,
+		}
+	}
+	if r.LiftedAt != nil {
+		lifted := r.LiftedAt.Format(time.RFC3339)
+		resp.LiftedAt = &lifted
+	}
+	return resp
+}
+
+func toGuestSummaryResponse(g *servicedto.GuestSummary) *controllerdto.GuestSummaryResponse {
+	if g == nil {
+		return nil
+	}
+	return &controllerdto.GuestSummaryResponse{
+		Visits:      g.Visits,
+		NoShows:     g.NoShows,
+		VisitNumber: g.VisitNumber,
+		Tags:        nonNilStrings(g.Tags),
+	}
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
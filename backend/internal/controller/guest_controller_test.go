@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+func TestGuestController_ProfileAndNoShow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	userClient := newControllerFakeUserClient()
+	guest, _ := userClient.CreateUser(ctx, servicedto.CreateUserParams{Name: "Regular", Email: "regular@example.com"})
+	resClient := newControllerFakeReservationClient()
+	past := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	_, _ = resClient.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: guest.ID, Date: past, Time: "20:00", People: 4, Status: servicedto.StatusSeated})
+	missed, _ := resClient.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: guest.ID, Date: past.AddDate(0, 1, 0), Time: "20:00", People: 2, Status: servicedto.StatusConfirmed})
+
+	adminCtl := NewAdminController(service.NewReservationService(resClient))
+	guestCtl := NewGuestController(service.NewGuestService(userClient, resClient, nil, time.UTC, 0))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextUserKey, servicedto.User{ID: 100, IsAdmin: true})
+	})
+	router.PATCH("/admin/reservations/:id/no-show", adminCtl.MarkNoShow)
+	router.GET("/admin/reservations/by-code/:code", adminCtl.GetReservationByCode)
+	router.GET("/admin/guests/:id", guestCtl.Profile)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	if w := do(http.MethodPatch, "/admin/reservations/2/no-show"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 marking no-show, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPatch, "/admin/reservations/1/no-show"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for seated party, got %d", w.Code)
+	}
+
+	w := do(http.MethodGet, "/admin/reservations/by-code/"+missed.Code)
+	var res controllerdto.AdminReservationResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	if w.Code != http.StatusOK || res.Guest == nil || res.Guest.Visits != 1 || res.Guest.NoShows != 1 || res.Guest.VisitNumber != 2 {
+		t.Fatalf("unexpected guest summary: %d %s", w.Code, w.Body.String())
+	}
+
+	w = do(http.MethodGet, "/admin/guests/1")
+	var profile controllerdto.GuestProfileResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &profile)
+	if w.Code != http.StatusOK || profile.Visits != 1 || profile.NoShows != 1 || profile.AveragePartySize != 4 ||
+		profile.LastVisit == nil || *profile.LastVisit != "2025-01-10" || len(profile.Recent) != 2 {
+		t.Fatalf("unexpected profile: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := do(http.MethodGet, "/admin/guests/99"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown guest, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/admin/guests/abc"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad id, got %d", w.Code)
+	}
+}
+
+func TestAdminController_NoShowReview(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	resClient := newControllerFakeReservationClient()
+	day := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	missed, _ := resClient.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 1, Date: day, Time: "20:00", People: 2, Status: servicedto.StatusNoShow})
+	seated, _ := resClient.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 1, Date: day, Time: "21:00", People: 2, Status: servicedto.StatusSeated})
+	adminCtl := NewAdminController(service.NewReservationService(resClient))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextUserKey, servicedto.User{ID: 100, IsAdmin: true})
+	})
+	router.POST("/admin/reservations/:id/no-show/revert", adminCtl.RevertNoShow)
+	router.GET("/admin/no-shows", adminCtl.NoShowSummary)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPost, fmt.Sprintf("/admin/reservations/%d/no-show/revert", missed.ID), `{"status":"confirmed"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid status, got %d", w.Code)
+	}
+	w := do(http.MethodPost, fmt.Sprintf("/admin/reservations/%d/no-show/revert", missed.ID), `{"status":"cancelled"}`)
+	var res controllerdto.ReservationResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	if w.Code != http.StatusOK || res.Status != servicedto.StatusCancelled {
+		t.Fatalf("expected reverted reservation, got %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, fmt.Sprintf("/admin/reservations/%d/no-show/revert", seated.ID), ""); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a seated party, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/admin/reservations/99/no-show/revert", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+
+	w = do(http.MethodGet, "/admin/no-shows?date=2025-01-10", "")
+	var summary controllerdto.NoShowSummaryResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &summary)
+	if w.Code != http.StatusOK || summary.Date != "2025-01-10" || summary.Entries == nil {
+		t.Fatalf("unexpected summary: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "/admin/no-shows?date=tomorrow", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad date, got %d", w.Code)
+	}
+}
+
+func TestGuestController_Restrictions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	userClient := newControllerFakeUserClient()
+	guest, _ := userClient.CreateUser(ctx, servicedto.CreateUserParams{Name: "Flaky", Email: "flaky@example.com"})
+	resClient := newControllerFakeReservationClient()
+	restrictionClient := &controllerFakeRestrictionClient{}
+	missed, _ := resClient.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: guest.ID, Date: time.Now(), Time: "00:00", People: 2, Status: servicedto.StatusConfirmed})
+
+	resSvc := service.NewReservationService(resClient, service.WithSanctions(restrictionClient, servicedto.SanctionPolicy{
+		Rules: []servicedto.SanctionRule{{Incidents: 1, Level: servicedto.RestrictionBlock}},
+	}))
+	adminCtl := NewAdminController(resSvc)
+	resCtl := NewReservationController(resSvc)
+	guestCtl := NewGuestController(service.NewGuestService(userClient, resClient, restrictionClient, time.UTC, 0))
+
+	as := func(user servicedto.User) gin.HandlerFunc {
+		return func(c *gin.Context) { c.Set(middleware.ContextUserKey, user) }
+	}
+	admin := as(servicedto.User{ID: 100, IsAdmin: true})
+	router := gin.New()
+	router.POST("/reservations", as(*guest), resCtl.CreateReservation)
+	router.PATCH("/admin/reservations/:id/no-show", admin, adminCtl.MarkNoShow)
+	router.GET("/admin/restrictions", admin, guestCtl.ListRestrictions)
+	router.GET("/admin/guests/:id", admin, guestCtl.Profile)
+	router.POST("/admin/guests/:id/restriction/lift", admin, guestCtl.LiftRestriction)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	book := `{"date":"2030-01-10","time":"20:00","people":2}`
+
+	if w := do(http.MethodPatch, fmt.Sprintf("/admin/reservations/%d/no-show", missed.ID), ""); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 marking no-show, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/reservations", book); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for blocked guest, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := do(http.MethodGet, "/admin/restrictions", "")
+	var list []controllerdto.RestrictionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list) != 1 || list[0].UserID != guest.ID || list[0].Level != servicedto.RestrictionBlock {
+		t.Fatalf("unexpected restrictions: %d %s", w.Code, w.Body.String())
+	}
+
+	w = do(http.MethodGet, fmt.Sprintf("/admin/guests/%d", guest.ID), "")
+	var profile controllerdto.GuestProfileResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &profile)
+	if profile.Restriction == nil || len(profile.Incidents) != 1 || profile.Incidents[0].Kind != servicedto.IncidentNoShow {
+		t.Fatalf("expected restriction and incident in profile, got %s", w.Body.String())
+	}
+
+	w = do(http.MethodPost, fmt.Sprintf("/admin/guests/%d/restriction/lift", guest.ID), `{"note":"called to apologise"}`)
+	var lifted controllerdto.RestrictionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &lifted)
+	if w.Code != http.StatusOK || lifted.LiftedAt == nil || lifted.LiftNote == nil || *lifted.LiftNote != "called to apologise" {
+		t.Fatalf("unexpected lift response: %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, fmt.Sprintf("/admin/guests/%d/restriction/lift", guest.ID), ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 with nothing to lift, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/reservations", book); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 after lift, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+type controllerFakeRestrictionClient struct {
+	incidents    []servicedto.Incident
+	restrictions []servicedto.Restriction
+}
+
+func (f *controllerFakeRestrictionClient) RecordIncident(ctx context.Context, params servicedto.CreateIncidentParams) (*servicedto.Incident, error) {
+	incident := servicedto.Incident{
+		ID:            uint(len(f.incidents) + 1),
+		UserID:        params.UserID,
+		ReservationID: params.ReservationID,
+		Kind:          params.Kind,
+		OccurredAt:    params.OccurredAt,
+	}
+	f.incidents = append(f.incidents, incident)
+	return &incident, nil
+}
+
+func (f *controllerFakeRestrictionClient) DeleteIncident(ctx context.Context, reservationID uint, kind string) error {
+	f.incidents = slices.DeleteFunc(f.incidents, func(i servicedto.Incident) bool {
+		return i.ReservationID == reservationID && i.Kind == kind
+	})
+	return nil
+}
+
+func (f *controllerFakeRestrictionClient) ListIncidentsByUser(ctx context.Context, userID uint) ([]servicedto.Incident, error) {
+	var list []servicedto.Incident
+	for _, i := range f.incidents {
+		if i.UserID == userID {
+			list = append(list, i)
+		}
+	}
+	return list, nil
+}
+
+func (f *controllerFakeRestrictionClient) CountIncidents(ctx context.Context, userID uint, kinds []string, since time.Time) (int, error) {
+	list, _ := f.ListIncidentsByUser(ctx, userID)
+	return len(list), nil
+}
+
+func (f *controllerFakeRestrictionClient) ListRestrictionsByUser(ctx context.Context, userID uint) ([]servicedto.Restriction, error) {
+	var list []servicedto.Restriction
+	for i := len(f.restrictions) - 1; i >= 0; i-- {
+		if f.restrictions[i].UserID == userID {
+			list = append(list, f.restrictions[i])
+		}
+	}
+	return list, nil
+}
+
+func (f *controllerFakeRestrictionClient) ListActiveRestrictions(ctx context.Context) ([]servicedto.Restriction, error) {
+	var list []servicedto.Restriction
+	for _, r := range f.restrictions {
+		if r.Active() {
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
+
+func (f *controllerFakeRestrictionClient) SaveRestriction(ctx context.Context, params servicedto.SaveRestrictionParams) (*servicedto.Restriction, error) {
+	r := servicedto.Restriction{ID: uint(len(f.restrictions) + 1), UserID: params.UserID, Level: params.Level, Reason: params.Reason, Incidents: params.Incidents}
+	f.restrictions = append(f.restrictions, r)
+	return &r, nil
+}
+
+func (f *controllerFakeRestrictionClient) LiftRestriction(ctx context.Context, params servicedto.LiftRestrictionParams) (*servicedto.Restriction, error) {
+	for i, r := range f.restrictions {
+		if r.UserID == params.UserID && r.Active() {
+			at, by := params.At, params.LiftedBy
+			f.restrictions[i].LiftedAt, f.restrictions[i].LiftedBy, f.restrictions[i].LiftNote = &at, &by, params.Note
+			lifted := f.restrictions[i]
+			return &lifted, nil
+		}
+	}
+	return nil, nil
+}
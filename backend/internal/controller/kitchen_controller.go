@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/service"
+)
+
+type KitchenController struct {
+	kitchenService *service.KitchenService
+}
+
+func NewKitchenController(kitchenService *service.KitchenService) *KitchenController {
+	return &KitchenController{kitchenService: kitchenService}
+}
+
+func (ctl *KitchenController) Summary(c *gin.Context) {
+	summary, err := ctl.kitchenService.KitchenSummary(c.Request.Context(), c.Query("date"), c.Query("period"))
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build kitchen summary"})
+		}
+		return
+	}
+
+	resp := controllerdto.KitchenSummaryResponse{
+		Date:    summary.Date,
+		Periods: make([]controllerdto.KitchenPeriodResponse, 0, len(summary.Periods)),
+	}
+	for _, p := range summary.Periods {
+		period := controllerdto.KitchenPeriodResponse{
+			Name:          p.Period.Name,
+			Start:         p.Period.Start,
+			End:           p.Period.End,
+			Reservations:  p.Reservations,
+			Covers:        p.Covers,
+			Allergens:     p.Allergens,
+			DietaryStyles: p.DietaryStyles,
+			Accessibility: p.Accessibility,
+			HighChairs:    p.HighChairs,
+			WithNeeds:     make([]controllerdto.KitchenReservationResponse, 0, len(p.WithNeeds)),
+		}
+		for _, r := range p.WithNeeds {
+			period.WithNeeds = append(period.WithNeeds, toKitchenReservationResponse(r))
+		}
+		resp.Periods = append(resp.Periods, period)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func toKitchenReservationResponse(r servicedto.Reservation) controllerdto.KitchenReservationResponse {
+	var name string
+	if r.User != nil {
+		name = r.User.Name
+	}
+	return controllerdto.KitchenReservationResponse{
+		ID:           r.ID,
+		Code:         r.Code,
+		Time:         r.Time,
+		People:       r.People,
+		GuestName:    name,
+		TableID:      r.TableID,
+		Status:       r.Status,
+		Comment:      r.Comment,
+		Requirements: toRequirementsResponse(r.Requirements),
+	}
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+func TestKitchenController_RequirementsFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resClient := newControllerFakeReservationClient()
+	resCtl := NewReservationController(service.NewReservationService(resClient))
+	kitchenCtl := NewKitchenController(service.NewKitchenService(resClient, nil))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextUserKey, servicedto.User{ID: 1, Name: "Guest"})
+	})
+	router.GET("/requirements-catalogue", resCtl.RequirementsCatalogue)
+	router.POST("/reservations", resCtl.CreateReservation)
+	router.GET("/admin/kitchen", kitchenCtl.Summary)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/requirements-catalogue", nil))
+	var catalogue controllerdto.RequirementsCatalogueResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &catalogue)
+	if w.Code != http.StatusOK || len(catalogue.Allergens) == 0 || catalogue.MaxHighChairs == 0 {
+		t.Fatalf("unexpected catalogue: %d %s", w.Code, w.Body.String())
+	}
+
+	create := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/reservations", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	w = create(`{"date":"2025-12-01","time":"20:00","people":2,"allergens":["crustaceans"],"accessibility":["wheelchair"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created controllerdto.ReservationResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if len(created.Allergens) != 1 || created.Allergens[0] != "crustaceans" {
+		t.Fatalf("expected allergens in response, got %s", w.Body.String())
+	}
+	if w := create(`{"date":"2025-12-01","time":"20:00","people":2,"allergens":["unicorn"]}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown allergen, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/kitchen?date=2025-12-01&period=dinner", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var summary controllerdto.KitchenSummaryResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &summary)
+	if len(summary.Periods) != 1 || summary.Periods[0].Allergens["crustaceans"] != 1 || len(summary.Periods[0].WithNeeds) != 1 {
+		t.Fatalf("unexpected kitchen summary: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/kitchen", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without date, got %d", w.Code)
+	}
+}
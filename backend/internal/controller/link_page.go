@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+)
+
+// linkPage is what a guest sees when opening a one-click link in a browser.
+// API clients asking for JSON get a ReservationLinkResponse instead.
+var linkPage = template.Must(template.New("link").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><meta name="robots" content="noindex"><title>Vesuvio</title></head>
+<body>
+{{if .Error}}<p>{{.Error}}</p>
+{{else}}<p>Reservation {{.Reservation.Code}}: {{.Reservation.People}} on {{.Reservation.Date}} at {{.Reservation.Time}} ({{.Reservation.Status}}).</p>
+{{if .Done}}<p>{{if eq .Action "confirm"}}Thank you, we have noted that you are coming.{{else}}Your reservation has been cancelled.{{end}}</p>
+{{else}}<form method="post" action="/links">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">{{if eq .Action "confirm"}}Confirm I am coming{{else}}Cancel my reservation{{end}}</button>
+</form>
+{{end}}{{end}}</body>
+</html>
+`))
+
+type linkPageData struct {
+	controllerdto.ReservationLinkResponse
+	Token string
+	Error string
+}
+
+func writeLink(c *gin.Context, token, action string, done bool, res servicedto.Reservation) {
+	resp := controllerdto.ReservationLinkResponse{Action: action, Done: done, Reservation: toReservationResponse(res)}
+	if !wantsHTML(c) {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+	renderLinkPage(c, http.StatusOK, linkPageData{ReservationLinkResponse: resp, Token: token})
+}
+
+func renderLinkError(c *gin.Context, status int, msg string) {
+	if !wantsHTML(c) {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+	renderLinkPage(c, status, linkPageData{Error: msg})
+}
+
+func renderLinkPage(c *gin.Context, status int, data linkPageData) {
+	var buf bytes.Buffer
+	if err := linkPage.Execute(&buf, data); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to render link"})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// wantsHTML reports whether the caller is a browser rather than an API client.
+func wantsHTML(c *gin.Context) bool {
+	return c.NegotiateFormat(binding.MIMEJSON, binding.MIMEHTML) == binding.MIMEHTML
+}
@@ -0,0 +1,324 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+const (
+	streamRetry            = 3 * time.Second // how soon browsers reconnect after a dropped stream
+	defaultStreamHeartbeat = 15 * time.Second
+	socketWriteWait        = 10 * time.Second
+	socketReadLimit        = 4096
+	maxFollowed            = 50 // reservations one socket may follow
+)
+
+type LiveController struct {
+	liveService *service.LiveService
+	heartbeat   time.Duration
+	upgrader    websocket.Upgrader
+}
+
+// NewLiveController sends a heartbeat comment every heartbeat, 15s if not
+// positive, so proxies keep idle streams open. Browsers open sockets from
+// any page, so only the frontend's origins may upgrade, "*" allowing any;
+// clients that send no Origin are not browsers and may too.
+func NewLiveController(liveService *service.LiveService, heartbeat time.Duration, origins []string) *LiveController {
+	if heartbeat <= 0 {
+		heartbeat = defaultStreamHeartbeat
+	}
+	return &LiveController{
+		liveService: liveService,
+		heartbeat:   heartbeat,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || slices.Contains(origins, "*") || slices.Contains(origins, strings.ToLower(origin))
+			},
+		},
+	}
+}
+
+// IssueStreamToken returns a short-lived token for opening the caller's
+// live streams, which browsers cannot send headers to.
+func (ctl *LiveController) IssueStreamToken(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	token, err := ctl.liveService.IssueStreamToken(c.Request.Context(), currentUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue stream token"})
+		return
+	}
+	c.JSON(http.StatusOK, controllerdto.StreamTokenResponse{Token: token.Token, ExpiresAt: token.ExpiresAt.Format(time.RFC3339)})
+}
+
+// StreamReservations is a Server-Sent Events stream of the reservation
+// changes on ?date= (default today), opened with a stream token. Each
+// event's id can be sent back as Last-Event-ID, or as ?last_event_id= with
+// a fresh token once the first has expired, to resume after it.
+func (ctl *LiveController) StreamReservations(c *gin.Context) {
+	input := servicedto.StreamReservationsInput{Date: c.Query("date")}
+	raw, name := c.GetHeader("Last-Event-ID"), "Last-Event-ID"
+	if raw == "" {
+		raw, name = c.Query("last_event_id"), "last_event_id"
+	}
+	if raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+			return
+		}
+		input.LastEventID = uint(id)
+	}
+
+	ctx := c.Request.Context()
+	stream, err := ctl.liveService.StreamReservations(ctx, input)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open reservation stream"})
+		}
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry.Milliseconds())
+	for _, u := range stream.Replay {
+		writeReservationUpdate(c.Writer, u)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(ctl.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case u, ok := <-stream.Updates:
+			if !ok {
+				// Fell behind; the browser reconnects with Last-Event-ID.
+				return
+			}
+			writeReservationUpdate(c.Writer, u)
+		case <-heartbeat.C:
+			io.WriteString(c.Writer, ": heartbeat\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeReservationUpdate(w io.Writer, u servicedto.ReservationUpdate) {
+	data, _ := json.Marshal(controllerdto.ReservationUpdateResponse{
+		ID:          u.ID,
+		Type:        u.Type,
+		At:          u.At.Format(time.RFC3339),
+		Reservation: toAdminReservationResponse(u.Reservation),
+	})
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", u.ID, u.Type, data)
+}
+
+// FollowReservations is a WebSocket on which guests follow the status of
+// their own reservations, opened with a stream token. Reservations given as ?reservation_id= (or all of
+// them with ?all=true) are followed from the start, and ?last_event_id=
+// replays the changes missed while reconnecting. The server pings every
+// heartbeat and drops guests that stop answering or fall too far behind.
+func (ctl *LiveController) FollowReservations(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	var lastEventID uint
+	if raw := c.Query("last_event_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last_event_id"})
+			return
+		}
+		lastEventID = uint(id)
+	}
+	var initial []uint
+	for _, raw := range queryList(c, "reservation_id") {
+		id, ok := parseIDParam(raw)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reservation_id"})
+			return
+		}
+		initial = append(initial, id)
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	stream, err := ctl.liveService.StreamStatusChanges(ctx, servicedto.StreamStatusChangesInput{UserID: currentUser.ID, LastEventID: lastEventID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to follow reservations"})
+		return
+	}
+	conn, err := ctl.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // the upgrader has replied
+	}
+	defer conn.Close()
+
+	s := &guestSocket{conn: conn, liveService: ctl.liveService, userID: currentUser.ID, followed: make(map[uint]bool)}
+	s.all = c.Query("all") == "true"
+	for _, id := range initial {
+		if err := s.handle(ctx, controllerdto.LiveClientMessage{Type: controllerdto.LiveMessageSubscribe, ReservationID: id}); err != nil {
+			return
+		}
+	}
+	for _, u := range stream.Replay {
+		if err := s.push(u); err != nil {
+			return
+		}
+	}
+
+	incoming := make(chan controllerdto.LiveClientMessage)
+	go s.read(ctx, cancel, incoming, 2*ctl.heartbeat)
+	ping := time.NewTicker(ctl.heartbeat)
+	defer ping.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-incoming:
+			err = s.handle(ctx, msg)
+		case u, ok := <-stream.Updates:
+			if !ok {
+				s.close(websocket.CloseTryAgainLater, "too far behind, reconnect with last_event_id")
+				return
+			}
+			err = s.push(u)
+		case <-ping.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteWait))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// guestSocket is one guest's WebSocket. Only the handler's goroutine
+// writes to it.
+type guestSocket struct {
+	conn        *websocket.Conn
+	liveService *service.LiveService
+	userID      uint
+	all         bool
+	followed    map[uint]bool
+}
+
+// read passes the guest's messages on until the connection fails or goes
+// quiet for longer than pongWait, then cancels the socket.
+func (s *guestSocket) read(ctx context.Context, cancel context.CancelFunc, incoming chan<- controllerdto.LiveClientMessage, pongWait time.Duration) {
+	defer cancel()
+	s.conn.SetReadLimit(socketReadLimit)
+	s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg controllerdto.LiveClientMessage
+		if json.Unmarshal(data, &msg) != nil {
+			msg = controllerdto.LiveClientMessage{}
+		}
+		select {
+		case incoming <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *guestSocket) handle(ctx context.Context, msg controllerdto.LiveClientMessage) error {
+	switch msg.Type {
+	case controllerdto.LiveMessageSubscribe:
+		if msg.ReservationID == 0 {
+			s.all = true
+			return s.send(controllerdto.LiveServerMessage{Type: controllerdto.LiveMessageSubscribed})
+		}
+		if !s.followed[msg.ReservationID] && len(s.followed) >= maxFollowed {
+			return s.fail(msg.ReservationID, "following too many reservations")
+		}
+		res, err := s.liveService.FollowReservation(ctx, servicedto.FollowReservationInput{UserID: s.userID, ReservationID: msg.ReservationID})
+		if err != nil {
+			switch err {
+			case service.ErrForbiddenReservation:
+				return s.fail(msg.ReservationID, "not allowed to follow this reservation")
+			case service.ErrReservationNotFound:
+				return s.fail(msg.ReservationID, "reservation not found")
+			default:
+				return s.fail(msg.ReservationID, "failed to follow reservation")
+			}
+		}
+		s.followed[res.ID] = true
+		resp := toReservationResponse(*res)
+		return s.send(controllerdto.LiveServerMessage{
+			Type:          controllerdto.LiveMessageSubscribed,
+			ReservationID: res.ID,
+			Status:        res.Status,
+			Reservation:   &resp,
+		})
+	case controllerdto.LiveMessageUnsubscribe:
+		if msg.ReservationID == 0 {
+			s.all = false
+			clear(s.followed)
+		}
+		delete(s.followed, msg.ReservationID)
+		return s.send(controllerdto.LiveServerMessage{Type: controllerdto.LiveMessageUnsubscribed, ReservationID: msg.ReservationID})
+	default:
+		return s.fail(0, "unknown message")
+	}
+}
+
+// push sends a status change if the guest follows the reservation.
+func (s *guestSocket) push(u servicedto.ReservationUpdate) error {
+	if !s.all && !s.followed[u.Reservation.ID] {
+		return nil
+	}
+	resp := toReservationResponse(u.Reservation)
+	return s.send(controllerdto.LiveServerMessage{
+		Type:           controllerdto.LiveMessageStatus,
+		EventID:        u.ID,
+		ReservationID:  u.Reservation.ID,
+		Status:         u.Status,
+		PreviousStatus: u.PreviousStatus,
+		At:             u.At.Format(time.RFC3339),
+		Reservation:    &resp,
+	})
+}
+
+func (s *guestSocket) fail(reservationID uint, msg string) error {
+	return s.send(controllerdto.LiveServerMessage{Type: controllerdto.LiveMessageError, ReservationID: reservationID, Error: msg})
+}
+
+func (s *guestSocket) send(msg controllerdto.LiveServerMessage) error {
+	s.conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+	return s.conn.WriteJSON(msg)
+}
+
+func (s *guestSocket) close(code int, reason string) {
+	s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(socketWriteWait))
+}
@@ -0,0 +1,282 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+func TestLiveController_StreamReservations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	resClient := newControllerFakeReservationClient()
+	date := time.Date(2030, 1, 11, 0, 0, 0, 0, time.UTC)
+	res, _ := resClient.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	broadcaster := service.NewMemoryBroadcaster(10)
+	update := func(id uint, status string) servicedto.ReservationUpdate {
+		r := *res
+		r.Status = status
+		return servicedto.ReservationUpdate{ID: id, Type: servicedto.LiveReservationUpdated, Reservation: r, At: date}
+	}
+	broadcaster.Publish(ctx, update(1, servicedto.StatusPending))
+	broadcaster.Publish(ctx, update(2, servicedto.StatusConfirmed))
+
+	users := newControllerFakeUserClient()
+	admin, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Host", Email: "host@example.com", IsAdmin: true})
+	guest, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Guest", Email: "guest@example.com"})
+	liveService := service.NewLiveService(broadcaster, nil, resClient, users, service.NewLinkSigner([]byte("secret")), time.UTC)
+	ctl := NewLiveController(liveService, 20*time.Millisecond, nil)
+	router := gin.New()
+	router.POST("/my/stream-token", middleware.AuthMiddleware(service.NewAuthService(users, servicedto.PhoneFormat{})), ctl.IssueStreamToken)
+	router.GET("/admin/reservations/stream", middleware.StreamTokenAuth(liveService), middleware.AdminOnly(), ctl.StreamReservations)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	issue := func(userID uint) string {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/my/stream-token", nil)
+		req.Header.Set("X-User-ID", fmt.Sprint(userID))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("issue token: %v", err)
+		}
+		defer resp.Body.Close()
+		var body controllerdto.StreamTokenResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || resp.StatusCode != http.StatusOK || body.Token == "" {
+			t.Fatalf("unexpected token response: %d %+v %v", resp.StatusCode, body, err)
+		}
+		return body.Token
+	}
+	// EventSource cannot send headers, so the stream is opened with a
+	// token, and only staff tokens open it.
+	for query, want := range map[string]int{
+		"":                          http.StatusUnauthorized,
+		"?token=forged":             http.StatusUnauthorized,
+		"?token=" + issue(guest.ID): http.StatusForbidden,
+	} {
+		resp, err := http.Get(server.URL + "/admin/reservations/stream" + query)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("%q: expected %d, got %d", query, want, resp.StatusCode)
+		}
+	}
+	token := issue(admin.ID)
+
+	get := func(ctx context.Context, query, lastEventID string) *http.Response {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/admin/reservations/stream"+query+"&token="+token, nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		return resp
+	}
+	for query, lastEventID := range map[string]string{"?date=tomorrow": "", "?date=2030-01-11": "abc"} {
+		resp := get(ctx, query, lastEventID)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%s %q: expected 400, got %d", query, lastEventID, resp.StatusCode)
+		}
+	}
+
+	streamCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	resp := get(streamCtx, "?date=2030-01-11", "1")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// The missed update is replayed, then live ones follow between
+	// heartbeats.
+	lines := bufio.NewScanner(resp.Body)
+	var ids []string
+	var statuses []string
+	heartbeats := 0
+	for len(ids) < 2 || heartbeats == 0 {
+		if !lines.Scan() {
+			t.Fatalf("stream ended early: %v", lines.Err())
+		}
+		line := lines.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			ids = append(ids, strings.TrimPrefix(line, "id: "))
+			if len(ids) == 1 {
+				broadcaster.Publish(ctx, update(3, servicedto.StatusSeated))
+			}
+		case strings.HasPrefix(line, "data: "):
+			var body controllerdto.ReservationUpdateResponse
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &body); err != nil {
+				t.Fatalf("bad data %q: %v", line, err)
+			}
+			statuses = append(statuses, body.Reservation.Status)
+		case line == ": heartbeat":
+			heartbeats++
+		}
+	}
+	if strings.Join(ids, ",") != "2,3" || strings.Join(statuses, ",") != "confirmed,seated" {
+		t.Fatalf("unexpected events: %v %v", ids, statuses)
+	}
+
+	// A reconnect with a fresh token resumes from ?last_event_id=.
+	resumed := get(streamCtx, "?date=2030-01-11&last_event_id=2", "")
+	defer resumed.Body.Close()
+	lines = bufio.NewScanner(resumed.Body)
+	for lines.Scan() && !strings.HasPrefix(lines.Text(), "id: ") {
+	}
+	if lines.Text() != "id: 3" {
+		t.Fatalf("expected the stream resumed after 2, got %q", lines.Text())
+	}
+}
+
+func TestLiveController_FollowReservations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	resClient := newControllerFakeReservationClient()
+	book := func(userID uint) servicedto.Reservation {
+		res, _ := resClient.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: userID, Date: time.Date(2030, 1, 11, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 2, Status: servicedto.StatusPending,
+		})
+		return *res
+	}
+	mine, other, theirs := book(1), book(1), book(2)
+	broadcaster := service.NewMemoryBroadcaster(10)
+	publish := func(id uint, res servicedto.Reservation, from, to string) {
+		broadcaster.Publish(ctx, servicedto.ReservationUpdate{
+			ID: id, Type: servicedto.LiveReservationUpdated, Reservation: res, PreviousStatus: from, Status: to,
+		})
+	}
+	publish(1, mine, servicedto.StatusPending, servicedto.StatusConfirmed)
+	publish(2, mine, servicedto.StatusConfirmed, servicedto.StatusSeated)
+
+	users := newControllerFakeUserClient()
+	guest, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Guest", Email: "guest@example.com"})
+	liveService := service.NewLiveService(broadcaster, nil, resClient, users, service.NewLinkSigner([]byte("secret")), time.UTC)
+	ctl := NewLiveController(liveService, 50*time.Millisecond, []string{"https://book.example.com"})
+	router := gin.New()
+	router.GET("/my/reservations/live", middleware.StreamTokenAuth(liveService), ctl.FollowReservations)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	token, _ := liveService.IssueStreamToken(ctx, *guest)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/my/reservations/live?token=" + token.Token
+
+	// Sockets need a token and, from a browser, the frontend's origin.
+	if _, resp, err := websocket.DefaultDialer.Dial(strings.Split(url, "?")[0], nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %v", err)
+	}
+	if _, resp, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://evil.example.com"}}); err == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 from another origin, got %v", err)
+	}
+	if _, resp, err := websocket.DefaultDialer.Dial(url+"&reservation_id=abc", nil); err == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad reservation id, got %v", err)
+	}
+
+	// Reconnecting replays what was missed after last_event_id.
+	conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("%s&reservation_id=%d&last_event_id=1", url, mine.ID), http.Header{"Origin": {"https://Book.example.com"}})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	read := func() controllerdto.LiveServerMessage {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var msg controllerdto.LiveServerMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return msg
+	}
+	if msg := read(); msg.Type != controllerdto.LiveMessageSubscribed || msg.ReservationID != mine.ID || msg.Reservation == nil {
+		t.Fatalf("unexpected subscribe reply: %+v", msg)
+	}
+	if msg := read(); msg.Type != controllerdto.LiveMessageStatus || msg.EventID != 2 || msg.Status != servicedto.StatusSeated ||
+		msg.PreviousStatus != servicedto.StatusConfirmed {
+		t.Fatalf("expected the missed change, got %+v", msg)
+	}
+
+	// Other guests' reservations cannot be followed.
+	for id, want := range map[uint]string{theirs.ID: "not allowed to follow this reservation", 999: "reservation not found"} {
+		conn.WriteJSON(controllerdto.LiveClientMessage{Type: controllerdto.LiveMessageSubscribe, ReservationID: id})
+		if msg := read(); msg.Type != controllerdto.LiveMessageError || msg.ReservationID != id || msg.Error != want {
+			t.Fatalf("expected %q for %d, got %+v", want, id, msg)
+		}
+	}
+
+	// Only followed reservations are pushed, until the guest follows all.
+	publish(3, other, servicedto.StatusPending, servicedto.StatusConfirmed)
+	publish(4, mine, servicedto.StatusSeated, servicedto.StatusNoShow)
+	if msg := read(); msg.EventID != 4 {
+		t.Fatalf("expected only the followed reservation, got %+v", msg)
+	}
+	conn.WriteJSON(controllerdto.LiveClientMessage{Type: controllerdto.LiveMessageSubscribe})
+	if msg := read(); msg.Type != controllerdto.LiveMessageSubscribed || msg.ReservationID != 0 {
+		t.Fatalf("unexpected subscribe reply: %+v", msg)
+	}
+	publish(5, other, servicedto.StatusConfirmed, servicedto.StatusCancelled)
+	if msg := read(); msg.EventID != 5 || msg.ReservationID != other.ID {
+		t.Fatalf("expected the other reservation, got %+v", msg)
+	}
+
+	// The server pings idle sockets.
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	conn.ReadMessage()
+	select {
+	case <-pinged:
+	default:
+		t.Fatal("expected a ping")
+	}
+}
+
+func TestLiveController_SocketOrigins(t *testing.T) {
+	request := func(origin string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/my/reservations/live", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		return r
+	}
+	open := NewLiveController(nil, 0, []string{"*"})
+	if !open.upgrader.CheckOrigin(request("https://any.example.com")) {
+		t.Fatal("expected * to allow any origin")
+	}
+	listed := NewLiveController(nil, 0, []string{"https://book.example.com"})
+	for origin, want := range map[string]bool{
+		"https://book.example.com": true,
+		"https://evil.example.com": false,
+		"":                         true, // not a browser
+	} {
+		if got := listed.upgrader.CheckOrigin(request(origin)); got != want {
+			t.Fatalf("%q: expected %v, got %v", origin, want, got)
+		}
+	}
+}
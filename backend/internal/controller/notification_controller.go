@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+type NotificationController struct {
+	preferenceService *service.PreferenceService
+}
+
+func NewNotificationController(preferenceService *service.PreferenceService) *NotificationController {
+	return &NotificationController{preferenceService: preferenceService}
+}
+
+// GetPreferences returns the current user's phone number and preferences.
+func (ctl *NotificationController) GetPreferences(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+
+	settings, err := ctl.preferenceService.GetSettings(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		writePreferenceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toNotificationPreferencesResponse(*settings))
+}
+
+// UpdatePreferences changes the current user's phone number or opts them
+// in or out of channels and reminders.
+func (ctl *NotificationController) UpdatePreferences(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+
+	var req controllerdto.NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := ctl.preferenceService.UpdateSettings(c.Request.Context(), servicedto.UpdateNotificationSettingsInput{
+		UserID:    currentUser.ID,
+		Phone:     req.Phone,
+		Email:     req.Email,
+		SMS:       req.SMS,
+		Reminders: req.Reminders,
+	})
+	if err != nil {
+		writePreferenceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toNotificationPreferencesResponse(*settings))
+}
+
+func writePreferenceError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidPhone:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update notification preferences"})
+	}
+}
+
+func toNotificationPreferencesResponse(s servicedto.NotificationSettings) controllerdto.NotificationPreferencesResponse {
+	return controllerdto.NotificationPreferencesResponse{
+		Phone:     s.Phone,
+		Email:     s.Email,
+		SMS:       s.SMS,
+		Reminders: s.Reminders,
+	}
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+func TestNotificationController_Preferences(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userClient := newControllerFakeUserClient()
+	user, _ := userClient.CreateUser(context.Background(), servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com", PasswordHash: "hash"})
+
+	ctl := NewNotificationController(service.NewPreferenceService(userClient, servicedto.PhoneFormat{}))
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(service.NewAuthService(userClient, servicedto.PhoneFormat{})))
+	router.GET("/my/notification-preferences", ctl.GetPreferences)
+	router.PUT("/my/notification-preferences", ctl.UpdatePreferences)
+
+	call := func(method, body string) (*httptest.ResponseRecorder, controllerdto.NotificationPreferencesResponse) {
+		req := httptest.NewRequest(method, "/my/notification-preferences", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", fmt.Sprintf("%d", user.ID))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp controllerdto.NotificationPreferencesResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+
+	w, got := call(http.MethodGet, "")
+	if w.Code != http.StatusOK || got.Phone != nil || !got.Email || !got.SMS || !got.Reminders {
+		t.Fatalf("expected defaults, got %d %s", w.Code, w.Body.String())
+	}
+
+	w, got = call(http.MethodPut, `{"phone":"+39 081 1234567","email":false}`)
+	if w.Code != http.StatusOK || got.Phone == nil || *got.Phone != "+390811234567" || got.Email || !got.SMS {
+		t.Fatalf("expected phone set and email off, got %d %s", w.Code, w.Body.String())
+	}
+
+	if w, _ := call(http.MethodPut, `{"phone":"0811234567"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a phone without a country code, got %d", w.Code)
+	}
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/service"
+)
+
+// maxWebhookBody caps payment webhook payloads.
+const maxWebhookBody = 64 << 10
+
+// PaymentSignatureHeader carries the provider's signature of the webhook body.
+const PaymentSignatureHeader = "X-Payment-Signature"
+
+type PaymentController struct {
+	reservationService *service.ReservationService
+}
+
+func NewPaymentController(reservationService *service.ReservationService) *PaymentController {
+	return &PaymentController{reservationService: reservationService}
+}
+
+// Webhook receives payment outcomes from the provider. The raw body is
+// passed on untouched because the signature covers its exact bytes.
+func (ctl *PaymentController) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read body"})
+		return
+	}
+
+	err = ctl.reservationService.HandlePaymentWebhook(c.Request.Context(), payload, c.GetHeader(PaymentSignatureHeader))
+	if err != nil {
+		switch err {
+		case service.ErrInvalidWebhook:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrPaymentNotFound, service.ErrPaymentsDisabled:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process webhook"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func toPaymentResponse(p *servicedto.Payment) *controllerdto.PaymentResponse {
+	if p == nil {
+		return nil
+	}
+	resp := &controllerdto.PaymentResponse{
+		Kind:     p.Kind,
+		Amount:   p.Amount,
+		Currency: p.Currency,
+		Status:   p.Status,
+	}
+	if p.Status == servicedto.PaymentPending {
+		resp.CheckoutURL = p.CheckoutURL
+	}
+	return resp
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"vesuvio/internal/client"
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+func TestPaymentController_DepositFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resClient := newControllerFakeReservationClient()
+	provider := client.NewFakePaymentProvider("whsec")
+	payments := &controllerFakePaymentClient{}
+	resSvc := service.NewReservationService(resClient,
+		service.WithPayments(provider, payments, servicedto.DepositPolicy{MinPeople: 8, AmountPerPerson: 1000}),
+	)
+	resCtl := NewReservationController(resSvc)
+	paymentCtl := NewPaymentController(resSvc)
+
+	router := gin.New()
+	router.POST("/webhooks/payments", paymentCtl.Webhook)
+	router.POST("/reservations", func(c *gin.Context) {
+		c.Set(middleware.ContextUserKey, servicedto.User{ID: 1, Name: "Guest"})
+	}, resCtl.CreateReservation)
+
+	req := httptest.NewRequest(http.MethodPost, "/reservations", bytes.NewBufferString(`{"date":"2030-01-10","time":"20:00","people":8}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var created controllerdto.ReservationResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if w.Code != http.StatusCreated || created.Status != servicedto.StatusAwaitingPayment || created.Payment == nil ||
+		created.Payment.Amount != 8000 || created.Payment.CheckoutURL == "" {
+		t.Fatalf("expected reservation awaiting payment, got %d %s", w.Code, w.Body.String())
+	}
+
+	webhook := func(payload []byte, signature string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/payments", bytes.NewReader(payload))
+		req.Header.Set(PaymentSignatureHeader, signature)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	payload, signature, err := provider.Complete(payments.payments[0].ProviderRef, true)
+	if err != nil {
+		t.Fatalf("complete payment: %v", err)
+	}
+	if code := webhook(payload, "sha256=deadbeef"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad signature, got %d", code)
+	}
+	if code := webhook(payload, signature); code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", code)
+	}
+	if res := resClient.reservations[created.ID]; res.Status != servicedto.StatusPending {
+		t.Fatalf("expected pending after payment, got %s", res.Status)
+	}
+}
+
+type controllerFakePaymentClient struct {
+	payments []servicedto.Payment
+}
+
+func (f *controllerFakePaymentClient) CreatePayment(ctx context.Context, params servicedto.CreatePaymentParams) (*servicedto.Payment, error) {
+	p := servicedto.Payment{
+		ID:            uint(len(f.payments) + 1),
+		ReservationID: params.ReservationID,
+		Kind:          params.Kind,
+		Amount:        params.Amount,
+		Currency:      params.Currency,
+		Status:        params.Status,
+		ProviderRef:   params.ProviderRef,
+		CheckoutURL:   params.CheckoutURL,
+	}
+	f.payments = append(f.payments, p)
+	return &p, nil
+}
+
+func (f *controllerFakePaymentClient) GetPaymentByReservation(ctx context.Context, reservationID uint) (*servicedto.Payment, error) {
+	for _, p := range f.payments {
+		if p.ReservationID == reservationID {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *controllerFakePaymentClient) GetPaymentByProviderRef(ctx context.Context, ref string) (*servicedto.Payment, error) {
+	for _, p := range f.payments {
+		if p.ProviderRef == ref {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *controllerFakePaymentClient) UpdatePaymentStatus(ctx context.Context, id uint, from, status string) (*servicedto.Payment, error) {
+	for i, p := range f.payments {
+		if p.ID == id && p.Status == from {
+			f.payments[i].Status = status
+			updated := f.payments[i]
+			return &updated, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *controllerFakePaymentClient) MarkSettlementDue(ctx context.Context, reservationID uint, outcome string) (*servicedto.Payment, error) {
+	for i, p := range f.payments {
+		if p.ReservationID == reservationID {
+			f.payments[i].Settlement = outcome
+			updated := f.payments[i]
+			return &updated, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *controllerFakePaymentClient) ClearSettlement(ctx context.Context, id uint, outcome string) error {
+	for i, p := range f.payments {
+		if p.ID == id && p.Settlement == outcome {
+			f.payments[i].Settlement = ""
+		}
+	}
+	return nil
+}
+
+func (f *controllerFakePaymentClient) ListDueSettlements(ctx context.Context, limit int) ([]servicedto.Payment, error) {
+	var due []servicedto.Payment
+	for _, p := range f.payments {
+		if p.Settlement != "" && len(due) < limit {
+			due = append(due, p)
+		}
+	}
+	return due, nil
+}
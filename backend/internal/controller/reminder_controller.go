@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+type ReminderController struct {
+	reminderService *service.ReminderService
+}
+
+func NewReminderController(reminderService *service.ReminderService) *ReminderController {
+	return &ReminderController{reminderService: reminderService}
+}
+
+// ListReminders returns the reminders sent for a reservation.
+func (ctl *ReminderController) ListReminders(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	reservationID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reservation id"})
+		return
+	}
+
+	reminders, err := ctl.reminderService.ListReminders(c.Request.Context(), currentUser, reservationID)
+	if err != nil {
+		switch err {
+		case service.ErrReservationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		case service.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list reminders"})
+		}
+		return
+	}
+
+	resp := make([]controllerdto.ReminderResponse, 0, len(reminders))
+	for _, r := range reminders {
+		resp = append(resp, controllerdto.ReminderResponse{
+			ID:            r.ID,
+			OffsetMinutes: int(r.Offset / time.Minute),
+			Channel:       r.Channel,
+			Recipient:     r.Recipient,
+			Status:        r.Status,
+			Attempts:      r.Attempts,
+			Error:         r.Error,
+			SentAt:        formatOptionalTime(r.SentAt),
+			CreatedAt:     r.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
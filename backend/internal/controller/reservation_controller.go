@@ -31,16 +31,21 @@ func (ctl *ReservationController) CreateReservation(c *gin.Context) {
 	}
 
 	out, err := ctl.reservationService.CreateReservation(c.Request.Context(), servicedto.CreateReservationInput{
-		UserID:  currentUser.ID,
-		Date:    req.Date,
-		Time:    req.Time,
-		People:  req.People,
-		Comment: req.Comment,
+		UserID:       currentUser.ID,
+		Date:         req.Date,
+		Time:         req.Time,
+		People:       req.People,
+		Comment:      req.Comment,
+		Requirements: toServiceRequirements(req.Requirements),
 	})
 	if err != nil {
 		switch err {
-		case service.ErrInvalidInput:
+		case service.ErrInvalidInput, service.ErrInvalidRequirement:
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrOverlappingReservation:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case service.ErrGuestRestricted:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create reservation"})
 		}
@@ -99,6 +104,8 @@ func (ctl *ReservationController) CancelReservation(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "not allowed to cancel this reservation"})
 		case service.ErrReservationNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		case service.ErrStatusConflict:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel reservation"})
 		}
@@ -108,16 +115,137 @@ func (ctl *ReservationController) CancelReservation(c *gin.Context) {
 	c.JSON(http.StatusOK, toReservationResponse(*res))
 }
 
+// ModifyReservation changes the slot, party size or comment of the current
+// user's booking. Omitted fields are left as they are.
+func (ctl *ReservationController) ModifyReservation(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reservation id"})
+		return
+	}
+
+	var req controllerdto.ModifyReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := ctl.reservationService.ModifyReservation(c.Request.Context(), servicedto.ModifyReservationInput{
+		UserID:        currentUser.ID,
+		ReservationID: uint(id),
+		Date:          req.Date,
+		Time:          req.Time,
+		People:        req.People,
+		Comment:       req.Comment,
+	})
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInput, service.ErrInvalidRequirement:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case service.ErrForbiddenReservation:
+			c.JSON(http.StatusForbidden, gin.H{"error": "not allowed to modify this reservation"})
+		case service.ErrReservationNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "reservation not found"})
+		case service.ErrInvalidStatus:
+			c.JSON(http.StatusConflict, gin.H{"error": "only pending or confirmed reservations can be changed"})
+		case service.ErrOverlappingReservation, service.ErrDepositRequired:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to modify reservation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toReservationResponse(*res))
+}
+
+// ShowLink is where a one-click link from a reminder lands. It shows the
+// booking and the action without carrying it out, since mail scanners and
+// chat previews open links nobody clicked; the page posts the token back to
+// FollowLink.
+func (ctl *ReservationController) ShowLink(c *gin.Context) {
+	token := c.Param("token")
+	res, action, err := ctl.reservationService.PreviewReservationLink(c.Request.Context(), token)
+	if err != nil {
+		writeLinkError(c, err)
+		return
+	}
+	writeLink(c, token, action, false, *res)
+}
+
+// FollowLink carries out a signed one-click link. It needs no login: the
+// signature identifies the reservation and the action.
+func (ctl *ReservationController) FollowLink(c *gin.Context) {
+	var req controllerdto.FollowLinkRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, action, err := ctl.reservationService.FollowReservationLink(c.Request.Context(), req.Token)
+	if err != nil {
+		writeLinkError(c, err)
+		return
+	}
+	writeLink(c, req.Token, action, true, *res)
+}
+
+func writeLinkError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrInvalidLink, service.ErrLinksDisabled, service.ErrReservationNotFound:
+		renderLinkError(c, http.StatusNotFound, "link not found")
+	case service.ErrLinkExpired:
+		renderLinkError(c, http.StatusGone, err.Error())
+	case service.ErrInvalidStatus:
+		renderLinkError(c, http.StatusConflict, "reservation is no longer confirmed")
+	default:
+		renderLinkError(c, http.StatusInternalServerError, "failed to follow link")
+	}
+}
+
 func toReservationResponse(res servicedto.Reservation) controllerdto.ReservationResponse {
 	return controllerdto.ReservationResponse{
-		ID:        res.ID,
-		UserID:    res.UserID,
-		Date:      res.Date.Format("2006-01-02"),
-		Time:      res.Time,
-		People:    res.People,
-		Comment:   res.Comment,
-		Status:    res.Status,
-		CreatedAt: res.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: res.UpdatedAt.Format(time.RFC3339),
+		ID:           res.ID,
+		Code:         res.Code,
+		UserID:       res.UserID,
+		Date:         res.Date.Format("2006-01-02"),
+		Time:         res.Time,
+		People:       res.People,
+		Comment:      res.Comment,
+		Requirements: toRequirementsResponse(res.Requirements),
+		Status:       res.Status,
+		Payment:      toPaymentResponse(res.Payment),
+		AttendingAt:  formatOptionalTime(res.AttendingAt),
+		CreatedAt:    res.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    res.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func (ctl *ReservationController) RequirementsCatalogue(c *gin.Context) {
+	catalogue := ctl.reservationService.RequirementsCatalogue()
+	c.JSON(http.StatusOK, controllerdto.RequirementsCatalogueResponse{
+		Allergens:     catalogue.Allergens,
+		DietaryStyles: catalogue.DietaryStyles,
+		Accessibility: catalogue.Accessibility,
+		MaxHighChairs: catalogue.MaxHighChairs,
+	})
+}
+
+func toServiceRequirements(r controllerdto.Requirements) servicedto.Requirements {
+	return servicedto.Requirements{
+		Allergens:     r.Allergens,
+		DietaryStyle:  r.DietaryStyle,
+		Accessibility: r.Accessibility,
+		HighChairs:    r.HighChairs,
+	}
+}
+
+func toRequirementsResponse(r servicedto.Requirements) controllerdto.Requirements {
+	return controllerdto.Requirements{
+		Allergens:     r.Allergens,
+		DietaryStyle:  r.DietaryStyle,
+		Accessibility: r.Accessibility,
+		HighChairs:    r.HighChairs,
 	}
 }
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,7 +24,7 @@ func TestReservationController_UserFlow(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	userClient := newControllerFakeUserClient()
-	authSvc := service.NewAuthService(userClient)
+	authSvc := service.NewAuthService(userClient, servicedto.PhoneFormat{})
 
 	// Seed user
 	user, _ := userClient.CreateUser(context.Background(), servicedto.CreateUserParams{
@@ -164,11 +166,160 @@ func TestReservationController_ErrorBranches(t *testing.T) {
 	}
 }
 
+func TestReservationController_CreateOverlapping(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resClient := newControllerFakeReservationClient()
+	resSvc := service.NewReservationService(resClient, service.WithOverlapPolicy(servicedto.OverlapPolicyReject))
+	resCtl := NewReservationController(resSvc)
+	_, _ = resClient.CreateReservation(context.Background(), servicedto.CreateReservationParams{
+		UserID: 1,
+		Date:   time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		Time:   "20:00",
+		People: 2,
+		Status: servicedto.StatusPending,
+	})
+
+	body, _ := json.Marshal(controllerdto.CreateReservationRequest{Date: "2025-12-01", Time: "20:30", People: 2})
+	req := httptest.NewRequest(http.MethodPost, "/reservations", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c := newTestContext(req, w)
+	c.Set(middleware.ContextUserKey, servicedto.User{ID: 1})
+	resCtl.CreateReservation(c)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for overlapping reservation, got %d", w.Code)
+	}
+}
+
+func TestReservationController_ModifyReservation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userClient := newControllerFakeUserClient()
+	authSvc := service.NewAuthService(userClient, servicedto.PhoneFormat{})
+	owner, _ := userClient.CreateUser(context.Background(), servicedto.CreateUserParams{Name: "Owner", Email: "owner@example.com", PasswordHash: "hash"})
+	other, _ := userClient.CreateUser(context.Background(), servicedto.CreateUserParams{Name: "Other", Email: "other@example.com", PasswordHash: "hash"})
+
+	resClient := newControllerFakeReservationClient()
+	resCtl := NewReservationController(service.NewReservationService(resClient))
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(authSvc))
+	router.PATCH("/reservations/:id", resCtl.ModifyReservation)
+
+	res, _ := resClient.CreateReservation(context.Background(), servicedto.CreateReservationParams{
+		UserID: owner.ID, Date: time.Date(2030, 1, 11, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+	modify := func(userID uint, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", fmt.Sprintf("%d", userID))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	path := fmt.Sprintf("/reservations/%d", res.ID)
+
+	w := modify(owner.ID, path, `{"time":"21:30","people":4}`)
+	var got controllerdto.ReservationResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if w.Code != http.StatusOK || got.Time != "21:30" || got.People != 4 || got.Status != servicedto.StatusPending {
+		t.Fatalf("expected the booking to change, got %d %s", w.Code, w.Body.String())
+	}
+
+	for _, tc := range []struct {
+		userID uint
+		path   string
+		body   string
+		want   int
+	}{
+		{owner.ID, path, `{"date":"11/01/2030"}`, http.StatusBadRequest},
+		{owner.ID, "/reservations/abc", `{}`, http.StatusBadRequest},
+		{other.ID, path, `{"people":3}`, http.StatusForbidden},
+		{owner.ID, "/reservations/999", `{"people":3}`, http.StatusNotFound},
+	} {
+		if w := modify(tc.userID, tc.path, tc.body); w.Code != tc.want {
+			t.Fatalf("%s %s: expected %d, got %d", tc.path, tc.body, tc.want, w.Code)
+		}
+	}
+
+	resClient.UpdateReservationStatus(context.Background(), servicedto.UpdateReservationStatusParams{ID: res.ID, Status: servicedto.StatusCancelled})
+	if w := modify(owner.ID, path, `{"people":3}`); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 for a cancelled booking, got %d", w.Code)
+	}
+}
+
+func TestReservationController_FollowLink(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resClient := newControllerFakeReservationClient()
+	links := service.NewLinkSigner([]byte("secret"))
+	resCtl := NewReservationController(service.NewReservationService(resClient, service.WithReservationLinks(links)))
+	router := gin.New()
+	router.GET("/links/:token", resCtl.ShowLink)
+	router.POST("/links", resCtl.FollowLink)
+
+	date := time.Date(2099, 1, 10, 0, 0, 0, 0, time.UTC)
+	res, _ := resClient.CreateReservation(context.Background(), servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+	slot := date.Add(20 * time.Hour)
+
+	show := func(token, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/links/"+token, nil)
+		req.Header.Set("Accept", accept)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	follow := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/links", strings.NewReader(fmt.Sprintf(`{"token":%q}`, token)))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Opening a link, as a mail scanner would, changes nothing.
+	cancel := links.Sign(res.ID, servicedto.LinkActionCancel, slot)
+	w := show(cancel, "application/json")
+	var out controllerdto.ReservationLinkResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	if w.Code != http.StatusOK || out.Done || out.Action != servicedto.LinkActionCancel {
+		t.Fatalf("expected a preview of the cancellation, got %d %s", w.Code, w.Body.String())
+	}
+	if w := show(cancel, "text/html"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `action="/links"`) || !strings.Contains(w.Body.String(), cancel) {
+		t.Fatalf("expected a landing page posting the token, got %d %s", w.Code, w.Body.String())
+	}
+	if got, _ := resClient.GetReservationByID(context.Background(), res.ID); got.Status != servicedto.StatusConfirmed {
+		t.Fatalf("expected GET to leave the booking alone, got %s", got.Status)
+	}
+
+	w = follow(links.Sign(res.ID, servicedto.LinkActionConfirm, slot))
+	out = controllerdto.ReservationLinkResponse{}
+	_ = json.Unmarshal(w.Body.Bytes(), &out)
+	if w.Code != http.StatusOK || !out.Done || out.Action != servicedto.LinkActionConfirm || out.Reservation.AttendingAt == nil {
+		t.Fatalf("expected attendance confirmed, got %d %s", w.Code, w.Body.String())
+	}
+	if w := show("not-a-link", "application/json"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an invalid link, got %d", w.Code)
+	}
+	if w := follow("not-a-link"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an invalid link, got %d", w.Code)
+	}
+	if w := follow(links.Sign(res.ID, servicedto.LinkActionCancel, time.Now().Add(-time.Minute))); w.Code != http.StatusGone {
+		t.Fatalf("expected 410 for an expired link, got %d", w.Code)
+	}
+	if w := follow(cancel); w.Code != http.StatusOK {
+		t.Fatalf("expected cancellation, got %d %s", w.Code, w.Body.String())
+	}
+	if w := follow(links.Sign(res.ID, servicedto.LinkActionConfirm, slot)); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 once cancelled, got %d", w.Code)
+	}
+}
+
 func TestReservationController_AdminFlow(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	userClient := newControllerFakeUserClient()
-	authSvc := service.NewAuthService(userClient)
+	authSvc := service.NewAuthService(userClient, servicedto.PhoneFormat{})
 	admin, _ := userClient.CreateUser(context.Background(), servicedto.CreateUserParams{
 		Name:         "Admin",
 		Email:        "admin@example.com",
@@ -236,6 +387,244 @@ func TestReservationController_AdminFlow(t *testing.T) {
 	}
 }
 
+func TestAdminController_ListReservationsPaginationHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resClient := newControllerFakeReservationClient()
+	adminCtl := NewAdminController(service.NewReservationService(resClient))
+	for i := 0; i < 3; i++ {
+		_, _ = resClient.CreateReservation(context.Background(), servicedto.CreateReservationParams{
+			UserID: 1,
+			Date:   time.Date(2025, 12, 1+i, 0, 0, 0, 0, time.UTC),
+			Time:   "20:00",
+			People: 2,
+			Status: servicedto.StatusPending,
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/reservations?from=2025-12-01&to=2025-12-31&status=pending,confirmed&limit=2", nil)
+	w := httptest.NewRecorder()
+	adminCtl.ListReservations(newTestContext(req, w))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Total-Count") != "3" {
+		t.Fatalf("expected X-Total-Count 3, got %q", w.Header().Get("X-Total-Count"))
+	}
+	cursor := w.Header().Get("X-Next-Cursor")
+	if cursor == "" {
+		t.Fatalf("expected X-Next-Cursor on a partial page")
+	}
+	var list []controllerdto.AdminReservationResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 2 {
+		t.Fatalf("expected 2 reservations, got %d", len(list))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations?from=2025-12-01&to=2025-12-31&status=pending,confirmed&limit=2&cursor="+cursor, nil)
+	w = httptest.NewRecorder()
+	adminCtl.ListReservations(newTestContext(req, w))
+	list = nil
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list) != 1 || w.Header().Get("X-Next-Cursor") != "" {
+		t.Fatalf("unexpected last page: %d %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations?date=2025-12-01&limit=many", nil)
+	w = httptest.NewRecorder()
+	adminCtl.ListReservations(newTestContext(req, w))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid limit, got %d", w.Code)
+	}
+}
+
+func TestAdminController_ExportReservations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resClient := newControllerFakeReservationClient()
+	adminCtl := NewAdminController(service.NewReservationService(resClient))
+	for i := 0; i < 3; i++ {
+		_, _ = resClient.CreateReservation(context.Background(), servicedto.CreateReservationParams{
+			UserID: 1,
+			Date:   time.Date(2025, 12, 1+i, 0, 0, 0, 0, time.UTC),
+			Time:   "20:00",
+			People: 2,
+			Status: servicedto.StatusPending,
+		})
+	}
+
+	export := func(target string, user servicedto.User) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c := newTestContext(httptest.NewRequest(http.MethodGet, target, nil), w)
+		c.Set(middleware.ContextUserKey, user)
+		adminCtl.ExportReservations(c)
+		return w
+	}
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+
+	w := export("/admin/reservations/export?from=2025-12-01&to=2025-12-02&columns=code,date&locale=it", admin)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("unexpected response: %d %v", w.Code, w.Header())
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="reservations-2025-12-01-2025-12-02.csv"` {
+		t.Fatalf("unexpected Content-Disposition %q", got)
+	}
+	if want := "\uFEFFCode,Date\nVSV-000001,01/12/2025\nVSV-000002,02/12/2025\n"; w.Body.String() != want {
+		t.Fatalf("expected %q, got %q", want, w.Body.String())
+	}
+
+	w = export("/admin/reservations/export?from=2025-12-01&format=xlsx", admin)
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Type"), "spreadsheetml") || !strings.HasPrefix(w.Body.String(), "PK") {
+		t.Fatalf("expected a workbook, got %d %v", w.Code, w.Header())
+	}
+
+	if w := export("/admin/reservations/export?from=2025-12-01&columns=secret", admin); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown column, got %d", w.Code)
+	}
+}
+
+func TestAdminController_ExportReservationsForbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	adminCtl := NewAdminController(service.NewReservationService(newControllerFakeReservationClient()))
+	w := httptest.NewRecorder()
+	c := newTestContext(httptest.NewRequest(http.MethodGet, "/admin/reservations/export?from=2025-12-01", nil), w)
+	c.Set(middleware.ContextUserKey, servicedto.User{ID: 1})
+	adminCtl.ExportReservations(c)
+	if w.Code != http.StatusForbidden || w.Header().Get("Content-Disposition") != "" {
+		t.Fatalf("expected 403 without a download, got %d %v", w.Code, w.Header())
+	}
+}
+
+func TestAdminController_SearchReservations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resClient := newControllerFakeReservationClient()
+	adminCtl := NewAdminController(service.NewReservationService(resClient))
+	for i := 1; i <= 2; i++ {
+		_, _ = resClient.CreateReservation(context.Background(), servicedto.CreateReservationParams{
+			UserID: uint(i),
+			Date:   time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+			Time:   "20:00",
+			People: 2,
+			Status: servicedto.StatusPending,
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/reservations/search?q=user2", nil)
+	w := httptest.NewRecorder()
+	c := newTestContext(req, w)
+	c.Set(middleware.ContextUserKey, servicedto.User{ID: 99, IsAdmin: true})
+	adminCtl.SearchReservations(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var list []controllerdto.AdminReservationResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || list[0].User.ID != 2 {
+		t.Fatalf("unexpected search result: %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations/search?q=x", nil)
+	w = httptest.NewRecorder()
+	c = newTestContext(req, w)
+	c.Set(middleware.ContextUserKey, servicedto.User{ID: 99, IsAdmin: true})
+	adminCtl.SearchReservations(c)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for short query, got %d", w.Code)
+	}
+}
+
+func TestAdminController_BulkConfirm(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resClient := newControllerFakeReservationClient()
+	adminCtl := NewAdminController(service.NewReservationService(resClient))
+	res, _ := resClient.CreateReservation(context.Background(), servicedto.CreateReservationParams{
+		UserID: 1, Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+
+	body := fmt.Sprintf(`{"ids":[%d,404]}`, res.ID)
+	req := httptest.NewRequest(http.MethodPost, "/admin/reservations/bulk/confirm", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c := newTestContext(req, w)
+	c.Set(middleware.ContextUserKey, servicedto.User{ID: 99, IsAdmin: true})
+	adminCtl.BulkConfirm(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp controllerdto.BulkStatusResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Updated != 1 || resp.Failed != 1 || resp.Results[0].Status != servicedto.StatusConfirmed || resp.Results[1].Outcome != servicedto.BulkOutcomeNotFound {
+		t.Fatalf("unexpected bulk response: %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/reservations/bulk/status", bytes.NewBufferString(`{"ids":[1],"status":"weird"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	c = newTestContext(req, w)
+	c.Set(middleware.ContextUserKey, servicedto.User{ID: 99, IsAdmin: true})
+	adminCtl.BulkUpdateStatus(c)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid status, got %d", w.Code)
+	}
+}
+
+func TestAdminController_NotesAndTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resClient := newControllerFakeReservationClient()
+	adminCtl := NewAdminController(service.NewReservationService(resClient))
+	res, _ := resClient.CreateReservation(context.Background(), servicedto.CreateReservationParams{
+		UserID: 1, Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+	id := fmt.Sprintf("%d", res.ID)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/reservations/"+id+"/notes", bytes.NewBufferString(`{"body":"allergic to shellfish"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c := newTestContext(req, w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: id}}
+	c.Set(middleware.ContextUserKey, admin)
+	adminCtl.AddStaffNote(c)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/admin/reservations/"+id+"/tags", bytes.NewBufferString(`{"tags":["vip","unknown"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	c = newTestContext(req, w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: id}}
+	c.Set(middleware.ContextUserKey, admin)
+	adminCtl.SetTags(c)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown tag, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/admin/reservations/"+id+"/tags", bytes.NewBufferString(`{"tags":["vip"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	c = newTestContext(req, w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: id}}
+	c.Set(middleware.ContextUserKey, admin)
+	adminCtl.SetTags(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations?date=2025-12-01&tag=vip", nil)
+	w = httptest.NewRecorder()
+	adminCtl.ListReservations(newTestContext(req, w))
+	var list []controllerdto.AdminReservationResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || len(list[0].Tags) != 1 || len(list[0].Notes) != 1 || list[0].Notes[0].Body != "allergic to shellfish" {
+		t.Fatalf("unexpected admin list: %s", w.Body.String())
+	}
+}
+
 func TestAdminController_ErrorBranches(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -294,6 +683,93 @@ func TestAdminController_ErrorBranches(t *testing.T) {
 	}
 }
 
+func TestAdminController_GetReservationByCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resClient := newControllerFakeReservationClient()
+	adminCtl := NewAdminController(service.NewReservationService(resClient))
+	res, _ := resClient.CreateReservation(context.Background(), servicedto.CreateReservationParams{
+		UserID: 7,
+		Date:   time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		Time:   "20:00",
+		People: 2,
+		Status: servicedto.StatusPending,
+	})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextUserKey, servicedto.User{ID: 1, IsAdmin: true})
+	})
+	router.GET("/admin/reservations/by-code/:code", adminCtl.GetReservationByCode)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/reservations/by-code/"+res.Code, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var found controllerdto.AdminReservationResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &found)
+	if found.ID != res.ID || found.Code != res.Code || found.User.ID != 7 {
+		t.Fatalf("unexpected reservation: %+v", found)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/reservations/by-code/VSV-UNKNWN", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown code, got %d", w.Code)
+	}
+}
+
+func TestAdminController_CreateReservation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userClient := newControllerFakeUserClient()
+	resSvc := service.NewReservationService(newControllerFakeReservationClient(), service.WithGuestClient(userClient))
+	adminCtl := NewAdminController(resSvc)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextUserKey, servicedto.User{ID: 1, IsAdmin: true})
+	})
+	router.POST("/admin/reservations", adminCtl.CreateReservation)
+
+	send := func(payload controllerdto.AdminCreateReservationRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, "/admin/reservations", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send(controllerdto.AdminCreateReservationRequest{
+		Guest:  &controllerdto.GuestRequest{Name: "Luis Garcia", Phone: "+34 600 123 456"},
+		Date:   "2025-12-01",
+		Time:   "20:00",
+		People: 4,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created controllerdto.AdminReservationResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if created.Channel != servicedto.ChannelPhone || !created.User.IsGuest || created.User.Name != "Luis Garcia" {
+		t.Fatalf("unexpected reservation: %+v", created)
+	}
+
+	w = send(controllerdto.AdminCreateReservationRequest{UserID: 404, Date: "2025-12-01", Time: "20:00", People: 2})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown user, got %d", w.Code)
+	}
+
+	w = send(controllerdto.AdminCreateReservationRequest{Date: "2025-12-01", Time: "20:00", People: 2})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without user or guest, got %d", w.Code)
+	}
+}
+
 // Fake reservation client for controller tests.
 type controllerFakeReservationClient struct {
 	reservations map[uint]servicedto.Reservation
@@ -313,19 +789,25 @@ func (f *controllerFakeReservationClient) CreateReservation(ctx context.Context,
 	now := time.Now()
 	res := servicedto.Reservation{
 		ID:     id,
+		Code:   fmt.Sprintf("VSV-%06d", id),
 		UserID: params.UserID,
 		User: &servicedto.User{
 			ID:    params.UserID,
 			Name:  fmt.Sprintf("User%d", params.UserID),
 			Email: fmt.Sprintf("user%d@example.com", params.UserID),
 		},
-		Date:      params.Date,
-		Time:      params.Time,
-		People:    params.People,
-		Comment:   params.Comment,
-		Status:    params.Status,
-		CreatedAt: now,
-		UpdatedAt: now,
+		Date:         params.Date,
+		Time:         params.Time,
+		People:       params.People,
+		TableID:      params.TableID,
+		Comment:      params.Comment,
+		Requirements: params.Requirements,
+		Status:       params.Status,
+		Channel:      params.Channel,
+		ReviewReason: params.ReviewReason,
+		SeatedAt:     params.SeatedAt,
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 	f.reservations[id] = res
 	return &res, nil
@@ -354,28 +836,204 @@ func (f *controllerFakeReservationClient) GetReservationByID(ctx context.Context
 	return &copy, nil
 }
 
-func (f *controllerFakeReservationClient) UpdateReservationStatus(ctx context.Context, id uint, status string) (*servicedto.Reservation, error) {
+func (f *controllerFakeReservationClient) GetReservationByCode(ctx context.Context, code string) (*servicedto.Reservation, error) {
+	for _, r := range f.reservations {
+		if r.Code == code {
+			copy := r
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *controllerFakeReservationClient) UpdateReservationStatus(ctx context.Context, params servicedto.UpdateReservationStatusParams) (*servicedto.Reservation, error) {
+	r, ok := f.reservations[params.ID]
+	if !ok || (params.From != "" && r.Status != params.From) {
+		return nil, nil
+	}
+	r.Status = params.Status
+	r.UpdatedAt = time.Now()
+	f.reservations[params.ID] = r
+	copy := r
+	return &copy, nil
+}
+
+func (f *controllerFakeReservationClient) ModifyReservation(ctx context.Context, params servicedto.ModifyReservationParams) (*servicedto.Reservation, error) {
+	r, ok := f.reservations[params.ID]
+	if !ok || r.Status != params.From {
+		return nil, nil
+	}
+	r.Date, r.Time, r.People, r.Comment = params.Date, params.Time, params.People, params.Comment
+	r.Status, r.ReviewReason, r.Decision = params.Status, params.ReviewReason, params.Decision
+	r.UpdatedAt = time.Now()
+	f.reservations[params.ID] = r
+	copy := r
+	return &copy, nil
+}
+
+func (f *controllerFakeReservationClient) ConfirmAttendance(ctx context.Context, id uint, at time.Time) (bool, error) {
+	r, ok := f.reservations[id]
+	if !ok || r.Status != servicedto.StatusConfirmed {
+		return false, nil
+	}
+	r.AttendingAt = &at
+	f.reservations[id] = r
+	return true, nil
+}
+
+func (f *controllerFakeReservationClient) EscalateReservation(ctx context.Context, id uint, reason string, at time.Time) (bool, error) {
+	r, ok := f.reservations[id]
+	if !ok || r.Status != servicedto.StatusPending || r.EscalatedAt != nil {
+		return false, nil
+	}
+	r.ReviewReason = &reason
+	r.EscalatedAt = &at
+	f.reservations[id] = r
+	return true, nil
+}
+
+func (f *controllerFakeReservationClient) UpdateReservationStatuses(ctx context.Context, changes []servicedto.UpdateReservationStatusParams) ([]servicedto.Reservation, error) {
+	for _, params := range changes {
+		if _, ok := f.reservations[params.ID]; !ok {
+			return nil, nil
+		}
+	}
+	updated := make([]servicedto.Reservation, 0, len(changes))
+	for _, params := range changes {
+		r, _ := f.UpdateReservationStatus(ctx, params)
+		updated = append(updated, *r)
+	}
+	return updated, nil
+}
+
+func (f *controllerFakeReservationClient) SeatReservation(ctx context.Context, id uint, tableID *uint, at time.Time, actor servicedto.StatusActor) (*servicedto.Reservation, error) {
 	r, ok := f.reservations[id]
 	if !ok {
 		return nil, nil
 	}
-	r.Status = status
-	r.UpdatedAt = time.Now()
+	r.Status = servicedto.StatusSeated
+	if tableID != nil {
+		r.TableID = tableID
+	}
+	if r.SeatedAt == nil {
+		r.SeatedAt = &at
+	}
 	f.reservations[id] = r
 	copy := r
 	return &copy, nil
 }
 
-func (f *controllerFakeReservationClient) ListReservationsByDate(ctx context.Context, date time.Time, status *string) ([]servicedto.Reservation, error) {
+func (f *controllerFakeReservationClient) SearchReservations(ctx context.Context, search servicedto.ReservationSearch) ([]servicedto.Reservation, error) {
+	list := make([]servicedto.Reservation, 0, len(f.reservations))
+	for _, r := range f.reservations {
+		list = append(list, r)
+	}
+	return list, nil
+}
+
+func (f *controllerFakeReservationClient) QueryReservations(ctx context.Context, q servicedto.ReservationQuery) (*servicedto.ReservationPage, error) {
 	var list []servicedto.Reservation
 	for _, r := range f.reservations {
-		if !r.Date.Equal(date) {
+		if q.From != nil && r.Date.Before(*q.From) {
 			continue
 		}
-		if status != nil && r.Status != *status {
+		if q.To != nil && r.Date.After(*q.To) {
+			continue
+		}
+		if len(q.Statuses) > 0 && !slices.Contains(q.Statuses, r.Status) {
+			continue
+		}
+		if len(q.Tags) > 0 && !slices.ContainsFunc(q.Tags, func(tag string) bool { return slices.Contains(r.Tags, tag) }) {
 			continue
 		}
 		list = append(list, r)
 	}
+	slices.SortFunc(list, func(a, b servicedto.Reservation) int { return int(a.ID) - int(b.ID) })
+	page := &servicedto.ReservationPage{Total: int64(len(list))}
+	if q.After != nil {
+		list = slices.DeleteFunc(list, func(r servicedto.Reservation) bool { return r.ID <= q.After.ID })
+	}
+	if q.Limit > 0 && len(list) > q.Limit {
+		list = list[:q.Limit]
+		page.Next = &servicedto.ReservationCursor{ID: list[len(list)-1].ID}
+	}
+	page.Reservations = list
+	return page, nil
+}
+
+func (f *controllerFakeReservationClient) ListStatusChanges(ctx context.Context, reservationID uint) ([]servicedto.StatusChange, error) {
+	return nil, nil
+}
+
+func (f *controllerFakeReservationClient) ListStatusChangesByReservations(ctx context.Context, ids []uint) ([]servicedto.StatusChange, error) {
+	return nil, nil
+}
+
+func (f *controllerFakeReservationClient) ListReservationsByUsers(ctx context.Context, userIDs []uint) ([]servicedto.Reservation, error) {
+	var list []servicedto.Reservation
+	for _, r := range f.reservations {
+		if slices.Contains(userIDs, r.UserID) {
+			list = append(list, r)
+		}
+	}
+	slices.SortFunc(list, func(a, b servicedto.Reservation) int { return int(a.ID) - int(b.ID) })
 	return list, nil
 }
+
+func (f *controllerFakeReservationClient) SummarizeGuests(ctx context.Context, q servicedto.GuestSummaryQuery) (map[uint]servicedto.GuestSummary, error) {
+	visit := func(r servicedto.Reservation) bool {
+		return r.Status == servicedto.StatusSeated || r.Status == servicedto.StatusConfirmed &&
+			(r.Date.Before(q.Today) || r.Date.Equal(q.Today) && r.Time < q.Clock)
+	}
+	summaries := make(map[uint]servicedto.GuestSummary, len(q.ReservationIDs))
+	for _, id := range q.ReservationIDs {
+		res, ok := f.reservations[id]
+		if !ok {
+			continue
+		}
+		summary := servicedto.GuestSummary{VisitNumber: 1}
+		for _, r := range f.reservations {
+			if r.UserID != res.UserID {
+				continue
+			}
+			if r.Status == servicedto.StatusNoShow {
+				summary.NoShows++
+			}
+			if visit(r) {
+				summary.Visits++
+				if r.ID != res.ID && (r.Date.Before(res.Date) || r.Date.Equal(res.Date) && (r.Time < res.Time || r.Time == res.Time && r.ID < res.ID)) {
+					summary.VisitNumber++
+				}
+			}
+			for _, tag := range r.Tags {
+				if !slices.Contains(summary.Tags, tag) {
+					summary.Tags = append(summary.Tags, tag)
+				}
+			}
+		}
+		slices.Sort(summary.Tags)
+		summaries[id] = summary
+	}
+	return summaries, nil
+}
+
+func (f *controllerFakeReservationClient) AddStaffNote(ctx context.Context, params servicedto.CreateStaffNoteParams) (*servicedto.StaffNote, error) {
+	r := f.reservations[params.ReservationID]
+	note := servicedto.StaffNote{
+		ID:            uint(len(r.Notes) + 1),
+		ReservationID: params.ReservationID,
+		AuthorID:      params.AuthorID,
+		Body:          params.Body,
+		CreatedAt:     time.Now(),
+	}
+	r.Notes = append(r.Notes, note)
+	f.reservations[params.ReservationID] = r
+	return &note, nil
+}
+
+func (f *controllerFakeReservationClient) SetReservationTags(ctx context.Context, reservationID uint, tags []string) error {
+	r := f.reservations[reservationID]
+	r.Tags = tags
+	f.reservations[reservationID] = r
+	return nil
+}
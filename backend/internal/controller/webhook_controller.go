@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	controllerdto "vesuvio/internal/dto/controller"
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/middleware"
+	"vesuvio/internal/service"
+)
+
+type WebhookController struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookController(webhookService *service.WebhookService) *WebhookController {
+	return &WebhookController{webhookService: webhookService}
+}
+
+func (ctl *WebhookController) CreateWebhook(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+
+	var req controllerdto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := ctl.webhookService.CreateWebhook(c.Request.Context(), currentUser, servicedto.CreateWebhookInput{
+		URL:         req.URL,
+		Events:      req.Events,
+		Description: req.Description,
+	})
+	if err != nil {
+		writeWebhookError(c, err, "failed to create webhook")
+		return
+	}
+	c.JSON(http.StatusCreated, toWebhookResponse(*webhook))
+}
+
+func (ctl *WebhookController) ListWebhooks(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+
+	webhooks, err := ctl.webhookService.ListWebhooks(c.Request.Context(), currentUser)
+	if err != nil {
+		writeWebhookError(c, err, "failed to list webhooks")
+		return
+	}
+	resp := make([]controllerdto.WebhookResponse, 0, len(webhooks))
+	for _, w := range webhooks {
+		resp = append(resp, toWebhookResponse(w))
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (ctl *WebhookController) UpdateWebhook(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+
+	var req controllerdto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := ctl.webhookService.UpdateWebhook(c.Request.Context(), currentUser, servicedto.UpdateWebhookInput{
+		ID:          id,
+		URL:         req.URL,
+		Events:      req.Events,
+		Active:      req.Active,
+		Description: req.Description,
+	})
+	if err != nil {
+		writeWebhookError(c, err, "failed to update webhook")
+		return
+	}
+	c.JSON(http.StatusOK, toWebhookResponse(*webhook))
+}
+
+func (ctl *WebhookController) DeleteWebhook(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+
+	if err := ctl.webhookService.DeleteWebhook(c.Request.Context(), currentUser, id); err != nil {
+		writeWebhookError(c, err, "failed to delete webhook")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries returns a webhook's delivery log, newest first. It takes
+// optional status and limit query parameters.
+func (ctl *WebhookController) ListDeliveries(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+	var status *string
+	if raw := c.Query("status"); raw != "" {
+		status = &raw
+	}
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := ctl.webhookService.ListDeliveries(c.Request.Context(), currentUser, id, status, limit)
+	if err != nil {
+		writeWebhookError(c, err, "failed to list deliveries")
+		return
+	}
+	resp := make([]controllerdto.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, controllerdto.WebhookDeliveryResponse{
+			ID:            d.ID,
+			EventID:       d.EventID,
+			Event:         d.Event,
+			Status:        d.Status,
+			Attempts:      d.Attempts,
+			NextAttemptAt: formatOptionalTime(d.NextAttemptAt),
+			StatusCode:    d.StatusCode,
+			Error:         d.Error,
+			DeliveredAt:   formatOptionalTime(d.DeliveredAt),
+			CreatedAt:     d.CreatedAt.Format(time.RFC3339),
+			Payload:       json.RawMessage(d.Payload),
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ReplayDeliveries sends failed deliveries of a webhook again.
+func (ctl *WebhookController) ReplayDeliveries(c *gin.Context) {
+	currentUser := c.MustGet(middleware.ContextUserKey).(servicedto.User)
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+
+	// The body is optional: without one every failed delivery is replayed.
+	var req controllerdto.ReplayWebhookRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	replayed, err := ctl.webhookService.ReplayDeliveries(c.Request.Context(), currentUser, servicedto.ReplayWebhookInput{
+		WebhookID:   id,
+		DeliveryIDs: req.DeliveryIDs,
+	})
+	if err != nil {
+		writeWebhookError(c, err, "failed to replay deliveries")
+		return
+	}
+	c.JSON(http.StatusAccepted, controllerdto.ReplayWebhookResponse{Replayed: replayed})
+}
+
+func writeWebhookError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case service.ErrInvalidWebhookURL, service.ErrPrivateWebhookURL, service.ErrInvalidWebhookEvent, service.ErrInvalidStatus:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case service.ErrWebhookNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case service.ErrUnauthorized:
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func toWebhookResponse(w servicedto.Webhook) controllerdto.WebhookResponse {
+	return controllerdto.WebhookResponse{
+		ID:          w.ID,
+		URL:         w.URL,
+		Events:      w.Events,
+		Active:      w.Active,
+		Description: w.Description,
+		Secret:      w.Secret,
+		CreatedAt:   w.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   w.UpdatedAt.Format(time.RFC3339),
+	}
+}
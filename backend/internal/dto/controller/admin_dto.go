@@ -2,20 +2,172 @@ package controllerdto
 
 // AdminReservationResponse includes reservation plus user info.
 type AdminReservationResponse struct {
-	ID        uint          `json:"id"`
-	User      AdminUserInfo `json:"user"`
-	Date      string        `json:"date"`
-	Time      string        `json:"time"`
-	People    int           `json:"people"`
-	Comment   *string       `json:"comment,omitempty"`
-	Status    string        `json:"status"`
-	CreatedAt string        `json:"created_at"`
-	UpdatedAt string        `json:"updated_at"`
+	ID           uint                  `json:"id"`
+	Code         string                `json:"code"`
+	User         AdminUserInfo         `json:"user"`
+	Date         string                `json:"date"`
+	Time         string                `json:"time"`
+	People       int                   `json:"people"`
+	TableID      *uint                 `json:"table_id,omitempty"`
+	Comment      *string               `json:"comment,omitempty"`
+	Status       string                `json:"status"`
+	Channel      string                `json:"channel"`
+	NeedsReview  bool                  `json:"needs_review"`
+	ReviewReason *string               `json:"review_reason,omitempty"`
+	Decision     *DecisionResponse     `json:"decision,omitempty"`
+	EscalatedAt  *string               `json:"escalated_at,omitempty"`
+	AttendingAt  *string               `json:"attending_at,omitempty"`
+	SeatedAt     *string               `json:"seated_at,omitempty"`
+	Tags         []string              `json:"tags"`
+	Notes        []StaffNoteResponse   `json:"notes"`
+	Guest        *GuestSummaryResponse `json:"guest,omitempty"`
+	Payment      *PaymentResponse      `json:"payment,omitempty"`
+	CreatedAt    string                `json:"created_at"`
+	UpdatedAt    string                `json:"updated_at"`
+
+	Requirements
+}
+
+// DecisionResponse names the auto-confirm rule that set a booking's
+// initial status.
+type DecisionResponse struct {
+	Rule string `json:"rule"`
+	Note string `json:"note"`
+}
+
+// StaffNoteResponse is an internal note shown to staff only.
+type StaffNoteResponse struct {
+	ID         uint   `json:"id"`
+	AuthorID   uint   `json:"author_id"`
+	AuthorName string `json:"author_name"`
+	Body       string `json:"body"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// AddStaffNoteRequest adds an internal note to a reservation.
+type AddStaffNoteRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// SetTagsRequest replaces the tags of a reservation; an empty list clears
+// them.
+type SetTagsRequest struct {
+	Tags []string `json:"tags"` // vip, birthday, allergy, regular
+}
+
+// TagsResponse lists the tags of a reservation.
+type TagsResponse struct {
+	Tags []string `json:"tags"`
 }
 
 // AdminUserInfo exposes limited user data in admin responses.
 type AdminUserInfo struct {
-	ID    uint   `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID      uint    `json:"id"`
+	Name    string  `json:"name"`
+	Email   string  `json:"email"`
+	Phone   *string `json:"phone,omitempty"`
+	IsGuest bool    `json:"is_guest"`
+}
+
+// AdminCreateReservationRequest books on behalf of an existing user
+// (user_id) or a phone/walk-in guest (guest), exactly one of them.
+type AdminCreateReservationRequest struct {
+	UserID  uint          `json:"user_id,omitempty"`
+	Guest   *GuestRequest `json:"guest,omitempty"`
+	Date    string        `json:"date" binding:"required"` // YYYY-MM-DD
+	Time    string        `json:"time" binding:"required"` // HH:MM
+	People  int           `json:"people" binding:"required"`
+	Comment *string       `json:"comment,omitempty"`
+	Channel string        `json:"channel,omitempty"` // phone (default), walk_in, web, partner
+
+	Requirements
+}
+
+// GuestRequest identifies a guest without an account.
+type GuestRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Phone string `json:"phone" binding:"required"`
+}
+
+// BulkStatusRequest selects reservations by ids or by filter, exactly one of
+// them. Status is only read by the generic status endpoint.
+type BulkStatusRequest struct {
+	IDs    []uint             `json:"ids,omitempty"`
+	Filter *BulkFilterRequest `json:"filter,omitempty"`
+	Status string             `json:"status,omitempty"`
+	Atomic bool               `json:"atomic"`
+	Reason *string            `json:"reason,omitempty" binding:"omitempty,max=255"`
+}
+
+// BulkFilterRequest mirrors the admin listing filters.
+type BulkFilterRequest struct {
+	Date      string   `json:"date,omitempty"`
+	From      string   `json:"from,omitempty"`
+	To        string   `json:"to,omitempty"`
+	Statuses  []string `json:"statuses,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	MinPeople int      `json:"min_people,omitempty"`
+	MaxPeople int      `json:"max_people,omitempty"`
+}
+
+// BulkStatusResponse reports the outcome of a bulk status change.
+type BulkStatusResponse struct {
+	Updated   int                `json:"updated"`
+	Unchanged int                `json:"unchanged"`
+	Failed    int                `json:"failed"`
+	Results   []BulkStatusResult `json:"results"`
+}
+
+// BulkStatusResult is the outcome for one reservation.
+type BulkStatusResult struct {
+	ID      uint   `json:"id"`
+	Outcome string `json:"outcome"` // updated, unchanged, not_found or failed
+	Status  string `json:"status,omitempty"`
+}
+
+// StatusChangeResponse is one entry of a reservation's status history.
+type StatusChangeResponse struct {
+	FromStatus string  `json:"from_status"`
+	ToStatus   string  `json:"to_status"`
+	ActorID    *uint   `json:"actor_id,omitempty"`
+	Source     string  `json:"source"`
+	Reason     *string `json:"reason,omitempty"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// RevertNoShowRequest corrects a mistaken no-show; status is seated
+// (default) or cancelled.
+type RevertNoShowRequest struct {
+	Status string `json:"status"`
+}
+
+// NoShowSummaryResponse reviews the no-shows of one day.
+type NoShowSummaryResponse struct {
+	Date     string                `json:"date"`
+	Auto     int                   `json:"auto"`
+	Manual   int                   `json:"manual"`
+	Reverted int                   `json:"reverted"`
+	Covers   int                   `json:"covers"`
+	Entries  []NoShowEntryResponse `json:"entries"`
+}
+
+// NoShowEntryResponse is one reservation marked as a no-show.
+type NoShowEntryResponse struct {
+	Reservation AdminReservationResponse `json:"reservation"`
+	MarkedAt    string                   `json:"marked_at"`
+	Auto        bool                     `json:"auto"`
+	RevertedTo  *string                  `json:"reverted_to,omitempty"`
+}
+
+// ReminderResponse is one entry in a reservation's reminder log.
+type ReminderResponse struct {
+	ID            uint    `json:"id"`
+	OffsetMinutes int     `json:"offset_minutes"`
+	Channel       string  `json:"channel"`
+	Recipient     string  `json:"recipient"`
+	Status        string  `json:"status"`
+	Attempts      int     `json:"attempts"`
+	Error         *string `json:"error,omitempty"`
+	SentAt        *string `json:"sent_at,omitempty"`
+	CreatedAt     string  `json:"created_at"`
 }
@@ -5,14 +5,16 @@ type RegisterRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	Phone    string `json:"phone"`
 }
 
 // RegisterResponse returns basic user info after registration.
 type RegisterResponse struct {
-	ID      uint   `json:"id"`
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	IsAdmin bool   `json:"is_admin"`
+	ID      uint    `json:"id"`
+	Name    string  `json:"name"`
+	Email   string  `json:"email"`
+	Phone   *string `json:"phone,omitempty"`
+	IsAdmin bool    `json:"is_admin"`
 }
 
 // LoginRequest represents login payload.
@@ -23,8 +25,9 @@ type LoginRequest struct {
 
 // LoginResponse returns user info after successful login.
 type LoginResponse struct {
-	ID      uint   `json:"id"`
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	IsAdmin bool   `json:"is_admin"`
+	ID      uint    `json:"id"`
+	Name    string  `json:"name"`
+	Email   string  `json:"email"`
+	Phone   *string `json:"phone,omitempty"`
+	IsAdmin bool    `json:"is_admin"`
 }
@@ -0,0 +1,8 @@
+package controllerdto
+
+// CalendarFeedResponse is the address calendar apps subscribe to.
+type CalendarFeedResponse struct {
+	URL       string `json:"url"`
+	Scope     string `json:"scope"`
+	UpdatedAt string `json:"updated_at"`
+}
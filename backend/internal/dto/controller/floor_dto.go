@@ -0,0 +1,56 @@
+package controllerdto
+
+// TableResponse describes a table on the floor.
+type TableResponse struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Area  string `json:"area"`
+	Seats int    `json:"seats"`
+}
+
+// CreateTableRequest payload for adding a table.
+type CreateTableRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Area  string `json:"area,omitempty"` // defaults to main
+	Seats int    `json:"seats" binding:"required"`
+}
+
+// FloorStatusResponse is the live floor view for hosts.
+type FloorStatusResponse struct {
+	At         string                     `json:"at"`
+	Covers     int                        `json:"covers"`
+	Areas      []FloorAreaResponse        `json:"areas"`
+	Unassigned []AdminReservationResponse `json:"unassigned"`
+	Upcoming   []AdminReservationResponse `json:"upcoming"`
+}
+
+// FloorAreaResponse summarises one area of the floor.
+type FloorAreaResponse struct {
+	Name     string               `json:"name"`
+	Seats    int                  `json:"seats"`
+	Covers   int                  `json:"covers"`
+	Occupied int                  `json:"occupied_tables"`
+	Tables   []FloorTableResponse `json:"tables"`
+}
+
+// FloorTableResponse is a table and the party sitting at it, if any.
+type FloorTableResponse struct {
+	TableResponse
+	Occupied    bool                      `json:"occupied"`
+	Reservation *AdminReservationResponse `json:"reservation,omitempty"`
+	FreeAt      *string                   `json:"free_at,omitempty"`
+}
+
+// WalkInRequest seats a party without a booking, identified by user_id or guest.
+type WalkInRequest struct {
+	UserID  uint          `json:"user_id,omitempty"`
+	Guest   *GuestRequest `json:"guest,omitempty"`
+	People  int           `json:"people" binding:"required"`
+	TableID *uint         `json:"table_id,omitempty"`
+	Comment *string       `json:"comment,omitempty"`
+}
+
+// SeatReservationRequest optionally assigns a table when a party arrives.
+type SeatReservationRequest struct {
+	TableID *uint `json:"table_id,omitempty"`
+}
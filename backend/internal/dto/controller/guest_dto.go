@@ -0,0 +1,63 @@
+package controllerdto
+
+// GuestProfileResponse is a guest's visit history and preferences.
+type GuestProfileResponse struct {
+	User             AdminUserInfo              `json:"user"`
+	Visits           int                        `json:"visits"`
+	NoShows          int                        `json:"no_shows"`
+	Cancellations    int                        `json:"cancellations"`
+	LateCancels      int                        `json:"late_cancels"`
+	Upcoming         int                        `json:"upcoming"`
+	AveragePartySize float64                    `json:"average_party_size"`
+	FirstVisit       *string                    `json:"first_visit,omitempty"`
+	LastVisit        *string                    `json:"last_visit,omitempty"`
+	Preferences      GuestPreferencesResponse   `json:"preferences"`
+	Tags             []string                   `json:"tags"`
+	Recent           []AdminReservationResponse `json:"recent"`
+	Restriction      *RestrictionResponse       `json:"restriction,omitempty"`
+	Incidents        []IncidentResponse         `json:"incidents"`
+}
+
+// GuestPreferencesResponse lists requirements the guest has asked for before.
+type GuestPreferencesResponse struct {
+	Allergens     []string `json:"allergens"`
+	DietaryStyles []string `json:"dietary_styles"`
+	Accessibility []string `json:"accessibility"`
+	HighChairs    bool     `json:"high_chairs"`
+}
+
+// GuestSummaryResponse is the compact guest history shown on a reservation.
+type GuestSummaryResponse struct {
+	Visits      int      `json:"visits"`
+	NoShows     int      `json:"no_shows"`
+	VisitNumber int      `json:"visit_number"`
+	Tags        []string `json:"tags"`
+}
+
+// RestrictionResponse is a sanction on a guest's online bookings.
+type RestrictionResponse struct {
+	ID        uint           `json:"id"`
+	UserID    uint           `json:"user_id"`
+	User      *AdminUserInfo `json:"user,omitempty"`
+	Level     string         `json:"level"`
+	Reason    string         `json:"reason"`
+	Incidents int            `json:"incidents"`
+	CreatedAt string         `json:"created_at"`
+	UpdatedAt string         `json:"updated_at"`
+	LiftedAt  *string        `json:"lifted_at,omitempty"`
+	LiftedBy  *uint          `json:"lifted_by,omitempty"`
+	LiftNote  *string        `json:"lift_note,omitempty"`
+}
+
+// IncidentResponse is a recorded no-show or late cancellation.
+type IncidentResponse struct {
+	ID            uint   `json:"id"`
+	ReservationID uint   `json:"reservation_id"`
+	Kind          string `json:"kind"`
+	OccurredAt    string `json:"occurred_at"`
+}
+
+// LiftRestrictionRequest lifts a guest's restriction with an optional note.
+type LiftRestrictionRequest struct {
+	Note *string `json:"note"`
+}
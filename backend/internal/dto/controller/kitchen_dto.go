@@ -0,0 +1,37 @@
+package controllerdto
+
+// KitchenSummaryResponse aggregates requirements per service period.
+type KitchenSummaryResponse struct {
+	Date    string                  `json:"date"`
+	Periods []KitchenPeriodResponse `json:"periods"`
+}
+
+// KitchenPeriodResponse covers one service period. The maps count
+// reservations per allergen, dietary style or accessibility need.
+type KitchenPeriodResponse struct {
+	Name          string                       `json:"name"`
+	Start         string                       `json:"start,omitempty"`
+	End           string                       `json:"end,omitempty"`
+	Reservations  int                          `json:"reservations"`
+	Covers        int                          `json:"covers"`
+	Allergens     map[string]int               `json:"allergens"`
+	DietaryStyles map[string]int               `json:"dietary_styles"`
+	Accessibility map[string]int               `json:"accessibility"`
+	HighChairs    int                          `json:"high_chairs"`
+	WithNeeds     []KitchenReservationResponse `json:"with_needs"`
+}
+
+// KitchenReservationResponse is a reservation with requirements, as the
+// kitchen needs to see it.
+type KitchenReservationResponse struct {
+	ID        uint    `json:"id"`
+	Code      string  `json:"code"`
+	Time      string  `json:"time"`
+	People    int     `json:"people"`
+	GuestName string  `json:"guest_name"`
+	TableID   *uint   `json:"table_id,omitempty"`
+	Status    string  `json:"status"`
+	Comment   *string `json:"comment,omitempty"`
+
+	Requirements
+}
@@ -0,0 +1,46 @@
+package controllerdto
+
+// ReservationUpdateResponse is the data of one live update event.
+type ReservationUpdateResponse struct {
+	ID          uint                     `json:"id"`
+	Type        string                   `json:"type"`
+	At          string                   `json:"at"`
+	Reservation AdminReservationResponse `json:"reservation"`
+}
+
+// StreamTokenResponse opens the caller's live streams as ?token= until
+// expires_at.
+type StreamTokenResponse struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// Guest live messages. Guests send subscribe and unsubscribe; the server
+// answers with subscribed, unsubscribed or error and pushes status.
+const (
+	LiveMessageSubscribe    = "subscribe"
+	LiveMessageUnsubscribe  = "unsubscribe"
+	LiveMessageSubscribed   = "subscribed"
+	LiveMessageUnsubscribed = "unsubscribed"
+	LiveMessageStatus       = "status"
+	LiveMessageError        = "error"
+)
+
+// LiveClientMessage is a message from a guest's WebSocket.
+type LiveClientMessage struct {
+	Type          string `json:"type"`
+	ReservationID uint   `json:"reservation_id"`
+}
+
+// LiveServerMessage is a message to a guest's WebSocket. Status messages
+// carry an event_id to send back as last_event_id when reconnecting.
+type LiveServerMessage struct {
+	Type           string               `json:"type"`
+	EventID        uint                 `json:"event_id,omitempty"`
+	ReservationID  uint                 `json:"reservation_id,omitempty"`
+	Status         string               `json:"status,omitempty"`
+	PreviousStatus string               `json:"previous_status,omitempty"`
+	At             string               `json:"at,omitempty"`
+	Reservation    *ReservationResponse `json:"reservation,omitempty"`
+	Error          string               `json:"error,omitempty"`
+}
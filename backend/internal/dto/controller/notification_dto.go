@@ -0,0 +1,18 @@
+package controllerdto
+
+// NotificationPreferencesRequest changes how a user hears from the
+// restaurant. Omitted fields are left as they are; an empty phone removes it.
+type NotificationPreferencesRequest struct {
+	Phone     *string `json:"phone"`
+	Email     *bool   `json:"email"`
+	SMS       *bool   `json:"sms"`
+	Reminders *bool   `json:"reminders"`
+}
+
+// NotificationPreferencesResponse is a user's phone number and preferences.
+type NotificationPreferencesResponse struct {
+	Phone     *string `json:"phone"`
+	Email     bool    `json:"email"`
+	SMS       bool    `json:"sms"`
+	Reminders bool    `json:"reminders"`
+}
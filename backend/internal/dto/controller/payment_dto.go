@@ -0,0 +1,12 @@
+package controllerdto
+
+// PaymentResponse is the deposit or card guarantee of a reservation.
+// Amounts are in minor units, e.g. cents. CheckoutURL is only set while the
+// guest still has to pay.
+type PaymentResponse struct {
+	Kind        string `json:"kind"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Status      string `json:"status"`
+	CheckoutURL string `json:"checkout_url,omitempty"`
+}
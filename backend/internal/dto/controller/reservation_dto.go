@@ -6,17 +6,64 @@ type CreateReservationRequest struct {
 	Time    string  `json:"time" binding:"required"` // HH:MM
 	People  int     `json:"people" binding:"required"`
 	Comment *string `json:"comment,omitempty"`
+
+	Requirements
+}
+
+// ModifyReservationRequest changes a booking; omitted fields keep their
+// value and an empty comment clears it.
+type ModifyReservationRequest struct {
+	Date    string  `json:"date,omitempty"` // YYYY-MM-DD
+	Time    string  `json:"time,omitempty"` // HH:MM
+	People  int     `json:"people,omitempty"`
+	Comment *string `json:"comment,omitempty"`
+}
+
+// Requirements are structured dietary and accessibility needs, picked from
+// the requirements catalogue.
+type Requirements struct {
+	Allergens     []string `json:"allergens,omitempty"`
+	DietaryStyle  *string  `json:"dietary_style,omitempty"`
+	Accessibility []string `json:"accessibility,omitempty"`
+	HighChairs    int      `json:"high_chairs,omitempty"`
+}
+
+// RequirementsCatalogueResponse lists the values guests may choose from.
+type RequirementsCatalogueResponse struct {
+	Allergens     []string `json:"allergens"`
+	DietaryStyles []string `json:"dietary_styles"`
+	Accessibility []string `json:"accessibility"`
+	MaxHighChairs int      `json:"max_high_chairs"`
 }
 
 // ReservationResponse basic reservation data for clients.
 type ReservationResponse struct {
-	ID        uint    `json:"id"`
-	UserID    uint    `json:"user_id"`
-	Date      string  `json:"date"`
-	Time      string  `json:"time"`
-	People    int     `json:"people"`
-	Comment   *string `json:"comment,omitempty"`
-	Status    string  `json:"status"`
-	CreatedAt string  `json:"created_at"`
-	UpdatedAt string  `json:"updated_at"`
+	ID          uint             `json:"id"`
+	Code        string           `json:"code"`
+	UserID      uint             `json:"user_id"`
+	Date        string           `json:"date"`
+	Time        string           `json:"time"`
+	People      int              `json:"people"`
+	Comment     *string          `json:"comment,omitempty"`
+	Status      string           `json:"status"`
+	Payment     *PaymentResponse `json:"payment,omitempty"`
+	AttendingAt *string          `json:"attending_at,omitempty"`
+	CreatedAt   string           `json:"created_at"`
+	UpdatedAt   string           `json:"updated_at"`
+
+	Requirements
+}
+
+// FollowLinkRequest carries out a one-click link. The token travels in the
+// body so that merely opening the link changes nothing.
+type FollowLinkRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+// ReservationLinkResponse shows what a one-click link does, and whether it
+// has been carried out.
+type ReservationLinkResponse struct {
+	Action      string              `json:"action"`
+	Done        bool                `json:"done"`
+	Reservation ReservationResponse `json:"reservation"`
 }
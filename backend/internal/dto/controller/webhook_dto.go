@@ -0,0 +1,57 @@
+package controllerdto
+
+import "encoding/json"
+
+// CreateWebhookRequest subscribes a URL to reservation events.
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Events      []string `json:"events" binding:"required"`
+	Description *string  `json:"description"`
+}
+
+// UpdateWebhookRequest changes a subscription; omitted fields are kept.
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url"`
+	Events      []string `json:"events"`
+	Active      *bool    `json:"active"`
+	Description *string  `json:"description"`
+}
+
+// WebhookResponse describes a subscription. Secret is only returned when the
+// webhook is created.
+type WebhookResponse struct {
+	ID          uint     `json:"id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Active      bool     `json:"active"`
+	Description *string  `json:"description"`
+	Secret      string   `json:"secret,omitempty"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+// WebhookDeliveryResponse is one entry in a webhook's delivery log.
+type WebhookDeliveryResponse struct {
+	ID            uint            `json:"id"`
+	EventID       string          `json:"event_id"`
+	Event         string          `json:"event"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt *string         `json:"next_attempt_at"`
+	StatusCode    *int            `json:"status_code"`
+	Error         *string         `json:"error"`
+	DeliveredAt   *string         `json:"delivered_at"`
+	CreatedAt     string          `json:"created_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// ReplayWebhookRequest picks the failed deliveries to send again; all of
+// them when DeliveryIDs is empty.
+type ReplayWebhookRequest struct {
+	DeliveryIDs []uint `json:"delivery_ids"`
+}
+
+// ReplayWebhookResponse counts the deliveries queued again.
+type ReplayWebhookResponse struct {
+	Replayed int `json:"replayed"`
+}
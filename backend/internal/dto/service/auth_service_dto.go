@@ -1,6 +1,9 @@
 package servicedto
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // User is the service-level representation.
 type User struct {
@@ -8,6 +11,8 @@ type User struct {
 	Name      string
 	Email     string
 	IsAdmin   bool
+	Phone     *string
+	IsGuest   bool
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -23,6 +28,7 @@ type RegisterUserInput struct {
 	Name     string
 	Email    string
 	Password string
+	Phone    string // optional, for text messages
 }
 
 type RegisterUserOutput struct {
@@ -45,4 +51,46 @@ type CreateUserParams struct {
 	Email        string
 	PasswordHash string
 	IsAdmin      bool
+	Phone        *string
+	IsGuest      bool
+}
+
+// PhoneFormat says how to read the phone numbers people type.
+type PhoneFormat struct {
+	// CountryCode, such as "39", is assumed for numbers written without "+"
+	// or "00"; empty rejects them, since guests may be texted from abroad.
+	CountryCode string
+	// TrunkPrefix is dropped from such local numbers before the country code
+	// is added, e.g. "0" in the UK; Italy and Spain have none.
+	TrunkPrefix string
+}
+
+// Normalize returns a phone number in E.164 form: "+", a country code not
+// starting with 0, and at most 15 digits in all. Spaces, dashes, dots and
+// brackets are dropped and a leading "00" counts as "+".
+func (f PhoneFormat) Normalize(raw string) (string, bool) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9', r == '+' && i == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+	phone := b.String()
+	switch {
+	case strings.HasPrefix(phone, "+"):
+	case strings.HasPrefix(phone, "00"):
+		phone = "+" + phone[2:]
+	case f.CountryCode != "" && phone != "":
+		phone = "+" + strings.TrimPrefix(f.CountryCode, "+") + strings.TrimPrefix(phone, f.TrunkPrefix)
+	default:
+		return "", false
+	}
+	if digits := len(phone) - 1; digits < 7 || digits > 15 || phone[1] == '0' {
+		return "", false
+	}
+	return phone, true
 }
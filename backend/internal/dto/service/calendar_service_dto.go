@@ -0,0 +1,35 @@
+package servicedto
+
+import "time"
+
+// Calendar feed scopes: a guest's own bookings, or every booking for staff.
+const (
+	CalendarFeedScopeUser  = "user"
+	CalendarFeedScopeAdmin = "admin"
+)
+
+// CalendarFeed is a user's tokenised calendar subscription. URL is set by
+// the service.
+type CalendarFeed struct {
+	ID        uint
+	UserID    uint
+	Scope     string
+	Token     string
+	URL       string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SaveCalendarFeedParams creates a user's feed of a scope or replaces its
+// token.
+type SaveCalendarFeedParams struct {
+	UserID uint
+	Scope  string
+	Token  string
+}
+
+// CalendarFile is a rendered iCalendar document.
+type CalendarFile struct {
+	Filename string
+	Body     []byte
+}
@@ -0,0 +1,42 @@
+package servicedto
+
+// Export formats.
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// Export columns, in their default order.
+const (
+	ExportColumnCode       = "code"
+	ExportColumnDate       = "date"
+	ExportColumnTime       = "time"
+	ExportColumnPeople     = "people"
+	ExportColumnStatus     = "status"
+	ExportColumnGuestName  = "guest_name"
+	ExportColumnGuestEmail = "guest_email"
+	ExportColumnGuestPhone = "guest_phone"
+	ExportColumnVisits     = "visits"
+	ExportColumnNoShows    = "no_shows"
+	ExportColumnTable      = "table_id"
+	ExportColumnChannel    = "channel"
+	ExportColumnTags       = "tags"
+	ExportColumnComment    = "comment"
+	ExportColumnNotes      = "notes"
+	ExportColumnCreatedAt  = "created_at"
+)
+
+// ExportReservationsInput selects the reservations to export like the admin
+// listing does. Columns defaults to all of them; Locale, a language tag
+// such as "it" or "en-US", formats dates and times, which are ISO 8601
+// without one.
+type ExportReservationsInput struct {
+	Format   string
+	Date     string
+	From     string
+	To       string
+	Statuses []string
+	Tags     []string
+	Columns  []string
+	Locale   string
+}
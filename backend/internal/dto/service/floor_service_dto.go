@@ -0,0 +1,43 @@
+package servicedto
+
+import "time"
+
+// Table is a physical table on the restaurant floor.
+type Table struct {
+	ID    uint
+	Name  string
+	Area  string
+	Seats int
+}
+
+// CreateTableParams carries data for adding a table.
+type CreateTableParams struct {
+	Name  string
+	Area  string
+	Seats int
+}
+
+// FloorStatus is a snapshot of who is in the room and who is about to arrive.
+type FloorStatus struct {
+	At         time.Time
+	Areas      []FloorArea
+	Unassigned []Reservation // seated parties without a table
+	Upcoming   []Reservation // pending or confirmed, starting within the lookahead
+	Covers     int           // guests currently seated
+}
+
+// FloorArea groups the tables of one area.
+type FloorArea struct {
+	Name     string
+	Seats    int
+	Covers   int
+	Occupied int
+	Tables   []FloorTable
+}
+
+// FloorTable is a table and the party currently sitting at it, if any.
+type FloorTable struct {
+	Table       Table
+	Reservation *Reservation
+	FreeAt      *time.Time // when the current party is expected to leave
+}
@@ -0,0 +1,48 @@
+package servicedto
+
+import "time"
+
+// GuestProfile aggregates a guest's history for staff. A visit is a
+// reservation that was seated, or confirmed for a time that has passed.
+type GuestProfile struct {
+	User             User
+	Visits           int
+	NoShows          int
+	Cancellations    int
+	LateCancels      int // cancelled within the late-cancel window before the slot
+	Upcoming         int
+	AveragePartySize float64 // over visits
+	FirstVisit       *time.Time
+	LastVisit        *time.Time
+	Preferences      GuestPreferences
+	Tags             []string      // staff tags used on any of the guest's reservations
+	Recent           []Reservation // newest first
+	Restriction      *Restriction  // active restriction, if any
+	Incidents        []Incident    // recorded no-shows and late cancels, newest first
+}
+
+// GuestPreferences collects the requirements a guest has asked for before.
+type GuestPreferences struct {
+	Allergens     []string
+	DietaryStyles []string
+	Accessibility []string
+	HighChairs    bool
+}
+
+// GuestSummary is the compact form of GuestProfile shown next to a
+// reservation.
+type GuestSummary struct {
+	Visits      int
+	NoShows     int
+	VisitNumber int // this reservation's place among the guest's visits, 1-based
+	Tags        []string
+}
+
+// GuestSummaryQuery asks for the guest summaries of a page of reservations.
+// A visit is a seated booking, or a confirmed one whose slot started before
+// Clock on Today, both in the restaurant's time zone.
+type GuestSummaryQuery struct {
+	ReservationIDs []uint
+	Today          time.Time
+	Clock          string // HH:MM
+}
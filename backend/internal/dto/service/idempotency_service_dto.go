@@ -0,0 +1,44 @@
+package servicedto
+
+import "time"
+
+// IdempotencyRecord is a stored idempotent request and, once finished, its response.
+type IdempotencyRecord struct {
+	UserID       uint
+	Key          string
+	RequestHash  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// Completed reports whether the original request has finished.
+func (r IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// BeginIdempotentRequestInput identifies a request carrying an Idempotency-Key.
+type BeginIdempotentRequestInput struct {
+	UserID      uint
+	Key         string
+	RequestHash string
+}
+
+// CompleteIdempotentRequestInput carries the response to remember for a key.
+type CompleteIdempotentRequestInput struct {
+	UserID       uint
+	Key          string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+}
+
+// CreateIdempotencyKeyParams is used by the client to reserve a key.
+type CreateIdempotencyKeyParams struct {
+	UserID      uint
+	Key         string
+	RequestHash string
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,50 @@
+package servicedto
+
+// Requirements are the structured dietary and accessibility needs of a
+// party, chosen from the RequirementsCatalogue.
+type Requirements struct {
+	Allergens     []string
+	DietaryStyle  *string
+	Accessibility []string
+	HighChairs    int
+}
+
+// IsEmpty reports whether no requirement is set.
+func (r Requirements) IsEmpty() bool {
+	return len(r.Allergens) == 0 && r.DietaryStyle == nil && len(r.Accessibility) == 0 && r.HighChairs == 0
+}
+
+// RequirementsCatalogue lists the values guests may pick from.
+type RequirementsCatalogue struct {
+	Allergens     []string
+	DietaryStyles []string
+	Accessibility []string
+	MaxHighChairs int
+}
+
+// ServicePeriod is a named sitting such as lunch or dinner. Start and End
+// are HH:MM local times; End is exclusive.
+type ServicePeriod struct {
+	Name  string
+	Start string
+	End   string
+}
+
+// KitchenSummary is what the kitchen needs to know about one day.
+type KitchenSummary struct {
+	Date    string
+	Periods []KitchenPeriod
+}
+
+// KitchenPeriod aggregates the requirements of one service period. The maps
+// count reservations per allergen, style or need.
+type KitchenPeriod struct {
+	Period        ServicePeriod
+	Reservations  int
+	Covers        int
+	Allergens     map[string]int
+	DietaryStyles map[string]int
+	Accessibility map[string]int
+	HighChairs    int
+	WithNeeds     []Reservation // reservations with at least one requirement
+}
@@ -0,0 +1,62 @@
+package servicedto
+
+import "time"
+
+// Live update types pushed to admin dashboards.
+const (
+	LiveReservationCreated = "reservation.created"
+	LiveReservationUpdated = "reservation.updated"
+)
+
+// ReservationUpdate is a reservation change pushed to live views. ID is the
+// outbox event's, which clients resume after.
+// Reservation is as it was when the update was published; Status and
+// PreviousStatus are the ones of the change itself.
+type ReservationUpdate struct {
+	ID             uint
+	Type           string
+	Reservation    Reservation
+	Status         string
+	PreviousStatus string // empty for new bookings
+	At             time.Time
+}
+
+// StatusChanged reports whether the update is a new booking or moved one to
+// another status.
+func (u ReservationUpdate) StatusChanged() bool {
+	return u.Type == LiveReservationCreated || u.PreviousStatus != u.Status
+}
+
+// StreamReservationsInput follows the reservations of one date. LastEventID
+// resumes after the last update the client received.
+type StreamReservationsInput struct {
+	Date        string // YYYY-MM-DD, defaults to today
+	LastEventID uint
+}
+
+// FollowReservationInput asks to follow a guest's own reservation.
+type FollowReservationInput struct {
+	UserID        uint
+	ReservationID uint
+}
+
+// StreamStatusChangesInput follows the status of a guest's reservations.
+// LastEventID resumes after the last update the guest received.
+type StreamStatusChangesInput struct {
+	UserID      uint
+	LastEventID uint
+}
+
+// ReservationStream is an open subscription. Replay holds the missed updates
+// that are still buffered; Updates is closed when the subscriber falls too
+// far behind or its context ends.
+type ReservationStream struct {
+	Replay  []ReservationUpdate
+	Updates <-chan ReservationUpdate
+}
+
+// StreamToken opens a user's live streams until ExpiresAt.
+type StreamToken struct {
+	Token     string
+	ExpiresAt time.Time
+}
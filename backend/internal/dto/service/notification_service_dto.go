@@ -0,0 +1,110 @@
+package servicedto
+
+import "time"
+
+// Notification is one message to a guest on one channel.
+type Notification struct {
+	ReservationID uint
+	To            string // email address or phone number, per channel
+	Name          string
+	Subject       string
+	Body          string
+	HTML          string // optional HTML alternative to Body, for email
+	Text          string // optional short version of Body, for SMS
+}
+
+// NotificationPreferences are the messages a user agreed to receive. Email
+// covers every channel but SMS; Reminders turns reminders off on all of them.
+type NotificationPreferences struct {
+	Email     bool
+	SMS       bool
+	Reminders bool
+}
+
+// DefaultNotificationPreferences apply until a user changes them.
+var DefaultNotificationPreferences = NotificationPreferences{Email: true, SMS: true, Reminders: true}
+
+// NotificationSettings are a user's phone number and preferences.
+type NotificationSettings struct {
+	Phone *string
+	NotificationPreferences
+}
+
+// UpdateNotificationSettingsInput changes a user's phone number and
+// preferences. Nil fields are left as they are; an empty phone removes it.
+type UpdateNotificationSettingsInput struct {
+	UserID    uint
+	Phone     *string
+	Email     *bool
+	SMS       *bool
+	Reminders *bool
+}
+
+// Reservation lifecycle events. Guests are told about all but the other
+// status changes, such as being seated or marked as a no-show.
+const (
+	EventReservationCreated       = "created"
+	EventReservationConfirmed     = "confirmed"
+	EventReservationCancelled     = "cancelled"
+	EventReservationModified      = "modified"
+	EventReservationStatusChanged = "status_changed"
+)
+
+// ReservationEvent is a change to a booking the guest should hear about.
+// Reservation.User is set when the guest is known.
+type ReservationEvent struct {
+	ID          uint // the outbox event it came from; 0 when not from the outbox
+	Kind        string
+	Reservation Reservation
+}
+
+// Reminder delivery statuses.
+const (
+	ReminderSending = "sending"
+	ReminderSent    = "sent"
+	ReminderFailed  = "failed"
+)
+
+// Reminder is one entry in the reminder log.
+type Reminder struct {
+	ID            uint
+	ReservationID uint
+	Offset        time.Duration
+	Channel       string
+	Recipient     string
+	Status        string
+	Attempts      int
+	Error         *string
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// ClaimReminderParams claims a reminder for sending.
+type ClaimReminderParams struct {
+	ReservationID uint
+	Offset        time.Duration
+	Channel       string
+	Recipient     string
+	MaxAttempts   int       // failed reminders are retried until this many attempts
+	StaleBefore   time.Time // reminders still sending since before this are retried too
+}
+
+// ReminderResult counts what one reminder run did.
+type ReminderResult struct {
+	Sent   int
+	Failed int
+}
+
+// Actions of the signed links in reminders.
+const (
+	LinkActionConfirm = "confirm"
+	LinkActionCancel  = "cancel"
+)
+
+// ReservationLink is a verified one-click link.
+type ReservationLink struct {
+	ReservationID uint
+	Action        string
+	ExpiresAt     time.Time
+}
@@ -0,0 +1,71 @@
+package servicedto
+
+import "time"
+
+// Domain event types, written to the outbox in the same transaction as the
+// change they describe.
+const (
+	DomainEventReservationCreated  = "reservation_created"
+	DomainEventStatusChanged       = "status_changed"
+	DomainEventReservationModified = "reservation_modified"
+	DomainEventEscalated           = "reservation_escalated"
+	DomainEventAttendanceConfirmed = "attendance_confirmed"
+	DomainEventStaffNoteAdded      = "staff_note_added"
+	DomainEventTagsChanged         = "tags_changed"
+)
+
+// Outbox event statuses. Failed events ran out of attempts.
+const (
+	OutboxPending    = "pending"
+	OutboxDispatched = "dispatched"
+	OutboxFailed     = "failed"
+)
+
+// DomainEvent is a change to a reservation, waiting in the outbox to be
+// handed to the in-process handlers.
+type DomainEvent struct {
+	ID            uint
+	Type          string
+	ReservationID uint
+	FromStatus    string // status changes and modifications only
+	ToStatus      string // the reservation's status after the change
+	Attempts      int
+	Handled       []string // handlers that already processed the event
+	Claim         string   // the dispatch run that claimed the event
+	CreatedAt     time.Time
+}
+
+// ClaimEventsParams claims up to Limit events due at Now for one dispatch
+// run. Claimed events are not handed to other runs until Until, when an
+// event whose run died before finishing it becomes due again.
+type ClaimEventsParams struct {
+	Claim string
+	Now   time.Time
+	Until time.Time
+	Limit int
+}
+
+// ListEventsParams lists up to Limit events recorded at or after Since,
+// whatever their dispatch status, with IDs above AfterID in ID order.
+type ListEventsParams struct {
+	Since   time.Time
+	AfterID uint
+	Limit   int
+}
+
+// RetryEventParams records a failed dispatch. The event is given up on when
+// NextAttemptAt is nil.
+type RetryEventParams struct {
+	ID            uint
+	Claim         string
+	Handled       []string
+	Error         string
+	NextAttemptAt *time.Time
+}
+
+// OutboxResult counts what one dispatch run did.
+type OutboxResult struct {
+	Dispatched int
+	Retrying   int
+	Failed     int
+}
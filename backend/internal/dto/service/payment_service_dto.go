@@ -0,0 +1,90 @@
+package servicedto
+
+import "time"
+
+// Payment kinds. A deposit is charged when the guest pays and refunded on
+// a timely cancellation; a guarantee only holds the card and is captured on
+// a no-show.
+const (
+	PaymentKindDeposit   = "deposit"
+	PaymentKindGuarantee = "guarantee"
+)
+
+// Payment statuses.
+const (
+	PaymentPending    = "pending"    // waiting for the guest to pay
+	PaymentAuthorized = "authorized" // card held, nothing charged yet
+	PaymentCaptured   = "captured"   // money taken
+	PaymentReleased   = "released"   // hold or checkout voided
+	PaymentRefunded   = "refunded"   // captured money returned
+	PaymentFailed     = "failed"
+)
+
+// Payment is the deposit or card guarantee taken for a reservation.
+type Payment struct {
+	ID            uint
+	ReservationID uint
+	Kind          string
+	Amount        int64 // minor units
+	Currency      string
+	Status        string
+	ProviderRef   string
+	CheckoutURL   string
+	Settlement    string // refund, release or capture still owed; empty when none
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// SettlementResult counts what one settlement retry run did.
+type SettlementResult struct {
+	Settled int
+	Failed  int
+}
+
+type CreatePaymentParams struct {
+	ReservationID uint
+	Kind          string
+	Amount        int64
+	Currency      string
+	Status        string
+	ProviderRef   string
+	CheckoutURL   string
+}
+
+// ProviderPaymentParams asks the provider for a new payment. Capture false
+// only authorises the card.
+type ProviderPaymentParams struct {
+	Reference   string // reservation confirmation code
+	Amount      int64
+	Currency    string
+	Capture     bool
+	Description string
+}
+
+// ProviderPayment is the provider's answer to a new payment. Completed is
+// set when no guest action is needed, e.g. a saved card.
+type ProviderPayment struct {
+	Ref         string
+	CheckoutURL string
+	Completed   bool
+}
+
+// PaymentEvent is a verified payment outcome from a provider webhook.
+type PaymentEvent struct {
+	ProviderRef string
+	Succeeded   bool
+}
+
+// DepositPolicy decides when online bookings need a deposit or card
+// guarantee, and what happens to it when the booking ends.
+type DepositPolicy struct {
+	Kind            string // PaymentKindDeposit or PaymentKindGuarantee
+	AmountPerPerson int64  // minor units
+	Currency        string
+	MinPeople       int            // parties at least this large pay; 0 disables
+	PeakDays        []time.Weekday // every booking on these days pays
+	PeakDates       []string       // YYYY-MM-DD dates on which every booking pays
+	// ChargeLateCancels keeps the deposit, or captures the guarantee, when
+	// the guest cancels inside the late-cancel window.
+	ChargeLateCancels bool
+}
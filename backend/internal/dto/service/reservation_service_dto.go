@@ -1,34 +1,121 @@
 package servicedto
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 const (
 	StatusPending   = "pending"
 	StatusConfirmed = "confirmed"
 	StatusCancelled = "cancelled"
+	StatusSeated    = "seated"
+	StatusNoShow    = "no_show"
+	// StatusAwaitingPayment holds the slot until the deposit or card
+	// guarantee succeeds, then moves to pending.
+	StatusAwaitingPayment = "awaiting_payment"
+)
+
+// Booking channels record how a reservation was made, for reporting.
+const (
+	ChannelWeb     = "web"
+	ChannelPhone   = "phone"
+	ChannelWalkIn  = "walk_in"
+	ChannelPartner = "partner"
 )
 
+// Overlap policies decide what happens when a guest books a slot that
+// overlaps another active reservation of theirs, including one that runs
+// past midnight.
+const (
+	OverlapPolicyOff    = "off"
+	OverlapPolicyReject = "reject"
+	OverlapPolicyFlag   = "flag"
+)
+
+// ConfirmationCodePrefix starts every reservation confirmation code.
+const ConfirmationCodePrefix = "VSV-"
+
 // Reservation is the service-level representation.
 type Reservation struct {
-	ID        uint
-	UserID    uint
-	User      *User
-	Date      time.Time
-	Time      string
-	People    int
-	Comment   *string
-	Status    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID           uint
+	Code         string
+	UserID       uint
+	User         *User
+	Date         time.Time
+	Time         string
+	People       int
+	TableID      *uint
+	Comment      *string
+	Status       string
+	Channel      string
+	ReviewReason *string    // why the reservation was flagged for admin review
+	Decision     *Decision  // which auto-confirm rule decided the initial status
+	EscalatedAt  *time.Time // when the expiry worker escalated a stale pending booking
+	AttendingAt  *time.Time // when the guest confirmed attendance from a reminder link
+	SeatedAt     *time.Time // when the party was seated; occupancy runs from here
+	Requirements Requirements
+	Tags         []string      // staff only
+	Notes        []StaffNote   // staff only
+	Guest        *GuestSummary // staff only, set on admin views
+	Payment      *Payment      // deposit or card guarantee, if one was required
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Staff tag vocabulary.
+const (
+	TagVIP      = "vip"
+	TagBirthday = "birthday"
+	TagAllergy  = "allergy"
+	TagRegular  = "regular"
+)
+
+// StaffNote is an internal note on a reservation.
+type StaffNote struct {
+	ID            uint
+	ReservationID uint
+	AuthorID      uint
+	AuthorName    string
+	Body          string
+	CreatedAt     time.Time
+}
+
+// CreateStaffNoteParams carries data for persisting a staff note.
+type CreateStaffNoteParams struct {
+	ReservationID uint
+	AuthorID      uint
+	Body          string
 }
 
 // CreateReservationInput carries data for creating a reservation.
 type CreateReservationInput struct {
-	UserID  uint
-	Date    string
-	Time    string
-	People  int
-	Comment *string
+	UserID       uint
+	Date         string
+	Time         string
+	People       int
+	Comment      *string
+	Requirements Requirements
+	Channel      string // defaults to ChannelWeb
+}
+
+// GuestInput identifies a phone or walk-in guest without an account.
+type GuestInput struct {
+	Name  string
+	Phone string
+}
+
+// AdminCreateReservationInput books on behalf of an existing user (UserID)
+// or a guest identified by name and phone.
+type AdminCreateReservationInput struct {
+	UserID       uint
+	Guest        *GuestInput
+	Date         string
+	Time         string
+	People       int
+	Comment      *string
+	Requirements Requirements
+	Channel      string // defaults to ChannelPhone
 }
 
 type CreateReservationOutput struct {
@@ -40,22 +127,357 @@ type ListUserReservationsInput struct {
 	Status *string
 }
 
+// ModifyReservationInput changes a guest's own booking. Empty fields keep
+// their current value.
+type ModifyReservationInput struct {
+	UserID        uint
+	ReservationID uint
+	Date          string
+	Time          string
+	People        int
+	Comment       *string
+}
+
+// ModifyReservationParams stores changed booking details. The change only
+// applies if the reservation is still in status From; Status, ReviewReason
+// and Decision replace the current values.
+type ModifyReservationParams struct {
+	ID           uint
+	From         string
+	Date         time.Time
+	Time         string
+	People       int
+	Comment      *string
+	Status       string
+	ReviewReason *string
+	Decision     *Decision
+	Actor        StatusActor
+}
+
 type CancelReservationInput struct {
 	UserID        uint
 	ReservationID uint
 }
 
+// AdminListReservationsInput filters the admin listing. Date is shorthand
+// for From = To = Date. Statuses and Status are combined.
 type AdminListReservationsInput struct {
-	Date   string
-	Status *string
+	Date      string
+	From      string
+	To        string
+	Status    *string
+	Statuses  []string
+	Tags      []string
+	MinPeople int
+	MaxPeople int
+	Sort      string // datetime (default), created_at or people
+	Order     string // asc (default) or desc
+	Cursor    string
+	Limit     int
 }
 
-// CreateReservationParams used by the client layer when persisting.
-type CreateReservationParams struct {
+type AdminListReservationsOutput struct {
+	Reservations []Reservation
+	Total        int64
+	NextCursor   string // empty on the last page
+}
+
+// Sort fields for reservation queries.
+const (
+	SortByDateTime  = "datetime"
+	SortByCreatedAt = "created_at"
+	SortByPeople    = "people"
+)
+
+// ReservationQuery selects reservations for the client layer. Zero values
+// leave a filter unset; From and To are inclusive dates.
+type ReservationQuery struct {
+	From       *time.Time
+	To         *time.Time
+	Statuses   []string
+	Tags       []string // reservations with any of these tags
+	MinPeople  int
+	MaxPeople  int
+	Sort       string
+	Descending bool
+	After      *ReservationCursor
+	Limit      int
+}
+
+// ReservationCursor is the position of the last row of a page. Only the
+// fields of the query's sort key are used, with ID breaking ties.
+type ReservationCursor struct {
+	Date      time.Time
+	Time      string
+	CreatedAt time.Time
+	People    int
+	ID        uint
+}
+
+// ReservationPage is one page of a reservation query.
+type ReservationPage struct {
+	Reservations []Reservation
+	Total        int64
+	Next         *ReservationCursor // nil on the last page
+}
+
+// SearchReservationsInput is a free-text reservation search by staff.
+type SearchReservationsInput struct {
+	Query string
+	Limit int
+}
+
+// ReservationSearch selects search candidates for the client layer. Term is
+// lower-case and matched as a substring; PhoneDigits, when set, is matched
+// against guest phone numbers. Up to Limit rows are returned on each side of
+// Around, nearest dates first.
+type ReservationSearch struct {
+	Term        string
+	PhoneDigits string
+	Around      time.Time
+	Limit       int
+}
+
+// Sources of a status change, recorded in the reservation history.
+const (
+	StatusSourceAdmin     = "admin"
+	StatusSourceAdminBulk = "admin_bulk"
+	StatusSourceGuest     = "guest"
+	StatusSourceSystem    = "system"
+)
+
+// StatusActor records who changed a reservation's status and why.
+type StatusActor struct {
+	UserID *uint // nil for system changes
+	Source string
+	Reason *string
+}
+
+// UpdateReservationStatusParams moves one reservation to a new status.
+// When From is set the change only applies if the reservation is still in
+// that status, so concurrent workers cannot both act on it.
+type UpdateReservationStatusParams struct {
+	ID     uint
+	Status string
+	From   string
+	Actor  StatusActor
+}
+
+// StatusChange is one entry in a reservation's status history.
+type StatusChange struct {
+	ID            uint
+	ReservationID uint
+	FromStatus    string
+	ToStatus      string
+	ActorID       *uint
+	Source        string
+	Reason        *string
+	CreatedAt     time.Time
+}
+
+// BulkStatusInput changes the status of many reservations at once. Exactly
+// one of IDs or Filter selects them. When Atomic is set either every
+// reservation is updated or none is; otherwise each is reported separately.
+type BulkStatusInput struct {
+	IDs    []uint
+	Filter *BulkReservationFilter
+	Status string
+	Atomic bool
+	Reason *string
+}
+
+// BulkReservationFilter selects reservations like the admin listing does.
+type BulkReservationFilter struct {
+	Date      string
+	From      string
+	To        string
+	Statuses  []string
+	Tags      []string
+	MinPeople int
+	MaxPeople int
+}
+
+// Outcomes of one reservation in a bulk status change.
+const (
+	BulkOutcomeUpdated   = "updated"
+	BulkOutcomeUnchanged = "unchanged"
+	BulkOutcomeNotFound  = "not_found"
+	BulkOutcomeFailed    = "failed"
+)
+
+// BulkStatusResult is the outcome for one reservation.
+type BulkStatusResult struct {
+	ID          uint
+	Outcome     string
+	Reservation *Reservation // nil unless found
+}
+
+// BulkStatusOutput summarises a bulk status change.
+type BulkStatusOutput struct {
+	Results   []BulkStatusResult
+	Updated   int
+	Unchanged int
+	Failed    int
+}
+
+// WalkInInput seats a party that arrived without a booking.
+type WalkInInput struct {
 	UserID  uint
-	Date    time.Time
-	Time    string
+	Guest   *GuestInput
 	People  int
+	TableID *uint
 	Comment *string
-	Status  string
+}
+
+// SeatReservationInput marks a booked party as arrived.
+type SeatReservationInput struct {
+	ReservationID uint
+	TableID       *uint
+}
+
+// CreateReservationParams used by the client layer when persisting.
+type CreateReservationParams struct {
+	UserID       uint
+	Date         time.Time
+	Time         string
+	People       int
+	TableID      *uint
+	Comment      *string
+	Requirements Requirements
+	Status       string
+	Channel      string
+	ReviewReason *string
+	Decision     *Decision
+	SeatedAt     *time.Time    // set for walk-ins, who are seated on arrival
+	Overlap      *OverlapGuard // re-checked under a lock on the guest before the insert
+}
+
+// OverlapGuard is the per-guest overlap rule. The client re-checks it inside
+// the insert transaction while holding a lock on the guest, so two concurrent
+// bookings cannot both slip past it.
+type OverlapGuard struct {
+	Window time.Duration // bookings starting closer together than this overlap
+	Reject bool          // refuse the booking instead of holding it for review
+}
+
+// Conflict returns the first active booking in existing that overlaps one
+// starting at slotTime on date, or nil. Slots are compared as instants, so a
+// late booking overlaps an early one on the next date.
+func (g OverlapGuard) Conflict(existing []Reservation, date time.Time, slotTime string, ignoreID uint) *Reservation {
+	start, ok := slotStart(date, slotTime)
+	if !ok {
+		return nil
+	}
+	for i, r := range existing {
+		if r.ID == ignoreID || !r.Active() {
+			continue
+		}
+		other, ok := slotStart(r.Date, r.Time)
+		if !ok {
+			continue
+		}
+		if gap := start.Sub(other); gap < g.Window && -gap < g.Window {
+			return &existing[i]
+		}
+	}
+	return nil
+}
+
+// Reason explains a conflict to the admin reviewing the flagged booking.
+func (g OverlapGuard) Reason(conflict Reservation) string {
+	return fmt.Sprintf("overlaps reservation %s at %s", conflict.Code, conflict.Time)
+}
+
+// Active reports whether the booking still holds its slot.
+func (r Reservation) Active() bool {
+	switch r.Status {
+	case StatusPending, StatusConfirmed, StatusSeated, StatusAwaitingPayment:
+		return true
+	default:
+		return false
+	}
+}
+
+func slotStart(date time.Time, slotTime string) (time.Time, bool) {
+	slot, err := time.Parse("15:04", slotTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), slot.Hour(), slot.Minute(), 0, 0, time.UTC), true
+}
+
+// Auto-confirm rules. RuleAutoConfirm means every condition held; the others
+// name the first condition that kept the booking pending.
+const (
+	RuleAutoConfirm = "auto_confirm"
+	RuleFlagged     = "flagged"
+	RuleDeposit     = "deposit"
+	RuleBlackout    = "blackout"
+	RulePartySize   = "party_size"
+	RuleNoShows     = "no_shows"
+	RuleCapacity    = "capacity"
+)
+
+// Decision records which auto-confirm rule set a new booking's status.
+type Decision struct {
+	Rule string
+	Note string
+}
+
+// AutoConfirmPolicy lists the conditions a new booking must meet to be
+// confirmed without an admin.
+type AutoConfirmPolicy struct {
+	MaxPeople      int      // largest party confirmed automatically
+	MaxLoadPercent int      // seats booked around the slot, new party included, as a share of all seats; 0 ignores load
+	AllowNoShows   bool     // confirm guests who have missed a booking before
+	BlackoutDates  []string // YYYY-MM-DD dates that always need review
+}
+
+// Expiry actions for stale pending reservations.
+const (
+	ExpiryActionCancel   = "cancel"
+	ExpiryActionEscalate = "escalate"
+)
+
+// ExpiryPolicy decides when a reservation nobody acted on expires.
+type ExpiryPolicy struct {
+	PendingMaxAge  time.Duration // pending longer than this since booking; 0 disables
+	PendingMinLead time.Duration // still pending this close to the slot; 0 disables
+	Action         string        // ExpiryActionEscalate (the default) or ExpiryActionCancel
+	PaymentTimeout time.Duration // awaiting payment longer than this is cancelled; 0 disables
+}
+
+// ExpiryResult counts what one expiry run did.
+type ExpiryResult struct {
+	Cancelled int
+	Escalated int
+}
+
+// NoShowSummary reviews the no-shows of one day, automatic and manual.
+type NoShowSummary struct {
+	Date     time.Time
+	Entries  []NoShowEntry
+	Auto     int // marked by the no-show job
+	Manual   int // marked by staff
+	Reverted int // later corrected by staff
+	Covers   int // guests who did not come, corrections excluded
+}
+
+// NoShowEntry is one reservation that was marked as a no-show.
+type NoShowEntry struct {
+	Reservation Reservation
+	MarkedAt    time.Time
+	Auto        bool
+	RevertedTo  *string // status staff corrected it to, if any
+}
+
+// RevertNoShowInput corrects a mistaken no-show to seated or cancelled.
+type RevertNoShowInput struct {
+	ReservationID uint
+	Status        string // defaults to StatusSeated
+}
+
+// AutoNoShowResult counts what one no-show run did.
+type AutoNoShowResult struct {
+	Marked int
 }
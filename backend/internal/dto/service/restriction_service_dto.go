@@ -0,0 +1,93 @@
+package servicedto
+
+import "time"
+
+// Incident kinds counted against a guest.
+const (
+	IncidentNoShow     = "no_show"
+	IncidentLateCancel = "late_cancel"
+)
+
+// Restriction levels, from mildest to strictest. Approval keeps online
+// bookings pending for staff review, deposit additionally flags that a
+// deposit is required, and block refuses online bookings altogether.
+const (
+	RestrictionApproval = "approval"
+	RestrictionDeposit  = "deposit"
+	RestrictionBlock    = "block"
+)
+
+// Incident is one no-show or late cancellation by a guest.
+type Incident struct {
+	ID            uint
+	UserID        uint
+	ReservationID uint
+	Kind          string
+	OccurredAt    time.Time
+	CreatedAt     time.Time
+}
+
+type CreateIncidentParams struct {
+	UserID        uint
+	ReservationID uint
+	Kind          string
+	OccurredAt    time.Time
+}
+
+// Restriction is a sanction on a guest's online bookings. It is active
+// until LiftedAt is set.
+type Restriction struct {
+	ID        uint
+	UserID    uint
+	User      *User
+	Level     string
+	Reason    string
+	Incidents int
+	LiftedAt  *time.Time
+	LiftedBy  *uint
+	LiftNote  *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Active reports whether the restriction still applies.
+func (r Restriction) Active() bool {
+	return r.LiftedAt == nil
+}
+
+// SaveRestrictionParams sets the level of a guest's active restriction,
+// creating one when there is none.
+type SaveRestrictionParams struct {
+	UserID    uint
+	Level     string
+	Reason    string
+	Incidents int
+}
+
+type LiftRestrictionParams struct {
+	UserID   uint
+	LiftedBy uint
+	Note     *string
+	At       time.Time
+}
+
+// SanctionRule applies Level once a guest reaches Incidents within the
+// policy window.
+type SanctionRule struct {
+	Incidents int
+	Level     string
+}
+
+// SanctionPolicy decides when guests are restricted. Incidents before the
+// last lifted restriction are not counted again.
+type SanctionPolicy struct {
+	Rules            []SanctionRule
+	Window           time.Duration
+	LateCancelWindow time.Duration // cancellations closer to the slot are late
+	CountLateCancels bool          // late cancels count towards Rules like no-shows
+}
+
+type LiftRestrictionInput struct {
+	UserID uint
+	Note   *string
+}
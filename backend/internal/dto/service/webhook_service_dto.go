@@ -0,0 +1,145 @@
+package servicedto
+
+import (
+	"net/netip"
+	"time"
+)
+
+// Webhook event types subscribers can filter on.
+const (
+	WebhookReservationCreated   = "reservation.created"
+	WebhookReservationConfirmed = "reservation.confirmed"
+	WebhookReservationCancelled = "reservation.cancelled"
+	WebhookReservationUpdated   = "reservation.updated"
+)
+
+// Webhook delivery statuses. Pending deliveries wait for their next
+// attempt; failed ones ran out of attempts and can be replayed.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is a subscription to reservation events.
+type Webhook struct {
+	ID          uint
+	URL         string
+	Secret      string // signs deliveries; only shown when the webhook is created
+	Events      []string
+	Active      bool
+	Description *string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// CreateWebhookInput subscribes URL to Events.
+type CreateWebhookInput struct {
+	URL         string
+	Events      []string
+	Description *string
+}
+
+// UpdateWebhookInput changes a subscription; nil fields are left as they are.
+type UpdateWebhookInput struct {
+	ID          uint
+	URL         *string
+	Events      []string
+	Active      *bool
+	Description *string
+}
+
+// CreateWebhookParams persists a subscription.
+type CreateWebhookParams struct {
+	URL         string
+	Secret      string
+	Events      []string
+	Description *string
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one subscription.
+type WebhookDelivery struct {
+	ID            uint
+	WebhookID     uint
+	EventID       string
+	Event         string
+	Payload       []byte
+	Status        string
+	Attempts      int
+	NextAttemptAt *time.Time
+	StatusCode    *int    // of the last attempt
+	Error         *string // of the last attempt
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// CreateWebhookDeliveryParams queues an event for a subscription.
+type CreateWebhookDeliveryParams struct {
+	WebhookID     uint
+	EventID       string
+	Event         string
+	Payload       []byte
+	NextAttemptAt time.Time
+}
+
+// WebhookAttemptParams records the outcome of one delivery attempt. The
+// delivery stays pending when NextAttemptAt is set.
+type WebhookAttemptParams struct {
+	ID            uint
+	Status        string
+	NextAttemptAt *time.Time
+	StatusCode    *int
+	Error         *string
+	At            time.Time
+}
+
+// WebhookPolicy controls retries of deliveries that fail: attempt n waits
+// Backoff * 2^(n-1), at most MaxBackoff, after the one before.
+type WebhookPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+// WebhookResult counts what one delivery run did.
+type WebhookResult struct {
+	Delivered int
+	Retrying  int
+	Failed    int
+}
+
+// ReplayWebhookInput sends failed deliveries of a webhook again, all of
+// them when DeliveryIDs is empty.
+type ReplayWebhookInput struct {
+	WebhookID   uint
+	DeliveryIDs []uint
+}
+
+// nonPublicPrefixes are special-purpose ranges netip has no predicate for.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, may embed private IPv4
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+}
+
+// PublicAddress reports whether webhooks may be sent to addr: it must not be
+// loopback, link-local, private or otherwise reserved, so that subscribers
+// cannot point deliveries at the restaurant's own network.
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -54,3 +54,22 @@ func AdminOnly() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// StreamTokenAuth loads the user from a stream token in ?token=, for
+// EventSource and WebSocket requests, which cannot carry X-User-ID.
+func StreamTokenAuth(liveService *service.LiveService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
+			return
+		}
+		user, err := liveService.AuthenticateStream(c.Request.Context(), token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		c.Set(ContextUserKey, *user)
+		c.Next()
+	}
+}
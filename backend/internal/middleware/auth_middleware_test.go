@@ -14,7 +14,7 @@ import (
 
 func TestAuthMiddleware_MissingHeader(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	authSvc := service.NewAuthService(newMiddlewareFakeUserClient())
+	authSvc := service.NewAuthService(newMiddlewareFakeUserClient(), servicedto.PhoneFormat{})
 
 	r := gin.New()
 	r.Use(AuthMiddleware(authSvc))
@@ -33,7 +33,7 @@ func TestAuthMiddleware_MissingHeader(t *testing.T) {
 
 func TestAuthMiddleware_InvalidUser(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	authSvc := service.NewAuthService(newMiddlewareFakeUserClient())
+	authSvc := service.NewAuthService(newMiddlewareFakeUserClient(), servicedto.PhoneFormat{})
 
 	r := gin.New()
 	r.Use(AuthMiddleware(authSvc))
@@ -61,7 +61,7 @@ func TestAdminOnly_Forbidden(t *testing.T) {
 		PasswordHash: "hash",
 		IsAdmin:      false,
 	})
-	authSvc := service.NewAuthService(userClient)
+	authSvc := service.NewAuthService(userClient, servicedto.PhoneFormat{})
 
 	r := gin.New()
 	r.Use(AuthMiddleware(authSvc), AdminOnly())
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/service"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+// Idempotency replays the stored response when a request is retried with the
+// same Idempotency-Key header and body. Requests without the header pass
+// through untouched. Mount it after AuthMiddleware so keys are scoped per user.
+func Idempotency(idempotencyService *service.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		var userID uint
+		if val, exists := c.Get(ContextUserKey); exists {
+			userID = val.(servicedto.User).ID
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentRequestBytes+1))
+		if err != nil || len(body) > maxIdempotentRequestBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// The store must outlive the request when the client disconnects.
+		ctx := context.WithoutCancel(c.Request.Context())
+		stored, err := idempotencyService.Begin(ctx, servicedto.BeginIdempotentRequestInput{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: hashRequest(c.Request.Method, c.Request.URL.Path, body),
+		})
+		if err != nil {
+			switch err {
+			case service.ErrInvalidInput:
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid " + IdempotencyKeyHeader})
+			case service.ErrIdempotencyKeyReused:
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			case service.ErrIdempotencyRequestInProgress:
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to process idempotency key"})
+			}
+			return
+		}
+		if stored != nil {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(stored.StatusCode, stored.ContentType, stored.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		defer func() {
+			if r := recover(); r != nil {
+				if err := idempotencyService.Release(ctx, userID, key); err != nil {
+					log.Printf("failed to release idempotency key: %v", err)
+				}
+				panic(r)
+			}
+		}()
+
+		c.Next()
+
+		if err := idempotencyService.Complete(ctx, servicedto.CompleteIdempotentRequestInput{
+			UserID:       userID,
+			Key:          key,
+			StatusCode:   recorder.Status(),
+			ContentType:  recorder.Header().Get("Content-Type"),
+			ResponseBody: recorder.body.Bytes(),
+		}); err != nil {
+			log.Printf("failed to store idempotent response: %v", err)
+		}
+	}
+}
+
+func hashRequest(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(path))
+	h.Write([]byte{'\n'})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copies everything written to the client so it can be stored.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
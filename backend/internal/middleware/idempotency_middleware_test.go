@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	servicedto "vesuvio/internal/dto/service"
+	"vesuvio/internal/service"
+)
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := service.NewIdempotencyService(newMiddlewareFakeIdempotencyClient(), time.Hour)
+
+	calls := 0
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(ContextUserKey, servicedto.User{ID: 1})
+	})
+	r.POST("/reservations", Idempotency(svc), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/reservations", strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send(`{"people":2}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", first.Code)
+	}
+
+	second := send(`{"people":2}`)
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("expected replayed response %q, got %d %q", first.Body.String(), second.Code, second.Body.String())
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expected replay header")
+	}
+	if calls != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls)
+	}
+
+	mismatch := send(`{"people":4}`)
+	if mismatch.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for reused key, got %d", mismatch.Code)
+	}
+}
+
+func TestIdempotencyWithoutHeaderPassesThrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := service.NewIdempotencyService(newMiddlewareFakeIdempotencyClient(), time.Hour)
+
+	calls := 0
+	r := gin.New()
+	r.POST("/reservations", Idempotency(svc), func(c *gin.Context) {
+		calls++
+		c.Status(http.StatusCreated)
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/reservations", strings.NewReader(`{}`))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+	}
+	if calls != 2 {
+		t.Fatalf("expected handler to run twice, ran %d times", calls)
+	}
+}
+
+type middlewareFakeIdempotencyClient struct {
+	records map[string]servicedto.IdempotencyRecord
+}
+
+func newMiddlewareFakeIdempotencyClient() *middlewareFakeIdempotencyClient {
+	return &middlewareFakeIdempotencyClient{records: make(map[string]servicedto.IdempotencyRecord)}
+}
+
+func (f *middlewareFakeIdempotencyClient) CreateIdempotencyKey(ctx context.Context, params servicedto.CreateIdempotencyKeyParams) (bool, error) {
+	k := fmt.Sprintf("%d|%s", params.UserID, params.Key)
+	if _, ok := f.records[k]; ok {
+		return false, nil
+	}
+	f.records[k] = servicedto.IdempotencyRecord{
+		UserID:      params.UserID,
+		Key:         params.Key,
+		RequestHash: params.RequestHash,
+		ExpiresAt:   params.ExpiresAt,
+	}
+	return true, nil
+}
+
+func (f *middlewareFakeIdempotencyClient) GetIdempotencyKey(ctx context.Context, userID uint, key string) (*servicedto.IdempotencyRecord, error) {
+	r, ok := f.records[fmt.Sprintf("%d|%s", userID, key)]
+	if !ok {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+func (f *middlewareFakeIdempotencyClient) CompleteIdempotencyKey(ctx context.Context, input servicedto.CompleteIdempotentRequestInput) error {
+	k := fmt.Sprintf("%d|%s", input.UserID, input.Key)
+	r := f.records[k]
+	r.StatusCode = input.StatusCode
+	r.ContentType = input.ContentType
+	r.ResponseBody = input.ResponseBody
+	f.records[k] = r
+	return nil
+}
+
+func (f *middlewareFakeIdempotencyClient) DeleteIdempotencyKey(ctx context.Context, userID uint, key string) error {
+	delete(f.records, fmt.Sprintf("%d|%s", userID, key))
+	return nil
+}
+
+func (f *middlewareFakeIdempotencyClient) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) error {
+	return nil
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// CalendarFeedModel stores the secret token of a user's calendar feed. Each
+// user has at most one feed per scope.
+type CalendarFeedModel struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_calendar_feed_owner"`
+	User      UserModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Scope     string    `gorm:"size:16;not null;uniqueIndex:idx_calendar_feed_owner"`
+	Token     string    `gorm:"size:64;not null;uniqueIndex"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// GuestIncidentModel records a no-show or late cancellation against the
+// guest who made the reservation. A reservation counts at most once per kind.
+type GuestIncidentModel struct {
+	ID            uint      `gorm:"primaryKey"`
+	UserID        uint      `gorm:"not null;index"`
+	ReservationID uint      `gorm:"not null;uniqueIndex:idx_incident_reservation_kind"`
+	Kind          string    `gorm:"size:20;not null;uniqueIndex:idx_incident_reservation_kind"` // no_show or late_cancel
+	OccurredAt    time.Time `gorm:"not null;index"`
+	CreatedAt     time.Time
+}
+
+// GuestRestrictionModel is a sanction applied to a guest's online bookings.
+// A guest has at most one restriction with LiftedAt unset.
+type GuestRestrictionModel struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	User      UserModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Level     string    `gorm:"size:20;not null"` // approval, deposit or block
+	Reason    string    `gorm:"size:255;not null"`
+	Incidents int       `gorm:"not null"` // incidents counted when the level was set
+	LiftedAt  *time.Time
+	LiftedBy  *uint
+	LiftNote  *string `gorm:"size:255"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// IdempotencyKeyModel stores the outcome of a request made with an
+// Idempotency-Key header so retries can be answered with the same response.
+type IdempotencyKeyModel struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"not null;uniqueIndex:idx_idempotency_scope"`
+	Key          string `gorm:"size:255;not null;uniqueIndex:idx_idempotency_scope"`
+	RequestHash  string `gorm:"size:64;not null"`
+	StatusCode   int    `gorm:"not null;default:0"` // 0 while the original request is in flight
+	ContentType  string `gorm:"size:255"`
+	ResponseBody []byte
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// JobLeaseModel lets one instance at a time run a background job. The
+// holder renews the lease on every run; another instance takes over once
+// it expires.
+type JobLeaseModel struct {
+	Name      string    `gorm:"primaryKey;size:64"`
+	Holder    string    `gorm:"size:128;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// NotificationPreferenceModel stores the messages a user agreed to receive.
+// Users without a row get the defaults.
+type NotificationPreferenceModel struct {
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false"`
+	User      UserModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Email     bool      `gorm:"not null"`
+	SMS       bool      `gorm:"not null"`
+	Reminders bool      `gorm:"not null"`
+	UpdatedAt time.Time
+}
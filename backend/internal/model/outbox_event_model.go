@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// OutboxEventModel is a domain event written in the same transaction as the
+// reservation change it describes, so it survives a crash before dispatch.
+type OutboxEventModel struct {
+	ID            uint      `gorm:"primaryKey"`
+	Type          string    `gorm:"size:40;not null"`
+	ReservationID uint      `gorm:"not null;index"`
+	FromStatus    *string   `gorm:"size:20"`
+	ToStatus      string    `gorm:"size:20;not null"`
+	Status        string    `gorm:"size:20;not null;index:idx_outbox_due"` // pending, dispatched or failed
+	NextAttemptAt time.Time `gorm:"not null;index:idx_outbox_due"`         // while claimed, when the claim lapses
+	ClaimToken    *string   `gorm:"size:64"`                               // the dispatch run holding the event
+	Attempts      int       `gorm:"not null;default:0"`
+	Handled       []string  `gorm:"type:text;serializer:json"` // handlers done with the event
+	Error         *string   `gorm:"size:255"`
+	DispatchedAt  *time.Time
+	CreatedAt     time.Time `gorm:"index"` // live views poll by it
+}
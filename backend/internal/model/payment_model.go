@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// PaymentModel is the deposit or card guarantee taken for a reservation
+// through the payment provider. A reservation has at most one payment.
+type PaymentModel struct {
+	ID            uint    `gorm:"primaryKey"`
+	ReservationID uint    `gorm:"not null;uniqueIndex"`
+	Kind          string  `gorm:"size:20;not null"` // deposit or guarantee
+	Amount        int64   `gorm:"not null"`         // minor units, e.g. cents
+	Currency      string  `gorm:"size:3;not null"`
+	Status        string  `gorm:"size:20;not null"`
+	ProviderRef   string  `gorm:"size:64;not null;uniqueIndex"` // the provider's payment ID
+	CheckoutURL   string  `gorm:"size:255"`
+	Settlement    *string `gorm:"size:20;index"` // owed since the booking ended, until done
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// ReminderModel logs one reminder for a reservation on one channel. The
+// unique index lets a single instance claim each reminder before sending.
+type ReminderModel struct {
+	ID            uint             `gorm:"primaryKey"`
+	ReservationID uint             `gorm:"not null;uniqueIndex:idx_reminder_once"`
+	Reservation   ReservationModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	OffsetMinutes int              `gorm:"not null;uniqueIndex:idx_reminder_once"` // minutes before the slot
+	Channel       string           `gorm:"size:20;not null;uniqueIndex:idx_reminder_once"`
+	Recipient     string           `gorm:"size:255;not null"`
+	Status        string           `gorm:"size:20;not null"` // sending, sent or failed
+	Attempts      int              `gorm:"not null;default:0"`
+	Error         *string          `gorm:"size:255"`
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -4,14 +4,31 @@ import "time"
 
 // ReservationModel represents a booking in the system.
 type ReservationModel struct {
-	ID        uint      `gorm:"primaryKey"`
-	UserID    uint      `gorm:"not null;index"`
-	User      UserModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	Date      time.Time `gorm:"type:date;not null"`
-	Time      string    `gorm:"size:5;not null"` // HH:MM
-	People    int       `gorm:"not null"`
-	Comment   *string   `gorm:"type:text"`
-	Status    string    `gorm:"size:20;not null;default:pending"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID            uint                   `gorm:"primaryKey"`
+	Code          string                 `gorm:"size:16;uniqueIndex"` // human-friendly confirmation code
+	UserID        uint                   `gorm:"not null;index"`
+	User          UserModel              `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Date          time.Time              `gorm:"type:date;not null"`
+	Time          string                 `gorm:"size:5;not null"` // HH:MM
+	People        int                    `gorm:"not null"`
+	TableID       *uint                  `gorm:"index"`
+	Table         *TableModel            `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	Comment       *string                `gorm:"type:text"`
+	Allergens     []string               `gorm:"type:text;serializer:json"`
+	DietaryStyle  *string                `gorm:"size:30"`
+	Accessibility []string               `gorm:"type:text;serializer:json"`
+	HighChairs    int                    `gorm:"not null;default:0"`
+	Status        string                 `gorm:"size:20;not null;default:pending"`
+	Channel       string                 `gorm:"size:20;not null;default:web"` // how the booking was made
+	ReviewReason  *string                `gorm:"size:255"`                     // set when the booking needs an admin look
+	DecisionRule  *string                `gorm:"size:30"`                      // auto-confirm rule that decided the initial status
+	DecisionNote  *string                `gorm:"size:255"`
+	EscalatedAt   *time.Time             // set when a stale pending booking was escalated to staff
+	AttendingAt   *time.Time             // when the guest confirmed attendance from a reminder
+	SeatedAt      *time.Time             // when the party was seated
+	Notes         []ReservationNoteModel `gorm:"foreignKey:ReservationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Tags          []ReservationTagModel  `gorm:"foreignKey:ReservationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Payment       *PaymentModel          `gorm:"foreignKey:ReservationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
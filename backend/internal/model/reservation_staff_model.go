@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// ReservationNoteModel is an internal note written by staff on a
+// reservation. Guests never see notes.
+type ReservationNoteModel struct {
+	ID            uint      `gorm:"primaryKey"`
+	ReservationID uint      `gorm:"not null;index"`
+	AuthorID      uint      `gorm:"not null;index"`
+	Author        UserModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Body          string    `gorm:"type:text;not null"`
+	CreatedAt     time.Time
+}
+
+// ReservationTagModel attaches one tag from the staff vocabulary to a
+// reservation.
+type ReservationTagModel struct {
+	ID            uint   `gorm:"primaryKey"`
+	ReservationID uint   `gorm:"not null;uniqueIndex:idx_reservation_tag"`
+	Tag           string `gorm:"size:20;not null;uniqueIndex:idx_reservation_tag;index"`
+	CreatedAt     time.Time
+}
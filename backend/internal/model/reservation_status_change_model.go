@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// ReservationStatusChangeModel is one entry in a reservation's status
+// history, written in the same transaction as the change itself.
+type ReservationStatusChangeModel struct {
+	ID            uint             `gorm:"primaryKey"`
+	ReservationID uint             `gorm:"not null;index"`
+	Reservation   ReservationModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	FromStatus    string           `gorm:"size:20;not null"`
+	ToStatus      string           `gorm:"size:20;not null"`
+	ActorID       *uint            `gorm:"index"`            // nil for system changes
+	Source        string           `gorm:"size:20;not null"` // admin, admin_bulk, guest or system
+	Reason        *string          `gorm:"size:255"`
+	CreatedAt     time.Time
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// TableModel is a physical table on the restaurant floor.
+type TableModel struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:50;not null;uniqueIndex"`
+	Area      string `gorm:"size:50;not null;default:main"` // e.g. main, terrace, bar
+	Seats     int    `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
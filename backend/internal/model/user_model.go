@@ -9,6 +9,10 @@ type UserModel struct {
 	Email        string `gorm:"size:255;not null;uniqueIndex"`
 	PasswordHash string `gorm:"not null"`
 	IsAdmin      bool   `gorm:"default:false"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	// Phone and IsGuest describe phone/walk-in guests created by staff. Guests
+	// have no password and cannot log in.
+	Phone     *string `gorm:"size:32;index"`
+	IsGuest   bool    `gorm:"default:false"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// WebhookModel is an admin-managed subscription to reservation events.
+type WebhookModel struct {
+	ID          uint     `gorm:"primaryKey"`
+	URL         string   `gorm:"size:2048;not null"`
+	Secret      string   `gorm:"size:64;not null"`
+	Events      []string `gorm:"type:text;serializer:json"`
+	Active      bool     `gorm:"not null"`
+	Description *string  `gorm:"size:255"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// WebhookDeliveryModel logs one event sent to one webhook. Pending
+// deliveries are picked up once NextAttemptAt has passed.
+type WebhookDeliveryModel struct {
+	ID            uint         `gorm:"primaryKey"`
+	WebhookID     uint         `gorm:"not null;uniqueIndex:idx_webhook_delivery_event"`
+	Webhook       WebhookModel `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	EventID       string       `gorm:"size:32;not null;uniqueIndex:idx_webhook_delivery_event"`
+	Event         string       `gorm:"size:40;not null"`
+	Payload       []byte       `gorm:"not null"`
+	Status        string       `gorm:"size:20;not null;index:idx_webhook_delivery_due"`
+	Attempts      int          `gorm:"not null;default:0"`
+	NextAttemptAt *time.Time   `gorm:"index:idx_webhook_delivery_due"`
+	StatusCode    *int
+	Error         *string `gorm:"size:255"`
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
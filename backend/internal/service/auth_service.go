@@ -18,10 +18,12 @@ type UserClient interface {
 
 type AuthService struct {
 	userClient UserClient
+	phones     servicedto.PhoneFormat
 }
 
-func NewAuthService(userClient UserClient) *AuthService {
-	return &AuthService{userClient: userClient}
+// NewAuthService reads the phone numbers users register with using phones.
+func NewAuthService(userClient UserClient, phones servicedto.PhoneFormat) *AuthService {
+	return &AuthService{userClient: userClient, phones: phones}
 }
 
 func (s *AuthService) Register(ctx context.Context, input servicedto.RegisterUserInput) (*servicedto.RegisterUserOutput, error) {
@@ -30,6 +32,14 @@ func (s *AuthService) Register(ctx context.Context, input servicedto.RegisterUse
 	if name == "" || email == "" || strings.TrimSpace(input.Password) == "" {
 		return nil, ErrInvalidInput
 	}
+	var phone *string
+	if strings.TrimSpace(input.Phone) != "" {
+		normalized, ok := s.phones.Normalize(input.Phone)
+		if !ok {
+			return nil, ErrInvalidPhone
+		}
+		phone = &normalized
+	}
 
 	existing, err := s.userClient.GetUserByEmail(ctx, email)
 	if err != nil {
@@ -49,6 +59,7 @@ func (s *AuthService) Register(ctx context.Context, input servicedto.RegisterUse
 		Email:        email,
 		PasswordHash: string(hash),
 		IsAdmin:      false,
+		Phone:        phone,
 	})
 	if err != nil {
 		return nil, err
@@ -24,7 +24,7 @@ func TestRegisterDuplicateEmail(t *testing.T) {
 		t.Fatalf("seed user: %v", err)
 	}
 
-	svc := NewAuthService(userClient)
+	svc := NewAuthService(userClient, servicedto.PhoneFormat{})
 	_, err = svc.Register(ctx, servicedto.RegisterUserInput{
 		Name:     "New",
 		Email:    "test@example.com",
@@ -50,7 +50,7 @@ func TestLoginWrongPassword(t *testing.T) {
 		t.Fatalf("seed user: %v", err)
 	}
 
-	svc := NewAuthService(userClient)
+	svc := NewAuthService(userClient, servicedto.PhoneFormat{})
 	_, err = svc.Login(ctx, servicedto.LoginUserInput{
 		Email:    "login@example.com",
 		Password: "wrong",
@@ -64,7 +64,7 @@ func TestRegisterSuccessAndLoginSuccess(t *testing.T) {
 	userClient := newFakeUserClient()
 	ctx := context.Background()
 
-	svc := NewAuthService(userClient)
+	svc := NewAuthService(userClient, servicedto.PhoneFormat{})
 	registerOut, err := svc.Register(ctx, servicedto.RegisterUserInput{
 		Name:     "Alice",
 		Email:    "alice@example.com",
@@ -89,9 +89,28 @@ func TestRegisterSuccessAndLoginSuccess(t *testing.T) {
 	}
 }
 
+func TestRegisterPhone(t *testing.T) {
+	svc := NewAuthService(newFakeUserClient(), servicedto.PhoneFormat{})
+	ctx := context.Background()
+
+	out, err := svc.Register(ctx, servicedto.RegisterUserInput{Name: "Ana", Email: "ana@example.com", Password: "secret", Phone: "0034 600 123 456"})
+	if err != nil || out.User.Phone == nil || *out.User.Phone != "+34600123456" {
+		t.Fatalf("expected a normalized phone, got %+v %v", out, err)
+	}
+	if _, err := svc.Register(ctx, servicedto.RegisterUserInput{Name: "Bo", Email: "bo@example.com", Password: "secret", Phone: "600123456"}); err != ErrInvalidPhone {
+		t.Fatalf("expected ErrInvalidPhone, got %v", err)
+	}
+
+	local := NewAuthService(newFakeUserClient(), servicedto.PhoneFormat{CountryCode: "34"})
+	out, err = local.Register(ctx, servicedto.RegisterUserInput{Name: "Bo", Email: "bo@example.com", Password: "secret", Phone: "600 123 456"})
+	if err != nil || out.User.Phone == nil || *out.User.Phone != "+34600123456" {
+		t.Fatalf("expected the default country code added, got %+v %v", out, err)
+	}
+}
+
 func TestGetUserByIDNotFound(t *testing.T) {
 	userClient := newFakeUserClient()
-	svc := NewAuthService(userClient)
+	svc := NewAuthService(userClient, servicedto.PhoneFormat{})
 	ctx := context.Background()
 
 	_, err := svc.GetUserByID(ctx, 123)
@@ -102,14 +121,16 @@ func TestGetUserByIDNotFound(t *testing.T) {
 
 // fakeUserClient is a simple in-memory implementation for tests.
 type fakeUserClient struct {
-	users  map[uint]servicedto.UserWithPassword
-	nextID uint
+	users       map[uint]servicedto.UserWithPassword
+	preferences map[uint]servicedto.NotificationPreferences
+	nextID      uint
 }
 
 func newFakeUserClient() *fakeUserClient {
 	return &fakeUserClient{
-		users:  make(map[uint]servicedto.UserWithPassword),
-		nextID: 1,
+		users:       make(map[uint]servicedto.UserWithPassword),
+		preferences: make(map[uint]servicedto.NotificationPreferences),
+		nextID:      1,
 	}
 }
 
@@ -129,6 +150,8 @@ func (f *fakeUserClient) CreateUser(ctx context.Context, params servicedto.Creat
 			Name:      params.Name,
 			Email:     params.Email,
 			IsAdmin:   params.IsAdmin,
+			Phone:     params.Phone,
+			IsGuest:   params.IsGuest,
 			CreatedAt: now,
 			UpdatedAt: now,
 		},
@@ -156,3 +179,33 @@ func (f *fakeUserClient) GetUserByID(ctx context.Context, id uint) (*servicedto.
 	copy := u.User
 	return &copy, nil
 }
+
+func (f *fakeUserClient) GetUserByPhone(ctx context.Context, phone string) (*servicedto.User, error) {
+	for _, u := range f.users {
+		if u.Phone != nil && *u.Phone == phone {
+			copy := u.User
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeUserClient) UpdateUserPhone(ctx context.Context, userID uint, phone *string) error {
+	u := f.users[userID]
+	u.Phone = phone
+	f.users[userID] = u
+	return nil
+}
+
+func (f *fakeUserClient) GetNotificationPreferences(ctx context.Context, userID uint) (*servicedto.NotificationPreferences, error) {
+	prefs, ok := f.preferences[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &prefs, nil
+}
+
+func (f *fakeUserClient) SaveNotificationPreferences(ctx context.Context, userID uint, prefs servicedto.NotificationPreferences) error {
+	f.preferences[userID] = prefs
+	return nil
+}
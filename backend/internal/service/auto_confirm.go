@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// DefaultAutoConfirmMaxPeople is the largest party auto-confirmed when the
+// policy does not set one.
+const DefaultAutoConfirmMaxPeople = 4
+
+// WithAutoConfirm confirms new bookings that meet the policy instead of
+// leaving them pending for an admin. Load is only checked when a table
+// client is configured.
+func WithAutoConfirm(policy servicedto.AutoConfirmPolicy) ReservationOption {
+	return func(s *ReservationService) {
+		if policy.MaxPeople <= 0 {
+			policy.MaxPeople = DefaultAutoConfirmMaxPeople
+		}
+		s.autoConfirm = &policy
+	}
+}
+
+// bookingRequest is what the auto-confirm rules look at. reservationID is
+// set when an existing booking is being changed.
+type bookingRequest struct {
+	reservationID uint
+	userID        uint
+	date          time.Time
+	slotTime      string
+	people        int
+	flagged       bool
+	deposit       bool
+}
+
+// decideStatus runs the auto-confirm rules in order and returns the status a
+// new booking starts in along with the rule that decided it. Without a
+// policy every booking stays pending and no decision is recorded.
+func (s *ReservationService) decideStatus(ctx context.Context, req bookingRequest) (string, *servicedto.Decision, error) {
+	p := s.autoConfirm
+	if p == nil {
+		return servicedto.StatusPending, nil, nil
+	}
+	pending := func(rule, note string, args ...any) (string, *servicedto.Decision, error) {
+		return servicedto.StatusPending, &servicedto.Decision{Rule: rule, Note: fmt.Sprintf(note, args...)}, nil
+	}
+
+	if req.flagged {
+		return pending(servicedto.RuleFlagged, "flagged for review")
+	}
+	if req.deposit {
+		return pending(servicedto.RuleDeposit, "deposit required")
+	}
+	if date := req.date.Format("2006-01-02"); slices.Contains(p.BlackoutDates, date) {
+		return pending(servicedto.RuleBlackout, "%s is a blackout date", date)
+	}
+	if req.people > p.MaxPeople {
+		return pending(servicedto.RulePartySize, "party of %d is over %d", req.people, p.MaxPeople)
+	}
+	if !p.AllowNoShows {
+		status := servicedto.StatusNoShow
+		missed, err := s.reservationClient.ListReservationsByUser(ctx, req.userID, &status)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(missed) > 0 {
+			return pending(servicedto.RuleNoShows, "guest has %d no-shows", len(missed))
+		}
+	}
+	load := -1
+	if p.MaxLoadPercent > 0 && s.tableClient != nil {
+		var err error
+		if load, err = s.slotLoad(ctx, req); err != nil {
+			return "", nil, err
+		}
+		if load > p.MaxLoadPercent {
+			return pending(servicedto.RuleCapacity, "slot would be %d%% booked, over %d%%", load, p.MaxLoadPercent)
+		}
+	}
+
+	note := fmt.Sprintf("party of %d", req.people)
+	if load >= 0 {
+		note += fmt.Sprintf(", slot %d%% booked", load)
+	}
+	return servicedto.StatusConfirmed, &servicedto.Decision{Rule: servicedto.RuleAutoConfirm, Note: note}, nil
+}
+
+// slotLoad returns the seats held by active bookings whose time window
+// overlaps the requested slot, new party included, as a percentage of all
+// seats. A floor without tables counts as full.
+func (s *ReservationService) slotLoad(ctx context.Context, req bookingRequest) (int, error) {
+	tables, err := s.tableClient.ListTables(ctx)
+	if err != nil {
+		return 0, err
+	}
+	seats := 0
+	for _, t := range tables {
+		seats += t.Seats
+	}
+	if seats == 0 {
+		return 100, nil
+	}
+
+	existing, err := reservationsOn(ctx, s.reservationClient, req.date)
+	if err != nil {
+		return 0, err
+	}
+	start := minutesOfDay(req.slotTime)
+	window := int(s.reservationDuration / time.Minute)
+	booked := req.people
+	for _, r := range existing {
+		other := minutesOfDay(r.Time)
+		if r.ID == req.reservationID || !isActiveStatus(r.Status) || other < 0 || start-other >= window || other-start >= window {
+			continue
+		}
+		booked += r.People
+	}
+	return booked * 100 / seats, nil
+}
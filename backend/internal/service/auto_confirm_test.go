@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestAutoConfirmRules(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeReservationClient()
+	tables := newFakeTableClient()
+	_, _ = tables.CreateTable(ctx, servicedto.CreateTableParams{Name: "T1", Seats: 10})
+	svc := NewReservationService(client,
+		WithTableClient(tables),
+		WithOverlapPolicy(servicedto.OverlapPolicyFlag),
+		WithAutoConfirm(servicedto.AutoConfirmPolicy{
+			MaxPeople:      4,
+			MaxLoadPercent: 50,
+			BlackoutDates:  []string{"2030-12-31"},
+		}),
+	)
+
+	// User 9 missed a booking before.
+	missed, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 9, Date: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 2, Status: servicedto.StatusNoShow,
+	})
+
+	for _, tc := range []struct {
+		name   string
+		userID uint
+		date   string
+		time   string
+		people int
+		status string
+		rule   string
+	}{
+		{"small party", 1, "2030-01-10", "20:00", 2, servicedto.StatusConfirmed, servicedto.RuleAutoConfirm},
+		{"overlap flagged", 1, "2030-01-10", "21:00", 2, servicedto.StatusPending, servicedto.RuleFlagged},
+		{"blackout", 2, "2030-12-31", "20:00", 2, servicedto.StatusPending, servicedto.RuleBlackout},
+		{"large party", 3, "2030-01-11", "20:00", 5, servicedto.StatusPending, servicedto.RulePartySize},
+		{"no-shows", missed.UserID, "2030-01-12", "20:00", 2, servicedto.StatusPending, servicedto.RuleNoShows},
+		{"still room", 4, "2030-01-10", "22:00", 3, servicedto.StatusConfirmed, servicedto.RuleAutoConfirm},
+		{"slot full", 5, "2030-01-10", "21:30", 2, servicedto.StatusPending, servicedto.RuleCapacity},
+		{"later sitting", 6, "2030-01-10", "23:30", 2, servicedto.StatusConfirmed, servicedto.RuleAutoConfirm},
+	} {
+		out, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{
+			UserID: tc.userID, Date: tc.date, Time: tc.time, People: tc.people,
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		res := out.Reservation
+		if res.Status != tc.status || res.Decision == nil || res.Decision.Rule != tc.rule || res.Decision.Note == "" {
+			t.Fatalf("%s: expected %s by %s, got %s %+v", tc.name, tc.status, tc.rule, res.Status, res.Decision)
+		}
+	}
+}
+
+func TestAutoConfirmDisabledAndDeposits(t *testing.T) {
+	ctx := context.Background()
+	svc := NewReservationService(newFakeReservationClient())
+	out, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 1, Date: "2030-01-10", Time: "20:00", People: 2})
+	if err != nil || out.Reservation.Status != servicedto.StatusPending || out.Reservation.Decision != nil {
+		t.Fatalf("expected undecided pending booking, got %+v %v", out, err)
+	}
+
+	// Bookings that pay up front are never auto-confirmed.
+	svc, _, _, _ = newDepositTestService(servicedto.DepositPolicy{MinPeople: 2})
+	WithAutoConfirm(servicedto.AutoConfirmPolicy{})(svc)
+	out, err = svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 1, Date: "2030-01-10", Time: "20:00", People: 2})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if res := out.Reservation; res.Status != servicedto.StatusAwaitingPayment || res.Decision == nil || res.Decision.Rule != servicedto.RuleDeposit {
+		t.Fatalf("expected deposit decision, got %s %+v", res.Status, res.Decision)
+	}
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// CalendarFeedClient abstracts calendar feed token persistence.
+type CalendarFeedClient interface {
+	GetCalendarFeed(ctx context.Context, userID uint, scope string) (*servicedto.CalendarFeed, error)
+	GetCalendarFeedByToken(ctx context.Context, token string) (*servicedto.CalendarFeed, error)
+	SaveCalendarFeed(ctx context.Context, params servicedto.SaveCalendarFeedParams) (*servicedto.CalendarFeed, error)
+}
+
+// Feeds hold a guest's bookings from the last 90 days on, and for staff
+// the bookings from a week ago to 90 days ahead. Calendar apps are asked to
+// refresh them hourly.
+const (
+	guestFeedHistory = 90 * 24 * time.Hour
+	staffFeedHistory = 7 * 24 * time.Hour
+	staffFeedAhead   = 90 * 24 * time.Hour
+	feedRefresh      = "PT1H"
+)
+
+// CalendarService renders reservations as iCalendar documents, one booking
+// at a time or as feeds calendar apps subscribe to.
+type CalendarService struct {
+	reservationClient ReservationClient
+	feedClient        CalendarFeedClient
+	guestClient       GuestClient
+	baseURL           string
+	uidDomain         string
+	duration          time.Duration
+	location          *time.Location
+	now               func() time.Time
+}
+
+// NewCalendarService publishes feeds under baseURL, whose host also names
+// the event UIDs. Events last duration.
+func NewCalendarService(resClient ReservationClient, feedClient CalendarFeedClient, guestClient GuestClient, baseURL string, duration time.Duration, loc *time.Location) *CalendarService {
+	domain := "vesuvio"
+	if u, err := url.Parse(baseURL); err == nil && u.Hostname() != "" {
+		domain = u.Hostname()
+	}
+	return &CalendarService{
+		reservationClient: resClient,
+		feedClient:        feedClient,
+		guestClient:       guestClient,
+		baseURL:           strings.TrimRight(baseURL, "/"),
+		uidDomain:         domain,
+		duration:          duration,
+		location:          loc,
+		now:               time.Now,
+	}
+}
+
+// ReservationCalendar renders one booking for its guest, or for staff.
+func (s *CalendarService) ReservationCalendar(ctx context.Context, user servicedto.User, reservationID uint) (*servicedto.CalendarFile, error) {
+	res, err := s.reservationClient.GetReservationByID(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, ErrReservationNotFound
+	}
+	if res.UserID != user.ID && !user.IsAdmin {
+		return nil, ErrForbiddenReservation
+	}
+	staff := res.UserID != user.ID
+	if staff && res.User == nil && s.guestClient != nil {
+		if res.User, err = s.guestClient.GetUserByID(ctx, res.UserID); err != nil {
+			return nil, err
+		}
+	}
+	return &servicedto.CalendarFile{
+		Filename: strings.ToLower(res.Code) + ".ics",
+		Body:     s.render("", []servicedto.Reservation{*res}, staff),
+	}, nil
+}
+
+// CalendarFeed returns the user's feed of the scope, creating it on first
+// use. Only admins have a staff feed.
+func (s *CalendarService) CalendarFeed(ctx context.Context, user servicedto.User, scope string) (*servicedto.CalendarFeed, error) {
+	if err := checkFeedScope(user, scope); err != nil {
+		return nil, err
+	}
+	feed, err := s.feedClient.GetCalendarFeed(ctx, user.ID, scope)
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return s.ResetCalendarFeed(ctx, user, scope)
+	}
+	feed.URL = s.feedURL(feed.Token)
+	return feed, nil
+}
+
+// ResetCalendarFeed gives the feed a new address; the old one stops working.
+func (s *CalendarService) ResetCalendarFeed(ctx context.Context, user servicedto.User, scope string) (*servicedto.CalendarFeed, error) {
+	if err := checkFeedScope(user, scope); err != nil {
+		return nil, err
+	}
+	token, err := randomHex(24)
+	if err != nil {
+		return nil, err
+	}
+	feed, err := s.feedClient.SaveCalendarFeed(ctx, servicedto.SaveCalendarFeedParams{UserID: user.ID, Scope: scope, Token: token})
+	if err != nil {
+		return nil, err
+	}
+	feed.URL = s.feedURL(feed.Token)
+	return feed, nil
+}
+
+// RenderFeed renders the feed with the token. Staff feeds stop working when
+// their owner is no longer an admin.
+func (s *CalendarService) RenderFeed(ctx context.Context, token string) (*servicedto.CalendarFile, error) {
+	feed, err := s.feedClient.GetCalendarFeedByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return nil, ErrCalendarFeedNotFound
+	}
+	owner, err := s.guestClient.GetUserByID(ctx, feed.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil || (feed.Scope == servicedto.CalendarFeedScopeAdmin && !owner.IsAdmin) {
+		return nil, ErrCalendarFeedNotFound
+	}
+
+	today, _ := time.Parse("2006-01-02", s.now().In(s.location).Format("2006-01-02"))
+	var reservations []servicedto.Reservation
+	name := "Vesuvio"
+	if feed.Scope == servicedto.CalendarFeedScopeAdmin {
+		from, to := today.Add(-staffFeedHistory), today.Add(staffFeedAhead)
+		page, err := s.reservationClient.QueryReservations(ctx, servicedto.ReservationQuery{From: &from, To: &to})
+		if err != nil {
+			return nil, err
+		}
+		reservations, name = page.Reservations, "Vesuvio bookings"
+	} else {
+		all, err := s.reservationClient.ListReservationsByUser(ctx, owner.ID, nil)
+		if err != nil {
+			return nil, err
+		}
+		since := today.Add(-guestFeedHistory)
+		for _, r := range all {
+			if !r.Date.Before(since) {
+				reservations = append(reservations, r)
+			}
+		}
+	}
+	return &servicedto.CalendarFile{
+		Filename: "vesuvio.ics",
+		Body:     s.render(name, reservations, feed.Scope == servicedto.CalendarFeedScopeAdmin),
+	}, nil
+}
+
+func (s *CalendarService) feedURL(token string) string {
+	return s.baseURL + "/feeds/" + token + ".ics"
+}
+
+func checkFeedScope(user servicedto.User, scope string) error {
+	switch scope {
+	case servicedto.CalendarFeedScopeUser:
+		return nil
+	case servicedto.CalendarFeedScopeAdmin:
+		if !user.IsAdmin {
+			return ErrUnauthorized
+		}
+		return nil
+	default:
+		return ErrInvalidInput
+	}
+}
+
+// render writes a calendar of the reservations. Feeds are named; staff
+// calendars describe each booking by its guest.
+func (s *CalendarService) render(name string, reservations []servicedto.Reservation, staff bool) []byte {
+	type event struct {
+		res   servicedto.Reservation
+		start time.Time
+	}
+	events := make([]event, 0, len(reservations))
+	for _, r := range reservations {
+		if start, ok := slotStart(r, s.location); ok {
+			events = append(events, event{res: r, start: start})
+		}
+	}
+	slices.SortFunc(events, func(a, b event) int {
+		return cmp.Or(a.start.Compare(b.start), cmp.Compare(a.res.ID, b.res.ID))
+	})
+
+	w := &icalWriter{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//Vesuvio//Reservations//EN")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	if name != "" {
+		w.text("X-WR-CALNAME", name)
+		w.text("X-WR-TIMEZONE", s.location.String())
+		w.line("REFRESH-INTERVAL;VALUE=DURATION", feedRefresh)
+		w.line("X-PUBLISHED-TTL", feedRefresh)
+	}
+	if len(events) > 0 {
+		w.timezone(s.location, events[0].start.AddDate(-1, 0, 0), events[len(events)-1].start.Add(s.duration))
+	}
+	for _, e := range events {
+		r := e.res
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", fmt.Sprintf("reservation-%d@%s", r.ID, s.uidDomain))
+		w.utc("DTSTAMP", r.UpdatedAt)
+		w.utc("CREATED", r.CreatedAt)
+		w.utc("LAST-MODIFIED", r.UpdatedAt)
+		w.local("DTSTART", e.start, s.location)
+		w.local("DTEND", e.start.Add(s.duration), s.location)
+		w.text("SUMMARY", calendarSummary(r, staff))
+		w.text("DESCRIPTION", calendarDescription(r, staff))
+		w.line("STATUS", calendarStatus(r.Status))
+		w.line("END", "VEVENT")
+	}
+	w.line("END", "VCALENDAR")
+	return w.bytes()
+}
+
+func calendarSummary(r servicedto.Reservation, staff bool) string {
+	if !staff {
+		return fmt.Sprintf("Vesuvio: table for %d", r.People)
+	}
+	name := "Guest"
+	if r.User != nil {
+		name = r.User.Name
+	}
+	return fmt.Sprintf("%s (%d)", name, r.People)
+}
+
+func calendarDescription(r servicedto.Reservation, staff bool) string {
+	lines := []string{
+		"Confirmation code: " + r.Code,
+		fmt.Sprintf("Party of %d", r.People),
+		"Status: " + strings.ReplaceAll(r.Status, "_", " "),
+	}
+	if staff && r.User != nil && r.User.Phone != nil {
+		lines = append(lines, "Phone: "+*r.User.Phone)
+	}
+	if r.Comment != nil && *r.Comment != "" {
+		lines = append(lines, "Comment: "+*r.Comment)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// calendarStatus maps a reservation status to an event status. Bookings
+// still waiting on staff or payment are tentative.
+func calendarStatus(status string) string {
+	switch status {
+	case servicedto.StatusCancelled:
+		return "CANCELLED"
+	case servicedto.StatusPending, servicedto.StatusAwaitingPayment:
+		return "TENTATIVE"
+	default:
+		return "CONFIRMED"
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func newCalendarTestService(t *testing.T) (*CalendarService, *fakeReservationClient, *fakeUserClient) {
+	t.Helper()
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reservations := newFakeReservationClient()
+	users := newFakeUserClient()
+	svc := NewCalendarService(reservations, newFakeCalendarFeedClient(), users, "https://book.vesuvio.example/", 2*time.Hour, rome)
+	svc.now = func() time.Time { return time.Date(2030, 1, 11, 12, 0, 0, 0, time.UTC) }
+	return svc, reservations, users
+}
+
+func TestReservationCalendar(t *testing.T) {
+	ctx := context.Background()
+	svc, reservations, users := newCalendarTestService(t)
+	guest, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com"})
+	other, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Bo", Email: "bo@example.com"})
+	admin, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Host", Email: "host@example.com", IsAdmin: true})
+	comment := "Birthday; window seat, please\nand a cake"
+	res, _ := reservations.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: guest.ID, Date: mustDate(t, "2030-07-14"), Time: "20:30", People: 4, Comment: &comment, Status: servicedto.StatusConfirmed,
+	})
+
+	file, err := svc.ReservationCalendar(ctx, *guest, res.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file.Filename != "vsv-000001.ics" {
+		t.Fatalf("unexpected filename %q", file.Filename)
+	}
+	body := string(file.Body)
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:reservation-1@book.vesuvio.example\r\n",
+		"BEGIN:VTIMEZONE\r\nTZID:Europe/Rome\r\n",
+		"TZOFFSETTO:+0200\r\n",
+		"DTSTART;TZID=Europe/Rome:20300714T203000\r\n",
+		"DTEND;TZID=Europe/Rome:20300714T223000\r\n",
+		"SUMMARY:Vesuvio: table for 4\r\n",
+		`Comment: Birthday\; window seat\, please\nand a cake`,
+		"STATUS:CONFIRMED\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(strings.ReplaceAll(body, "\r\n ", ""), want) {
+			t.Fatalf("expected %q in:\n%s", want, body)
+		}
+	}
+	for _, line := range strings.Split(body, "\r\n") {
+		if len(line) > icalMaxLine {
+			t.Fatalf("line longer than %d octets: %q", icalMaxLine, line)
+		}
+	}
+
+	// Staff see the guest's name; other guests see nothing.
+	file, err = svc.ReservationCalendar(ctx, *admin, res.ID)
+	if err != nil || !strings.Contains(string(file.Body), "SUMMARY:Ana (4)") {
+		t.Fatalf("expected the staff summary, got %v\n%s", err, file.Body)
+	}
+	if _, err := svc.ReservationCalendar(ctx, *other, res.ID); err != ErrForbiddenReservation {
+		t.Fatalf("expected ErrForbiddenReservation, got %v", err)
+	}
+	if _, err := svc.ReservationCalendar(ctx, *guest, 99); err != ErrReservationNotFound {
+		t.Fatalf("expected ErrReservationNotFound, got %v", err)
+	}
+}
+
+func TestCalendarFeeds(t *testing.T) {
+	ctx := context.Background()
+	svc, reservations, users := newCalendarTestService(t)
+	guest, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com"})
+	other, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Bo", Email: "bo@example.com"})
+	admin, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Host", Email: "host@example.com", IsAdmin: true})
+	book := func(userID uint, date, status string) *servicedto.Reservation {
+		res, _ := reservations.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: userID, Date: mustDate(t, date), Time: "20:00", People: 2, Status: status,
+		})
+		return res
+	}
+	book(guest.ID, "2030-01-20", servicedto.StatusCancelled)
+	book(guest.ID, "2029-06-01", servicedto.StatusSeated) // too old for the feed
+	pending := book(other.ID, "2030-01-12", servicedto.StatusPending)
+	pending.User = other // loaded with the booking, as the staff queries do
+	reservations.reservations[pending.ID] = *pending
+
+	feed, err := svc.CalendarFeed(ctx, *guest, servicedto.CalendarFeedScopeUser)
+	if err != nil || !strings.HasPrefix(feed.URL, "https://book.vesuvio.example/feeds/") || !strings.HasSuffix(feed.URL, ".ics") {
+		t.Fatalf("unexpected feed: %+v %v", feed, err)
+	}
+	if again, _ := svc.CalendarFeed(ctx, *guest, servicedto.CalendarFeedScopeUser); again.Token != feed.Token {
+		t.Fatalf("expected the same feed, got %+v", again)
+	}
+
+	file, err := svc.RenderFeed(ctx, feed.Token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body := string(file.Body)
+	if strings.Count(body, "BEGIN:VEVENT") != 1 || !strings.Contains(body, "STATUS:CANCELLED\r\n") || !strings.Contains(body, "X-WR-CALNAME:Vesuvio\r\n") {
+		t.Fatalf("unexpected guest feed:\n%s", body)
+	}
+
+	// Resetting moves the feed to a new address.
+	reset, err := svc.ResetCalendarFeed(ctx, *guest, servicedto.CalendarFeedScopeUser)
+	if err != nil || reset.Token == feed.Token {
+		t.Fatalf("expected a new token, got %+v %v", reset, err)
+	}
+	if _, err := svc.RenderFeed(ctx, feed.Token); err != ErrCalendarFeedNotFound {
+		t.Fatalf("expected the old token to stop working, got %v", err)
+	}
+
+	// Staff feeds list everyone's bookings, and only admins have one.
+	if _, err := svc.CalendarFeed(ctx, *guest, servicedto.CalendarFeedScopeAdmin); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if _, err := svc.CalendarFeed(ctx, *guest, "team"); err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+	staff, err := svc.CalendarFeed(ctx, *admin, servicedto.CalendarFeedScopeAdmin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file, err = svc.RenderFeed(ctx, staff.Token)
+	if err != nil || strings.Count(string(file.Body), "BEGIN:VEVENT") != 2 || !strings.Contains(string(file.Body), "SUMMARY:Bo (2)") {
+		t.Fatalf("unexpected staff feed: %v\n%s", err, file.Body)
+	}
+
+	// The staff feed stops working once its owner is no longer an admin.
+	demoted := users.users[admin.ID]
+	demoted.IsAdmin = false
+	users.users[admin.ID] = demoted
+	if _, err := svc.RenderFeed(ctx, staff.Token); err != ErrCalendarFeedNotFound {
+		t.Fatalf("expected ErrCalendarFeedNotFound, got %v", err)
+	}
+	if _, err := svc.RenderFeed(ctx, "unknown"); err != ErrCalendarFeedNotFound {
+		t.Fatalf("expected ErrCalendarFeedNotFound, got %v", err)
+	}
+}
+
+// fakeCalendarFeedClient keeps feeds in memory.
+type fakeCalendarFeedClient struct {
+	feeds  []servicedto.CalendarFeed
+	nextID uint
+}
+
+func newFakeCalendarFeedClient() *fakeCalendarFeedClient {
+	return &fakeCalendarFeedClient{nextID: 1}
+}
+
+func (f *fakeCalendarFeedClient) GetCalendarFeed(ctx context.Context, userID uint, scope string) (*servicedto.CalendarFeed, error) {
+	for _, feed := range f.feeds {
+		if feed.UserID == userID && feed.Scope == scope {
+			return &feed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeCalendarFeedClient) GetCalendarFeedByToken(ctx context.Context, token string) (*servicedto.CalendarFeed, error) {
+	for _, feed := range f.feeds {
+		if feed.Token == token {
+			return &feed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeCalendarFeedClient) SaveCalendarFeed(ctx context.Context, params servicedto.SaveCalendarFeedParams) (*servicedto.CalendarFeed, error) {
+	now := time.Now()
+	for i, feed := range f.feeds {
+		if feed.UserID == params.UserID && feed.Scope == params.Scope {
+			f.feeds[i].Token, f.feeds[i].UpdatedAt = params.Token, now
+			copy := f.feeds[i]
+			return &copy, nil
+		}
+	}
+	feed := servicedto.CalendarFeed{ID: f.nextID, UserID: params.UserID, Scope: params.Scope, Token: params.Token, CreatedAt: now, UpdatedAt: now}
+	f.nextID++
+	f.feeds = append(f.feeds, feed)
+	return &feed, nil
+}
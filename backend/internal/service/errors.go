@@ -3,12 +3,51 @@ package service
 import "errors"
 
 var (
-	ErrEmailAlreadyExists   = errors.New("email already exists")
-	ErrInvalidCredentials   = errors.New("invalid credentials")
-	ErrUserNotFound         = errors.New("user not found")
-	ErrReservationNotFound  = errors.New("reservation not found")
-	ErrUnauthorized         = errors.New("unauthorized")
-	ErrInvalidStatus        = errors.New("invalid status")
-	ErrInvalidInput         = errors.New("invalid input")
-	ErrForbiddenReservation = errors.New("user cannot modify this reservation")
+	ErrEmailAlreadyExists     = errors.New("email already exists")
+	ErrInvalidCredentials     = errors.New("invalid credentials")
+	ErrUserNotFound           = errors.New("user not found")
+	ErrReservationNotFound    = errors.New("reservation not found")
+	ErrUnauthorized           = errors.New("unauthorized")
+	ErrInvalidStatus          = errors.New("invalid status")
+	ErrInvalidInput           = errors.New("invalid input")
+	ErrForbiddenReservation   = errors.New("user cannot modify this reservation")
+	ErrOverlappingReservation = errors.New("you already have a reservation at an overlapping time on this date")
+	ErrInvalidCursor          = errors.New("invalid cursor")
+	ErrTooManyReservations    = errors.New("too many reservations for one bulk action")
+	ErrInvalidTag             = errors.New("invalid tag")
+	ErrInvalidRequirement     = errors.New("invalid dietary or accessibility requirement")
+	ErrGuestRestricted        = errors.New("online booking is not available for this account, please contact the restaurant")
+	ErrRestrictionNotFound    = errors.New("guest has no active restriction")
+	ErrDepositRequired        = errors.New("this change needs a deposit, please make a new booking")
+	ErrInvalidPhone           = errors.New("phone number must be in international format, e.g. +34600123456")
+	ErrStatusConflict         = errors.New("reservation status changed meanwhile, please reload")
+
+	ErrPaymentNotFound  = errors.New("payment not found")
+	ErrPaymentsDisabled = errors.New("payments are not enabled")
+	ErrInvalidWebhook   = errors.New("invalid webhook")
+
+	ErrWebhookNotFound     = errors.New("webhook not found")
+	ErrInvalidWebhookURL   = errors.New("webhook url must be an absolute http or https url")
+	ErrPrivateWebhookURL   = errors.New("webhook url must not point to a loopback, link-local or private address")
+	ErrInvalidWebhookEvent = errors.New("webhook events must be one or more of reservation.created, reservation.confirmed, reservation.cancelled, reservation.updated")
+
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+	ErrInvalidLink   = errors.New("invalid link")
+	ErrLinkExpired   = errors.New("link has expired")
+	ErrLinksDisabled = errors.New("reservation links are not enabled")
+
+	ErrInvalidStreamToken = errors.New("invalid or expired stream token")
+
+	ErrTableNotFound  = errors.New("table not found")
+	ErrTableOccupied  = errors.New("table is occupied")
+	ErrTableNameTaken = errors.New("a table with this name already exists")
+	ErrNotToday       = errors.New("only reservations for today can be seated")
+
+	ErrIdempotencyKeyReused         = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyRequestInProgress = errors.New("a request with this idempotency key is still in progress")
+
+	// Wiring mistakes: an optional collaborator the call needs was not configured.
+	errGuestClientMissing = errors.New("guest client not configured")
+	errTableClientMissing = errors.New("table client not configured")
 )
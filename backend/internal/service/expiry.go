@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// expiryBatchSize is how many open reservations an expiry run reads at once.
+const expiryBatchSize = 200
+
+// WithExpiry sets when stale pending and unpaid reservations expire; see
+// ExpireStaleReservations. Stale pending bookings are escalated unless the
+// policy asks to cancel them.
+func WithExpiry(policy servicedto.ExpiryPolicy) ReservationOption {
+	return func(s *ReservationService) {
+		if policy.Action != servicedto.ExpiryActionCancel {
+			policy.Action = servicedto.ExpiryActionEscalate
+		}
+		s.expiry = policy
+	}
+}
+
+// ExpireStaleReservations cancels or escalates pending reservations nobody
+// reviewed in time and cancels bookings whose payment was never completed.
+// Each change is a compare-and-set on the reservation, so runs on several
+// instances never act twice on the same booking.
+func (s *ReservationService) ExpireStaleReservations(ctx context.Context) (*servicedto.ExpiryResult, error) {
+	now := s.now()
+	result := &servicedto.ExpiryResult{}
+	query := servicedto.ReservationQuery{
+		Statuses: []string{servicedto.StatusPending, servicedto.StatusAwaitingPayment},
+		Limit:    expiryBatchSize,
+	}
+	for {
+		page, err := s.reservationClient.QueryReservations(ctx, query)
+		if err != nil {
+			return result, err
+		}
+		for _, r := range page.Reservations {
+			if err := s.expireReservation(ctx, r, now, result); err != nil {
+				return result, err
+			}
+		}
+		if page.Next == nil {
+			return result, nil
+		}
+		query.After = page.Next
+	}
+}
+
+func (s *ReservationService) expireReservation(ctx context.Context, r servicedto.Reservation, now time.Time, result *servicedto.ExpiryResult) error {
+	reason, ok := s.expiryReason(r, now)
+	if !ok {
+		return nil
+	}
+
+	if r.Status == servicedto.StatusPending && s.expiry.Action == servicedto.ExpiryActionEscalate {
+		if r.EscalatedAt != nil {
+			return nil
+		}
+		escalated, err := s.reservationClient.EscalateReservation(ctx, r.ID, *joinReasons(r.ReviewReason, &reason), now)
+		if escalated {
+			result.Escalated++
+		}
+		return err
+	}
+
+	// A booking paid, reviewed or cancelled since it was read keeps its
+	// payment; only one this run really cancelled is released.
+	updated, err := s.reservationClient.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{
+		ID:     r.ID,
+		Status: servicedto.StatusCancelled,
+		From:   r.Status,
+		Actor:  servicedto.StatusActor{Source: servicedto.StatusSourceSystem, Reason: &reason},
+	})
+	if err != nil || updated == nil {
+		return err
+	}
+	result.Cancelled++
+	s.settleEnded(ctx, r.ID, settleRelease)
+	return nil
+}
+
+// expiryReason reports whether the reservation has expired under the
+// policy and why.
+func (s *ReservationService) expiryReason(r servicedto.Reservation, now time.Time) (string, bool) {
+	p := s.expiry
+	if r.Status == servicedto.StatusAwaitingPayment {
+		if p.PaymentTimeout > 0 && now.Sub(r.CreatedAt) >= p.PaymentTimeout {
+			return "payment not completed in time", true
+		}
+		return "", false
+	}
+	if p.PendingMaxAge > 0 && now.Sub(r.CreatedAt) >= p.PendingMaxAge {
+		return "not reviewed in time", true
+	}
+	if start, ok := slotStart(r, s.location); ok && p.PendingMinLead > 0 && start.Sub(now) < p.PendingMinLead {
+		return "not reviewed before the slot", true
+	}
+	return "", false
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+// seedStaleReservations creates, relative to now: a fresh pending booking,
+// one pending for three days, one pending an hour before its slot, one
+// confirmed long ago, and bookings awaiting payment for 45 and 10 minutes.
+func seedStaleReservations(t *testing.T, svc *ReservationService, client *fakeReservationClient, now time.Time) map[string]uint {
+	t.Helper()
+	ctx := context.Background()
+	ids := make(map[string]uint)
+	add := func(name, date, slot, status string, age time.Duration) {
+		res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: 1, Date: mustDate(t, date), Time: slot, People: 2, Status: status,
+		})
+		res.CreatedAt = now.Add(-age)
+		client.reservations[res.ID] = *res
+		ids[name] = res.ID
+	}
+	add("fresh", "2030-01-20", "20:00", servicedto.StatusPending, time.Hour)
+	add("old", "2030-01-20", "20:00", servicedto.StatusPending, 72*time.Hour)
+	add("soon", "2030-01-10", "13:00", servicedto.StatusPending, time.Hour)
+	add("confirmed", "2030-01-10", "13:00", servicedto.StatusConfirmed, 72*time.Hour)
+
+	for _, tc := range []struct {
+		name string
+		age  time.Duration
+	}{{"unpaid", 45 * time.Minute}, {"paying", 10 * time.Minute}} {
+		out, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 2, Date: "2030-01-21", Time: "20:00", People: 8})
+		if err != nil || out.Reservation.Status != servicedto.StatusAwaitingPayment {
+			t.Fatalf("seed %s: %+v %v", tc.name, out, err)
+		}
+		res := client.reservations[out.Reservation.ID]
+		res.CreatedAt = now.Add(-tc.age)
+		client.reservations[res.ID] = res
+		ids[tc.name] = res.ID
+	}
+	return ids
+}
+
+func mustDate(t *testing.T, date string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		t.Fatalf("parse %s: %v", date, err)
+	}
+	return d
+}
+
+func TestExpireStaleReservationsCancels(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	svc, client, provider, payments := newDepositTestService(servicedto.DepositPolicy{MinPeople: 8})
+	WithExpiry(servicedto.ExpiryPolicy{
+		PendingMaxAge: 48 * time.Hour, PendingMinLead: 2 * time.Hour, PaymentTimeout: 30 * time.Minute,
+		Action: servicedto.ExpiryActionCancel,
+	})(svc)
+	svc.now = func() time.Time { return now }
+	ids := seedStaleReservations(t, svc, client, now)
+
+	result, err := svc.ExpireStaleReservations(ctx)
+	if err != nil {
+		t.Fatalf("expire: %v", err)
+	}
+	if result.Cancelled != 3 || result.Escalated != 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	for name, want := range map[string]string{
+		"fresh":     servicedto.StatusPending,
+		"old":       servicedto.StatusCancelled,
+		"soon":      servicedto.StatusCancelled,
+		"confirmed": servicedto.StatusConfirmed,
+		"unpaid":    servicedto.StatusCancelled,
+		"paying":    servicedto.StatusAwaitingPayment,
+	} {
+		if got := client.reservations[ids[name]].Status; got != want {
+			t.Fatalf("%s: expected %s, got %s", name, want, got)
+		}
+	}
+	for _, h := range client.history {
+		if h.Source != servicedto.StatusSourceSystem || h.Reason == nil {
+			t.Fatalf("expected system change with a reason, got %+v", h)
+		}
+	}
+	if !slices.Equal(provider.calls, []string{"void"}) || payments.payments[0].Status != servicedto.PaymentReleased {
+		t.Fatalf("expected the unpaid checkout to be voided, got %v %+v", provider.calls, payments.payments[0])
+	}
+
+	// A second run, or another instance, finds nothing left to do.
+	if result, err := svc.ExpireStaleReservations(ctx); err != nil || result.Cancelled != 0 {
+		t.Fatalf("expected nothing to expire, got %+v %v", result, err)
+	}
+}
+
+func TestExpireStaleReservationsEscalates(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	svc, client, _, _ := newDepositTestService(servicedto.DepositPolicy{MinPeople: 8})
+	WithExpiry(servicedto.ExpiryPolicy{PendingMaxAge: 48 * time.Hour, PendingMinLead: 2 * time.Hour, PaymentTimeout: 30 * time.Minute})(svc)
+	svc.now = func() time.Time { return now }
+	ids := seedStaleReservations(t, svc, client, now)
+
+	result, err := svc.ExpireStaleReservations(ctx)
+	if err != nil {
+		t.Fatalf("expire: %v", err)
+	}
+	if result.Escalated != 2 || result.Cancelled != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	for _, name := range []string{"old", "soon"} {
+		res := client.reservations[ids[name]]
+		if res.Status != servicedto.StatusPending || res.EscalatedAt == nil || res.ReviewReason == nil {
+			t.Fatalf("%s: expected escalated pending booking, got %+v", name, res)
+		}
+	}
+	if client.reservations[ids["unpaid"]].Status != servicedto.StatusCancelled {
+		t.Fatal("expected unpaid booking to be cancelled even when escalating")
+	}
+
+	if result, err := svc.ExpireStaleReservations(ctx); err != nil || result.Escalated != 0 {
+		t.Fatalf("expected no repeat escalation, got %+v %v", result, err)
+	}
+}
+
+func TestExpireStaleReservationsKeepsPaymentWhenPaidMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	svc, client, provider, payments := newDepositTestService(servicedto.DepositPolicy{MinPeople: 8})
+	WithExpiry(servicedto.ExpiryPolicy{PaymentTimeout: 30 * time.Minute})(svc)
+	svc.now = func() time.Time { return now }
+	ids := seedStaleReservations(t, svc, client, now)
+
+	// The guest's payment lands after the run read the booking.
+	client.beforeStatusChange = func() {
+		client.beforeStatusChange = nil
+		if err := svc.HandlePaymentWebhook(ctx, []byte("ok:"+payments.payments[0].ProviderRef), "signed"); err != nil {
+			t.Fatalf("webhook: %v", err)
+		}
+	}
+	result, err := svc.ExpireStaleReservations(ctx)
+	if err != nil || result.Cancelled != 0 {
+		t.Fatalf("expected nothing cancelled, got %+v %v", result, err)
+	}
+	if got := client.reservations[ids["unpaid"]].Status; got != servicedto.StatusPending {
+		t.Fatalf("expected the paid booking to move on, got %s", got)
+	}
+	if len(provider.calls) != 0 || payments.payments[0].Status != servicedto.PaymentAuthorized {
+		t.Fatalf("expected the payment kept, got %v %+v", provider.calls, payments.payments[0])
+	}
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// exportColumn is a column an export can have.
+type exportColumn struct {
+	name, heading string
+}
+
+// exportColumns are in their default order.
+var exportColumns = []exportColumn{
+	{servicedto.ExportColumnCode, "Code"},
+	{servicedto.ExportColumnDate, "Date"},
+	{servicedto.ExportColumnTime, "Time"},
+	{servicedto.ExportColumnPeople, "Party size"},
+	{servicedto.ExportColumnStatus, "Status"},
+	{servicedto.ExportColumnGuestName, "Guest"},
+	{servicedto.ExportColumnGuestEmail, "Email"},
+	{servicedto.ExportColumnGuestPhone, "Phone"},
+	{servicedto.ExportColumnVisits, "Visits"},
+	{servicedto.ExportColumnNoShows, "No-shows"},
+	{servicedto.ExportColumnTable, "Table"},
+	{servicedto.ExportColumnChannel, "Channel"},
+	{servicedto.ExportColumnTags, "Tags"},
+	{servicedto.ExportColumnComment, "Comment"},
+	{servicedto.ExportColumnNotes, "Staff notes"},
+	{servicedto.ExportColumnCreatedAt, "Booked at"},
+}
+
+// exportLocale is how a locale writes dates and times of day.
+type exportLocale struct {
+	date  string
+	clock string
+}
+
+// isoExportLocale is used when no locale is asked for.
+var isoExportLocale = exportLocale{date: "2006-01-02", clock: "15:04"}
+
+// exportLocales are keyed by lower-case language tag; a tag that is not
+// listed falls back to its language.
+var exportLocales = map[string]exportLocale{
+	"en":    {date: "02/01/2006", clock: "15:04"},
+	"en-us": {date: "01/02/2006", clock: "3:04 PM"},
+	"it":    {date: "02/01/2006", clock: "15:04"},
+	"fr":    {date: "02/01/2006", clock: "15:04"},
+	"es":    {date: "02/01/2006", clock: "15:04"},
+	"pt":    {date: "02/01/2006", clock: "15:04"},
+	"de":    {date: "02.01.2006", clock: "15:04"},
+	"nl":    {date: "02-01-2006", clock: "15:04"},
+}
+
+func lookupExportLocale(tag string) (exportLocale, bool) {
+	if tag == "" {
+		return isoExportLocale, true
+	}
+	tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+	if l, ok := exportLocales[tag]; ok {
+		return l, true
+	}
+	lang, _, _ := strings.Cut(tag, "-")
+	l, ok := exportLocales[lang]
+	return l, ok
+}
+
+// ReservationExport is a validated export, ready to be written.
+type ReservationExport struct {
+	Filename    string
+	ContentType string
+
+	service *ReservationService
+	query   servicedto.ReservationQuery
+	format  string
+	columns []string
+	locale  exportLocale
+}
+
+// ExportReservations checks an export of the admin listing. Nothing is
+// read until the export is written, so problems with the input surface
+// before any output does.
+func (s *ReservationService) ExportReservations(ctx context.Context, admin servicedto.User, input servicedto.ExportReservationsInput) (*ReservationExport, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	query, err := filterQuery(servicedto.BulkReservationFilter{
+		Date:     input.Date,
+		From:     input.From,
+		To:       input.To,
+		Statuses: input.Statuses,
+		Tags:     input.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	query.Sort = servicedto.SortByDateTime
+	query.Limit = MaxReservationPageSize
+
+	e := &ReservationExport{service: s, query: query, format: input.Format}
+	switch e.format {
+	case "", servicedto.ExportCSV:
+		e.format, e.ContentType = servicedto.ExportCSV, "text/csv; charset=utf-8"
+	case servicedto.ExportXLSX:
+		e.ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return nil, ErrInvalidInput
+	}
+	for _, name := range input.Columns {
+		if !slices.ContainsFunc(exportColumns, func(c exportColumn) bool { return c.name == name }) {
+			return nil, ErrInvalidInput
+		}
+		if !slices.Contains(e.columns, name) {
+			e.columns = append(e.columns, name)
+		}
+	}
+	if len(e.columns) == 0 {
+		for _, c := range exportColumns {
+			e.columns = append(e.columns, c.name)
+		}
+	}
+	locale, ok := lookupExportLocale(input.Locale)
+	if !ok {
+		return nil, ErrInvalidInput
+	}
+	e.locale = locale
+
+	name := "reservations"
+	for _, bound := range []*time.Time{query.From, query.To} {
+		if bound != nil {
+			name += "-" + bound.Format("2006-01-02")
+		}
+	}
+	e.Filename = name + "." + e.format
+	return e, nil
+}
+
+// exportCell is one value of a row. Cells that are not numbers are text and
+// kept from being read as a formula.
+type exportCell struct {
+	text   string
+	number bool
+}
+
+// rowWriter encodes an export's rows.
+type rowWriter interface {
+	row(cells []exportCell) error
+	flush() error
+	close() error
+}
+
+// Write streams the export to w a page of reservations at a time, flushing
+// w after each page when it can be flushed.
+func (e *ReservationExport) Write(ctx context.Context, w io.Writer) error {
+	var out rowWriter
+	var err error
+	if e.format == servicedto.ExportXLSX {
+		out, err = newXLSXWriter(w, "Reservations")
+	} else {
+		out, err = newCSVWriter(w)
+	}
+	if err != nil {
+		return err
+	}
+
+	header := make([]exportCell, len(e.columns))
+	for i, name := range e.columns {
+		j := slices.IndexFunc(exportColumns, func(c exportColumn) bool { return c.name == name })
+		header[i] = exportCell{text: exportColumns[j].heading}
+	}
+	if err := out.row(header); err != nil {
+		return err
+	}
+
+	summaries := slices.Contains(e.columns, servicedto.ExportColumnVisits) || slices.Contains(e.columns, servicedto.ExportColumnNoShows)
+	query := e.query
+	for {
+		page, err := e.service.reservationClient.QueryReservations(ctx, query)
+		if err != nil {
+			return err
+		}
+		if summaries {
+			if err := e.service.attachGuestSummaries(ctx, page.Reservations); err != nil {
+				return err
+			}
+		}
+		for _, r := range page.Reservations {
+			if err := out.row(e.cells(r)); err != nil {
+				return err
+			}
+		}
+		if err := out.flush(); err != nil {
+			return err
+		}
+		if f, ok := w.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+		if page.Next == nil {
+			break
+		}
+		query.After = page.Next
+	}
+	return out.close()
+}
+
+func (e *ReservationExport) cells(r servicedto.Reservation) []exportCell {
+	cells := make([]exportCell, len(e.columns))
+	for i, name := range e.columns {
+		var c exportCell
+		switch name {
+		case servicedto.ExportColumnCode:
+			c.text = r.Code
+		case servicedto.ExportColumnDate:
+			c.text = r.Date.Format(e.locale.date)
+		case servicedto.ExportColumnTime:
+			c.text = r.Time
+			if t, err := time.Parse("15:04", r.Time); err == nil {
+				c.text = t.Format(e.locale.clock)
+			}
+		case servicedto.ExportColumnPeople:
+			c = exportCell{text: strconv.Itoa(r.People), number: true}
+		case servicedto.ExportColumnStatus:
+			c.text = r.Status
+		case servicedto.ExportColumnGuestName:
+			if r.User != nil {
+				c.text = r.User.Name
+			}
+		case servicedto.ExportColumnGuestEmail:
+			if r.User != nil && !r.User.IsGuest {
+				c.text = r.User.Email
+			}
+		case servicedto.ExportColumnGuestPhone:
+			if r.User != nil && r.User.Phone != nil {
+				c.text = *r.User.Phone
+			}
+		case servicedto.ExportColumnVisits:
+			if r.Guest != nil {
+				c = exportCell{text: strconv.Itoa(r.Guest.Visits), number: true}
+			}
+		case servicedto.ExportColumnNoShows:
+			if r.Guest != nil {
+				c = exportCell{text: strconv.Itoa(r.Guest.NoShows), number: true}
+			}
+		case servicedto.ExportColumnTable:
+			if r.TableID != nil {
+				c = exportCell{text: strconv.FormatUint(uint64(*r.TableID), 10), number: true}
+			}
+		case servicedto.ExportColumnChannel:
+			c.text = r.Channel
+		case servicedto.ExportColumnTags:
+			c.text = strings.Join(r.Tags, ", ")
+		case servicedto.ExportColumnComment:
+			if r.Comment != nil {
+				c.text = *r.Comment
+			}
+		case servicedto.ExportColumnNotes:
+			notes := make([]string, len(r.Notes))
+			for j, n := range r.Notes {
+				notes[j] = fmt.Sprintf("%s: %s", n.AuthorName, n.Body)
+			}
+			c.text = strings.Join(notes, "\n")
+		case servicedto.ExportColumnCreatedAt:
+			c.text = r.CreatedAt.In(e.service.location).Format(e.locale.date + " " + e.locale.clock)
+		}
+		cells[i] = c
+	}
+	return cells
+}
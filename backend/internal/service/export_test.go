@@ -0,0 +1,173 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestExportReservations_CSV(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	client := newFakeReservationClient()
+	svc := NewReservationService(client)
+	phone := "+390811234567"
+	comment := "=HYPERLINK(\"http://evil\")"
+	table := uint(7)
+	created, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: mustDate(t, "2030-01-11"), Time: "20:30", People: 4, TableID: &table, Comment: &comment, Status: servicedto.StatusConfirmed,
+	})
+	res := client.reservations[created.ID]
+	res.User = &servicedto.User{ID: 1, Name: "Ana, Maria", Email: "ana@example.com", Phone: &phone}
+	res.CreatedAt = time.Date(2030, 1, 2, 9, 5, 0, 0, time.UTC)
+	res.Notes = []servicedto.StaffNote{{AuthorName: "Host", Body: "Window table"}}
+	res.Tags = []string{"-10% regular"}
+	client.reservations[created.ID] = res
+	client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 2, Date: mustDate(t, "2030-01-20"), Time: "19:00", People: 2, Status: servicedto.StatusPending,
+	})
+
+	export, err := svc.ExportReservations(ctx, admin, servicedto.ExportReservationsInput{
+		From: "2030-01-01", To: "2030-01-15", Locale: "en_US",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if export.Filename != "reservations-2030-01-01-2030-01-15.csv" || !strings.HasPrefix(export.ContentType, "text/csv") {
+		t.Fatalf("unexpected export: %+v", export)
+	}
+	var buf bytes.Buffer
+	if err := export.Write(ctx, &buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	body, ok := strings.CutPrefix(buf.String(), "\uFEFF")
+	if !ok {
+		t.Fatal("expected a byte order mark")
+	}
+	rows, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("expected a header and one row, got %q %v", rows, err)
+	}
+	got := make(map[string]string)
+	for i, heading := range rows[0] {
+		got[heading] = rows[1][i]
+	}
+	for heading, want := range map[string]string{
+		"Code":        "VSV-000001",
+		"Date":        "01/11/2030",
+		"Time":        "8:30 PM",
+		"Party size":  "4",
+		"Guest":       "Ana, Maria",
+		"Phone":       "'" + phone, // text cells never start like a formula
+		"Visits":      "0",
+		"Table":       "7",
+		"Tags":        "'-10% regular",
+		"Comment":     "'" + comment,
+		"Staff notes": "Host: Window table",
+		"Booked at":   "01/02/2030 9:05 AM",
+	} {
+		if got[heading] != want {
+			t.Fatalf("%s: expected %q, got %q", heading, want, got[heading])
+		}
+	}
+
+	// Columns can be picked and dates default to ISO 8601.
+	export, _ = svc.ExportReservations(ctx, admin, servicedto.ExportReservationsInput{
+		Date: "2030-01-20", Columns: []string{servicedto.ExportColumnDate, servicedto.ExportColumnCode, servicedto.ExportColumnDate},
+	})
+	buf.Reset()
+	export.Write(ctx, &buf)
+	if want := "\uFEFFDate,Code\n2030-01-20,VSV-000002\n"; buf.String() != want {
+		t.Fatalf("expected %q, got %q", want, buf.String())
+	}
+
+	for _, input := range []servicedto.ExportReservationsInput{
+		{From: "2030-01-01", Format: "pdf"},
+		{From: "2030-01-01", Columns: []string{"password"}},
+		{From: "2030-01-01", Locale: "xx"},
+		{Format: servicedto.ExportCSV},
+	} {
+		if _, err := svc.ExportReservations(ctx, admin, input); err != ErrInvalidInput {
+			t.Fatalf("expected ErrInvalidInput for %+v, got %v", input, err)
+		}
+	}
+	if _, err := svc.ExportReservations(ctx, servicedto.User{ID: 1}, servicedto.ExportReservationsInput{From: "2030-01-01"}); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized for a guest, got %v", err)
+	}
+}
+
+func TestExportReservations_XLSX(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	client := newFakeReservationClient()
+	svc := NewReservationService(client)
+	// More than a page, so the export has to follow the cursor.
+	for i := 0; i < MaxReservationPageSize+1; i++ {
+		client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: 1, Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+		})
+	}
+
+	export, err := svc.ExportReservations(ctx, admin, servicedto.ExportReservationsInput{
+		Format: servicedto.ExportXLSX, Date: "2030-01-11", Columns: []string{servicedto.ExportColumnCode, servicedto.ExportColumnPeople},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := export.Write(ctx, &buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("not a zip archive: %v", err)
+	}
+	var sheet struct {
+		Rows []struct {
+			Ref   int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	for _, f := range archive.File {
+		r, _ := f.Open()
+		data, _ := io.ReadAll(r)
+		var doc struct{}
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("%s is not well-formed: %v", f.Name, err)
+		}
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			xml.Unmarshal(data, &sheet)
+		}
+	}
+	if len(sheet.Rows) != MaxReservationPageSize+2 {
+		t.Fatalf("expected a header and %d rows, got %d", MaxReservationPageSize+1, len(sheet.Rows))
+	}
+	last := sheet.Rows[len(sheet.Rows)-1]
+	if last.Ref != MaxReservationPageSize+2 || last.Cells[0].Inline != "VSV-000501" || last.Cells[1].Ref != "B502" || last.Cells[1].Value != "2" {
+		t.Fatalf("unexpected last row: %+v", last)
+	}
+	if header := sheet.Rows[0].Cells; header[1].Type != "inlineStr" || header[1].Inline != "Party size" {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(i); got != want {
+			t.Fatalf("column %d: expected %s, got %s", i, want, got)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// DefaultArrivalLookahead is how far ahead the floor view lists arrivals.
+const DefaultArrivalLookahead = time.Hour
+
+// TableClient abstracts table persistence.
+type TableClient interface {
+	CreateTable(ctx context.Context, params servicedto.CreateTableParams) (*servicedto.Table, error)
+	ListTables(ctx context.Context) ([]servicedto.Table, error)
+	GetTableByID(ctx context.Context, id uint) (*servicedto.Table, error)
+}
+
+// FloorService manages tables and reports live occupancy for hosts.
+type FloorService struct {
+	tableClient         TableClient
+	reservationClient   ReservationClient
+	reservationDuration time.Duration
+	lookahead           time.Duration
+	location            *time.Location
+	now                 func() time.Time
+}
+
+func NewFloorService(tableClient TableClient, resClient ReservationClient, reservationDuration time.Duration, loc *time.Location) *FloorService {
+	if reservationDuration <= 0 {
+		reservationDuration = DefaultReservationDuration
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &FloorService{
+		tableClient:         tableClient,
+		reservationClient:   resClient,
+		reservationDuration: reservationDuration,
+		lookahead:           DefaultArrivalLookahead,
+		location:            loc,
+		now:                 time.Now,
+	}
+}
+
+func (s *FloorService) ListTables(ctx context.Context) ([]servicedto.Table, error) {
+	return s.tableClient.ListTables(ctx)
+}
+
+func (s *FloorService) CreateTable(ctx context.Context, admin servicedto.User, params servicedto.CreateTableParams) (*servicedto.Table, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	params.Name = strings.TrimSpace(params.Name)
+	params.Area = strings.ToLower(strings.TrimSpace(params.Area))
+	if params.Area == "" {
+		params.Area = "main"
+	}
+	if params.Name == "" || params.Seats <= 0 {
+		return nil, ErrInvalidInput
+	}
+
+	tables, err := s.tableClient.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		if strings.EqualFold(t.Name, params.Name) {
+			return nil, ErrTableNameTaken
+		}
+	}
+
+	return s.tableClient.CreateTable(ctx, params)
+}
+
+// FloorStatus returns current occupancy by area and table, seated parties
+// without a table, and the arrivals expected within the lookahead.
+func (s *FloorService) FloorStatus(ctx context.Context) (*servicedto.FloorStatus, error) {
+	now := s.now().In(s.location)
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	tables, err := s.tableClient.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Yesterday for parties still seated after midnight, tomorrow for
+	// arrivals just past it.
+	nearby, err := reservationsBetween(ctx, s.reservationClient, date.AddDate(0, 0, -1), date.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	status := &servicedto.FloorStatus{
+		At:         now,
+		Unassigned: []servicedto.Reservation{},
+		Upcoming:   []servicedto.Reservation{},
+	}
+	atTable := make(map[uint]servicedto.Reservation)
+	for _, r := range nearby {
+		if isInRoom(r, now, s.location, s.reservationDuration) {
+			status.Covers += r.People
+			if r.TableID != nil {
+				atTable[*r.TableID] = r
+			} else {
+				status.Unassigned = append(status.Unassigned, r)
+			}
+			continue
+		}
+		if r.Status != servicedto.StatusPending && r.Status != servicedto.StatusConfirmed {
+			continue
+		}
+		start, ok := slotStart(r, s.location)
+		if ok && !start.Before(now) && start.Before(now.Add(s.lookahead)) {
+			status.Upcoming = append(status.Upcoming, r)
+		}
+	}
+	sort.Slice(status.Upcoming, func(i, j int) bool {
+		a, _ := slotStart(status.Upcoming[i], s.location)
+		b, _ := slotStart(status.Upcoming[j], s.location)
+		return a.Before(b)
+	})
+
+	areas := make(map[string]*servicedto.FloorArea)
+	var order []string
+	for _, t := range tables {
+		area, ok := areas[t.Area]
+		if !ok {
+			area = &servicedto.FloorArea{Name: t.Area}
+			areas[t.Area] = area
+			order = append(order, t.Area)
+		}
+		ft := servicedto.FloorTable{Table: t}
+		if r, ok := atTable[t.ID]; ok {
+			r := r
+			ft.Reservation = &r
+			if start, ok := seatedSince(r, s.location); ok {
+				freeAt := start.Add(s.reservationDuration)
+				ft.FreeAt = &freeAt
+			}
+			area.Occupied++
+			area.Covers += r.People
+		}
+		area.Seats += t.Seats
+		area.Tables = append(area.Tables, ft)
+	}
+	for _, name := range order {
+		status.Areas = append(status.Areas, *areas[name])
+	}
+
+	return status, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestFloorStatus(t *testing.T) {
+	ctx := context.Background()
+	tables := newFakeTableClient()
+	window, _ := tables.CreateTable(ctx, servicedto.CreateTableParams{Name: "T1", Area: "main", Seats: 4})
+	corner, _ := tables.CreateTable(ctx, servicedto.CreateTableParams{Name: "T2", Area: "main", Seats: 2})
+	_, _ = tables.CreateTable(ctx, servicedto.CreateTableParams{Name: "P1", Area: "terrace", Seats: 6})
+
+	reservations := newFakeReservationClient()
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	seed := func(hhmm string, people int, status string, tableID *uint) {
+		reservations.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: 1, Date: date, Time: hhmm, People: people, Status: status, TableID: tableID,
+		})
+	}
+	seed("19:30", 3, servicedto.StatusSeated, &window.ID) // still at the table
+	seed("17:00", 2, servicedto.StatusSeated, &corner.ID) // expected to have left
+	seed("20:00", 2, servicedto.StatusSeated, nil)        // seated at the bar, no table
+	seed("21:00", 4, servicedto.StatusConfirmed, nil)     // arriving soon
+	seed("21:15", 2, servicedto.StatusCancelled, nil)     // not coming
+	seed("22:00", 2, servicedto.StatusPending, nil)       // beyond the lookahead
+
+	svc := NewFloorService(tables, reservations, 2*time.Hour, time.UTC)
+	svc.now = func() time.Time { return time.Date(2025, 12, 1, 20, 30, 0, 0, time.UTC) }
+
+	status, err := svc.FloorStatus(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Covers != 5 {
+		t.Fatalf("expected 5 covers, got %d", status.Covers)
+	}
+	if len(status.Areas) != 2 || status.Areas[0].Name != "main" || status.Areas[0].Occupied != 1 || status.Areas[0].Seats != 6 {
+		t.Fatalf("unexpected areas: %+v", status.Areas)
+	}
+	occupied := status.Areas[0].Tables[0]
+	if occupied.Reservation == nil || occupied.FreeAt == nil || occupied.FreeAt.Hour() != 21 || occupied.FreeAt.Minute() != 30 {
+		t.Fatalf("expected T1 occupied until 21:30, got %+v", occupied)
+	}
+	if status.Areas[0].Tables[1].Reservation != nil {
+		t.Fatalf("expected T2 to be free")
+	}
+	if len(status.Unassigned) != 1 || status.Unassigned[0].Time != "20:00" {
+		t.Fatalf("unexpected unassigned parties: %+v", status.Unassigned)
+	}
+	if len(status.Upcoming) != 1 || status.Upcoming[0].Time != "21:00" {
+		t.Fatalf("unexpected upcoming arrivals: %+v", status.Upcoming)
+	}
+}
+
+func TestFloorStatusAcrossMidnight(t *testing.T) {
+	ctx := context.Background()
+	tables := newFakeTableClient()
+	late, _ := tables.CreateTable(ctx, servicedto.CreateTableParams{Name: "T1", Area: "main", Seats: 4})
+	delayed, _ := tables.CreateTable(ctx, servicedto.CreateTableParams{Name: "T2", Area: "main", Seats: 2})
+
+	reservations := newFakeReservationClient()
+	yesterday := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	today := yesterday.AddDate(0, 0, 1)
+	seatedAt := time.Date(2025, 12, 1, 23, 15, 0, 0, time.UTC)
+	seed := func(date time.Time, hhmm string, status string, tableID *uint, seatedAt *time.Time) {
+		reservations.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: 1, Date: date, Time: hhmm, People: 2, Status: status, TableID: tableID, SeatedAt: seatedAt,
+		})
+	}
+	seed(yesterday, "23:30", servicedto.StatusSeated, &late.ID, nil)          // slot runs past midnight
+	seed(yesterday, "21:00", servicedto.StatusSeated, &delayed.ID, &seatedAt) // arrived two hours late
+	seed(yesterday, "23:45", servicedto.StatusConfirmed, nil, nil)            // never came
+	seed(today, "01:00", servicedto.StatusConfirmed, nil, nil)                // arriving soon
+
+	svc := NewFloorService(tables, reservations, 2*time.Hour, time.UTC)
+	svc.now = func() time.Time { return time.Date(2025, 12, 2, 0, 30, 0, 0, time.UTC) }
+
+	status, err := svc.FloorStatus(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Covers != 4 || status.Areas[0].Occupied != 2 {
+		t.Fatalf("expected both late parties in the room, got %+v", status)
+	}
+	freeAt := status.Areas[0].Tables[1].FreeAt
+	if freeAt == nil || !freeAt.Equal(seatedAt.Add(2*time.Hour)) {
+		t.Fatalf("expected T2 free two hours after seating, got %v", freeAt)
+	}
+	if len(status.Upcoming) != 1 || status.Upcoming[0].Time != "01:00" {
+		t.Fatalf("unexpected upcoming arrivals: %+v", status.Upcoming)
+	}
+}
+
+func TestCreateTableValidation(t *testing.T) {
+	ctx := context.Background()
+	svc := NewFloorService(newFakeTableClient(), newFakeReservationClient(), 0, nil)
+	admin := servicedto.User{ID: 1, IsAdmin: true}
+
+	table, err := svc.CreateTable(ctx, admin, servicedto.CreateTableParams{Name: " T1 ", Seats: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if table.Name != "T1" || table.Area != "main" {
+		t.Fatalf("unexpected table: %+v", table)
+	}
+
+	if _, err := svc.CreateTable(ctx, admin, servicedto.CreateTableParams{Name: "t1", Seats: 2}); err != ErrTableNameTaken {
+		t.Fatalf("expected ErrTableNameTaken, got %v", err)
+	}
+	if _, err := svc.CreateTable(ctx, admin, servicedto.CreateTableParams{Name: "T9", Seats: 0}); err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+	if _, err := svc.CreateTable(ctx, servicedto.User{ID: 2}, servicedto.CreateTableParams{Name: "T9", Seats: 2}); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+// fakeTableClient is an in-memory table store for tests.
+type fakeTableClient struct {
+	tables []servicedto.Table
+}
+
+func newFakeTableClient() *fakeTableClient {
+	return &fakeTableClient{}
+}
+
+func (f *fakeTableClient) CreateTable(ctx context.Context, params servicedto.CreateTableParams) (*servicedto.Table, error) {
+	table := servicedto.Table{
+		ID:    uint(len(f.tables) + 1),
+		Name:  params.Name,
+		Area:  params.Area,
+		Seats: params.Seats,
+	}
+	f.tables = append(f.tables, table)
+	return &table, nil
+}
+
+func (f *fakeTableClient) ListTables(ctx context.Context) ([]servicedto.Table, error) {
+	return append([]servicedto.Table(nil), f.tables...), nil
+}
+
+func (f *fakeTableClient) GetTableByID(ctx context.Context, id uint) (*servicedto.Table, error) {
+	for _, t := range f.tables {
+		if t.ID == id {
+			copy := t
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// DefaultLateCancelWindow is how close to the slot a cancellation counts as
+// late.
+const DefaultLateCancelWindow = 24 * time.Hour
+
+// recentGuestReservations is how many reservations a profile lists.
+const recentGuestReservations = 20
+
+// GuestService builds guest profiles from users and their reservations.
+type GuestService struct {
+	userClient        GuestClient
+	reservationClient ReservationClient
+	restrictionClient RestrictionClient
+	location          *time.Location
+	lateCancelWindow  time.Duration
+	now               func() time.Time
+}
+
+func NewGuestService(userClient GuestClient, resClient ReservationClient, restrictionClient RestrictionClient, loc *time.Location, lateCancelWindow time.Duration) *GuestService {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if lateCancelWindow <= 0 {
+		lateCancelWindow = DefaultLateCancelWindow
+	}
+	return &GuestService{
+		userClient:        userClient,
+		reservationClient: resClient,
+		restrictionClient: restrictionClient,
+		location:          loc,
+		lateCancelWindow:  lateCancelWindow,
+		now:               time.Now,
+	}
+}
+
+// GuestProfile aggregates the visit history of a user or phone guest.
+func (s *GuestService) GuestProfile(ctx context.Context, admin servicedto.User, userID uint) (*servicedto.GuestProfile, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	if userID == 0 {
+		return nil, ErrInvalidInput
+	}
+	user, err := s.userClient.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	history, err := s.reservationClient.ListReservationsByUsers(ctx, []uint{userID})
+	if err != nil {
+		return nil, err
+	}
+	cancelledAt, err := s.cancellationTimes(ctx, history)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	profile := &servicedto.GuestProfile{User: *user}
+	covers := 0
+	for _, r := range history {
+		start, _ := slotStart(r, s.location)
+		switch {
+		case isVisit(r, now, s.location):
+			profile.Visits++
+			covers += r.People
+			date := r.Date
+			if profile.FirstVisit == nil {
+				profile.FirstVisit = &date
+			}
+			profile.LastVisit = &date
+		case r.Status == servicedto.StatusNoShow:
+			profile.NoShows++
+		case r.Status == servicedto.StatusCancelled:
+			profile.Cancellations++
+			if at, ok := cancelledAt[r.ID]; ok && start.Sub(at) < s.lateCancelWindow {
+				profile.LateCancels++
+			}
+		case isActiveStatus(r.Status) && start.After(now):
+			profile.Upcoming++
+		}
+
+		for _, tag := range r.Tags {
+			if !slices.Contains(profile.Tags, tag) {
+				profile.Tags = append(profile.Tags, tag)
+			}
+		}
+		if r.Status != servicedto.StatusCancelled {
+			addPreferences(&profile.Preferences, r.Requirements)
+		}
+	}
+	if profile.Visits > 0 {
+		profile.AveragePartySize = float64(covers) / float64(profile.Visits)
+	}
+	slices.Sort(profile.Tags)
+	slices.Sort(profile.Preferences.Allergens)
+	slices.Sort(profile.Preferences.DietaryStyles)
+	slices.Sort(profile.Preferences.Accessibility)
+
+	for i := len(history) - 1; i >= 0 && len(profile.Recent) < recentGuestReservations; i-- {
+		profile.Recent = append(profile.Recent, history[i])
+	}
+
+	if profile.Restriction, err = activeRestriction(ctx, s.restrictionClient, userID); err != nil {
+		return nil, err
+	}
+	if s.restrictionClient != nil {
+		if profile.Incidents, err = s.restrictionClient.ListIncidentsByUser(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return profile, nil
+}
+
+// ListRestrictions returns the guests whose online booking is currently
+// restricted, oldest restriction first.
+func (s *GuestService) ListRestrictions(ctx context.Context) ([]servicedto.Restriction, error) {
+	if s.restrictionClient == nil {
+		return []servicedto.Restriction{}, nil
+	}
+	return s.restrictionClient.ListActiveRestrictions(ctx)
+}
+
+// LiftRestriction ends a guest's active restriction. Incidents before the
+// lift no longer count towards new sanctions.
+func (s *GuestService) LiftRestriction(ctx context.Context, admin servicedto.User, input servicedto.LiftRestrictionInput) (*servicedto.Restriction, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	if input.UserID == 0 {
+		return nil, ErrInvalidInput
+	}
+	if input.Note != nil {
+		note := strings.TrimSpace(*input.Note)
+		if len(note) > 255 {
+			return nil, ErrInvalidInput
+		}
+		input.Note = &note
+		if note == "" {
+			input.Note = nil
+		}
+	}
+	if s.restrictionClient == nil {
+		return nil, ErrRestrictionNotFound
+	}
+
+	lifted, err := s.restrictionClient.LiftRestriction(ctx, servicedto.LiftRestrictionParams{
+		UserID:   input.UserID,
+		LiftedBy: admin.ID,
+		Note:     input.Note,
+		At:       s.now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if lifted == nil {
+		return nil, ErrRestrictionNotFound
+	}
+	return lifted, nil
+}
+
+// cancellationTimes returns when each cancelled reservation was last moved
+// to cancelled, for those with recorded history.
+func (s *GuestService) cancellationTimes(ctx context.Context, history []servicedto.Reservation) (map[uint]time.Time, error) {
+	var ids []uint
+	for _, r := range history {
+		if r.Status == servicedto.StatusCancelled {
+			ids = append(ids, r.ID)
+		}
+	}
+	changes, err := s.reservationClient.ListStatusChangesByReservations(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	at := make(map[uint]time.Time, len(ids))
+	for _, ch := range changes {
+		if ch.ToStatus == servicedto.StatusCancelled {
+			at[ch.ReservationID] = ch.CreatedAt
+		}
+	}
+	return at, nil
+}
+
+// isVisit reports whether the guest came: seated, or confirmed for a slot
+// that has already started.
+func isVisit(r servicedto.Reservation, now time.Time, loc *time.Location) bool {
+	switch r.Status {
+	case servicedto.StatusSeated:
+		return true
+	case servicedto.StatusConfirmed:
+		start, ok := slotStart(r, loc)
+		return ok && start.Before(now)
+	default:
+		return false
+	}
+}
+
+func addPreferences(p *servicedto.GuestPreferences, req servicedto.Requirements) {
+	for _, a := range req.Allergens {
+		if !slices.Contains(p.Allergens, a) {
+			p.Allergens = append(p.Allergens, a)
+		}
+	}
+	if req.DietaryStyle != nil && !slices.Contains(p.DietaryStyles, *req.DietaryStyle) {
+		p.DietaryStyles = append(p.DietaryStyles, *req.DietaryStyle)
+	}
+	for _, a := range req.Accessibility {
+		if !slices.Contains(p.Accessibility, a) {
+			p.Accessibility = append(p.Accessibility, a)
+		}
+	}
+	if req.HighChairs > 0 {
+		p.HighChairs = true
+	}
+}
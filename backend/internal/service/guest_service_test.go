@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func seedGuestHistory(t *testing.T, client *fakeReservationClient, users *fakeUserClient) (uint, servicedto.Reservation) {
+	t.Helper()
+	ctx := context.Background()
+	user, err := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Regular", Email: "regular@example.com"})
+	if err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+	vegan := "vegan"
+
+	var upcoming servicedto.Reservation
+	for _, p := range []servicedto.CreateReservationParams{
+		{Date: day(10, 1), Time: "19:00", People: 2, Status: servicedto.StatusSeated, Requirements: servicedto.Requirements{Allergens: []string{"nuts"}}},
+		{Date: day(10, 15), Time: "20:00", People: 4, Status: servicedto.StatusConfirmed, Requirements: servicedto.Requirements{DietaryStyle: &vegan, HighChairs: 1}},
+		{Date: day(11, 1), Time: "20:00", People: 2, Status: servicedto.StatusNoShow},
+		{Date: day(11, 20), Time: "20:00", People: 2, Status: servicedto.StatusCancelled, Requirements: servicedto.Requirements{Allergens: []string{"fish"}}},
+		{Date: day(11, 25), Time: "20:00", People: 2, Status: servicedto.StatusCancelled},
+		{Date: day(12, 10), Time: "20:00", People: 3, Status: servicedto.StatusConfirmed},
+	} {
+		p.UserID = user.ID
+		res, _ := client.CreateReservation(ctx, p)
+		upcoming = *res
+	}
+	client.history = append(client.history,
+		servicedto.StatusChange{ReservationID: 4, FromStatus: servicedto.StatusConfirmed, ToStatus: servicedto.StatusCancelled, CreatedAt: time.Date(2025, 11, 20, 10, 0, 0, 0, time.UTC)},
+		servicedto.StatusChange{ReservationID: 5, FromStatus: servicedto.StatusConfirmed, ToStatus: servicedto.StatusCancelled, CreatedAt: time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC)},
+	)
+	first := client.reservations[1]
+	first.Tags = []string{servicedto.TagVIP}
+	client.reservations[1] = first
+	return user.ID, upcoming
+}
+
+func TestGuestProfile(t *testing.T) {
+	client := newFakeReservationClient()
+	users := newFakeUserClient()
+	userID, _ := seedGuestHistory(t, client, users)
+
+	svc := NewGuestService(users, client, nil, time.UTC, 0)
+	svc.now = func() time.Time { return time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+
+	profile, err := svc.GuestProfile(ctx, admin, userID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.Visits != 2 || profile.NoShows != 1 || profile.Cancellations != 2 || profile.LateCancels != 1 || profile.Upcoming != 1 {
+		t.Fatalf("unexpected counts: %+v", profile)
+	}
+	if profile.AveragePartySize != 3 {
+		t.Fatalf("expected average party size 3, got %v", profile.AveragePartySize)
+	}
+	if profile.FirstVisit == nil || profile.FirstVisit.Day() != 1 || profile.LastVisit == nil || profile.LastVisit.Day() != 15 {
+		t.Fatalf("unexpected first/last visit: %v %v", profile.FirstVisit, profile.LastVisit)
+	}
+	prefs := profile.Preferences
+	if len(prefs.Allergens) != 1 || prefs.Allergens[0] != "nuts" || len(prefs.DietaryStyles) != 1 || !prefs.HighChairs {
+		t.Fatalf("unexpected preferences: %+v", prefs)
+	}
+	if len(profile.Tags) != 1 || profile.Tags[0] != servicedto.TagVIP {
+		t.Fatalf("unexpected tags: %v", profile.Tags)
+	}
+	if len(profile.Recent) != 6 || profile.Recent[0].ID != 6 {
+		t.Fatalf("expected newest reservation first, got %+v", profile.Recent)
+	}
+
+	if _, err := svc.GuestProfile(ctx, admin, 99); err != ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := svc.GuestProfile(ctx, servicedto.User{ID: userID}, userID); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized for a guest, got %v", err)
+	}
+}
+
+func TestAdminReservationsIncludeGuestSummary(t *testing.T) {
+	client := newFakeReservationClient()
+	users := newFakeUserClient()
+	_, upcoming := seedGuestHistory(t, client, users)
+
+	svc := NewReservationService(client)
+	svc.now = func() time.Time { return time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC) }
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+
+	res, err := svc.AdminGetReservationByCode(context.Background(), admin, upcoming.Code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Guest == nil || res.Guest.VisitNumber != 3 || res.Guest.Visits != 2 || res.Guest.NoShows != 1 {
+		t.Fatalf("unexpected guest summary: %+v", res.Guest)
+	}
+}
+
+func TestMarkNoShow(t *testing.T) {
+	client := newFakeReservationClient()
+	svc := NewReservationService(client)
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	ctx := context.Background()
+
+	res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 1, Date: time.Now(), Time: "20:00", People: 2, Status: servicedto.StatusConfirmed})
+	seated, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 1, Date: time.Now(), Time: "20:00", People: 2, Status: servicedto.StatusSeated})
+
+	if _, err := svc.MarkNoShow(ctx, servicedto.User{ID: 1}, res.ID); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	updated, err := svc.MarkNoShow(ctx, admin, res.ID)
+	if err != nil || updated.Status != servicedto.StatusNoShow {
+		t.Fatalf("expected no_show, got %+v (%v)", updated, err)
+	}
+	if _, err := svc.MarkNoShow(ctx, admin, seated.ID); err != ErrInvalidStatus {
+		t.Fatalf("expected ErrInvalidStatus for seated party, got %v", err)
+	}
+	if _, err := svc.MarkNoShow(ctx, admin, 99); err != ErrReservationNotFound {
+		t.Fatalf("expected ErrReservationNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iCalendar (RFC 5545) formats.
+const (
+	icalDateTime    = "20060102T150405"
+	icalUTCDateTime = "20060102T150405Z"
+	icalMaxLine     = 75 // octets per line before folding
+)
+
+// icalWriter writes content lines with CRLF endings, folding long ones.
+type icalWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *icalWriter) line(name, value string) {
+	line := name + ":" + value
+	for len(line) > icalMaxLine {
+		cut := icalMaxLine
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.buf.WriteString(line[:cut] + "\r\n")
+		line = " " + line[cut:]
+	}
+	w.buf.WriteString(line + "\r\n")
+}
+
+// text writes a TEXT property, escaped.
+func (w *icalWriter) text(name, value string) {
+	w.line(name, icalEscape(value))
+}
+
+// utc writes a date-time property in UTC.
+func (w *icalWriter) utc(name string, t time.Time) {
+	w.line(name, t.UTC().Format(icalUTCDateTime))
+}
+
+// local writes a date-time property as local time in loc, referring to the
+// VTIMEZONE written by timezone. UTC is written as such.
+func (w *icalWriter) local(name string, t time.Time, loc *time.Location) {
+	if loc == time.UTC {
+		w.utc(name, t)
+		return
+	}
+	w.line(name+";TZID="+loc.String(), t.In(loc).Format(icalDateTime))
+}
+
+// timezone writes a VTIMEZONE for loc covering from to to, with one
+// observance per offset change. Nothing is written for UTC.
+func (w *icalWriter) timezone(loc *time.Location, from, to time.Time) {
+	if loc == time.UTC {
+		return
+	}
+	w.line("BEGIN", "VTIMEZONE")
+	w.line("TZID", loc.String())
+	t := from.In(loc)
+	_, prev := t.Zone()
+	for {
+		name, offset := t.Zone()
+		kind := "STANDARD"
+		if t.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		w.line("BEGIN", kind)
+		// Observances start at local time in the offset they replace.
+		w.line("DTSTART", t.In(time.FixedZone("", prev)).Format(icalDateTime))
+		w.line("TZOFFSETFROM", icalOffset(prev))
+		w.line("TZOFFSETTO", icalOffset(offset))
+		w.text("TZNAME", name)
+		w.line("END", kind)
+
+		_, end := t.ZoneBounds()
+		if end.IsZero() || end.After(to) {
+			break
+		}
+		t, prev = end.In(loc), offset
+	}
+	w.line("END", "VTIMEZONE")
+}
+
+func (w *icalWriter) bytes() []byte {
+	return w.buf.Bytes()
+}
+
+func icalOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func icalEscape(s string) string {
+	return icalEscaper.Replace(s)
+}
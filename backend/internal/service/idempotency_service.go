@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyClient abstracts idempotency key persistence.
+type IdempotencyClient interface {
+	CreateIdempotencyKey(ctx context.Context, params servicedto.CreateIdempotencyKeyParams) (bool, error)
+	GetIdempotencyKey(ctx context.Context, userID uint, key string) (*servicedto.IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, input servicedto.CompleteIdempotentRequestInput) error
+	DeleteIdempotencyKey(ctx context.Context, userID uint, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) error
+}
+
+type IdempotencyService struct {
+	idempotencyClient IdempotencyClient
+	ttl               time.Duration
+	now               func() time.Time
+}
+
+func NewIdempotencyService(idempotencyClient IdempotencyClient, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{idempotencyClient: idempotencyClient, ttl: ttl, now: time.Now}
+}
+
+// Begin claims the key for a new request. It returns a nil record when the
+// caller should process the request, or the stored record when a finished
+// request with the same key and body should be replayed.
+func (s *IdempotencyService) Begin(ctx context.Context, input servicedto.BeginIdempotentRequestInput) (*servicedto.IdempotencyRecord, error) {
+	key := strings.TrimSpace(input.Key)
+	if key == "" || len(key) > maxIdempotencyKeyLength || input.RequestHash == "" {
+		return nil, ErrInvalidInput
+	}
+
+	now := s.now()
+	params := servicedto.CreateIdempotencyKeyParams{
+		UserID:      input.UserID,
+		Key:         key,
+		RequestHash: input.RequestHash,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+
+	// The second attempt covers a key that expired or was released between
+	// the failed insert and the lookup.
+	for attempt := 0; attempt < 2; attempt++ {
+		created, err := s.idempotencyClient.CreateIdempotencyKey(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return nil, nil
+		}
+
+		existing, err := s.idempotencyClient.GetIdempotencyKey(ctx, input.UserID, key)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			continue
+		}
+		if !existing.ExpiresAt.After(now) {
+			if err := s.idempotencyClient.DeleteExpiredIdempotencyKeys(ctx, now); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if existing.RequestHash != input.RequestHash {
+			return nil, ErrIdempotencyKeyReused
+		}
+		if !existing.Completed() {
+			return nil, ErrIdempotencyRequestInProgress
+		}
+		return existing, nil
+	}
+	return nil, ErrIdempotencyRequestInProgress
+}
+
+// Complete stores the response for the key. Server errors release the key
+// instead, so the client can retry the request.
+func (s *IdempotencyService) Complete(ctx context.Context, input servicedto.CompleteIdempotentRequestInput) error {
+	if input.StatusCode >= 500 {
+		return s.Release(ctx, input.UserID, input.Key)
+	}
+	input.Key = strings.TrimSpace(input.Key)
+	return s.idempotencyClient.CompleteIdempotencyKey(ctx, input)
+}
+
+// Release forgets the key without storing a response.
+func (s *IdempotencyService) Release(ctx context.Context, userID uint, key string) error {
+	return s.idempotencyClient.DeleteIdempotencyKey(ctx, userID, strings.TrimSpace(key))
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestIdempotencyBeginAndReplay(t *testing.T) {
+	client := newFakeIdempotencyClient()
+	svc := NewIdempotencyService(client, time.Hour)
+	ctx := context.Background()
+	input := servicedto.BeginIdempotentRequestInput{UserID: 1, Key: "abc", RequestHash: "h1"}
+
+	stored, err := svc.Begin(ctx, input)
+	if err != nil || stored != nil {
+		t.Fatalf("expected new key to be claimed, got %+v, %v", stored, err)
+	}
+
+	if _, err := svc.Begin(ctx, input); err != ErrIdempotencyRequestInProgress {
+		t.Fatalf("expected ErrIdempotencyRequestInProgress, got %v", err)
+	}
+
+	err = svc.Complete(ctx, servicedto.CompleteIdempotentRequestInput{
+		UserID: 1, Key: "abc", StatusCode: 201, ContentType: "application/json", ResponseBody: []byte(`{"id":1}`),
+	})
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	stored, err = svc.Begin(ctx, input)
+	if err != nil {
+		t.Fatalf("unexpected error on replay: %v", err)
+	}
+	if stored == nil || stored.StatusCode != 201 || string(stored.ResponseBody) != `{"id":1}` {
+		t.Fatalf("unexpected stored response: %+v", stored)
+	}
+
+	input.RequestHash = "h2"
+	if _, err := svc.Begin(ctx, input); err != ErrIdempotencyKeyReused {
+		t.Fatalf("expected ErrIdempotencyKeyReused, got %v", err)
+	}
+
+	// Keys are scoped per user.
+	stored, err = svc.Begin(ctx, servicedto.BeginIdempotentRequestInput{UserID: 2, Key: "abc", RequestHash: "h2"})
+	if err != nil || stored != nil {
+		t.Fatalf("expected other user's key to be independent, got %+v, %v", stored, err)
+	}
+}
+
+func TestIdempotencyExpiredKeyIsReclaimed(t *testing.T) {
+	client := newFakeIdempotencyClient()
+	svc := NewIdempotencyService(client, time.Hour)
+	ctx := context.Background()
+	now := time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	input := servicedto.BeginIdempotentRequestInput{UserID: 1, Key: "abc", RequestHash: "h1"}
+	if _, err := svc.Begin(ctx, input); err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	_ = svc.Complete(ctx, servicedto.CompleteIdempotentRequestInput{UserID: 1, Key: "abc", StatusCode: 201})
+
+	now = now.Add(2 * time.Hour)
+	input.RequestHash = "h2"
+	stored, err := svc.Begin(ctx, input)
+	if err != nil || stored != nil {
+		t.Fatalf("expected expired key to be reclaimed, got %+v, %v", stored, err)
+	}
+}
+
+func TestIdempotencyServerErrorReleasesKey(t *testing.T) {
+	client := newFakeIdempotencyClient()
+	svc := NewIdempotencyService(client, time.Hour)
+	ctx := context.Background()
+	input := servicedto.BeginIdempotentRequestInput{UserID: 1, Key: "abc", RequestHash: "h1"}
+
+	if _, err := svc.Begin(ctx, input); err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err := svc.Complete(ctx, servicedto.CompleteIdempotentRequestInput{UserID: 1, Key: "abc", StatusCode: 500}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	stored, err := svc.Begin(ctx, input)
+	if err != nil || stored != nil {
+		t.Fatalf("expected key to be free after server error, got %+v, %v", stored, err)
+	}
+}
+
+func TestIdempotencyInvalidKey(t *testing.T) {
+	svc := NewIdempotencyService(newFakeIdempotencyClient(), time.Hour)
+	ctx := context.Background()
+
+	if _, err := svc.Begin(ctx, servicedto.BeginIdempotentRequestInput{UserID: 1, Key: "  ", RequestHash: "h"}); err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+// fakeIdempotencyClient is an in-memory idempotency key store for tests.
+type fakeIdempotencyClient struct {
+	records map[string]servicedto.IdempotencyRecord
+}
+
+func newFakeIdempotencyClient() *fakeIdempotencyClient {
+	return &fakeIdempotencyClient{records: make(map[string]servicedto.IdempotencyRecord)}
+}
+
+func fakeIdempotencyKey(userID uint, key string) string {
+	return fmt.Sprintf("%d|%s", userID, key)
+}
+
+func (f *fakeIdempotencyClient) CreateIdempotencyKey(ctx context.Context, params servicedto.CreateIdempotencyKeyParams) (bool, error) {
+	k := fakeIdempotencyKey(params.UserID, params.Key)
+	if _, ok := f.records[k]; ok {
+		return false, nil
+	}
+	f.records[k] = servicedto.IdempotencyRecord{
+		UserID:      params.UserID,
+		Key:         params.Key,
+		RequestHash: params.RequestHash,
+		ExpiresAt:   params.ExpiresAt,
+	}
+	return true, nil
+}
+
+func (f *fakeIdempotencyClient) GetIdempotencyKey(ctx context.Context, userID uint, key string) (*servicedto.IdempotencyRecord, error) {
+	r, ok := f.records[fakeIdempotencyKey(userID, key)]
+	if !ok {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+func (f *fakeIdempotencyClient) CompleteIdempotencyKey(ctx context.Context, input servicedto.CompleteIdempotentRequestInput) error {
+	k := fakeIdempotencyKey(input.UserID, input.Key)
+	r := f.records[k]
+	r.StatusCode = input.StatusCode
+	r.ContentType = input.ContentType
+	r.ResponseBody = input.ResponseBody
+	f.records[k] = r
+	return nil
+}
+
+func (f *fakeIdempotencyClient) DeleteIdempotencyKey(ctx context.Context, userID uint, key string) error {
+	delete(f.records, fakeIdempotencyKey(userID, key))
+	return nil
+}
+
+func (f *fakeIdempotencyClient) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) error {
+	for k, r := range f.records {
+		if r.ExpiresAt.Before(before) {
+			delete(f.records, k)
+		}
+	}
+	return nil
+}
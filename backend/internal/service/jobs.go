@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// errLeaseLost cancels a run whose lease could not be renewed.
+var errLeaseLost = errors.New("job lease lost")
+
+// LeaseClient abstracts the job leases that elect one instance to run each
+// background job.
+type LeaseClient interface {
+	AcquireLease(ctx context.Context, name, holder string, now, until time.Time) (bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+// Job is background work run every Interval by at most one instance.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// JobRunner runs jobs on a schedule. Every tick the instance first takes or
+// renews the job's lease; the lease outlives one interval, so the holder
+// keeps running the job and another instance only takes over once the
+// holder stops renewing.
+type JobRunner struct {
+	leaseClient LeaseClient
+	holder      string
+	jobs        []Job
+	now         func() time.Time
+	wg          sync.WaitGroup
+}
+
+// NewJobRunner returns a runner identified by holder, which must be unique
+// per instance.
+func NewJobRunner(leaseClient LeaseClient, holder string) *JobRunner {
+	return &JobRunner{leaseClient: leaseClient, holder: holder, now: time.Now}
+}
+
+// Add registers a job. Jobs without an interval are ignored.
+func (r *JobRunner) Add(job Job) {
+	if job.Interval > 0 {
+		r.jobs = append(r.jobs, job)
+	}
+}
+
+// Start runs every job in its own goroutine until ctx is cancelled.
+func (r *JobRunner) Start(ctx context.Context) {
+	for _, job := range r.jobs {
+		r.wg.Add(1)
+		go func(job Job) {
+			defer r.wg.Done()
+			r.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Wait blocks until every job has stopped and its lease was released.
+func (r *JobRunner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *JobRunner) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.runOnce(ctx, job); err != nil && ctx.Err() == nil {
+			log.Printf("job %s failed: %v", job.Name, err)
+		}
+		select {
+		case <-ctx.Done():
+			// Let another instance take over without waiting for expiry.
+			if err := r.leaseClient.ReleaseLease(context.Background(), job.Name, r.holder); err != nil {
+				log.Printf("job %s: release lease: %v", job.Name, err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce runs the job if this instance holds its lease and reports
+// whether it ran. The lease is renewed every interval while the job runs,
+// and the run is cancelled if a renewal fails, before another instance can
+// take the expired lease and run the job alongside it.
+func (r *JobRunner) runOnce(ctx context.Context, job Job) (bool, error) {
+	now := r.now()
+	acquired, err := r.leaseClient.AcquireLease(ctx, job.Name, r.holder, now, now.Add(2*job.Interval))
+	if err != nil || !acquired {
+		return false, err
+	}
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		r.keepLease(runCtx, job, stop, cancel)
+	}()
+	err = job.Run(runCtx)
+	close(stop)
+	<-stopped
+	if errors.Is(context.Cause(runCtx), errLeaseLost) {
+		return true, errLeaseLost
+	}
+	return true, err
+}
+
+// keepLease renews the job's lease every interval until stop is closed,
+// cancelling the run with errLeaseLost when a renewal fails.
+func (r *JobRunner) keepLease(ctx context.Context, job Job, stop <-chan struct{}, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := r.now()
+		held, err := r.leaseClient.AcquireLease(ctx, job.Name, r.holder, now, now.Add(2*job.Interval))
+		if err != nil || !held {
+			cancel(errLeaseLost)
+			return
+		}
+	}
+}
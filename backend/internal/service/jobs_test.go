@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJobRunnerLease(t *testing.T) {
+	ctx := context.Background()
+	leases := &fakeLeaseClient{leases: make(map[string]fakeLease)}
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	a := NewJobRunner(leases, "a")
+	b := NewJobRunner(leases, "b")
+	a.now, b.now = clock, clock
+	runs := map[string]int{}
+	job := func(holder string) Job {
+		return Job{Name: "expire", Interval: time.Minute, Run: func(ctx context.Context) error {
+			runs[holder]++
+			return nil
+		}}
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := a.runOnce(ctx, job("a")); err != nil {
+			t.Fatalf("run a: %v", err)
+		}
+		if _, err := b.runOnce(ctx, job("b")); err != nil {
+			t.Fatalf("run b: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+	if runs["a"] != 3 || runs["b"] != 0 {
+		t.Fatalf("expected only the lease holder to run, got %v", runs)
+	}
+
+	// a stops renewing; b takes over once the lease expires.
+	now = now.Add(2 * time.Minute)
+	if ran, err := b.runOnce(ctx, job("b")); err != nil || !ran {
+		t.Fatalf("expected b to take over, got %t %v", ran, err)
+	}
+	if ran, _ := a.runOnce(ctx, job("a")); ran {
+		t.Fatal("expected a to wait for b's lease")
+	}
+
+	if err := leases.ReleaseLease(ctx, "expire", "b"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if ran, _ := a.runOnce(ctx, job("a")); !ran {
+		t.Fatal("expected a released lease to be free")
+	}
+
+	failing := Job{Name: "failing", Interval: time.Minute, Run: func(ctx context.Context) error { return errors.New("boom") }}
+	if ran, err := a.runOnce(ctx, failing); !ran || err == nil {
+		t.Fatalf("expected job error to be returned, got %t %v", ran, err)
+	}
+}
+
+func TestJobRunnerStartStop(t *testing.T) {
+	leases := &fakeLeaseClient{leases: make(map[string]fakeLease)}
+	runner := NewJobRunner(leases, "a")
+	ran := make(chan struct{}, 1)
+	runner.Add(Job{Name: "tick", Interval: time.Hour, Run: func(ctx context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}})
+	runner.Add(Job{Name: "disabled"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runner.Start(ctx)
+	<-ran // jobs run once on start
+	cancel()
+	runner.Wait()
+	if len(leases.leases) != 0 {
+		t.Fatalf("expected leases released on stop, got %v", leases.leases)
+	}
+}
+
+func TestJobRunnerRenewsLeaseDuringRun(t *testing.T) {
+	ctx := context.Background()
+	leases := &fakeLeaseClient{leases: make(map[string]fakeLease)}
+	a := NewJobRunner(leases, "a")
+	b := NewJobRunner(leases, "b")
+
+	// The run outlasts several lease lengths; a keeps renewing, so b never
+	// gets the lease while a is still running.
+	slow := Job{Name: "slow", Interval: 30 * time.Millisecond, Run: func(ctx context.Context) error {
+		deadline := time.After(150 * time.Millisecond)
+		for {
+			select {
+			case <-deadline:
+				return nil
+			case <-time.After(5 * time.Millisecond):
+				if ran, _ := b.runOnce(ctx, Job{Name: "slow", Interval: time.Hour, Run: func(context.Context) error { return nil }}); ran {
+					return errors.New("b took the lease during a's run")
+				}
+			}
+		}
+	}}
+	if ran, err := a.runOnce(ctx, slow); !ran || err != nil {
+		t.Fatalf("expected a to run to completion, got %t %v", ran, err)
+	}
+
+	// When the lease is taken anyway, the run is cancelled.
+	stolen := Job{Name: "stolen", Interval: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		leases.set("stolen", fakeLease{holder: "b", until: time.Now().Add(time.Hour)})
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	}}
+	if ran, err := a.runOnce(ctx, stolen); !ran || !errors.Is(err, errLeaseLost) {
+		t.Fatalf("expected the run to be cancelled with errLeaseLost, got %t %v", ran, err)
+	}
+}
+
+type fakeLease struct {
+	holder string
+	until  time.Time
+}
+
+type fakeLeaseClient struct {
+	mu     sync.Mutex
+	leases map[string]fakeLease
+}
+
+func (f *fakeLeaseClient) set(name string, l fakeLease) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.leases[name] = l
+}
+
+func (f *fakeLeaseClient) AcquireLease(ctx context.Context, name, holder string, now, until time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if l, ok := f.leases[name]; ok && l.holder != holder && l.until.After(now) {
+		return false, nil
+	}
+	f.leases[name] = fakeLease{holder: holder, until: until}
+	return true, nil
+}
+
+func (f *fakeLeaseClient) ReleaseLease(ctx context.Context, name, holder string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if l, ok := f.leases[name]; ok && l.holder == holder {
+		delete(f.leases, name)
+	}
+	return nil
+}
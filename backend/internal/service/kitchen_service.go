@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// DefaultServicePeriods are used unless configured otherwise.
+var DefaultServicePeriods = []servicedto.ServicePeriod{
+	{Name: "lunch", Start: "12:00", End: "15:00"},
+	{Name: "dinner", Start: "19:00", End: "23:00"},
+}
+
+// otherPeriod collects reservations outside every configured period.
+const otherPeriod = "other"
+
+// KitchenService summarises dietary and accessibility needs per service.
+type KitchenService struct {
+	reservationClient ReservationClient
+	periods           []servicedto.ServicePeriod
+}
+
+// NewKitchenService uses DefaultServicePeriods when periods is empty.
+func NewKitchenService(resClient ReservationClient, periods []servicedto.ServicePeriod) *KitchenService {
+	if len(periods) == 0 {
+		periods = DefaultServicePeriods
+	}
+	return &KitchenService{reservationClient: resClient, periods: periods}
+}
+
+// KitchenSummary aggregates the requirements of the active reservations on
+// a date, per service period. An empty period returns all of them.
+func (s *KitchenService) KitchenSummary(ctx context.Context, date, period string) (*servicedto.KitchenSummary, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, ErrInvalidInput
+	}
+	periods := s.periods
+	if period != "" {
+		i := slices.IndexFunc(s.periods, func(p servicedto.ServicePeriod) bool { return p.Name == period })
+		if i < 0 {
+			return nil, ErrInvalidInput
+		}
+		periods = s.periods[i : i+1]
+	}
+
+	reservations, err := reservationsOn(ctx, s.reservationClient, day,
+		servicedto.StatusPending, servicedto.StatusConfirmed, servicedto.StatusSeated)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(reservations, func(a, b servicedto.Reservation) int {
+		if a.Time != b.Time {
+			if a.Time < b.Time {
+				return -1
+			}
+			return 1
+		}
+		return int(a.ID) - int(b.ID)
+	})
+
+	summary := &servicedto.KitchenSummary{Date: date}
+	var other []servicedto.Reservation
+	for _, p := range periods {
+		var in []servicedto.Reservation
+		for _, r := range reservations {
+			if r.Time >= p.Start && r.Time < p.End {
+				in = append(in, r)
+			}
+		}
+		summary.Periods = append(summary.Periods, summarisePeriod(p, in))
+	}
+	if period == "" {
+		for _, r := range reservations {
+			if !slices.ContainsFunc(s.periods, func(p servicedto.ServicePeriod) bool { return r.Time >= p.Start && r.Time < p.End }) {
+				other = append(other, r)
+			}
+		}
+		if len(other) > 0 {
+			summary.Periods = append(summary.Periods, summarisePeriod(servicedto.ServicePeriod{Name: otherPeriod}, other))
+		}
+	}
+	return summary, nil
+}
+
+func summarisePeriod(p servicedto.ServicePeriod, reservations []servicedto.Reservation) servicedto.KitchenPeriod {
+	out := servicedto.KitchenPeriod{
+		Period:        p,
+		Allergens:     map[string]int{},
+		DietaryStyles: map[string]int{},
+		Accessibility: map[string]int{},
+		WithNeeds:     []servicedto.Reservation{},
+	}
+	for _, r := range reservations {
+		out.Reservations++
+		out.Covers += r.People
+		req := r.Requirements
+		for _, a := range req.Allergens {
+			out.Allergens[a]++
+		}
+		if req.DietaryStyle != nil {
+			out.DietaryStyles[*req.DietaryStyle]++
+		}
+		for _, a := range req.Accessibility {
+			out.Accessibility[a]++
+		}
+		out.HighChairs += req.HighChairs
+		if !req.IsEmpty() {
+			out.WithNeeds = append(out.WithNeeds, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestKitchenSummary(t *testing.T) {
+	client := newFakeReservationClient()
+	svc := NewKitchenService(client, nil)
+	ctx := context.Background()
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	vegan := "vegan"
+
+	for _, p := range []servicedto.CreateReservationParams{
+		{Time: "12:30", People: 4, Status: servicedto.StatusConfirmed, Requirements: servicedto.Requirements{Allergens: []string{"gluten", "nuts"}, HighChairs: 1}},
+		{Time: "13:00", People: 2, Status: servicedto.StatusPending},
+		{Time: "20:00", People: 2, Status: servicedto.StatusSeated, Requirements: servicedto.Requirements{Allergens: []string{"gluten"}, DietaryStyle: &vegan, Accessibility: []string{"wheelchair"}}},
+		{Time: "20:30", People: 6, Status: servicedto.StatusCancelled, Requirements: servicedto.Requirements{Allergens: []string{"fish"}}},
+		{Time: "17:00", People: 2, Status: servicedto.StatusConfirmed},
+	} {
+		p.UserID = 1
+		p.Date = date
+		client.CreateReservation(ctx, p)
+	}
+
+	summary, err := svc.KitchenSummary(ctx, "2025-12-01", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summary.Periods) != 3 || summary.Periods[2].Period.Name != "other" {
+		t.Fatalf("expected lunch, dinner and other, got %+v", summary.Periods)
+	}
+	lunch, dinner := summary.Periods[0], summary.Periods[1]
+	if lunch.Reservations != 2 || lunch.Covers != 6 || lunch.HighChairs != 1 || lunch.Allergens["nuts"] != 1 || len(lunch.WithNeeds) != 1 {
+		t.Fatalf("unexpected lunch summary: %+v", lunch)
+	}
+	if dinner.Reservations != 1 || dinner.Allergens["fish"] != 0 || dinner.DietaryStyles["vegan"] != 1 || dinner.Accessibility["wheelchair"] != 1 {
+		t.Fatalf("unexpected dinner summary: %+v", dinner)
+	}
+
+	only, err := svc.KitchenSummary(ctx, "2025-12-01", "dinner")
+	if err != nil || len(only.Periods) != 1 || only.Periods[0].Period.Name != "dinner" {
+		t.Fatalf("expected only dinner, got %+v (%v)", only, err)
+	}
+	if _, err := svc.KitchenSummary(ctx, "2025-12-01", "brunch"); err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput for unknown period, got %v", err)
+	}
+	if _, err := svc.KitchenSummary(ctx, "tomorrow", ""); err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput for bad date, got %v", err)
+	}
+}
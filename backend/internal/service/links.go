@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// LinkSigner signs the one-click links in guest messages so they work
+// without logging in but cannot be forged or altered.
+type LinkSigner struct {
+	secret []byte
+}
+
+func NewLinkSigner(secret []byte) *LinkSigner {
+	return &LinkSigner{secret: secret}
+}
+
+// Sign returns a URL-safe token for the action on a reservation, valid until
+// expiresAt.
+func (l *LinkSigner) Sign(reservationID uint, action string, expiresAt time.Time) string {
+	return l.seal(fmt.Sprintf("%d:%s:%d", reservationID, action, expiresAt.Unix()))
+}
+
+// Verify checks a token's signature and expiry.
+func (l *LinkSigner) Verify(token string, now time.Time) (*servicedto.ReservationLink, error) {
+	payload, ok := l.open(token)
+	if !ok {
+		return nil, ErrInvalidLink
+	}
+
+	parts := strings.Split(payload, ":")
+	if len(parts) != 3 {
+		return nil, ErrInvalidLink
+	}
+	id, err1 := strconv.ParseUint(parts[0], 10, 64)
+	expires, err2 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil || id == 0 {
+		return nil, ErrInvalidLink
+	}
+	link := &servicedto.ReservationLink{ReservationID: uint(id), Action: parts[1], ExpiresAt: time.Unix(expires, 0)}
+	if !now.Before(link.ExpiresAt) {
+		return nil, ErrLinkExpired
+	}
+	return link, nil
+}
+
+// SignStream returns a token that opens a user's live streams until
+// expiresAt. Its payload starts with "stream", which no link has, so
+// stream tokens and links cannot stand in for one another.
+func (l *LinkSigner) SignStream(userID uint, expiresAt time.Time) string {
+	return l.seal(fmt.Sprintf("stream:%d:%d", userID, expiresAt.Unix()))
+}
+
+// VerifyStream returns the user of a valid stream token.
+func (l *LinkSigner) VerifyStream(token string, now time.Time) (uint, error) {
+	payload, ok := l.open(token)
+	parts := strings.Split(payload, ":")
+	if !ok || len(parts) != 3 || parts[0] != "stream" {
+		return 0, ErrInvalidStreamToken
+	}
+	id, err1 := strconv.ParseUint(parts[1], 10, 64)
+	expires, err2 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil || id == 0 || !now.Before(time.Unix(expires, 0)) {
+		return 0, ErrInvalidStreamToken
+	}
+	return uint(id), nil
+}
+
+// seal encodes payload with its signature.
+func (l *LinkSigner) seal(payload string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(payload)) + "." + enc.EncodeToString(l.mac(payload))
+}
+
+// open returns the payload of a token whose signature is valid.
+func (l *LinkSigner) open(token string) (string, bool) {
+	enc := base64.RawURLEncoding
+	encPayload, encMAC, ok := strings.Cut(token, ".")
+	payload, err1 := enc.DecodeString(encPayload)
+	mac, err2 := enc.DecodeString(encMAC)
+	if !ok || err1 != nil || err2 != nil || !hmac.Equal(mac, l.mac(string(payload))) {
+		return "", false
+	}
+	return string(payload), true
+}
+
+func (l *LinkSigner) mac(payload string) []byte {
+	h := hmac.New(sha256.New, l.secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+// WithReservationLinks accepts the signed links sent to guests; see
+// FollowReservationLink.
+func WithReservationLinks(links *LinkSigner) ReservationOption {
+	return func(s *ReservationService) {
+		s.links = links
+	}
+}
+
+// PreviewReservationLink returns the booking and action behind a one-click
+// link without acting on it. Mail scanners and chat previews open links on
+// their own, so opening one must never change the booking.
+func (s *ReservationService) PreviewReservationLink(ctx context.Context, token string) (*servicedto.Reservation, string, error) {
+	link, res, err := s.resolveLink(ctx, token)
+	if err != nil {
+		return nil, "", err
+	}
+	return res, link.Action, nil
+}
+
+// FollowReservationLink carries out a one-click link from a guest message:
+// confirming attendance of a confirmed booking, or cancelling it exactly as
+// the guest would from their account.
+func (s *ReservationService) FollowReservationLink(ctx context.Context, token string) (*servicedto.Reservation, string, error) {
+	link, res, err := s.resolveLink(ctx, token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch link.Action {
+	case servicedto.LinkActionConfirm:
+		if res.AttendingAt != nil && res.Status == servicedto.StatusConfirmed {
+			return res, link.Action, nil
+		}
+		confirmed, err := s.reservationClient.ConfirmAttendance(ctx, res.ID, s.now())
+		if err != nil {
+			return nil, "", err
+		}
+		if !confirmed {
+			return nil, "", ErrInvalidStatus
+		}
+		res, err = s.reservationClient.GetReservationByID(ctx, res.ID)
+		return res, link.Action, err
+	default:
+		res, err = s.CancelReservation(ctx, servicedto.CancelReservationInput{UserID: res.UserID, ReservationID: res.ID})
+		return res, link.Action, err
+	}
+}
+
+// resolveLink verifies a link token and loads its reservation.
+func (s *ReservationService) resolveLink(ctx context.Context, token string) (*servicedto.ReservationLink, *servicedto.Reservation, error) {
+	if s.links == nil {
+		return nil, nil, ErrLinksDisabled
+	}
+	link, err := s.links.Verify(token, s.now())
+	if err != nil {
+		return nil, nil, err
+	}
+	if link.Action != servicedto.LinkActionConfirm && link.Action != servicedto.LinkActionCancel {
+		return nil, nil, ErrInvalidLink
+	}
+	res, err := s.reservationClient.GetReservationByID(ctx, link.ReservationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if res == nil {
+		return nil, nil, ErrReservationNotFound
+	}
+	return link, res, nil
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// ReservationBroadcaster fans reservation updates out to live views. Every
+// instance runs LiveService.Run, which polls the outbox itself and publishes
+// to that instance's own subscribers, so a MemoryBroadcaster per instance is
+// enough to reach them all.
+type ReservationBroadcaster interface {
+	Publish(ctx context.Context, update servicedto.ReservationUpdate) error
+	// Subscribe returns the buffered updates after afterID, then streams
+	// new ones until ctx ends or the subscriber falls behind, when the
+	// channel is closed.
+	Subscribe(ctx context.Context, afterID uint) ([]servicedto.ReservationUpdate, <-chan servicedto.ReservationUpdate, error)
+}
+
+// subscriberBuffer is how many updates a subscriber may fall behind before
+// it is dropped and has to reconnect.
+const subscriberBuffer = 64
+
+// MemoryBroadcaster keeps the last updates in memory for resuming clients.
+type MemoryBroadcaster struct {
+	mu          sync.Mutex
+	history     []servicedto.ReservationUpdate
+	seen        map[uint]struct{} // IDs in history
+	size        int
+	subscribers map[chan servicedto.ReservationUpdate]struct{}
+}
+
+// NewMemoryBroadcaster keeps up to size updates for replay.
+func NewMemoryBroadcaster(size int) *MemoryBroadcaster {
+	return &MemoryBroadcaster{
+		size:        size,
+		seen:        make(map[uint]struct{}),
+		subscribers: make(map[chan servicedto.ReservationUpdate]struct{}),
+	}
+}
+
+// Publish sends the update to every subscriber. Updates are delivered at
+// least once and not necessarily in ID order, as events commit in another
+// order than they were numbered, so only an ID already kept is dropped as
+// a redelivery.
+func (b *MemoryBroadcaster) Publish(ctx context.Context, update servicedto.ReservationUpdate) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.seen[update.ID]; ok {
+		return nil
+	}
+	b.history = append(b.history, update)
+	b.seen[update.ID] = struct{}{}
+	if len(b.history) > b.size {
+		for _, u := range b.history[:len(b.history)-b.size] {
+			delete(b.seen, u.ID)
+		}
+		b.history = b.history[len(b.history)-b.size:]
+	}
+	for ch := range b.subscribers {
+		select {
+		case ch <- update:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return nil
+}
+
+// Subscribe replays what was published after afterID. When afterID is no
+// longer kept, every kept update with a greater ID is replayed instead.
+func (b *MemoryBroadcaster) Subscribe(ctx context.Context, afterID uint) ([]servicedto.ReservationUpdate, <-chan servicedto.ReservationUpdate, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var replay []servicedto.ReservationUpdate
+	if afterID > 0 {
+		if i := slices.IndexFunc(b.history, func(u servicedto.ReservationUpdate) bool { return u.ID == afterID }); i >= 0 {
+			replay = slices.Clone(b.history[i+1:])
+		} else {
+			for _, u := range b.history {
+				if u.ID > afterID {
+					replay = append(replay, u)
+				}
+			}
+		}
+	}
+	ch := make(chan servicedto.ReservationUpdate, subscriberBuffer)
+	b.subscribers[ch] = struct{}{}
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}()
+	return replay, ch, nil
+}
+
+// LiveEventSource lists the outbox events live views are fed from.
+type LiveEventSource interface {
+	ListEvents(ctx context.Context, params servicedto.ListEventsParams) ([]servicedto.DomainEvent, error)
+}
+
+const (
+	// liveLookback is how far back every poll looks again for events: one
+	// numbered before those already published may commit after them.
+	liveLookback = 30 * time.Second
+	liveBatch    = 100
+	// streamTokenTTL is how long a stream token may be used to connect.
+	streamTokenTTL = time.Minute
+)
+
+// LiveService pushes reservation changes to admin dashboards and to the
+// guests they belong to. Every instance follows the outbox on its own, apart
+// from the dispatcher, so a slow guest message never holds back a dashboard
+// and each instance reaches its own subscribers.
+type LiveService struct {
+	broadcaster       ReservationBroadcaster
+	events            LiveEventSource
+	reservationClient ReservationClient
+	guestClient       GuestClient
+	tokens            *LinkSigner
+	location          *time.Location
+	now               func() time.Time
+
+	since     time.Time
+	published map[uint]time.Time // events published since since-liveLookback
+}
+
+func NewLiveService(broadcaster ReservationBroadcaster, events LiveEventSource, resClient ReservationClient, guestClient GuestClient, tokens *LinkSigner, loc *time.Location) *LiveService {
+	return &LiveService{
+		broadcaster:       broadcaster,
+		events:            events,
+		reservationClient: resClient,
+		guestClient:       guestClient,
+		tokens:            tokens,
+		location:          loc,
+		now:               time.Now,
+		published:         make(map[uint]time.Time),
+	}
+}
+
+// Run publishes new events every interval until ctx ends.
+func (s *LiveService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.PublishRecent(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("live updates: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishRecent publishes the events recorded since the last call, walk-ins
+// included, with the reservation as it is now. The first call starts from
+// the present. Not safe for concurrent use.
+func (s *LiveService) PublishRecent(ctx context.Context) error {
+	now := s.now()
+	if s.since.IsZero() {
+		s.since = now
+	}
+	since := s.since.Add(-liveLookback)
+	var afterID uint
+	for {
+		events, err := s.events.ListEvents(ctx, servicedto.ListEventsParams{Since: since, AfterID: afterID, Limit: liveBatch})
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			afterID = event.ID
+			if _, ok := s.published[event.ID]; ok {
+				continue
+			}
+			if err := s.publish(ctx, event); err != nil {
+				return err
+			}
+			s.published[event.ID] = event.CreatedAt
+		}
+		if len(events) < liveBatch {
+			break
+		}
+	}
+	s.since = now
+	for id, at := range s.published {
+		if at.Before(since) {
+			delete(s.published, id)
+		}
+	}
+	return nil
+}
+
+func (s *LiveService) publish(ctx context.Context, event servicedto.DomainEvent) error {
+	res, err := s.reservationClient.GetReservationByID(ctx, event.ReservationID)
+	if err != nil || res == nil {
+		return err
+	}
+	if res.User == nil && s.guestClient != nil {
+		if res.User, err = s.guestClient.GetUserByID(ctx, res.UserID); err != nil {
+			return err
+		}
+	}
+	kind := servicedto.LiveReservationUpdated
+	if event.Type == servicedto.DomainEventReservationCreated {
+		kind = servicedto.LiveReservationCreated
+	}
+	return s.broadcaster.Publish(ctx, servicedto.ReservationUpdate{
+		ID:             event.ID,
+		Type:           kind,
+		Reservation:    *res,
+		Status:         event.ToStatus,
+		PreviousStatus: event.FromStatus,
+		At:             event.CreatedAt,
+	})
+}
+
+// IssueStreamToken returns a token that opens the user's live streams for
+// a minute. Browsers cannot add headers to EventSource or WebSocket
+// requests, so streams take it as ?token= instead.
+func (s *LiveService) IssueStreamToken(ctx context.Context, user servicedto.User) (*servicedto.StreamToken, error) {
+	expiresAt := s.now().Add(streamTokenTTL)
+	return &servicedto.StreamToken{Token: s.tokens.SignStream(user.ID, expiresAt), ExpiresAt: expiresAt}, nil
+}
+
+// AuthenticateStream returns the user a stream token was issued to, as
+// the user is now.
+func (s *LiveService) AuthenticateStream(ctx context.Context, token string) (*servicedto.User, error) {
+	if s.guestClient == nil {
+		return nil, ErrInvalidStreamToken
+	}
+	userID, err := s.tokens.VerifyStream(token, s.now())
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.guestClient.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidStreamToken
+	}
+	return user, nil
+}
+
+// StreamReservations follows the reservations of a date until ctx ends.
+// Besides bookings on the date it reports those moved away from it, so
+// dashboards can drop them.
+func (s *LiveService) StreamReservations(ctx context.Context, input servicedto.StreamReservationsInput) (*servicedto.ReservationStream, error) {
+	if input.Date == "" {
+		input.Date = s.now().In(s.location).Format("2006-01-02")
+	}
+	day, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		return nil, ErrInvalidInput
+	}
+
+	// Subscribe before looking at the date so no change falls in between.
+	replay, updates, err := s.broadcaster.Subscribe(ctx, input.LastEventID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := reservationsOn(ctx, s.reservationClient, day)
+	if err != nil {
+		return nil, err
+	}
+	onDate := make(map[uint]bool, len(current))
+	for _, r := range current {
+		onDate[r.ID] = true
+	}
+	follows := func(u servicedto.ReservationUpdate) bool {
+		was := onDate[u.Reservation.ID]
+		is := u.Reservation.Date.Format("2006-01-02") == input.Date
+		onDate[u.Reservation.ID] = is
+		return was || is
+	}
+
+	return filterStream(ctx, replay, updates, follows), nil
+}
+
+// FollowReservation checks that the guest may follow the reservation, as
+// cancelling it would, and returns it.
+func (s *LiveService) FollowReservation(ctx context.Context, input servicedto.FollowReservationInput) (*servicedto.Reservation, error) {
+	if input.UserID == 0 || input.ReservationID == 0 {
+		return nil, ErrInvalidInput
+	}
+	res, err := s.reservationClient.GetReservationByID(ctx, input.ReservationID)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, ErrReservationNotFound
+	}
+	if res.UserID != input.UserID {
+		return nil, ErrForbiddenReservation
+	}
+	return res, nil
+}
+
+// StreamStatusChanges follows new bookings and status changes of the
+// guest's own reservations until ctx ends.
+func (s *LiveService) StreamStatusChanges(ctx context.Context, input servicedto.StreamStatusChangesInput) (*servicedto.ReservationStream, error) {
+	if input.UserID == 0 {
+		return nil, ErrInvalidInput
+	}
+	replay, updates, err := s.broadcaster.Subscribe(ctx, input.LastEventID)
+	if err != nil {
+		return nil, err
+	}
+	return filterStream(ctx, replay, updates, func(u servicedto.ReservationUpdate) bool {
+		return u.Reservation.UserID == input.UserID && u.StatusChanged()
+	}), nil
+}
+
+// filterStream passes on the updates keep accepts. The filtered channel is
+// closed with the subscription.
+func filterStream(ctx context.Context, replay []servicedto.ReservationUpdate, updates <-chan servicedto.ReservationUpdate, keep func(servicedto.ReservationUpdate) bool) *servicedto.ReservationStream {
+	stream := &servicedto.ReservationStream{}
+	for _, u := range replay {
+		if keep(u) {
+			stream.Replay = append(stream.Replay, u)
+		}
+	}
+	out := make(chan servicedto.ReservationUpdate, subscriberBuffer)
+	go func() {
+		defer close(out)
+		for u := range updates {
+			if !keep(u) {
+				continue
+			}
+			select {
+			case out <- u:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	stream.Updates = out
+	return stream
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestMemoryBroadcaster(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := NewMemoryBroadcaster(2)
+	for id := uint(1); id <= 3; id++ {
+		b.Publish(ctx, servicedto.ReservationUpdate{ID: id})
+	}
+
+	// Only the last two updates are kept for resuming clients.
+	replay, updates, err := b.Subscribe(ctx, 1)
+	if err != nil || len(replay) != 2 || replay[0].ID != 2 {
+		t.Fatalf("unexpected replay: %+v %v", replay, err)
+	}
+	if fresh, _, _ := b.Subscribe(ctx, 0); len(fresh) != 0 {
+		t.Fatalf("expected no replay without a last event id, got %+v", fresh)
+	}
+
+	b.Publish(ctx, servicedto.ReservationUpdate{ID: 3}) // redelivered
+	b.Publish(ctx, servicedto.ReservationUpdate{ID: 5})
+	b.Publish(ctx, servicedto.ReservationUpdate{ID: 4}) // committed after 5
+	for _, want := range []uint{5, 4} {
+		if u := <-updates; u.ID != want {
+			t.Fatalf("expected update %d, got %+v", want, u)
+		}
+	}
+	// Resuming after 5 gets 4, published after it.
+	if replay, _, _ := b.Subscribe(ctx, 5); len(replay) != 1 || replay[0].ID != 4 {
+		t.Fatalf("expected the later published update, got %+v", replay)
+	}
+
+	// A subscriber that stops reading is dropped.
+	for id := uint(6); id < 6+subscriberBuffer+1; id++ {
+		b.Publish(ctx, servicedto.ReservationUpdate{ID: id})
+	}
+	for range updates {
+	}
+
+	// Ending the context unsubscribes.
+	subCtx, unsubscribe := context.WithCancel(ctx)
+	_, sub, _ := b.Subscribe(subCtx, 0)
+	unsubscribe()
+	select {
+	case _, ok := <-sub:
+		if ok {
+			t.Fatal("expected no updates after unsubscribing")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the channel to be closed")
+	}
+}
+
+func TestStreamReservations(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	users := newFakeUserClient()
+	user, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com"})
+	client := newFakeReservationClient()
+	outbox := newFakeOutboxClient()
+	svc := NewLiveService(NewMemoryBroadcaster(100), outbox, client, users, nil, time.UTC)
+	svc.now = func() time.Time { return time.Date(2030, 1, 11, 18, 0, 0, 0, time.UTC) }
+	publish := func() {
+		for _, e := range client.events[len(outbox.events):] {
+			e.CreatedAt = svc.now()
+			outbox.events = append(outbox.events, e)
+		}
+		if err := svc.PublishRecent(ctx); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	book := func(date string) servicedto.Reservation {
+		res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: user.ID, Date: mustDate(t, date), Time: "20:00", People: 2, Status: servicedto.StatusPending,
+		})
+		return *res
+	}
+
+	moving := book("2030-01-11")
+	publish()
+	if _, err := svc.StreamReservations(ctx, servicedto.StreamReservationsInput{Date: "11/01/2030"}); err != ErrInvalidInput {
+		t.Fatalf("expected a bad date to be refused, got %v", err)
+	}
+	stream, err := svc.StreamReservations(ctx, servicedto.StreamReservationsInput{})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+
+	other := book("2030-01-12")
+	today := book("2030-01-11")
+	client.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{ID: today.ID, Status: servicedto.StatusConfirmed})
+	client.ModifyReservation(ctx, servicedto.ModifyReservationParams{
+		ID: moving.ID, From: servicedto.StatusPending, Date: mustDate(t, "2030-01-12"), Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	client.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{ID: moving.ID, Status: servicedto.StatusCancelled})
+	client.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{ID: other.ID, Status: servicedto.StatusCancelled})
+	publish()
+
+	// Bookings on the date, and the one moved away from it once.
+	want := []struct {
+		kind string
+		id   uint
+	}{
+		{servicedto.LiveReservationCreated, today.ID},
+		{servicedto.LiveReservationUpdated, today.ID},
+		{servicedto.LiveReservationUpdated, moving.ID},
+	}
+	for _, w := range want {
+		select {
+		case u := <-stream.Updates:
+			if u.Type != w.kind || u.Reservation.ID != w.id || u.Reservation.User == nil {
+				t.Fatalf("expected %s of %d, got %+v", w.kind, w.id, u)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %s of %d", w.kind, w.id)
+		}
+	}
+	select {
+	case u := <-stream.Updates:
+		t.Fatalf("unexpected update %+v", u)
+	default:
+	}
+}
+
+func TestPublishRecent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newFakeReservationClient()
+	res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 1, Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 2})
+	start := time.Date(2030, 1, 11, 18, 0, 0, 0, time.UTC)
+	event := func(id uint, at time.Time) servicedto.DomainEvent {
+		return servicedto.DomainEvent{ID: id, Type: servicedto.DomainEventStatusChanged, ReservationID: res.ID, CreatedAt: at}
+	}
+	outbox := newFakeOutboxClient(event(1, start.Add(-time.Hour)), event(2, start.Add(-time.Second)))
+	svc := NewLiveService(NewMemoryBroadcaster(100), outbox, client, nil, nil, time.UTC)
+	svc.now = func() time.Time { return start }
+	_, updates, _ := svc.broadcaster.Subscribe(ctx, 0)
+	published := func(want ...uint) {
+		t.Helper()
+		if err := svc.PublishRecent(ctx); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		for _, id := range want {
+			select {
+			case u := <-updates:
+				if u.ID != id {
+					t.Fatalf("expected update %d, got %+v", id, u)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("expected update %d", id)
+			}
+		}
+		select {
+		case u := <-updates:
+			t.Fatalf("unexpected update %+v", u)
+		default:
+		}
+	}
+
+	// Old events are not pushed when the service starts.
+	published(2)
+	// Event 3 commits after 4 and is still pushed; 2 is not pushed again.
+	outbox.events = append(outbox.events, event(4, start.Add(time.Second)))
+	svc.now = func() time.Time { return start.Add(2 * time.Second) }
+	published(4)
+	outbox.events = append(outbox.events, event(3, start.Add(time.Second)))
+	svc.now = func() time.Time { return start.Add(3 * time.Second) }
+	published(3)
+}
+
+func TestStreamTokens(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserClient()
+	user, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com"})
+	signer := NewLinkSigner([]byte("secret"))
+	svc := NewLiveService(NewMemoryBroadcaster(1), nil, newFakeReservationClient(), users, signer, time.UTC)
+	now := time.Date(2030, 1, 11, 18, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	token, err := svc.IssueStreamToken(ctx, *user)
+	if err != nil || !token.ExpiresAt.Equal(now.Add(streamTokenTTL)) {
+		t.Fatalf("unexpected token: %+v %v", token, err)
+	}
+	if got, err := svc.AuthenticateStream(ctx, token.Token); err != nil || got.ID != user.ID {
+		t.Fatalf("expected the token's user, got %+v %v", got, err)
+	}
+
+	for name, bad := range map[string]string{
+		"tampered": token.Token[:len(token.Token)-2] + "xx",
+		"link":     signer.Sign(user.ID, servicedto.LinkActionCancel, now.Add(time.Hour)),
+		"unknown":  signer.SignStream(999, now.Add(time.Minute)),
+	} {
+		if _, err := svc.AuthenticateStream(ctx, bad); err != ErrInvalidStreamToken {
+			t.Fatalf("%s: expected an invalid token, got %v", name, err)
+		}
+	}
+	// A stream token is no link either.
+	if _, err := signer.Verify(token.Token, now); err != ErrInvalidLink {
+		t.Fatalf("expected a stream token refused as a link, got %v", err)
+	}
+	svc.now = func() time.Time { return token.ExpiresAt }
+	if _, err := svc.AuthenticateStream(ctx, token.Token); err != ErrInvalidStreamToken {
+		t.Fatalf("expected an expired token refused, got %v", err)
+	}
+}
+
+func TestStreamStatusChanges(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newFakeReservationClient()
+	b := NewMemoryBroadcaster(100)
+	svc := NewLiveService(b, nil, client, nil, nil, time.UTC)
+	mine, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 1, Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 2})
+	theirs, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 2, Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 2})
+
+	// Following needs the same ownership as cancelling.
+	for _, tc := range []struct {
+		input servicedto.FollowReservationInput
+		want  error
+	}{
+		{servicedto.FollowReservationInput{UserID: 1, ReservationID: theirs.ID}, ErrForbiddenReservation},
+		{servicedto.FollowReservationInput{UserID: 1, ReservationID: 999}, ErrReservationNotFound},
+		{servicedto.FollowReservationInput{ReservationID: mine.ID}, ErrInvalidInput},
+	} {
+		if _, err := svc.FollowReservation(ctx, tc.input); err != tc.want {
+			t.Fatalf("%+v: expected %v, got %v", tc.input, tc.want, err)
+		}
+	}
+	if res, err := svc.FollowReservation(ctx, servicedto.FollowReservationInput{UserID: 1, ReservationID: mine.ID}); err != nil || res.ID != mine.ID {
+		t.Fatalf("expected to follow own reservation, got %+v %v", res, err)
+	}
+
+	update := func(id uint, res *servicedto.Reservation, from, to string) servicedto.ReservationUpdate {
+		kind := servicedto.LiveReservationUpdated
+		if from == "" {
+			kind = servicedto.LiveReservationCreated
+		}
+		return servicedto.ReservationUpdate{ID: id, Type: kind, Reservation: *res, PreviousStatus: from, Status: to}
+	}
+	b.Publish(ctx, update(1, mine, "", servicedto.StatusPending))
+	stream, err := svc.StreamStatusChanges(ctx, servicedto.StreamStatusChangesInput{UserID: 1, LastEventID: 0})
+	if err != nil || len(stream.Replay) != 0 {
+		t.Fatalf("unexpected stream: %+v %v", stream, err)
+	}
+	b.Publish(ctx, update(2, theirs, servicedto.StatusPending, servicedto.StatusConfirmed))
+	b.Publish(ctx, update(3, mine, servicedto.StatusPending, servicedto.StatusPending)) // modified, same status
+	b.Publish(ctx, update(4, mine, servicedto.StatusPending, servicedto.StatusConfirmed))
+	select {
+	case u := <-stream.Updates:
+		if u.ID != 4 {
+			t.Fatalf("expected only the guest's status change, got %+v", u)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a status change")
+	}
+
+	resumed, _ := svc.StreamStatusChanges(ctx, servicedto.StreamStatusChangesInput{UserID: 1, LastEventID: 1})
+	if len(resumed.Replay) != 1 || resumed.Replay[0].ID != 4 {
+		t.Fatalf("expected the missed change to be replayed, got %+v", resumed.Replay)
+	}
+}
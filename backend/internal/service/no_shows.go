@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// noShowLookback is how many days back the no-show job looks for confirmed
+// bookings nobody seated, so a missed run still catches up.
+const noShowLookback = 2
+
+// WithNoShowGrace marks confirmed bookings that were not seated within
+// grace of their slot as no-shows; see MarkOverdueNoShows.
+func WithNoShowGrace(grace time.Duration) ReservationOption {
+	return func(s *ReservationService) {
+		s.noShowGrace = grace
+	}
+}
+
+// MarkOverdueNoShows marks confirmed reservations whose slot started more
+// than the grace period ago as no-shows, recording the incident like a
+// host would. It does nothing when no grace period is configured.
+func (s *ReservationService) MarkOverdueNoShows(ctx context.Context) (*servicedto.AutoNoShowResult, error) {
+	result := &servicedto.AutoNoShowResult{}
+	if s.noShowGrace <= 0 {
+		return result, nil
+	}
+	now := s.now().In(s.location)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -noShowLookback)
+	page, err := s.reservationClient.QueryReservations(ctx, servicedto.ReservationQuery{
+		From:     &from,
+		To:       &to,
+		Statuses: []string{servicedto.StatusConfirmed},
+	})
+	if err != nil {
+		return result, err
+	}
+
+	reason := fmt.Sprintf("not seated within %d minutes", int(s.noShowGrace/time.Minute))
+	for _, r := range page.Reservations {
+		start, ok := slotStart(r, s.location)
+		if !ok || now.Before(start.Add(s.noShowGrace)) {
+			continue
+		}
+		updated, err := s.reservationClient.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{
+			ID:     r.ID,
+			Status: servicedto.StatusNoShow,
+			From:   servicedto.StatusConfirmed,
+			Actor:  servicedto.StatusActor{Source: servicedto.StatusSourceSystem, Reason: &reason},
+		})
+		if err != nil {
+			return result, err
+		}
+		if updated == nil {
+			continue // seated or changed by staff meanwhile
+		}
+		// A failed capture stays due for the settlement job; the booking is
+		// a no-show either way.
+		s.settleEnded(ctx, r.ID, settleForfeit)
+		result.Marked++
+		if err := s.recordIncident(ctx, *updated, servicedto.IncidentNoShow); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// NoShowSummary lists a day's no-shows, whether the job or staff marked
+// them, and any corrections since. An empty date means today.
+func (s *ReservationService) NoShowSummary(ctx context.Context, admin servicedto.User, date string) (*servicedto.NoShowSummary, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	if date == "" {
+		date = s.now().In(s.location).Format("2006-01-02")
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, ErrInvalidInput
+	}
+	reservations, err := reservationsOn(ctx, s.reservationClient, day)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(reservations))
+	for _, r := range reservations {
+		ids = append(ids, r.ID)
+	}
+	changes, err := s.reservationClient.ListStatusChangesByReservations(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byReservation := make(map[uint][]servicedto.StatusChange)
+	for _, c := range changes {
+		byReservation[c.ReservationID] = append(byReservation[c.ReservationID], c)
+	}
+
+	summary := &servicedto.NoShowSummary{Date: day, Entries: []servicedto.NoShowEntry{}}
+	for _, r := range reservations {
+		entry, ok := noShowEntry(r, byReservation[r.ID])
+		if !ok {
+			continue
+		}
+		if entry.Auto {
+			summary.Auto++
+		} else {
+			summary.Manual++
+		}
+		if entry.RevertedTo != nil {
+			summary.Reverted++
+		} else {
+			summary.Covers += r.People
+		}
+		summary.Entries = append(summary.Entries, entry)
+	}
+	slices.SortFunc(summary.Entries, func(a, b servicedto.NoShowEntry) int {
+		return a.MarkedAt.Compare(b.MarkedAt)
+	})
+	return summary, nil
+}
+
+// noShowEntry finds the last time the reservation was marked as a no-show
+// in its history, oldest first, and what it was corrected to afterwards.
+func noShowEntry(r servicedto.Reservation, history []servicedto.StatusChange) (servicedto.NoShowEntry, bool) {
+	var marked *servicedto.StatusChange
+	for j := len(history) - 1; j >= 0; j-- {
+		if history[j].ToStatus == servicedto.StatusNoShow {
+			marked = &history[j]
+			break
+		}
+	}
+	if marked == nil {
+		return servicedto.NoShowEntry{}, false
+	}
+	entry := servicedto.NoShowEntry{
+		Reservation: r,
+		MarkedAt:    marked.CreatedAt,
+		Auto:        marked.Source == servicedto.StatusSourceSystem,
+	}
+	if r.Status != servicedto.StatusNoShow {
+		status := r.Status
+		entry.RevertedTo = &status
+	}
+	return entry, true
+}
+
+// RevertNoShow corrects a mistaken no-show. The incident no longer counts
+// towards sanctions and a captured card guarantee is refunded; restrictions
+// already applied stay until staff lift them.
+func (s *ReservationService) RevertNoShow(ctx context.Context, admin servicedto.User, input servicedto.RevertNoShowInput) (*servicedto.Reservation, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	status := input.Status
+	if status == "" {
+		status = servicedto.StatusSeated
+	}
+	if input.ReservationID == 0 || (status != servicedto.StatusSeated && status != servicedto.StatusCancelled) {
+		return nil, ErrInvalidInput
+	}
+	res, err := s.reservationClient.GetReservationByID(ctx, input.ReservationID)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, ErrReservationNotFound
+	}
+	if res.Status != servicedto.StatusNoShow {
+		return nil, ErrInvalidStatus
+	}
+
+	if err := s.refundGuarantee(ctx, res.ID); err != nil {
+		return nil, err
+	}
+	reason := "no-show reverted"
+	actor := adminActor(admin)
+	actor.Reason = &reason
+	updated, err := s.reservationClient.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{
+		ID:     res.ID,
+		Status: status,
+		From:   servicedto.StatusNoShow,
+		Actor:  actor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrInvalidStatus
+	}
+	if s.restrictionClient != nil {
+		if err := s.restrictionClient.DeleteIncident(ctx, res.ID, servicedto.IncidentNoShow); err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+// refundGuarantee returns a card guarantee captured for a no-show. Deposits
+// were paid up front and are kept like for any party that came.
+func (s *ReservationService) refundGuarantee(ctx context.Context, reservationID uint) error {
+	if s.paymentClient == nil {
+		return nil
+	}
+	payment, err := s.paymentClient.GetPaymentByReservation(ctx, reservationID)
+	if err != nil || payment == nil {
+		return err
+	}
+	if payment.Kind != servicedto.PaymentKindGuarantee || payment.Status != servicedto.PaymentCaptured {
+		return nil
+	}
+	if err := s.paymentProvider.Refund(ctx, payment.ProviderRef); err != nil {
+		return err
+	}
+	_, err = s.paymentClient.UpdatePaymentStatus(ctx, payment.ID, payment.Status, servicedto.PaymentRefunded)
+	return err
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestMarkOverdueNoShows(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	svc, client, provider, payments := newDepositTestService(servicedto.DepositPolicy{MinPeople: 8})
+	restrictions := newFakeRestrictionClient()
+	WithSanctions(restrictions, servicedto.SanctionPolicy{})(svc)
+	WithNoShowGrace(45 * time.Minute)(svc)
+	svc.now = func() time.Time { return time.Date(2030, 1, 10, 21, 0, 0, 0, time.UTC) }
+
+	add := func(date, slot, status string) uint {
+		res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: 1, Date: mustDate(t, date), Time: slot, People: 2, Status: status,
+		})
+		return res.ID
+	}
+	overdue := add("2030-01-10", "20:00", servicedto.StatusConfirmed)
+	withinGrace := add("2030-01-10", "20:30", servicedto.StatusConfirmed)
+	seated := add("2030-01-10", "19:00", servicedto.StatusSeated)
+	pending := add("2030-01-10", "19:00", servicedto.StatusPending)
+	yesterday := add("2030-01-09", "20:00", servicedto.StatusConfirmed)
+	longAgo := add("2030-01-05", "20:00", servicedto.StatusConfirmed)
+
+	// A guaranteed party of eight that never came has its card charged.
+	out, _ := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 2, Date: "2030-01-10", Time: "19:30", People: 8})
+	guaranteed := out.Reservation.ID
+	if err := svc.HandlePaymentWebhook(ctx, []byte("ok:"+out.Reservation.Payment.ProviderRef), "signed"); err != nil {
+		t.Fatalf("webhook: %v", err)
+	}
+	if _, err := svc.ConfirmReservation(ctx, admin, guaranteed); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+
+	result, err := svc.MarkOverdueNoShows(ctx)
+	if err != nil {
+		t.Fatalf("mark: %v", err)
+	}
+	if result.Marked != 3 {
+		t.Fatalf("expected three no-shows, got %+v", result)
+	}
+	for id, want := range map[uint]string{
+		overdue:     servicedto.StatusNoShow,
+		withinGrace: servicedto.StatusConfirmed,
+		seated:      servicedto.StatusSeated,
+		pending:     servicedto.StatusPending,
+		yesterday:   servicedto.StatusNoShow,
+		longAgo:     servicedto.StatusConfirmed,
+		guaranteed:  servicedto.StatusNoShow,
+	} {
+		if got := client.reservations[id].Status; got != want {
+			t.Fatalf("reservation %d: expected %s, got %s", id, want, got)
+		}
+	}
+	last := client.history[len(client.history)-1]
+	if last.Source != servicedto.StatusSourceSystem || last.Reason == nil || *last.Reason != "not seated within 45 minutes" {
+		t.Fatalf("unexpected history entry: %+v", last)
+	}
+	if len(restrictions.incidents) != 3 {
+		t.Fatalf("expected an incident per no-show, got %+v", restrictions.incidents)
+	}
+	if !slices.Equal(provider.calls, []string{"capture"}) || payments.payments[0].Status != servicedto.PaymentCaptured {
+		t.Fatalf("expected the guarantee to be captured, got %v %+v", provider.calls, payments.payments[0])
+	}
+
+	if result, err := svc.MarkOverdueNoShows(ctx); err != nil || result.Marked != 0 {
+		t.Fatalf("expected nothing left to mark, got %+v %v", result, err)
+	}
+
+	WithNoShowGrace(0)(svc)
+	if _, err := client.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{ID: longAgo, Status: servicedto.StatusConfirmed}); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := svc.MarkOverdueNoShows(ctx); result.Marked != 0 {
+		t.Fatalf("expected the job to be disabled without a grace period, got %+v", result)
+	}
+}
+
+// seatingRaceClient seats every listed booking right after the no-show job
+// lists it, as a host would between the job's query and its update.
+type seatingRaceClient struct {
+	*fakeReservationClient
+}
+
+func (c seatingRaceClient) QueryReservations(ctx context.Context, q servicedto.ReservationQuery) (*servicedto.ReservationPage, error) {
+	page, err := c.fakeReservationClient.QueryReservations(ctx, q)
+	for _, r := range page.Reservations {
+		_, _ = c.SeatReservation(ctx, r.ID, nil, time.Now(), servicedto.StatusActor{Source: servicedto.StatusSourceAdmin})
+	}
+	return page, err
+}
+
+func TestMarkOverdueNoShowsSettlesOnlyMarkedBookings(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	svc, client, provider, payments := newDepositTestService(servicedto.DepositPolicy{MinPeople: 8})
+	WithNoShowGrace(45 * time.Minute)(svc)
+	svc.now = func() time.Time { return time.Date(2030, 1, 10, 21, 0, 0, 0, time.UTC) }
+
+	out, _ := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 2, Date: "2030-01-10", Time: "19:30", People: 8})
+	guaranteed := out.Reservation.ID
+	_ = svc.HandlePaymentWebhook(ctx, []byte("ok:"+out.Reservation.Payment.ProviderRef), "signed")
+	if _, err := svc.ConfirmReservation(ctx, admin, guaranteed); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+
+	// The party is seated while the job runs: nothing is marked or charged.
+	svc.reservationClient = seatingRaceClient{client}
+	result, err := svc.MarkOverdueNoShows(ctx)
+	if err != nil {
+		t.Fatalf("mark: %v", err)
+	}
+	if result.Marked != 0 || client.reservations[guaranteed].Status != servicedto.StatusSeated {
+		t.Fatalf("expected the seated party to be left alone, got %+v %s", result, client.reservations[guaranteed].Status)
+	}
+	if slices.Contains(provider.calls, "capture") || payments.payments[0].Status != servicedto.PaymentAuthorized {
+		t.Fatalf("expected no capture, got %v %+v", provider.calls, payments.payments[0])
+	}
+}
+
+func TestMarkOverdueNoShowsKeepsFailedCaptureDue(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	svc, client, provider, payments := newDepositTestService(servicedto.DepositPolicy{MinPeople: 8})
+	WithNoShowGrace(45 * time.Minute)(svc)
+	svc.now = func() time.Time { return time.Date(2030, 1, 10, 21, 0, 0, 0, time.UTC) }
+
+	out, _ := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 2, Date: "2030-01-10", Time: "19:30", People: 8})
+	id := out.Reservation.ID
+	_ = svc.HandlePaymentWebhook(ctx, []byte("ok:"+out.Reservation.Payment.ProviderRef), "signed")
+	if _, err := svc.ConfirmReservation(ctx, admin, id); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	confirmedEvents := len(client.events)
+
+	// The booking is a no-show either way; the capture is left for the
+	// settlement job, and the guest is not told it was confirmed again.
+	provider.failSettle = true
+	for run := 0; run < 2; run++ {
+		result, err := svc.MarkOverdueNoShows(ctx)
+		if err != nil {
+			t.Fatalf("mark: %v", err)
+		}
+		if want := 1 - run; result.Marked != want {
+			t.Fatalf("run %d: expected %d marked, got %d", run, want, result.Marked)
+		}
+	}
+	if got := client.reservations[id].Status; got != servicedto.StatusNoShow {
+		t.Fatalf("expected a no-show, got %s", got)
+	}
+	events := client.events[confirmedEvents:]
+	if len(events) != 1 || events[0].ToStatus != servicedto.StatusNoShow {
+		t.Fatalf("expected only the no-show event, got %+v", events)
+	}
+	if p := payments.payments[0]; p.Status != servicedto.PaymentAuthorized || p.Settlement != settleForfeit {
+		t.Fatalf("expected the capture still due, got %+v", p)
+	}
+
+	provider.failSettle = false
+	if result, err := svc.SettleDuePayments(ctx); err != nil || result.Settled != 1 {
+		t.Fatalf("expected the capture retried, got %+v %v", result, err)
+	}
+	if !slices.Equal(provider.calls, []string{"capture"}) || payments.payments[0].Status != servicedto.PaymentCaptured {
+		t.Fatalf("expected the guarantee captured, got %v %+v", provider.calls, payments.payments[0])
+	}
+}
+
+func TestNoShowSummaryAndRevert(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	svc, client, provider, payments := newDepositTestService(servicedto.DepositPolicy{MinPeople: 8})
+	restrictions := newFakeRestrictionClient()
+	WithSanctions(restrictions, servicedto.SanctionPolicy{})(svc)
+	WithNoShowGrace(30 * time.Minute)(svc)
+	svc.now = func() time.Time { return time.Date(2030, 1, 10, 23, 0, 0, 0, time.UTC) }
+
+	auto, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: mustDate(t, "2030-01-10"), Time: "20:00", People: 3, Status: servicedto.StatusConfirmed,
+	})
+	manual, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 3, Date: mustDate(t, "2030-01-10"), Time: "22:45", People: 2, Status: servicedto.StatusPending,
+	})
+	out, _ := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 2, Date: "2030-01-10", Time: "19:30", People: 8})
+	guaranteed := out.Reservation
+	_ = svc.HandlePaymentWebhook(ctx, []byte("ok:"+guaranteed.Payment.ProviderRef), "signed")
+	_, _ = svc.ConfirmReservation(ctx, admin, guaranteed.ID)
+
+	if _, err := svc.MarkOverdueNoShows(ctx); err != nil {
+		t.Fatalf("mark: %v", err)
+	}
+	if _, err := svc.MarkNoShow(ctx, admin, manual.ID); err != nil {
+		t.Fatalf("manual no-show: %v", err)
+	}
+
+	// The party of eight did come; the host forgot to seat them.
+	res, err := svc.RevertNoShow(ctx, admin, servicedto.RevertNoShowInput{ReservationID: guaranteed.ID})
+	if err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	if res.Status != servicedto.StatusSeated {
+		t.Fatalf("expected seated, got %s", res.Status)
+	}
+	if !slices.Equal(provider.calls, []string{"capture", "refund"}) || payments.payments[0].Status != servicedto.PaymentRefunded {
+		t.Fatalf("expected the captured guarantee to be refunded, got %v %+v", provider.calls, payments.payments[0])
+	}
+	if slices.ContainsFunc(restrictions.incidents, func(i servicedto.Incident) bool { return i.ReservationID == guaranteed.ID }) {
+		t.Fatal("expected the reverted incident to be forgotten")
+	}
+
+	if _, err := svc.RevertNoShow(ctx, admin, servicedto.RevertNoShowInput{ReservationID: guaranteed.ID}); err != ErrInvalidStatus {
+		t.Fatalf("expected ErrInvalidStatus reverting twice, got %v", err)
+	}
+	if _, err := svc.RevertNoShow(ctx, admin, servicedto.RevertNoShowInput{ReservationID: auto.ID, Status: servicedto.StatusConfirmed}); err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput for a confirmed revert, got %v", err)
+	}
+	if _, err := svc.RevertNoShow(ctx, servicedto.User{ID: 1}, servicedto.RevertNoShowInput{ReservationID: auto.ID}); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+
+	summary, err := svc.NoShowSummary(ctx, admin, "")
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if summary.Auto != 2 || summary.Manual != 1 || summary.Reverted != 1 || summary.Covers != 5 || len(summary.Entries) != 3 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	for _, e := range summary.Entries {
+		switch e.Reservation.ID {
+		case guaranteed.ID:
+			if !e.Auto || e.RevertedTo == nil || *e.RevertedTo != servicedto.StatusSeated {
+				t.Fatalf("unexpected reverted entry: %+v", e)
+			}
+		case manual.ID:
+			if e.Auto || e.RevertedTo != nil {
+				t.Fatalf("unexpected manual entry: %+v", e)
+			}
+		}
+	}
+	if _, err := svc.NoShowSummary(ctx, admin, "10/01/2030"); err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput for a bad date, got %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// statusEvent returns the lifecycle event for a booking moving to status.
+func statusEvent(status string) string {
+	switch status {
+	case servicedto.StatusConfirmed:
+		return servicedto.EventReservationConfirmed
+	case servicedto.StatusCancelled:
+		return servicedto.EventReservationCancelled
+	default:
+		return servicedto.EventReservationStatusChanged
+	}
+}
+
+// DefaultLocale is used for events a locale has no templates for.
+const DefaultLocale = "en"
+
+// notificationSendTimeout bounds how long one message may take to send.
+const notificationSendTimeout = 30 * time.Second
+
+var lifecycleEvents = []string{
+	servicedto.EventReservationCreated,
+	servicedto.EventReservationConfirmed,
+	servicedto.EventReservationCancelled,
+	servicedto.EventReservationModified,
+}
+
+// TemplateNotifier renders lifecycle messages from templates on disk and
+// sends them on every channel that can reach the guest and that the guest
+// has not opted out of. Each event has a plain-text template
+// <dir>/<locale>/<event>.txt, which defines the subject as
+// {{define "subject"}}, and an HTML one <event>.html. Text messages are only
+// sent for events with an <event>.sms template that renders to something.
+type TemplateNotifier struct {
+	templates   map[string]eventTemplates
+	channels    []NotificationChannel
+	preferences PreferenceLookup
+}
+
+type eventTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+	sms  *texttemplate.Template // nil when the event is not texted
+}
+
+// NewTemplateNotifier loads the templates for locale, falling back to
+// DefaultLocale for events the locale does not translate. prefs may be nil,
+// which sends on every channel.
+func NewTemplateNotifier(dir, locale string, channels []NotificationChannel, prefs PreferenceLookup) (*TemplateNotifier, error) {
+	n := &TemplateNotifier{templates: make(map[string]eventTemplates), channels: channels, preferences: prefs}
+	for _, event := range lifecycleEvents {
+		t, err := loadLocaleTemplates(dir, locale, event)
+		if err != nil {
+			return nil, err
+		}
+		n.templates[event] = t
+	}
+	return n, nil
+}
+
+// loadLocaleTemplates loads the templates of one event for locale, or for
+// DefaultLocale when the locale does not translate it.
+func loadLocaleTemplates(dir, locale, event string) (eventTemplates, error) {
+	t, err := loadEventTemplates(dir, locale, event)
+	if errors.Is(err, fs.ErrNotExist) && locale != DefaultLocale {
+		t, err = loadEventTemplates(dir, DefaultLocale, event)
+	}
+	return t, err
+}
+
+func loadEventTemplates(dir, locale, event string) (eventTemplates, error) {
+	base := filepath.Join(dir, locale, event)
+	if _, err := os.Stat(base + ".txt"); err != nil {
+		return eventTemplates{}, err
+	}
+	text, err := texttemplate.ParseFiles(base + ".txt")
+	if err != nil {
+		return eventTemplates{}, err
+	}
+	if text.Lookup("subject") == nil {
+		return eventTemplates{}, fmt.Errorf("%s.txt does not define a subject", base)
+	}
+	html, err := htmltemplate.ParseFiles(base + ".html")
+	if err != nil {
+		return eventTemplates{}, err
+	}
+	t := eventTemplates{text: text, html: html}
+	if _, err := os.Stat(base + ".sms"); err == nil {
+		if t.sms, err = texttemplate.ParseFiles(base + ".sms"); err != nil {
+			return eventTemplates{}, err
+		}
+	}
+	return t, nil
+}
+
+// messageData is what the templates can use.
+type messageData struct {
+	Name    string
+	Code    string
+	Date    time.Time
+	Time    string
+	People  int
+	Status  string
+	Comment string
+
+	// One-click links, set in reminders when links are enabled.
+	ConfirmURL string
+	CancelURL  string
+}
+
+// render executes the templates into a message that still needs its
+// recipient. Text is empty when the event is not texted.
+func (t eventTemplates) render(data messageData) (servicedto.Notification, error) {
+	var subject, body, html strings.Builder
+	if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return servicedto.Notification{}, err
+	}
+	if err := t.text.Execute(&body, data); err != nil {
+		return servicedto.Notification{}, err
+	}
+	if err := t.html.Execute(&html, data); err != nil {
+		return servicedto.Notification{}, err
+	}
+	var sms strings.Builder
+	if t.sms != nil {
+		if err := t.sms.Execute(&sms, data); err != nil {
+			return servicedto.Notification{}, err
+		}
+	}
+	return servicedto.Notification{
+		Name:    data.Name,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimSpace(body.String()) + "\n",
+		HTML:    html.String(),
+		Text:    strings.Join(strings.Fields(sms.String()), " "),
+	}, nil
+}
+
+// Notify renders the event's message and sends it on each channel. Send
+// failures do not stop the other channels and are returned together.
+func (n *TemplateNotifier) Notify(ctx context.Context, event servicedto.ReservationEvent) error {
+	return n.notify(ctx, event, n.channels)
+}
+
+// Channels returns the names of the channels messages are sent on.
+func (n *TemplateNotifier) Channels() []string {
+	names := make([]string, 0, len(n.channels))
+	for _, ch := range n.channels {
+		names = append(names, ch.Name())
+	}
+	return names
+}
+
+// Via returns Notify for the named channel alone. Registered as one outbox
+// handler per channel, a failed send is retried on its channel without
+// sending the message again on the channels that delivered it.
+func (n *TemplateNotifier) Via(channel string) func(ctx context.Context, event servicedto.ReservationEvent) error {
+	var channels []NotificationChannel
+	for _, ch := range n.channels {
+		if ch.Name() == channel {
+			channels = append(channels, ch)
+		}
+	}
+	return func(ctx context.Context, event servicedto.ReservationEvent) error {
+		return n.notify(ctx, event, channels)
+	}
+}
+
+func (n *TemplateNotifier) notify(ctx context.Context, event servicedto.ReservationEvent, channels []NotificationChannel) error {
+	if event.Kind == servicedto.EventReservationStatusChanged {
+		return nil
+	}
+	t, ok := n.templates[event.Kind]
+	if !ok {
+		return fmt.Errorf("no template for %q", event.Kind)
+	}
+	res := event.Reservation
+	if res.User == nil {
+		return nil
+	}
+	data := messageData{
+		Name:   res.User.Name,
+		Code:   res.Code,
+		Date:   res.Date,
+		Time:   res.Time,
+		People: res.People,
+		Status: res.Status,
+	}
+	if res.Comment != nil {
+		data.Comment = *res.Comment
+	}
+
+	rendered, err := t.render(data)
+	if err != nil {
+		return err
+	}
+
+	prefs, err := preferencesFor(ctx, n.preferences, res.UserID)
+	if err != nil {
+		return err
+	}
+	var failures []error
+	for _, ch := range channels {
+		if !allowsChannel(prefs, ch.Name()) || (ch.Name() == SMSChannelName && rendered.Text == "") {
+			continue
+		}
+		to, ok := ch.Address(*res.User)
+		if !ok {
+			continue
+		}
+		msg := rendered
+		msg.ReservationID, msg.To = res.ID, to
+		sendCtx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+		err := ch.Send(sendCtx, msg)
+		cancel()
+		if err != nil {
+			failures = append(failures, fmt.Errorf("send %s message via %s: %w", event.Kind, ch.Name(), err))
+		}
+	}
+	return errors.Join(failures...)
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+const emailTemplatesDir = "../../templates/email"
+
+func TestTemplateNotifier(t *testing.T) {
+	ctx := context.Background()
+	email := &fakeChannel{name: "email"}
+	failing := &fakeChannel{name: "file", err: errors.New("disk full")}
+	n, err := NewTemplateNotifier(emailTemplatesDir, "en", []NotificationChannel{email, failing}, nil)
+	if err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+
+	res := servicedto.Reservation{
+		ID: 7, Code: "VSV-ABC123", Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 4,
+		Status: servicedto.StatusConfirmed, User: &servicedto.User{Name: "Ana", Email: "ana@example.com"},
+	}
+	// A failing channel is reported without stopping the others.
+	if err := n.Notify(ctx, servicedto.ReservationEvent{Kind: servicedto.EventReservationCreated, Reservation: res}); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expected the send failure to be returned, got %v", err)
+	}
+	if len(email.sent) != 1 || len(failing.sent) != 1 {
+		t.Fatalf("expected one message per channel, got %d %d", len(email.sent), len(failing.sent))
+	}
+	msg := email.sent[0]
+	if msg.To != "ana@example.com" || msg.Subject != "Your table is confirmed (VSV-ABC123)" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	if !strings.Contains(msg.Body, "Friday 11 January at 20:00") || !strings.Contains(msg.HTML, "<strong>VSV-ABC123</strong>") {
+		t.Fatalf("unexpected bodies: %q %q", msg.Body, msg.HTML)
+	}
+
+	// HTML templates escape what guests typed.
+	comment := "<b>window seat</b>"
+	res.Comment = &comment
+	n.Notify(ctx, servicedto.ReservationEvent{Kind: servicedto.EventReservationModified, Reservation: res})
+	if html := email.sent[1].HTML; strings.Contains(html, comment) || !strings.Contains(html, "&lt;b&gt;window seat") {
+		t.Fatalf("expected the comment to be escaped, got %q", html)
+	}
+
+	// Guests are not told about seating or no-shows.
+	res.Status = servicedto.StatusSeated
+	if err := n.Notify(ctx, servicedto.ReservationEvent{Kind: servicedto.EventReservationStatusChanged, Reservation: res}); err != nil || len(email.sent) != 2 {
+		t.Fatalf("expected no message for a status change, got %d %v", len(email.sent), err)
+	}
+}
+
+func TestTemplateNotifierLocales(t *testing.T) {
+	ctx := context.Background()
+	res := servicedto.Reservation{
+		Code: "VSV-ABC123", Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 2,
+		Status: servicedto.StatusCancelled, User: &servicedto.User{Name: "Ana", Email: "ana@example.com"},
+	}
+	for locale, subject := range map[string]string{
+		"it": "La tua prenotazione è stata cancellata (VSV-ABC123)",
+		"fr": "Your reservation has been cancelled (VSV-ABC123)", // not translated
+	} {
+		email := &fakeChannel{name: "email"}
+		n, err := NewTemplateNotifier(emailTemplatesDir, locale, []NotificationChannel{email}, nil)
+		if err != nil {
+			t.Fatalf("%s: load templates: %v", locale, err)
+		}
+		n.Notify(ctx, servicedto.ReservationEvent{Kind: servicedto.EventReservationCancelled, Reservation: res})
+		if len(email.sent) != 1 || email.sent[0].Subject != subject {
+			t.Fatalf("%s: unexpected messages: %+v", locale, email.sent)
+		}
+	}
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "en"), 0o755)
+	for _, event := range lifecycleEvents {
+		os.WriteFile(filepath.Join(dir, "en", event+".txt"), []byte("no subject"), 0o644)
+		os.WriteFile(filepath.Join(dir, "en", event+".html"), []byte("<p>hi</p>"), 0o644)
+	}
+	if _, err := NewTemplateNotifier(dir, "en", nil, nil); err == nil {
+		t.Fatal("expected templates without a subject to be rejected")
+	}
+}
+
+func TestTemplateNotifierRetriesOnlyFailedChannel(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserClient()
+	user, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com"})
+	client := newFakeReservationClient()
+	client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: user.ID, Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+
+	email := &fakeChannel{name: "email"}
+	file := &fakeChannel{name: "file", err: errors.New("disk full")}
+	n, err := NewTemplateNotifier(emailTemplatesDir, "en", []NotificationChannel{email, file}, nil)
+	if err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+	d := NewOutboxDispatcher(newFakeOutboxClient(client.events...))
+	lifecycle := NewLifecycleEvents(client, users)
+	for _, channel := range n.Channels() {
+		d.Register("guest-messages-"+channel, lifecycle.Handler(n.Via(channel), false))
+	}
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+
+	if result, _ := d.Dispatch(ctx); result.Retrying != 1 {
+		t.Fatalf("expected the event retried, got %+v", result)
+	}
+	file.err = nil
+	now = now.Add(time.Hour)
+	if result, _ := d.Dispatch(ctx); result.Dispatched != 1 {
+		t.Fatalf("expected the retry to succeed, got %+v", result)
+	}
+	if len(email.sent) != 1 || len(file.sent) != 2 {
+		t.Fatalf("expected only the failed channel resent, got %d emails and %d file writes", len(email.sent), len(file.sent))
+	}
+}
+
+func TestTemplateNotifierSMS(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserClient()
+	email := &fakeChannel{name: "email"}
+	sender := &fakeSMSSender{}
+	n, err := NewTemplateNotifier(emailTemplatesDir, "en", []NotificationChannel{email, NewSMSChannel(sender)}, users)
+	if err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+
+	phone := "+34600123456"
+	res := servicedto.Reservation{
+		UserID: 1, Code: "VSV-ABC123", Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 4,
+		Status: servicedto.StatusPending, User: &servicedto.User{ID: 1, Name: "Ana", Email: "ana@example.com", Phone: &phone},
+	}
+	notify := func(kind string) {
+		if err := n.Notify(ctx, servicedto.ReservationEvent{Kind: kind, Reservation: res}); err != nil {
+			t.Fatalf("notify %s: %v", kind, err)
+		}
+	}
+
+	// Only confirmations are texted.
+	notify(servicedto.EventReservationCreated)
+	notify(servicedto.EventReservationModified)
+	if len(sender.sent) != 0 || len(email.sent) != 2 {
+		t.Fatalf("expected emails only, got %d texts and %d emails", len(sender.sent), len(email.sent))
+	}
+	res.Status = servicedto.StatusConfirmed
+	notify(servicedto.EventReservationConfirmed)
+	want := "+34600123456: Vesuvio: your table for 4 on Fri 11 Jan at 20:00 is confirmed. Code VSV-ABC123."
+	if len(sender.sent) != 1 || sender.sent[0] != want {
+		t.Fatalf("expected %q, got %q", want, sender.sent)
+	}
+
+	// Opting out of a channel stops it; the other keeps going.
+	users.preferences[1] = servicedto.NotificationPreferences{Email: true, SMS: false, Reminders: true}
+	notify(servicedto.EventReservationConfirmed)
+	if len(sender.sent) != 1 || len(email.sent) != 4 {
+		t.Fatalf("expected the opt-out to stop texts only, got %d texts and %d emails", len(sender.sent), len(email.sent))
+	}
+}
+
+func TestReservationNotifications(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	users := newFakeUserClient()
+	user, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com", PasswordHash: "hash"})
+	client := newFakeReservationClient()
+	svc := NewReservationService(client, WithGuestClient(users))
+
+	out, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: user.ID, Date: "2030-01-11", Time: "20:00", People: 2})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	id := out.Reservation.ID
+	svc.ConfirmReservation(ctx, admin, id)
+	svc.ConfirmReservation(ctx, admin, id) // already confirmed: no second message
+	svc.AdminCancelReservation(ctx, admin, id)
+	walkIn, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: user.ID, Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 2,
+		Status: servicedto.StatusSeated, Channel: servicedto.ChannelWalkIn,
+	})
+	svc.AdminCancelReservation(ctx, admin, walkIn.ID) // walk-ins are not messaged
+
+	notifier := &recordingNotifier{}
+	dispatchLifecycle(t, client, users, notifier.Notify, false)
+	want := []string{servicedto.EventReservationCreated, servicedto.EventReservationConfirmed, servicedto.EventReservationCancelled}
+	if got := notifier.kinds(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+	if u := notifier.events[0].Reservation.User; u == nil || u.Email != "ana@example.com" {
+		t.Fatalf("expected the guest to be attached, got %+v", u)
+	}
+}
+
+// recordingNotifier records lifecycle events.
+type recordingNotifier struct {
+	events []servicedto.ReservationEvent
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event servicedto.ReservationEvent) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func (n *recordingNotifier) kinds() []string {
+	kinds := make([]string, 0, len(n.events))
+	for _, e := range n.events {
+		kinds = append(kinds, e.Kind)
+	}
+	return kinds
+}
+
+// fakeSMSSender records texts as "to: body".
+type fakeSMSSender struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (s *fakeSMSSender) SendSMS(ctx context.Context, to, body string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, to+": "+body)
+	return nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// OutboxClient abstracts the outbox the reservation client writes domain
+// events to.
+type OutboxClient interface {
+	ClaimDueEvents(ctx context.Context, params servicedto.ClaimEventsParams) ([]servicedto.DomainEvent, error)
+	CompleteEvent(ctx context.Context, id uint, claim string, at time.Time) error
+	RetryEvent(ctx context.Context, params servicedto.RetryEventParams) error
+}
+
+// EventHandler reacts to a domain event. Delivery is at least once, so
+// handlers must cope with seeing an event again.
+type EventHandler func(ctx context.Context, event servicedto.DomainEvent) error
+
+// Outbox retry limits: a failing event is retried after 5s, doubling up to
+// 10m between attempts, and given up on after 12 attempts, about an hour.
+const (
+	outboxBatchSize   = 100
+	outboxMaxAttempts = 12
+	outboxBackoff     = 5 * time.Second
+	outboxMaxBackoff  = 10 * time.Minute
+	// outboxClaimTimeout is how long a dispatch run holds the events it
+	// claimed; events left by a run that died are retried after it.
+	outboxClaimTimeout = 5 * time.Minute
+)
+
+type namedHandler struct {
+	name    string
+	handler EventHandler
+}
+
+// OutboxDispatcher drains the outbox, handing each event to every
+// registered handler in order. A handler that fails has the event retried
+// with backoff; handlers that already succeeded are not run again.
+type OutboxDispatcher struct {
+	client   OutboxClient
+	handlers []namedHandler
+	now      func() time.Time
+}
+
+func NewOutboxDispatcher(client OutboxClient) *OutboxDispatcher {
+	return &OutboxDispatcher{client: client, now: time.Now}
+}
+
+// Register adds a handler. The name identifies it in the outbox, so it must
+// be unique and stay the same across restarts.
+func (d *OutboxDispatcher) Register(name string, handler EventHandler) {
+	d.handlers = append(d.handlers, namedHandler{name: name, handler: handler})
+}
+
+// Dispatch claims the due events and hands them to the handlers.
+func (d *OutboxDispatcher) Dispatch(ctx context.Context) (*servicedto.OutboxResult, error) {
+	result := &servicedto.OutboxResult{}
+	claim, err := randomHex(16)
+	if err != nil {
+		return result, err
+	}
+	now := d.now()
+	events, err := d.client.ClaimDueEvents(ctx, servicedto.ClaimEventsParams{
+		Claim: claim,
+		Now:   now,
+		Until: now.Add(outboxClaimTimeout),
+		Limit: outboxBatchSize,
+	})
+	if err != nil {
+		return result, err
+	}
+	for _, event := range events {
+		if err := d.dispatch(ctx, event, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (d *OutboxDispatcher) dispatch(ctx context.Context, event servicedto.DomainEvent, result *servicedto.OutboxResult) error {
+	handled := slices.Clone(event.Handled)
+	var failures []error
+	for _, h := range d.handlers {
+		if slices.Contains(handled, h.name) {
+			continue
+		}
+		if err := h.handler(ctx, event); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", h.name, err))
+			continue
+		}
+		handled = append(handled, h.name)
+	}
+	if len(failures) == 0 {
+		result.Dispatched++
+		return d.client.CompleteEvent(ctx, event.ID, event.Claim, d.now())
+	}
+
+	params := servicedto.RetryEventParams{ID: event.ID, Claim: event.Claim, Handled: handled, Error: errors.Join(failures...).Error()}
+	if event.Attempts+1 < outboxMaxAttempts {
+		next := d.now().Add(exponentialBackoff(outboxBackoff, outboxMaxBackoff, event.Attempts+1))
+		params.NextAttemptAt = &next
+		result.Retrying++
+	} else {
+		log.Printf("outbox: giving up on %s event %d for reservation %d: %s", event.Type, event.ID, event.ReservationID, params.Error)
+		result.Failed++
+	}
+	return d.client.RetryEvent(ctx, params)
+}
+
+// exponentialBackoff is the wait after the given number of failed attempts:
+// base, doubling each time, at most limit.
+func exponentialBackoff(base, limit time.Duration, attempts int) time.Duration {
+	wait := base
+	for i := 1; i < attempts && wait < limit; i++ {
+		wait *= 2
+	}
+	return min(wait, limit)
+}
+
+// LifecycleEvents turns outbox events into the lifecycle events guests and
+// webhook subscribers are told about.
+type LifecycleEvents struct {
+	reservationClient ReservationClient
+	guestClient       GuestClient
+}
+
+func NewLifecycleEvents(resClient ReservationClient, guestClient GuestClient) *LifecycleEvents {
+	return &LifecycleEvents{reservationClient: resClient, guestClient: guestClient}
+}
+
+// Handler returns an outbox handler that passes lifecycle events to fn, with
+// the reservation as it was after the change and its guest when known.
+// Walk-ins are left out unless includeWalkIns is set.
+func (l *LifecycleEvents) Handler(fn func(ctx context.Context, event servicedto.ReservationEvent) error, includeWalkIns bool) EventHandler {
+	return func(ctx context.Context, event servicedto.DomainEvent) error {
+		lifecycle, err := l.lifecycleEvent(ctx, event)
+		if err != nil || lifecycle == nil {
+			return err
+		}
+		if !includeWalkIns && lifecycle.Reservation.Channel == servicedto.ChannelWalkIn {
+			return nil
+		}
+		return fn(ctx, *lifecycle)
+	}
+}
+
+func (l *LifecycleEvents) lifecycleEvent(ctx context.Context, event servicedto.DomainEvent) (*servicedto.ReservationEvent, error) {
+	var kind string
+	switch event.Type {
+	case servicedto.DomainEventReservationCreated:
+		kind = servicedto.EventReservationCreated
+	case servicedto.DomainEventStatusChanged:
+		kind = statusEvent(event.ToStatus)
+	case servicedto.DomainEventReservationModified:
+		kind = servicedto.EventReservationModified
+	}
+	if kind == "" {
+		return nil, nil
+	}
+
+	res, err := l.reservationClient.GetReservationByID(ctx, event.ReservationID)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	// The booking may have moved on since; report the status the event
+	// left it in.
+	res.Status = event.ToStatus
+	if res.User == nil && l.guestClient != nil {
+		if res.User, err = l.guestClient.GetUserByID(ctx, res.UserID); err != nil {
+			return nil, err
+		}
+	}
+	return &servicedto.ReservationEvent{ID: event.ID, Kind: kind, Reservation: *res}, nil
+}
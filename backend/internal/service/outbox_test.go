@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestOutboxDispatch(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2030, 1, 11, 20, 0, 0, 0, time.UTC)
+	outbox := newFakeOutboxClient(
+		servicedto.DomainEvent{ID: 1, Type: servicedto.DomainEventReservationCreated, ReservationID: 1},
+		servicedto.DomainEvent{ID: 2, Type: servicedto.DomainEventStatusChanged, ReservationID: 1},
+	)
+	d := NewOutboxDispatcher(outbox)
+	d.now = func() time.Time { return now }
+
+	var first, second []uint
+	var secondErr error
+	d.Register("first", func(ctx context.Context, event servicedto.DomainEvent) error {
+		first = append(first, event.ID)
+		return nil
+	})
+	d.Register("second", func(ctx context.Context, event servicedto.DomainEvent) error {
+		second = append(second, event.ID)
+		return secondErr
+	})
+
+	// A failing handler keeps the event for a later attempt; the handler
+	// that succeeded is not run again.
+	secondErr = errors.New("smtp down")
+	result, err := d.Dispatch(ctx)
+	if err != nil || result.Retrying != 2 || result.Dispatched != 0 {
+		t.Fatalf("expected both events to be retried, got %+v %v", result, err)
+	}
+	if e := outbox.events[0]; e.Attempts != 1 || !slices.Equal(e.Handled, []string{"first"}) || !outbox.next[1].Equal(now.Add(5*time.Second)) {
+		t.Fatalf("unexpected retry state: %+v next %s", e, outbox.next[1])
+	}
+	if result, _ := d.Dispatch(ctx); result.Dispatched+result.Retrying != 0 {
+		t.Fatalf("expected nothing due before the backoff, got %+v", result)
+	}
+
+	now = now.Add(5 * time.Second)
+	secondErr = nil
+	result, err = d.Dispatch(ctx)
+	if err != nil || result.Dispatched != 2 {
+		t.Fatalf("expected both events to be dispatched, got %+v %v", result, err)
+	}
+	if !slices.Equal(first, []uint{1, 2}) || !slices.Equal(second, []uint{1, 2, 1, 2}) {
+		t.Fatalf("unexpected handler calls: %v %v", first, second)
+	}
+	if outbox.status[1] != servicedto.OutboxDispatched || outbox.status[2] != servicedto.OutboxDispatched {
+		t.Fatalf("expected the events to be done, got %v", outbox.status)
+	}
+}
+
+func TestOutboxDispatchGivesUp(t *testing.T) {
+	ctx := context.Background()
+	outbox := newFakeOutboxClient(servicedto.DomainEvent{ID: 1, Type: servicedto.DomainEventReservationCreated, Attempts: outboxMaxAttempts - 1})
+	d := NewOutboxDispatcher(outbox)
+	d.Register("broken", func(ctx context.Context, event servicedto.DomainEvent) error {
+		return errors.New("still broken")
+	})
+
+	result, err := d.Dispatch(ctx)
+	if err != nil || result.Failed != 1 {
+		t.Fatalf("expected the event to be given up on, got %+v %v", result, err)
+	}
+	if outbox.status[1] != servicedto.OutboxFailed || outbox.errors[1] != "broken: still broken" {
+		t.Fatalf("unexpected outbox state: %v %v", outbox.status, outbox.errors)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{1: 5 * time.Second, 2: 10 * time.Second, 4: 40 * time.Second, 20: 10 * time.Minute} {
+		if got := exponentialBackoff(5*time.Second, 10*time.Minute, attempts); got != want {
+			t.Fatalf("attempt %d: expected %s, got %s", attempts, want, got)
+		}
+	}
+}
+
+func TestLifecycleEvents(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserClient()
+	user, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com"})
+	client := newFakeReservationClient()
+	res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: user.ID, Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	client.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{ID: res.ID, Status: servicedto.StatusConfirmed})
+	client.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{ID: res.ID, Status: servicedto.StatusSeated})
+
+	notifier := &recordingNotifier{}
+	dispatchLifecycle(t, client, users, notifier.Notify, false)
+	want := []string{servicedto.EventReservationCreated, servicedto.EventReservationConfirmed, servicedto.EventReservationStatusChanged}
+	if !slices.Equal(notifier.kinds(), want) {
+		t.Fatalf("expected events %v, got %v", want, notifier.kinds())
+	}
+	if seated := notifier.events[2].Reservation; seated.Status != servicedto.StatusSeated {
+		t.Fatalf("expected the seating reported, got %+v", seated)
+	}
+	// Each event reports the status the change left the booking in, even
+	// though it has moved on since.
+	if created := notifier.events[0].Reservation; created.Status != servicedto.StatusPending || created.User == nil || created.User.Email != "ana@example.com" {
+		t.Fatalf("unexpected created event: %+v", created)
+	}
+}
+
+// dispatchLifecycle hands the domain events the fake client recorded to fn
+// as lifecycle events, and fails the test if any of them fail.
+func dispatchLifecycle(t *testing.T, client *fakeReservationClient, users GuestClient, fn func(context.Context, servicedto.ReservationEvent) error, includeWalkIns bool) {
+	t.Helper()
+	d := NewOutboxDispatcher(newFakeOutboxClient(client.events...))
+	d.Register("lifecycle", NewLifecycleEvents(client, users).Handler(fn, includeWalkIns))
+	result, err := d.Dispatch(context.Background())
+	if err != nil || result.Retrying+result.Failed > 0 {
+		t.Fatalf("dispatch: %+v %v", result, err)
+	}
+}
+
+// fakeOutboxClient keeps events in memory, in insertion order.
+type fakeOutboxClient struct {
+	events []servicedto.DomainEvent
+	status map[uint]string
+	next   map[uint]time.Time
+	errors map[uint]string
+}
+
+func newFakeOutboxClient(events ...servicedto.DomainEvent) *fakeOutboxClient {
+	return &fakeOutboxClient{
+		events: slices.Clone(events),
+		status: make(map[uint]string),
+		next:   make(map[uint]time.Time),
+		errors: make(map[uint]string),
+	}
+}
+
+func (f *fakeOutboxClient) ListEvents(ctx context.Context, params servicedto.ListEventsParams) ([]servicedto.DomainEvent, error) {
+	all := slices.Clone(f.events)
+	slices.SortFunc(all, func(a, b servicedto.DomainEvent) int { return int(a.ID) - int(b.ID) })
+	var events []servicedto.DomainEvent
+	for _, e := range all {
+		if len(events) < params.Limit && e.ID > params.AfterID && !e.CreatedAt.Before(params.Since) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (f *fakeOutboxClient) ClaimDueEvents(ctx context.Context, params servicedto.ClaimEventsParams) ([]servicedto.DomainEvent, error) {
+	var due []servicedto.DomainEvent
+	for i, e := range f.events {
+		if len(due) == params.Limit {
+			break
+		}
+		if f.status[e.ID] == "" && !f.next[e.ID].After(params.Now) {
+			f.events[i].Claim = params.Claim
+			f.next[e.ID] = params.Until
+			due = append(due, f.events[i])
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeOutboxClient) claimed(id uint, claim string) bool {
+	for _, e := range f.events {
+		if e.ID == id {
+			return e.Claim == claim
+		}
+	}
+	return false
+}
+
+func (f *fakeOutboxClient) CompleteEvent(ctx context.Context, id uint, claim string, at time.Time) error {
+	if f.claimed(id, claim) {
+		f.status[id] = servicedto.OutboxDispatched
+	}
+	return nil
+}
+
+func (f *fakeOutboxClient) RetryEvent(ctx context.Context, params servicedto.RetryEventParams) error {
+	if !f.claimed(params.ID, params.Claim) {
+		return nil
+	}
+	for i := range f.events {
+		if f.events[i].ID == params.ID {
+			f.events[i].Attempts++
+			f.events[i].Handled = params.Handled
+		}
+	}
+	f.errors[params.ID] = params.Error
+	if params.NextAttemptAt == nil {
+		f.status[params.ID] = servicedto.OutboxFailed
+	} else {
+		f.next[params.ID] = *params.NextAttemptAt
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// PaymentProvider takes deposits and card guarantees. Implementations wrap
+// a real processor; the client package has an in-process fake.
+type PaymentProvider interface {
+	// CreatePayment starts a checkout the guest completes at CheckoutURL.
+	CreatePayment(ctx context.Context, params servicedto.ProviderPaymentParams) (*servicedto.ProviderPayment, error)
+	// Capture charges an authorised card guarantee.
+	Capture(ctx context.Context, ref string) error
+	// Void cancels an unpaid checkout or releases an authorised hold.
+	Void(ctx context.Context, ref string) error
+	// Refund returns a captured deposit in full.
+	Refund(ctx context.Context, ref string) error
+	// ParseWebhook verifies and decodes a payment outcome notification.
+	ParseWebhook(payload []byte, signature string) (*servicedto.PaymentEvent, error)
+}
+
+// PaymentClient abstracts payment persistence.
+type PaymentClient interface {
+	CreatePayment(ctx context.Context, params servicedto.CreatePaymentParams) (*servicedto.Payment, error)
+	GetPaymentByReservation(ctx context.Context, reservationID uint) (*servicedto.Payment, error)
+	GetPaymentByProviderRef(ctx context.Context, ref string) (*servicedto.Payment, error)
+	UpdatePaymentStatus(ctx context.Context, id uint, from, status string) (*servicedto.Payment, error)
+	MarkSettlementDue(ctx context.Context, reservationID uint, outcome string) (*servicedto.Payment, error)
+	ClearSettlement(ctx context.Context, id uint, outcome string) error
+	ListDueSettlements(ctx context.Context, limit int) ([]servicedto.Payment, error)
+}
+
+// errPaymentChanged fails a settlement whose payment a webhook moved while
+// it ran, so it stays due and is retried from the payment's new status.
+var errPaymentChanged = errors.New("payment changed during settlement")
+
+// settlementBatchSize bounds one SettleDuePayments run.
+const settlementBatchSize = 100
+
+// Deposit policy defaults.
+const (
+	DefaultDepositPerPerson = 2000
+	DefaultDepositCurrency  = "eur"
+)
+
+// What happens to a payment when its reservation ends.
+const (
+	settleRelease = "release" // the guest owes nothing
+	settleForfeit = "forfeit" // the restaurant keeps the money
+	settleArrived = "arrived" // the party came
+)
+
+// WithPayments requires deposits or card guarantees on online bookings
+// that match the policy, and settles them when bookings end.
+func WithPayments(provider PaymentProvider, paymentClient PaymentClient, policy servicedto.DepositPolicy) ReservationOption {
+	return func(s *ReservationService) {
+		s.paymentProvider = provider
+		s.paymentClient = paymentClient
+		s.deposits = normalizeDepositPolicy(policy)
+	}
+}
+
+func normalizeDepositPolicy(policy servicedto.DepositPolicy) servicedto.DepositPolicy {
+	if policy.Kind != servicedto.PaymentKindDeposit {
+		policy.Kind = servicedto.PaymentKindGuarantee
+	}
+	if policy.AmountPerPerson <= 0 {
+		policy.AmountPerPerson = DefaultDepositPerPerson
+	}
+	policy.Currency = strings.ToLower(strings.TrimSpace(policy.Currency))
+	if policy.Currency == "" {
+		policy.Currency = DefaultDepositCurrency
+	}
+	return policy
+}
+
+// depositAmount returns what an online booking must pay up front, or 0.
+// Guests restricted to deposits always pay.
+func (s *ReservationService) depositAmount(date time.Time, people int, restricted bool) int64 {
+	if s.paymentProvider == nil {
+		return 0
+	}
+	p := s.deposits
+	required := restricted ||
+		(p.MinPeople > 0 && people >= p.MinPeople) ||
+		slices.Contains(p.PeakDays, date.Weekday()) ||
+		slices.Contains(p.PeakDates, date.Format("2006-01-02"))
+	if !required {
+		return 0
+	}
+	return p.AmountPerPerson * int64(people)
+}
+
+// requestPayment opens a checkout for a reservation awaiting payment. If
+// the provider completes it straight away, the reservation moves on to
+// pending.
+func (s *ReservationService) requestPayment(ctx context.Context, res *servicedto.Reservation, amount int64) error {
+	capture := s.deposits.Kind == servicedto.PaymentKindDeposit
+	created, err := s.paymentProvider.CreatePayment(ctx, servicedto.ProviderPaymentParams{
+		Reference:   res.Code,
+		Amount:      amount,
+		Currency:    s.deposits.Currency,
+		Capture:     capture,
+		Description: fmt.Sprintf("%s for reservation %s on %s at %s", s.deposits.Kind, res.Code, res.Date.Format("2006-01-02"), res.Time),
+	})
+	if err != nil {
+		return err
+	}
+
+	status := servicedto.PaymentPending
+	if created.Completed {
+		status = paidStatus(s.deposits.Kind)
+	}
+	payment, err := s.paymentClient.CreatePayment(ctx, servicedto.CreatePaymentParams{
+		ReservationID: res.ID,
+		Kind:          s.deposits.Kind,
+		Amount:        amount,
+		Currency:      s.deposits.Currency,
+		Status:        status,
+		ProviderRef:   created.Ref,
+		CheckoutURL:   created.CheckoutURL,
+	})
+	if err != nil {
+		return err
+	}
+	res.Payment = payment
+	if created.Completed {
+		return s.paymentSucceeded(ctx, res)
+	}
+	return nil
+}
+
+// HandlePaymentWebhook applies a payment outcome reported by the provider.
+// Outcomes for payments that are no longer pending are ignored, so
+// redelivered webhooks are harmless.
+func (s *ReservationService) HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error {
+	if s.paymentProvider == nil {
+		return ErrPaymentsDisabled
+	}
+	event, err := s.paymentProvider.ParseWebhook(payload, signature)
+	if err != nil {
+		return ErrInvalidWebhook
+	}
+	payment, err := s.paymentClient.GetPaymentByProviderRef(ctx, event.ProviderRef)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return ErrPaymentNotFound
+	}
+
+	status := servicedto.PaymentFailed
+	if event.Succeeded {
+		status = paidStatus(payment.Kind)
+	}
+	updated, err := s.paymentClient.UpdatePaymentStatus(ctx, payment.ID, servicedto.PaymentPending, status)
+	if err != nil || updated == nil {
+		return err
+	}
+
+	res, err := s.reservationClient.GetReservationByID(ctx, payment.ReservationID)
+	if err != nil || res == nil {
+		return err
+	}
+	res.Payment = updated
+	if event.Succeeded {
+		return s.paymentSucceeded(ctx, res)
+	}
+	if res.Status != servicedto.StatusAwaitingPayment {
+		return nil
+	}
+	// A booking cancelled or expired since it was read stays as it is.
+	reason := "payment failed"
+	_, err = s.reservationClient.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{
+		ID:     res.ID,
+		Status: servicedto.StatusCancelled,
+		From:   servicedto.StatusAwaitingPayment,
+		Actor:  servicedto.StatusActor{Source: servicedto.StatusSourceSystem, Reason: &reason},
+	})
+	return err
+}
+
+// paymentSucceeded releases a reservation awaiting payment into the normal
+// review flow.
+func (s *ReservationService) paymentSucceeded(ctx context.Context, res *servicedto.Reservation) error {
+	if res.Status != servicedto.StatusAwaitingPayment {
+		return nil
+	}
+	// Only a booking still awaiting payment moves on: a late or redelivered
+	// webhook must not revive one that was cancelled or expired meanwhile.
+	reason := res.Payment.Kind + " received"
+	updated, err := s.reservationClient.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{
+		ID:     res.ID,
+		Status: servicedto.StatusPending,
+		From:   servicedto.StatusAwaitingPayment,
+		Actor:  servicedto.StatusActor{Source: servicedto.StatusSourceSystem, Reason: &reason},
+	})
+	if err != nil {
+		return err
+	}
+	if updated != nil {
+		res.Status = updated.Status
+		res.UpdatedAt = updated.UpdatedAt
+	}
+	return nil
+}
+
+// settlementFor returns how moving a reservation to status settles its
+// payment, or "" when the booking has not ended.
+func settlementFor(status string) string {
+	switch status {
+	case servicedto.StatusCancelled:
+		return settleRelease
+	case servicedto.StatusNoShow:
+		return settleForfeit
+	case servicedto.StatusSeated:
+		return settleArrived
+	default:
+		return ""
+	}
+}
+
+// settlePayment refunds, releases or captures the reservation's payment for
+// the way the booking ended, before the status change is stored, so a
+// provider failure leaves the reservation untouched and retryable.
+func (s *ReservationService) settlePayment(ctx context.Context, reservationID uint, outcome string) error {
+	if s.paymentClient == nil || outcome == "" {
+		return nil
+	}
+	payment, err := s.paymentClient.GetPaymentByReservation(ctx, reservationID)
+	if err != nil || payment == nil {
+		return err
+	}
+	return s.settle(ctx, *payment, outcome)
+}
+
+// settleEnded settles the payment of a reservation whose status change was
+// just stored, so money only moves for changes that happened. The
+// settlement is recorded as due first and stays due for SettleDuePayments
+// when the provider fails; the failure is logged rather than returned, as
+// the booking has changed either way.
+func (s *ReservationService) settleEnded(ctx context.Context, reservationID uint, outcome string) {
+	if s.paymentClient == nil || outcome == "" {
+		return
+	}
+	payment, err := s.paymentClient.MarkSettlementDue(ctx, reservationID, outcome)
+	if err == nil && payment != nil {
+		err = s.settleDue(ctx, *payment)
+	}
+	if err != nil {
+		log.Printf("payments: settling reservation %d (%s) failed, will retry: %v", reservationID, outcome, err)
+	}
+}
+
+// SettleDuePayments retries the settlements that failed after their
+// reservation's status changed.
+func (s *ReservationService) SettleDuePayments(ctx context.Context) (*servicedto.SettlementResult, error) {
+	result := &servicedto.SettlementResult{}
+	if s.paymentClient == nil {
+		return result, nil
+	}
+	due, err := s.paymentClient.ListDueSettlements(ctx, settlementBatchSize)
+	if err != nil {
+		return result, err
+	}
+	for _, payment := range due {
+		if err := s.settleDue(ctx, payment); err != nil {
+			log.Printf("payments: settling reservation %d (%s) failed: %v", payment.ReservationID, payment.Settlement, err)
+			result.Failed++
+			continue
+		}
+		result.Settled++
+	}
+	return result, nil
+}
+
+func (s *ReservationService) settleDue(ctx context.Context, payment servicedto.Payment) error {
+	if err := s.settle(ctx, payment, payment.Settlement); err != nil {
+		return err
+	}
+	return s.paymentClient.ClearSettlement(ctx, payment.ID, payment.Settlement)
+}
+
+func (s *ReservationService) settle(ctx context.Context, payment servicedto.Payment, outcome string) error {
+	var status string
+	var call func(context.Context, string) error
+	switch {
+	case payment.Status == servicedto.PaymentPending:
+		status, call = servicedto.PaymentReleased, s.paymentProvider.Void
+	case payment.Status == servicedto.PaymentAuthorized && outcome == settleForfeit:
+		status, call = servicedto.PaymentCaptured, s.paymentProvider.Capture
+	case payment.Status == servicedto.PaymentAuthorized:
+		status, call = servicedto.PaymentReleased, s.paymentProvider.Void
+	case payment.Status == servicedto.PaymentCaptured && payment.Kind == servicedto.PaymentKindDeposit && outcome == settleRelease:
+		status, call = servicedto.PaymentRefunded, s.paymentProvider.Refund
+	default:
+		// Kept deposits, captured guarantees and finished payments stay as
+		// they are.
+		return nil
+	}
+	if err := call(ctx, payment.ProviderRef); err != nil {
+		return err
+	}
+	updated, err := s.paymentClient.UpdatePaymentStatus(ctx, payment.ID, payment.Status, status)
+	if err == nil && updated == nil {
+		return errPaymentChanged
+	}
+	return err
+}
+
+// cancelSettlement decides what a guest's own cancellation does to the
+// payment: late cancellations forfeit it when the policy says so.
+func (s *ReservationService) cancelSettlement(res servicedto.Reservation) string {
+	if s.deposits.ChargeLateCancels && s.isLateCancel(res) {
+		return settleForfeit
+	}
+	return settleRelease
+}
+
+func paidStatus(kind string) string {
+	if kind == servicedto.PaymentKindDeposit {
+		return servicedto.PaymentCaptured
+	}
+	return servicedto.PaymentAuthorized
+}
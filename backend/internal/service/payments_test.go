@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func newDepositTestService(policy servicedto.DepositPolicy) (*ReservationService, *fakeReservationClient, *fakePaymentProvider, *fakePaymentClient) {
+	client := newFakeReservationClient()
+	provider := &fakePaymentProvider{states: make(map[string]string)}
+	payments := &fakePaymentClient{}
+	svc := NewReservationService(client, WithPayments(provider, payments, policy))
+	svc.now = func() time.Time { return time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC) }
+	return svc, client, provider, payments
+}
+
+func TestDepositRules(t *testing.T) {
+	svc, _, _, payments := newDepositTestService(servicedto.DepositPolicy{
+		Kind:            servicedto.PaymentKindDeposit,
+		AmountPerPerson: 1500,
+		MinPeople:       6,
+		PeakDays:        []time.Weekday{time.Saturday},
+		PeakDates:       []string{"2025-12-31"},
+	})
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		date   string
+		people int
+		amount int64
+	}{
+		{"2025-12-03", 2, 0},    // Wednesday, small party
+		{"2025-12-03", 6, 9000}, // large party
+		{"2025-12-06", 2, 3000}, // Saturday
+		{"2025-12-31", 4, 6000}, // peak date
+		{"2025-12-04", 5, 0},    // Thursday, just under the party size
+	} {
+		out, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 1, Date: tc.date, Time: "20:00", People: tc.people})
+		if err != nil {
+			t.Fatalf("%s for %d: %v", tc.date, tc.people, err)
+		}
+		res := out.Reservation
+		if tc.amount == 0 {
+			if res.Status != servicedto.StatusPending || res.Payment != nil {
+				t.Fatalf("%s for %d: expected no deposit, got %+v", tc.date, tc.people, res)
+			}
+			continue
+		}
+		if res.Status != servicedto.StatusAwaitingPayment || res.Payment == nil || res.Payment.Amount != tc.amount ||
+			res.Payment.Status != servicedto.PaymentPending || res.Payment.Currency != "eur" || res.Payment.CheckoutURL == "" {
+			t.Fatalf("%s for %d: expected deposit of %d, got %+v %+v", tc.date, tc.people, tc.amount, res, res.Payment)
+		}
+	}
+	if len(payments.payments) != 3 {
+		t.Fatalf("expected three payments, got %d", len(payments.payments))
+	}
+}
+
+func TestDepositRequiredByRestriction(t *testing.T) {
+	client := newFakeReservationClient()
+	restrictions := newFakeRestrictionClient()
+	restrictions.SaveRestriction(context.Background(), servicedto.SaveRestrictionParams{UserID: 1, Level: servicedto.RestrictionDeposit, Reason: "3 incidents"})
+	provider := &fakePaymentProvider{states: make(map[string]string)}
+	svc := NewReservationService(client,
+		WithSanctions(restrictions, servicedto.SanctionPolicy{}),
+		WithPayments(provider, &fakePaymentClient{}, servicedto.DepositPolicy{}),
+	)
+
+	out, err := svc.CreateReservation(context.Background(), servicedto.CreateReservationInput{UserID: 1, Date: "2025-12-03", Time: "20:00", People: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Reservation.Status != servicedto.StatusAwaitingPayment || out.Reservation.Payment.Kind != servicedto.PaymentKindGuarantee ||
+		out.Reservation.Payment.Amount != 2*DefaultDepositPerPerson {
+		t.Fatalf("expected default guarantee for restricted guest, got %+v", out.Reservation)
+	}
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	svc, client, provider, payments := newDepositTestService(servicedto.DepositPolicy{MinPeople: 2})
+	ctx := context.Background()
+
+	paid, _ := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 1, Date: "2025-12-03", Time: "20:00", People: 2})
+	failed, _ := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 1, Date: "2025-12-04", Time: "20:00", People: 2})
+
+	if err := svc.HandlePaymentWebhook(ctx, []byte("ok:"+paid.Reservation.Payment.ProviderRef), "bad"); err != ErrInvalidWebhook {
+		t.Fatalf("expected ErrInvalidWebhook, got %v", err)
+	}
+	if err := svc.HandlePaymentWebhook(ctx, []byte("ok:missing"), "signed"); err != ErrPaymentNotFound {
+		t.Fatalf("expected ErrPaymentNotFound, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ { // redelivery is harmless
+		if err := svc.HandlePaymentWebhook(ctx, []byte("ok:"+paid.Reservation.Payment.ProviderRef), "signed"); err != nil {
+			t.Fatalf("webhook: %v", err)
+		}
+	}
+	if res := client.reservations[paid.Reservation.ID]; res.Status != servicedto.StatusPending {
+		t.Fatalf("expected pending after payment, got %s", res.Status)
+	}
+	if p := payments.payments[0]; p.Status != servicedto.PaymentAuthorized {
+		t.Fatalf("expected authorised guarantee, got %s", p.Status)
+	}
+
+	if err := svc.HandlePaymentWebhook(ctx, []byte("fail:"+failed.Reservation.Payment.ProviderRef), "signed"); err != nil {
+		t.Fatalf("webhook: %v", err)
+	}
+	if res := client.reservations[failed.Reservation.ID]; res.Status != servicedto.StatusCancelled {
+		t.Fatalf("expected cancelled after failed payment, got %s", res.Status)
+	}
+	if len(provider.calls) != 0 {
+		t.Fatalf("expected no provider calls for webhooks, got %v", provider.calls)
+	}
+}
+
+func TestPaymentStartFailureCancelsBooking(t *testing.T) {
+	svc, client, provider, _ := newDepositTestService(servicedto.DepositPolicy{MinPeople: 2})
+	provider.failCreate = true
+
+	if _, err := svc.CreateReservation(context.Background(), servicedto.CreateReservationInput{UserID: 1, Date: "2025-12-03", Time: "20:00", People: 2}); err == nil {
+		t.Fatal("expected provider error")
+	}
+	if res := client.reservations[1]; res.Status != servicedto.StatusCancelled {
+		t.Fatalf("expected the held slot to be released, got %s", res.Status)
+	}
+}
+
+func TestPaymentSettlement(t *testing.T) {
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	ctx := context.Background()
+
+	// paidBooking creates a booking for tonight or next week whose payment
+	// has already succeeded.
+	paidBooking := func(svc *ReservationService, date string) servicedto.Reservation {
+		t.Helper()
+		out, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 1, Date: date, Time: "20:00", People: 2})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := svc.HandlePaymentWebhook(ctx, []byte("ok:"+out.Reservation.Payment.ProviderRef), "signed"); err != nil {
+			t.Fatalf("webhook: %v", err)
+		}
+		return out.Reservation
+	}
+	const tonight, nextWeek = "2025-12-01", "2025-12-08"
+
+	for _, tc := range []struct {
+		name   string
+		kind   string
+		date   string
+		act    func(*ReservationService, servicedto.Reservation) error
+		call   string
+		status string
+	}{
+		{"guarantee released on timely cancel", servicedto.PaymentKindGuarantee, nextWeek, guestCancel, "void", servicedto.PaymentReleased},
+		{"guarantee captured on late cancel", servicedto.PaymentKindGuarantee, tonight, guestCancel, "capture", servicedto.PaymentCaptured},
+		{"guarantee captured on no-show", servicedto.PaymentKindGuarantee, nextWeek, noShow(admin), "capture", servicedto.PaymentCaptured},
+		{"guarantee released on arrival", servicedto.PaymentKindGuarantee, tonight, seat(admin), "void", servicedto.PaymentReleased},
+		{"deposit refunded on timely cancel", servicedto.PaymentKindDeposit, nextWeek, guestCancel, "refund", servicedto.PaymentRefunded},
+		{"deposit refunded on staff cancel", servicedto.PaymentKindDeposit, tonight, staffCancel(admin), "refund", servicedto.PaymentRefunded},
+		{"deposit kept on late cancel", servicedto.PaymentKindDeposit, tonight, guestCancel, "", servicedto.PaymentCaptured},
+		{"deposit kept on no-show", servicedto.PaymentKindDeposit, nextWeek, noShow(admin), "", servicedto.PaymentCaptured},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, _, provider, payments := newDepositTestService(servicedto.DepositPolicy{Kind: tc.kind, MinPeople: 2, ChargeLateCancels: true})
+			res := paidBooking(svc, tc.date)
+			if err := tc.act(svc, res); err != nil {
+				t.Fatalf("act: %v", err)
+			}
+			if got := strings.Join(provider.calls, ","); got != tc.call {
+				t.Fatalf("expected provider call %q, got %q", tc.call, got)
+			}
+			if p := payments.payments[0]; p.Status != tc.status {
+				t.Fatalf("expected payment %s, got %s", tc.status, p.Status)
+			}
+		})
+	}
+
+	t.Run("provider failure leaves the settlement due", func(t *testing.T) {
+		svc, client, provider, payments := newDepositTestService(servicedto.DepositPolicy{MinPeople: 2})
+		res := paidBooking(svc, nextWeek)
+		provider.failSettle = true
+		if _, err := svc.MarkNoShow(ctx, admin, res.ID); err != nil {
+			t.Fatalf("no-show: %v", err)
+		}
+		if got := client.reservations[res.ID].Status; got != servicedto.StatusNoShow {
+			t.Fatalf("expected no-show, got %s", got)
+		}
+		if p := payments.payments[0]; p.Status != servicedto.PaymentAuthorized || p.Settlement != settleForfeit {
+			t.Fatalf("expected capture still due, got %+v", p)
+		}
+
+		result, err := svc.SettleDuePayments(ctx)
+		if err != nil || result.Failed != 1 || result.Settled != 0 {
+			t.Fatalf("expected a failed retry, got %+v, %v", result, err)
+		}
+		provider.failSettle = false
+		result, err = svc.SettleDuePayments(ctx)
+		if err != nil || result.Settled != 1 {
+			t.Fatalf("expected the retry to settle, got %+v, %v", result, err)
+		}
+		if p := payments.payments[0]; p.Status != servicedto.PaymentCaptured || p.Settlement != "" {
+			t.Fatalf("expected captured guarantee, got %+v", p)
+		}
+		if result, _ := svc.SettleDuePayments(ctx); result.Settled+result.Failed != 0 {
+			t.Fatalf("expected nothing left to settle, got %+v", result)
+		}
+	})
+
+	t.Run("lost status change settles nothing", func(t *testing.T) {
+		svc, client, provider, payments := newDepositTestService(servicedto.DepositPolicy{MinPeople: 2})
+		res := paidBooking(svc, nextWeek)
+		client.beforeStatusChange = func() {
+			stored := client.reservations[res.ID]
+			stored.Status = servicedto.StatusSeated
+			client.reservations[res.ID] = stored
+		}
+		if _, err := svc.MarkNoShow(ctx, admin, res.ID); err != ErrStatusConflict {
+			t.Fatalf("expected ErrStatusConflict, got %v", err)
+		}
+		if len(provider.calls) != 0 || payments.payments[0].Settlement != "" {
+			t.Fatalf("expected no settlement, got calls %v and %+v", provider.calls, payments.payments[0])
+		}
+	})
+
+	t.Run("webhook after cancellation keeps the booking cancelled", func(t *testing.T) {
+		svc, client, _, _ := newDepositTestService(servicedto.DepositPolicy{MinPeople: 2})
+		out, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 1, Date: nextWeek, Time: "20:00", People: 2})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		ref := out.Reservation.Payment.ProviderRef
+		// The cancellation lands after the webhook read the reservation.
+		client.beforeStatusChange = func() {
+			client.beforeStatusChange = nil
+			if err := guestCancel(svc, out.Reservation); err != nil {
+				t.Fatalf("cancel: %v", err)
+			}
+		}
+		if err := svc.HandlePaymentWebhook(ctx, []byte("ok:"+ref), "signed"); err != nil {
+			t.Fatalf("webhook: %v", err)
+		}
+		if got := client.reservations[out.Reservation.ID].Status; got != servicedto.StatusCancelled {
+			t.Fatalf("expected the booking to stay cancelled, got %s", got)
+		}
+	})
+}
+
+func guestCancel(svc *ReservationService, res servicedto.Reservation) error {
+	_, err := svc.CancelReservation(context.Background(), servicedto.CancelReservationInput{UserID: res.UserID, ReservationID: res.ID})
+	return err
+}
+
+func staffCancel(admin servicedto.User) func(*ReservationService, servicedto.Reservation) error {
+	return func(svc *ReservationService, res servicedto.Reservation) error {
+		_, err := svc.AdminCancelReservation(context.Background(), admin, res.ID)
+		return err
+	}
+}
+
+func noShow(admin servicedto.User) func(*ReservationService, servicedto.Reservation) error {
+	return func(svc *ReservationService, res servicedto.Reservation) error {
+		_, err := svc.MarkNoShow(context.Background(), admin, res.ID)
+		return err
+	}
+}
+
+func seat(admin servicedto.User) func(*ReservationService, servicedto.Reservation) error {
+	return func(svc *ReservationService, res servicedto.Reservation) error {
+		_, err := svc.SeatReservation(context.Background(), admin, servicedto.SeatReservationInput{ReservationID: res.ID})
+		return err
+	}
+}
+
+// fakePaymentProvider accepts webhooks of the form "ok:<ref>" or
+// "fail:<ref>" signed with "signed", and records settlement calls.
+type fakePaymentProvider struct {
+	states     map[string]string
+	calls      []string
+	failCreate bool
+	failSettle bool
+}
+
+func (f *fakePaymentProvider) CreatePayment(ctx context.Context, params servicedto.ProviderPaymentParams) (*servicedto.ProviderPayment, error) {
+	if f.failCreate {
+		return nil, errors.New("provider unavailable")
+	}
+	ref := "pay_" + params.Reference
+	f.states[ref] = "pending"
+	return &servicedto.ProviderPayment{Ref: ref, CheckoutURL: "https://pay.example.com/" + ref}, nil
+}
+
+func (f *fakePaymentProvider) Capture(ctx context.Context, ref string) error {
+	return f.settle("capture")
+}
+
+func (f *fakePaymentProvider) Void(ctx context.Context, ref string) error {
+	return f.settle("void")
+}
+
+func (f *fakePaymentProvider) Refund(ctx context.Context, ref string) error {
+	return f.settle("refund")
+}
+
+func (f *fakePaymentProvider) settle(call string) error {
+	if f.failSettle {
+		return errors.New("provider unavailable")
+	}
+	f.calls = append(f.calls, call)
+	return nil
+}
+
+func (f *fakePaymentProvider) ParseWebhook(payload []byte, signature string) (*servicedto.PaymentEvent, error) {
+	outcome, ref, ok := strings.Cut(string(payload), ":")
+	if signature != "signed" || !ok {
+		return nil, errors.New("bad signature")
+	}
+	return &servicedto.PaymentEvent{ProviderRef: ref, Succeeded: outcome == "ok"}, nil
+}
+
+type fakePaymentClient struct {
+	payments []servicedto.Payment
+}
+
+func (f *fakePaymentClient) CreatePayment(ctx context.Context, params servicedto.CreatePaymentParams) (*servicedto.Payment, error) {
+	p := servicedto.Payment{
+		ID:            uint(len(f.payments) + 1),
+		ReservationID: params.ReservationID,
+		Kind:          params.Kind,
+		Amount:        params.Amount,
+		Currency:      params.Currency,
+		Status:        params.Status,
+		ProviderRef:   params.ProviderRef,
+		CheckoutURL:   params.CheckoutURL,
+	}
+	f.payments = append(f.payments, p)
+	return &p, nil
+}
+
+func (f *fakePaymentClient) GetPaymentByReservation(ctx context.Context, reservationID uint) (*servicedto.Payment, error) {
+	for _, p := range f.payments {
+		if p.ReservationID == reservationID {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakePaymentClient) GetPaymentByProviderRef(ctx context.Context, ref string) (*servicedto.Payment, error) {
+	for _, p := range f.payments {
+		if p.ProviderRef == ref {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakePaymentClient) UpdatePaymentStatus(ctx context.Context, id uint, from, status string) (*servicedto.Payment, error) {
+	for i, p := range f.payments {
+		if p.ID == id && p.Status == from {
+			f.payments[i].Status = status
+			updated := f.payments[i]
+			return &updated, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakePaymentClient) MarkSettlementDue(ctx context.Context, reservationID uint, outcome string) (*servicedto.Payment, error) {
+	for i, p := range f.payments {
+		if p.ReservationID == reservationID {
+			f.payments[i].Settlement = outcome
+			updated := f.payments[i]
+			return &updated, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakePaymentClient) ClearSettlement(ctx context.Context, id uint, outcome string) error {
+	for i, p := range f.payments {
+		if p.ID == id && p.Settlement == outcome {
+			f.payments[i].Settlement = ""
+		}
+	}
+	return nil
+}
+
+func (f *fakePaymentClient) ListDueSettlements(ctx context.Context, limit int) ([]servicedto.Payment, error) {
+	var due []servicedto.Payment
+	for _, p := range f.payments {
+		if p.Settlement != "" && len(due) < limit {
+			due = append(due, p)
+		}
+	}
+	return due, nil
+}
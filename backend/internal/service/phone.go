@@ -0,0 +1,21 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"vesuvio/internal/dto/service"
+)
+
+// normalizePhone returns a phone number in E.164 form, rejecting numbers
+// without a country code. Services use their configured PhoneFormat, which
+// may supply one.
+func normalizePhone(raw string) (string, bool) {
+	return servicedto.PhoneFormat{}.Normalize(raw)
+}
+
+// guestEmail derives the placeholder email stored for guests, who have no
+// address of their own. The .invalid TLD can never receive mail.
+func guestEmail(phone string) string {
+	return fmt.Sprintf("guest-%s@guests.invalid", strings.TrimPrefix(phone, "+"))
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"vesuvio/internal/dto/service"
+)
+
+// PreferenceLookup reads the notification preferences of a user, nil when
+// the user has not set any.
+type PreferenceLookup interface {
+	GetNotificationPreferences(ctx context.Context, userID uint) (*servicedto.NotificationPreferences, error)
+}
+
+// PreferenceClient abstracts users' phone numbers and notification
+// preferences.
+type PreferenceClient interface {
+	PreferenceLookup
+	GetUserByID(ctx context.Context, id uint) (*servicedto.User, error)
+	UpdateUserPhone(ctx context.Context, userID uint, phone *string) error
+	SaveNotificationPreferences(ctx context.Context, userID uint, prefs servicedto.NotificationPreferences) error
+}
+
+// PreferenceService lets users choose how they hear from the restaurant.
+type PreferenceService struct {
+	client PreferenceClient
+	phones servicedto.PhoneFormat
+}
+
+// NewPreferenceService reads the phone numbers users add using phones.
+func NewPreferenceService(client PreferenceClient, phones servicedto.PhoneFormat) *PreferenceService {
+	return &PreferenceService{client: client, phones: phones}
+}
+
+// GetSettings returns the user's phone number and preferences.
+func (s *PreferenceService) GetSettings(ctx context.Context, userID uint) (*servicedto.NotificationSettings, error) {
+	user, err := s.client.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	prefs, err := preferencesFor(ctx, s.client, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &servicedto.NotificationSettings{Phone: user.Phone, NotificationPreferences: prefs}, nil
+}
+
+// UpdateSettings changes the fields set in input. Turning SMS on without a
+// phone number is allowed; messages start once a number is added.
+func (s *PreferenceService) UpdateSettings(ctx context.Context, input servicedto.UpdateNotificationSettingsInput) (*servicedto.NotificationSettings, error) {
+	settings, err := s.GetSettings(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Phone != nil {
+		var phone *string
+		if strings.TrimSpace(*input.Phone) != "" {
+			normalized, ok := s.phones.Normalize(*input.Phone)
+			if !ok {
+				return nil, ErrInvalidPhone
+			}
+			phone = &normalized
+		}
+		if err := s.client.UpdateUserPhone(ctx, input.UserID, phone); err != nil {
+			return nil, err
+		}
+		settings.Phone = phone
+	}
+
+	prefs := &settings.NotificationPreferences
+	changed := false
+	for _, f := range []struct {
+		value *bool
+		field *bool
+	}{{input.Email, &prefs.Email}, {input.SMS, &prefs.SMS}, {input.Reminders, &prefs.Reminders}} {
+		if f.value != nil {
+			*f.field = *f.value
+			changed = true
+		}
+	}
+	if changed {
+		if err := s.client.SaveNotificationPreferences(ctx, input.UserID, *prefs); err != nil {
+			return nil, err
+		}
+	}
+	return settings, nil
+}
+
+// preferencesFor returns the user's preferences, or the defaults when the
+// user has not set any or there is nowhere to look them up.
+func preferencesFor(ctx context.Context, lookup PreferenceLookup, userID uint) (servicedto.NotificationPreferences, error) {
+	if lookup == nil {
+		return servicedto.DefaultNotificationPreferences, nil
+	}
+	prefs, err := lookup.GetNotificationPreferences(ctx, userID)
+	if err != nil || prefs == nil {
+		return servicedto.DefaultNotificationPreferences, err
+	}
+	return *prefs, nil
+}
+
+// allowsChannel reports whether prefs let a message go out on channel. SMS
+// has its own switch; email and the local sinks share the email one.
+func allowsChannel(prefs servicedto.NotificationPreferences, channel string) bool {
+	if channel == SMSChannelName {
+		return prefs.SMS
+	}
+	return prefs.Email
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestPreferenceService(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserClient()
+	user, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com"})
+	svc := NewPreferenceService(users, servicedto.PhoneFormat{})
+
+	settings, err := svc.GetSettings(ctx, user.ID)
+	if err != nil || settings.Phone != nil || settings.NotificationPreferences != servicedto.DefaultNotificationPreferences {
+		t.Fatalf("expected defaults and no phone, got %+v %v", settings, err)
+	}
+
+	phone, off := "+34 600 123 456", false
+	settings, err = svc.UpdateSettings(ctx, servicedto.UpdateNotificationSettingsInput{UserID: user.ID, Phone: &phone, Reminders: &off})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	want := servicedto.NotificationPreferences{Email: true, SMS: true, Reminders: false}
+	if settings.Phone == nil || *settings.Phone != "+34600123456" || settings.NotificationPreferences != want {
+		t.Fatalf("unexpected settings: %+v", settings)
+	}
+	if saved := users.preferences[user.ID]; saved != want {
+		t.Fatalf("expected preferences to be saved, got %+v", saved)
+	}
+
+	empty := ""
+	settings, err = svc.UpdateSettings(ctx, servicedto.UpdateNotificationSettingsInput{UserID: user.ID, Phone: &empty})
+	if err != nil || settings.Phone != nil || users.users[user.ID].Phone != nil {
+		t.Fatalf("expected the phone to be removed, got %+v %v", settings, err)
+	}
+
+	bad := "600123456"
+	if _, err := svc.UpdateSettings(ctx, servicedto.UpdateNotificationSettingsInput{UserID: user.ID, Phone: &bad}); err != ErrInvalidPhone {
+		t.Fatalf("expected ErrInvalidPhone, got %v", err)
+	}
+	if _, err := svc.GetSettings(ctx, 999); err != ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
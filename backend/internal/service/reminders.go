@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// NotificationChannel delivers messages to guests. The client package has
+// email and SMS channels plus file and in-memory sinks for local runs.
+type NotificationChannel interface {
+	// Name identifies the channel in the reminder log.
+	Name() string
+	// Address returns where the guest is reached on this channel, if anywhere.
+	Address(user servicedto.User) (string, bool)
+	Send(ctx context.Context, n servicedto.Notification) error
+}
+
+// ReminderClient abstracts the reminder log.
+type ReminderClient interface {
+	ClaimReminder(ctx context.Context, params servicedto.ClaimReminderParams) (*servicedto.Reminder, error)
+	CompleteReminder(ctx context.Context, id uint, sendErr *string, at time.Time) error
+	ListRemindersByReservation(ctx context.Context, reservationID uint) ([]servicedto.Reminder, error)
+}
+
+// DefaultReminderOffsets are how long before the slot reminders go out.
+var DefaultReminderOffsets = []time.Duration{24 * time.Hour, 3 * time.Hour}
+
+// maxReminderAttempts bounds retries of a reminder a channel failed to send.
+const maxReminderAttempts = 3
+
+// reminderSendingTimeout is how long a reminder may stay claimed before it is
+// assumed lost, say to a crash between claiming and sending, and retried.
+const reminderSendingTimeout = 10 * time.Minute
+
+// reminderEvent names the reminder templates, <dir>/<locale>/reminder.*.
+const reminderEvent = "reminder"
+
+// ReminderService reminds guests of confirmed reservations ahead of their
+// slot, with one-click links to confirm attendance or cancel.
+type ReminderService struct {
+	reservationClient ReservationClient
+	reminderClient    ReminderClient
+	preferences       PreferenceLookup
+	channels          []NotificationChannel
+	templates         eventTemplates
+	links             *LinkSigner
+	baseURL           string
+	offsets           []time.Duration // ascending
+	location          *time.Location
+	now               func() time.Time
+}
+
+// NewReminderService sends reminders on every channel that can reach the
+// guest and that the guest has not opted out of; prefs may be nil, which
+// sends on all of them. Messages come from the reminder templates in
+// templatesDir, like TemplateNotifier's. Links point at baseURL and are left
+// out when links is nil.
+func NewReminderService(resClient ReservationClient, reminderClient ReminderClient, prefs PreferenceLookup, channels []NotificationChannel, templatesDir, locale string, links *LinkSigner, baseURL string, offsets []time.Duration, loc *time.Location) (*ReminderService, error) {
+	templates, err := loadLocaleTemplates(templatesDir, locale, reminderEvent)
+	if err != nil {
+		return nil, err
+	}
+	if len(offsets) == 0 {
+		offsets = DefaultReminderOffsets
+	}
+	offsets = slices.Clone(offsets)
+	slices.Sort(offsets)
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &ReminderService{
+		reservationClient: resClient,
+		reminderClient:    reminderClient,
+		preferences:       prefs,
+		channels:          channels,
+		templates:         templates,
+		links:             links,
+		baseURL:           strings.TrimSuffix(baseURL, "/"),
+		offsets:           offsets,
+		location:          loc,
+		now:               time.Now,
+	}, nil
+}
+
+// SendDueReminders sends the reminders that are due. A reservation gets the
+// reminder for the closest offset whose time has come, unless it was booked
+// after that time; the reminder log makes each one go out once even with
+// several instances running.
+func (s *ReminderService) SendDueReminders(ctx context.Context) (*servicedto.ReminderResult, error) {
+	result := &servicedto.ReminderResult{}
+	now := s.now().In(s.location)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	last := now.Add(s.offsets[len(s.offsets)-1])
+	to := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC)
+	page, err := s.reservationClient.QueryReservations(ctx, servicedto.ReservationQuery{
+		From:     &from,
+		To:       &to,
+		Statuses: []string{servicedto.StatusConfirmed},
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, r := range page.Reservations {
+		start, ok := slotStart(r, s.location)
+		if !ok || !now.Before(start) || r.User == nil {
+			continue
+		}
+		offset, ok := s.dueOffset(r, start, now)
+		if !ok {
+			continue
+		}
+		prefs, err := preferencesFor(ctx, s.preferences, r.UserID)
+		if err != nil {
+			return result, err
+		}
+		if !prefs.Reminders {
+			continue
+		}
+		for _, ch := range s.channels {
+			if !allowsChannel(prefs, ch.Name()) {
+				continue
+			}
+			if err := s.remind(ctx, ch, r, start, offset, result); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+func (s *ReminderService) dueOffset(r servicedto.Reservation, start, now time.Time) (time.Duration, bool) {
+	for _, offset := range s.offsets {
+		sendAt := start.Add(-offset)
+		if now.Before(sendAt) {
+			continue
+		}
+		return offset, !r.CreatedAt.After(sendAt)
+	}
+	return 0, false
+}
+
+func (s *ReminderService) remind(ctx context.Context, ch NotificationChannel, r servicedto.Reservation, start time.Time, offset time.Duration, result *servicedto.ReminderResult) error {
+	msg, err := s.reminderMessage(r, start)
+	if err != nil {
+		return err
+	}
+	if ch.Name() == SMSChannelName && msg.Text == "" {
+		return nil
+	}
+	to, ok := ch.Address(*r.User)
+	if !ok {
+		return nil
+	}
+	msg.To = to
+	reminder, err := s.reminderClient.ClaimReminder(ctx, servicedto.ClaimReminderParams{
+		ReservationID: r.ID,
+		Offset:        offset,
+		Channel:       ch.Name(),
+		Recipient:     to,
+		MaxAttempts:   maxReminderAttempts,
+		StaleBefore:   s.now().Add(-reminderSendingTimeout),
+	})
+	if err != nil || reminder == nil {
+		return err
+	}
+
+	var sendErr *string
+	if err := ch.Send(ctx, msg); err != nil {
+		msg := err.Error()
+		sendErr = &msg
+		result.Failed++
+	} else {
+		result.Sent++
+	}
+	return s.reminderClient.CompleteReminder(ctx, reminder.ID, sendErr, s.now())
+}
+
+func (s *ReminderService) reminderMessage(r servicedto.Reservation, start time.Time) (servicedto.Notification, error) {
+	data := messageData{
+		Name:   r.User.Name,
+		Code:   r.Code,
+		Date:   r.Date,
+		Time:   r.Time,
+		People: r.People,
+		Status: r.Status,
+	}
+	if r.Comment != nil {
+		data.Comment = *r.Comment
+	}
+	if s.links != nil {
+		data.ConfirmURL = s.linkURL(r.ID, servicedto.LinkActionConfirm, start)
+		data.CancelURL = s.linkURL(r.ID, servicedto.LinkActionCancel, start)
+	}
+	msg, err := s.templates.render(data)
+	msg.ReservationID = r.ID
+	return msg, err
+}
+
+// linkURL returns a one-click link that stops working when the slot starts.
+func (s *ReminderService) linkURL(reservationID uint, action string, expiresAt time.Time) string {
+	return s.baseURL + "/links/" + s.links.Sign(reservationID, action, expiresAt)
+}
+
+// ListReminders returns the reminder log of a reservation, oldest first.
+func (s *ReminderService) ListReminders(ctx context.Context, admin servicedto.User, reservationID uint) ([]servicedto.Reminder, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	res, err := s.reservationClient.GetReservationByID(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, ErrReservationNotFound
+	}
+	return s.reminderClient.ListRemindersByReservation(ctx, reservationID)
+}
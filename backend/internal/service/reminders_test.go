@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestSendDueReminders(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeReservationClient()
+	reminders := newFakeReminderClient()
+	email := &fakeChannel{name: "email"}
+	sms := &fakeChannel{name: "sms", err: errors.New("gateway down")}
+	links := NewLinkSigner([]byte("secret"))
+	svc, err := NewReminderService(client, reminders, nil, []NotificationChannel{email, sms}, emailTemplatesDir, "en", links, "https://vesuvio.example/", nil, time.UTC)
+	if err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+	now := time.Date(2030, 1, 10, 18, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	phone := "+391234567"
+	add := func(date, slot, status string, createdAt time.Time) uint {
+		res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: 1, Date: mustDate(t, date), Time: slot, People: 2, Status: status,
+		})
+		r := client.reservations[res.ID]
+		r.User = &servicedto.User{ID: 1, Name: "Ana", Email: "ana@example.com", Phone: &phone}
+		r.CreatedAt = createdAt
+		client.reservations[res.ID] = r
+		return res.ID
+	}
+	booked := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	tonight := add("2030-01-10", "20:00", servicedto.StatusConfirmed, booked)
+	tomorrow := add("2030-01-11", "17:00", servicedto.StatusConfirmed, booked)
+	add("2030-01-12", "20:00", servicedto.StatusConfirmed, booked)
+	add("2030-01-10", "20:00", servicedto.StatusPending, booked)
+	add("2030-01-10", "16:00", servicedto.StatusConfirmed, booked)
+	// Booked after the 3h reminder was due; the guest has just been told.
+	add("2030-01-10", "20:30", servicedto.StatusConfirmed, now.Add(-15*time.Minute))
+
+	result, err := svc.SendDueReminders(ctx)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if result.Sent != 2 || result.Failed != 2 {
+		t.Fatalf("expected two sent and two failed, got %+v", result)
+	}
+	sent := map[uint]time.Duration{}
+	for _, r := range reminders.reminders {
+		if r.Channel == "email" {
+			sent[r.ReservationID] = r.Offset
+		}
+	}
+	if len(sent) != 2 || sent[tonight] != 3*time.Hour || sent[tomorrow] != 24*time.Hour {
+		t.Fatalf("unexpected reminders: %+v", reminders.reminders)
+	}
+
+	msg := email.sent[0]
+	if msg.To != "ana@example.com" || !strings.Contains(msg.Body, "https://vesuvio.example/links/") {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	if text := sms.sent[0].Text; sms.sent[0].To != phone || !strings.Contains(text, "Confirm: https://vesuvio.example/links/") {
+		t.Fatalf("unexpected text message: %+v", sms.sent[0])
+	}
+
+	// Sent reminders go out once; failed ones are retried up to the limit.
+	for range 3 {
+		if _, err := svc.SendDueReminders(ctx); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	if len(email.sent) != 2 || len(sms.sent) != 2*maxReminderAttempts {
+		t.Fatalf("expected %d sms attempts and no repeated emails, got %d %d", 2*maxReminderAttempts, len(sms.sent), len(email.sent))
+	}
+	log, err := svc.ListReminders(ctx, servicedto.User{ID: 100, IsAdmin: true}, tonight)
+	if err != nil || len(log) != 2 {
+		t.Fatalf("expected a log entry per channel, got %+v %v", log, err)
+	}
+	if log[1].Status != servicedto.ReminderFailed || log[1].Attempts != maxReminderAttempts || *log[1].Error != "gateway down" {
+		t.Fatalf("unexpected failed reminder: %+v", log[1])
+	}
+	if _, err := svc.ListReminders(ctx, servicedto.User{ID: 1}, tonight); err != ErrUnauthorized {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+}
+
+func TestSendDueRemindersPreferences(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeReservationClient()
+	users := newFakeUserClient()
+	email := &fakeChannel{name: "email"}
+	sms := &fakeChannel{name: SMSChannelName}
+	svc, err := NewReminderService(client, newFakeReminderClient(), users, []NotificationChannel{email, sms}, emailTemplatesDir, "en", nil, "", nil, time.UTC)
+	if err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+	svc.now = func() time.Time { return time.Date(2030, 1, 10, 18, 0, 0, 0, time.UTC) }
+
+	phone := "+391234567"
+	for _, prefs := range []servicedto.NotificationPreferences{
+		{Email: false, SMS: true, Reminders: true}, // texts only
+		{Email: true, SMS: true, Reminders: false}, // no reminders at all
+		servicedto.DefaultNotificationPreferences,
+	} {
+		user, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: fmt.Sprintf("ana%d@example.com", len(users.users)), Phone: &phone})
+		users.preferences[user.ID] = prefs
+		res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: user.ID, Date: mustDate(t, "2030-01-10"), Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+		})
+		r := client.reservations[res.ID]
+		r.User = user
+		client.reservations[res.ID] = r
+	}
+
+	result, err := svc.SendDueReminders(ctx)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if result.Sent != 3 || len(email.sent) != 1 || len(sms.sent) != 2 {
+		t.Fatalf("expected one email and two texts, got %+v %d %d", result, len(email.sent), len(sms.sent))
+	}
+	if email.sent[0].To != "ana2@example.com" {
+		t.Fatalf("expected only the guest with defaults to get an email, got %s", email.sent[0].To)
+	}
+}
+
+func TestSendDueRemindersLocale(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeReservationClient()
+	email := &fakeChannel{name: "email"}
+	links := NewLinkSigner([]byte("secret"))
+	svc, err := NewReminderService(client, newFakeReminderClient(), nil, []NotificationChannel{email}, emailTemplatesDir, "it", links, "https://vesuvio.example", nil, time.UTC)
+	if err != nil {
+		t.Fatalf("load templates: %v", err)
+	}
+	svc.now = func() time.Time { return time.Date(2030, 1, 10, 18, 0, 0, 0, time.UTC) }
+
+	res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: mustDate(t, "2030-01-10"), Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+	r := client.reservations[res.ID]
+	r.User = &servicedto.User{ID: 1, Name: "Ana", Email: "ana@example.com"}
+	client.reservations[res.ID] = r
+
+	if _, err := svc.SendDueReminders(ctx); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if len(email.sent) != 1 {
+		t.Fatalf("expected one reminder, got %d", len(email.sent))
+	}
+	msg := email.sent[0]
+	if !strings.HasPrefix(msg.Subject, "Promemoria") || !strings.Contains(msg.Body, "Conferma che ci sarai: https://vesuvio.example/links/") {
+		t.Fatalf("expected an Italian reminder with links, got %+v", msg)
+	}
+	if !strings.Contains(msg.HTML, `href="https://vesuvio.example/links/`) {
+		t.Fatalf("expected links in the HTML part, got %s", msg.HTML)
+	}
+}
+
+func TestFollowReservationLink(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeReservationClient()
+	links := NewLinkSigner([]byte("secret"))
+	svc := NewReservationService(client, WithReservationLinks(links))
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	slot := time.Date(2030, 1, 11, 20, 0, 0, 0, time.UTC)
+
+	res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+
+	confirm := links.Sign(res.ID, servicedto.LinkActionConfirm, slot)
+	got, action, err := svc.PreviewReservationLink(ctx, confirm)
+	if err != nil || action != servicedto.LinkActionConfirm || got.AttendingAt != nil {
+		t.Fatalf("expected a preview that changes nothing, got %+v %s %v", got, action, err)
+	}
+	got, action, err = svc.FollowReservationLink(ctx, confirm)
+	if err != nil || action != servicedto.LinkActionConfirm || got.AttendingAt == nil || !got.AttendingAt.Equal(now) {
+		t.Fatalf("expected attendance confirmed, got %+v %s %v", got, action, err)
+	}
+
+	expired := links.Sign(res.ID, servicedto.LinkActionCancel, now)
+	if _, _, err := svc.FollowReservationLink(ctx, expired); err != ErrLinkExpired {
+		t.Fatalf("expected expired link, got %v", err)
+	}
+	forged := NewLinkSigner([]byte("other")).Sign(res.ID, servicedto.LinkActionCancel, slot)
+	if _, _, err := svc.FollowReservationLink(ctx, forged); err != ErrInvalidLink {
+		t.Fatalf("expected forged link rejected, got %v", err)
+	}
+	if _, _, err := svc.FollowReservationLink(ctx, "garbage"); err != ErrInvalidLink {
+		t.Fatalf("expected garbage rejected, got %v", err)
+	}
+
+	cancel := links.Sign(res.ID, servicedto.LinkActionCancel, slot)
+	got, _, err = svc.FollowReservationLink(ctx, cancel)
+	if err != nil || got.Status != servicedto.StatusCancelled {
+		t.Fatalf("expected cancellation, got %+v %v", got, err)
+	}
+	if _, _, err := svc.FollowReservationLink(ctx, links.Sign(res.ID, servicedto.LinkActionConfirm, slot)); err != ErrInvalidStatus {
+		t.Fatalf("expected a cancelled booking not to be confirmed, got %v", err)
+	}
+
+	if _, _, err := NewReservationService(client).FollowReservationLink(ctx, cancel); err != ErrLinksDisabled {
+		t.Fatalf("expected links disabled, got %v", err)
+	}
+}
+
+// fakeChannel records what it is asked to send and fails with err if set.
+type fakeChannel struct {
+	name string
+	err  error
+	sent []servicedto.Notification
+}
+
+func (c *fakeChannel) Name() string { return c.name }
+
+func (c *fakeChannel) Address(user servicedto.User) (string, bool) {
+	if c.name == "sms" {
+		if user.Phone == nil {
+			return "", false
+		}
+		return *user.Phone, true
+	}
+	return user.Email, user.Email != ""
+}
+
+func (c *fakeChannel) Send(ctx context.Context, n servicedto.Notification) error {
+	c.sent = append(c.sent, n)
+	return c.err
+}
+
+// fakeReminderClient is an in-memory reminder log for tests.
+type fakeReminderClient struct {
+	reminders []servicedto.Reminder
+}
+
+func newFakeReminderClient() *fakeReminderClient {
+	return &fakeReminderClient{}
+}
+
+func (f *fakeReminderClient) ClaimReminder(ctx context.Context, params servicedto.ClaimReminderParams) (*servicedto.Reminder, error) {
+	for i, r := range f.reminders {
+		if r.ReservationID != params.ReservationID || r.Offset != params.Offset || r.Channel != params.Channel {
+			continue
+		}
+		stale := r.Status == servicedto.ReminderSending && r.UpdatedAt.Before(params.StaleBefore)
+		if (r.Status != servicedto.ReminderFailed && !stale) || r.Attempts >= params.MaxAttempts {
+			return nil, nil
+		}
+		f.reminders[i].Status = servicedto.ReminderSending
+		f.reminders[i].Attempts++
+		f.reminders[i].UpdatedAt = time.Now()
+		copy := f.reminders[i]
+		return &copy, nil
+	}
+	r := servicedto.Reminder{
+		ID:            uint(len(f.reminders) + 1),
+		ReservationID: params.ReservationID,
+		Offset:        params.Offset,
+		Channel:       params.Channel,
+		Recipient:     params.Recipient,
+		Status:        servicedto.ReminderSending,
+		Attempts:      1,
+		UpdatedAt:     time.Now(),
+	}
+	f.reminders = append(f.reminders, r)
+	return &r, nil
+}
+
+func (f *fakeReminderClient) CompleteReminder(ctx context.Context, id uint, sendErr *string, at time.Time) error {
+	r := &f.reminders[id-1]
+	if sendErr != nil {
+		r.Status, r.Error = servicedto.ReminderFailed, sendErr
+		return nil
+	}
+	r.Status, r.SentAt, r.Error = servicedto.ReminderSent, &at, nil
+	return nil
+}
+
+func (f *fakeReminderClient) ListRemindersByReservation(ctx context.Context, reservationID uint) ([]servicedto.Reminder, error) {
+	var list []servicedto.Reminder
+	for _, r := range f.reminders {
+		if r.ReservationID == reservationID {
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
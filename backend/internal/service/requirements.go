@@ -0,0 +1,86 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"vesuvio/internal/dto/service"
+)
+
+// DefaultRequirementsCatalogue is used unless configured otherwise. The
+// allergens are the fourteen that EU menus must declare.
+var DefaultRequirementsCatalogue = servicedto.RequirementsCatalogue{
+	Allergens: []string{
+		"celery", "crustaceans", "eggs", "fish", "gluten", "lupin", "milk",
+		"molluscs", "mustard", "nuts", "peanuts", "sesame", "soy", "sulphites",
+	},
+	DietaryStyles: []string{"vegetarian", "vegan", "pescatarian", "halal", "kosher"},
+	Accessibility: []string{"wheelchair", "step_free", "hearing_impaired", "visually_impaired", "service_animal"},
+	MaxHighChairs: 4,
+}
+
+// WithRequirementsCatalogue replaces the parts of the default catalogue that
+// are set.
+func WithRequirementsCatalogue(c servicedto.RequirementsCatalogue) ReservationOption {
+	return func(s *ReservationService) {
+		if len(c.Allergens) > 0 {
+			s.catalogue.Allergens = c.Allergens
+		}
+		if len(c.DietaryStyles) > 0 {
+			s.catalogue.DietaryStyles = c.DietaryStyles
+		}
+		if len(c.Accessibility) > 0 {
+			s.catalogue.Accessibility = c.Accessibility
+		}
+		if c.MaxHighChairs > 0 {
+			s.catalogue.MaxHighChairs = c.MaxHighChairs
+		}
+	}
+}
+
+// RequirementsCatalogue returns the values guests may pick requirements from.
+func (s *ReservationService) RequirementsCatalogue() servicedto.RequirementsCatalogue {
+	return s.catalogue
+}
+
+// normalizeRequirements lower-cases and de-duplicates the requirements of a
+// party and checks them against the catalogue.
+func normalizeRequirements(c servicedto.RequirementsCatalogue, req servicedto.Requirements, people int) (servicedto.Requirements, error) {
+	var out servicedto.Requirements
+	var ok bool
+	if out.Allergens, ok = pickFromCatalogue(req.Allergens, c.Allergens); !ok {
+		return out, ErrInvalidRequirement
+	}
+	if out.Accessibility, ok = pickFromCatalogue(req.Accessibility, c.Accessibility); !ok {
+		return out, ErrInvalidRequirement
+	}
+	if req.DietaryStyle != nil {
+		style := strings.ToLower(strings.TrimSpace(*req.DietaryStyle))
+		if style != "" {
+			if !slices.Contains(c.DietaryStyles, style) {
+				return out, ErrInvalidRequirement
+			}
+			out.DietaryStyle = &style
+		}
+	}
+	if req.HighChairs < 0 || req.HighChairs > c.MaxHighChairs || req.HighChairs >= people {
+		return out, ErrInvalidRequirement
+	}
+	out.HighChairs = req.HighChairs
+	return out, nil
+}
+
+func pickFromCatalogue(values, catalogue []string) ([]string, bool) {
+	var out []string
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if !slices.Contains(catalogue, v) {
+			return nil, false
+		}
+		if !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	slices.Sort(out)
+	return out, true
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+
+	"vesuvio/internal/dto/service"
+)
+
+// MaxBulkReservations caps how many reservations one bulk action may touch.
+const MaxBulkReservations = 500
+
+// BulkUpdateStatus moves many reservations to the same status. Reservations
+// already in that status are left alone. Every change is recorded in the
+// status history like the single-item admin actions.
+func (s *ReservationService) BulkUpdateStatus(ctx context.Context, admin servicedto.User, input servicedto.BulkStatusInput) (*servicedto.BulkStatusOutput, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	if !isValidStatus(input.Status) {
+		return nil, ErrInvalidStatus
+	}
+	if (len(input.IDs) == 0) == (input.Filter == nil) {
+		return nil, ErrInvalidInput
+	}
+
+	results, err := s.bulkTargets(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	adminID := admin.ID
+	actor := servicedto.StatusActor{UserID: &adminID, Source: servicedto.StatusSourceAdminBulk, Reason: input.Reason}
+	var changes []servicedto.UpdateReservationStatusParams
+	var pending []int
+	for i, r := range results {
+		switch {
+		case r.Reservation == nil:
+			if input.Atomic {
+				return nil, ErrReservationNotFound
+			}
+		case r.Reservation.Status == input.Status:
+			results[i].Outcome = servicedto.BulkOutcomeUnchanged
+		default:
+			changes = append(changes, servicedto.UpdateReservationStatusParams{ID: r.ID, Status: input.Status, Actor: actor})
+			pending = append(pending, i)
+		}
+	}
+
+	changes, pending, err = s.settleBulk(ctx, input, results, changes, pending)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Atomic && len(changes) > 0 {
+		updated, err := s.reservationClient.UpdateReservationStatuses(ctx, changes)
+		if err != nil {
+			return nil, err
+		}
+		if updated == nil {
+			return nil, ErrReservationNotFound
+		}
+		for n, i := range pending {
+			res := updated[n]
+			results[i].Reservation = &res
+			results[i].Outcome = servicedto.BulkOutcomeUpdated
+		}
+	} else {
+		for n, i := range pending {
+			res, err := s.reservationClient.UpdateReservationStatus(ctx, changes[n])
+			switch {
+			case err != nil:
+				results[i].Outcome = servicedto.BulkOutcomeFailed
+			case res == nil:
+				results[i].Outcome = servicedto.BulkOutcomeNotFound
+				results[i].Reservation = nil
+			default:
+				results[i].Outcome = servicedto.BulkOutcomeUpdated
+				results[i].Reservation = res
+			}
+		}
+	}
+
+	if input.Status == servicedto.StatusNoShow {
+		for _, r := range results {
+			if r.Outcome != servicedto.BulkOutcomeUpdated {
+				continue
+			}
+			if err := s.recordIncident(ctx, *r.Reservation, servicedto.IncidentNoShow); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	out := &servicedto.BulkStatusOutput{Results: results}
+	for _, r := range results {
+		switch r.Outcome {
+		case servicedto.BulkOutcomeUpdated:
+			out.Updated++
+		case servicedto.BulkOutcomeUnchanged:
+			out.Unchanged++
+		default:
+			out.Failed++
+		}
+	}
+	return out, nil
+}
+
+// settleBulk settles the payments of the reservations about to change.
+// Atomic actions stop at the first failure; otherwise the failed ones are
+// marked and dropped from the changes.
+func (s *ReservationService) settleBulk(ctx context.Context, input servicedto.BulkStatusInput, results []servicedto.BulkStatusResult, changes []servicedto.UpdateReservationStatusParams, pending []int) ([]servicedto.UpdateReservationStatusParams, []int, error) {
+	outcome := settlementFor(input.Status)
+	if outcome == "" {
+		return changes, pending, nil
+	}
+	keptChanges, keptPending := changes[:0], pending[:0]
+	for n, i := range pending {
+		if err := s.settlePayment(ctx, changes[n].ID, outcome); err != nil {
+			if input.Atomic {
+				return nil, nil, err
+			}
+			results[i].Outcome = servicedto.BulkOutcomeFailed
+			continue
+		}
+		keptChanges = append(keptChanges, changes[n])
+		keptPending = append(keptPending, i)
+	}
+	return keptChanges, keptPending, nil
+}
+
+// bulkTargets resolves the reservations a bulk action applies to, in request
+// order for explicit IDs. Missing IDs come back with a nil Reservation.
+func (s *ReservationService) bulkTargets(ctx context.Context, input servicedto.BulkStatusInput) ([]servicedto.BulkStatusResult, error) {
+	if input.Filter != nil {
+		query, err := filterQuery(*input.Filter)
+		if err != nil {
+			return nil, err
+		}
+		query.Sort = servicedto.SortByDateTime
+		query.Limit = MaxBulkReservations
+		page, err := s.reservationClient.QueryReservations(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		if page.Total > MaxBulkReservations {
+			return nil, ErrTooManyReservations
+		}
+		results := make([]servicedto.BulkStatusResult, 0, len(page.Reservations))
+		for _, r := range page.Reservations {
+			res := r
+			results = append(results, servicedto.BulkStatusResult{ID: r.ID, Reservation: &res})
+		}
+		return results, nil
+	}
+
+	if len(input.IDs) > MaxBulkReservations {
+		return nil, ErrTooManyReservations
+	}
+	seen := make(map[uint]bool, len(input.IDs))
+	results := make([]servicedto.BulkStatusResult, 0, len(input.IDs))
+	for _, id := range input.IDs {
+		if id == 0 {
+			return nil, ErrInvalidInput
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		res, err := s.reservationClient.GetReservationByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		result := servicedto.BulkStatusResult{ID: id, Reservation: res}
+		if res == nil {
+			result.Outcome = servicedto.BulkOutcomeNotFound
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
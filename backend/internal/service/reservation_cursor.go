@@ -0,0 +1,59 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// cursorToken is the opaque pagination cursor handed to API clients. It
+// remembers the sort it was issued for so it cannot be replayed against a
+// different ordering.
+type cursorToken struct {
+	Sort      string    `json:"s"`
+	Desc      bool      `json:"o,omitempty"`
+	Date      time.Time `json:"d,omitempty"`
+	Time      string    `json:"t,omitempty"`
+	CreatedAt time.Time `json:"c,omitempty"`
+	People    int       `json:"p,omitempty"`
+	ID        uint      `json:"id"`
+}
+
+func encodeCursor(sort string, desc bool, c *servicedto.ReservationCursor) string {
+	if c == nil {
+		return ""
+	}
+	raw, _ := json.Marshal(cursorToken{
+		Sort:      sort,
+		Desc:      desc,
+		Date:      c.Date,
+		Time:      c.Time,
+		CreatedAt: c.CreatedAt,
+		People:    c.People,
+		ID:        c.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(cursor, sort string, desc bool) (*servicedto.ReservationCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var tok cursorToken
+	if err := json.Unmarshal(raw, &tok); err != nil || tok.ID == 0 || tok.Sort != sort || tok.Desc != desc {
+		return nil, ErrInvalidCursor
+	}
+	return &servicedto.ReservationCursor{
+		Date:      tok.Date,
+		Time:      tok.Time,
+		CreatedAt: tok.CreatedAt,
+		People:    tok.People,
+		ID:        tok.ID,
+	}, nil
+}
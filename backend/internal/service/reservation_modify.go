@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// ModifyReservation changes the date, time, party size or comment of a
+// guest's own pending or confirmed booking. A new slot or party size goes
+// through the checks a new booking would, so a confirmed booking goes back
+// to pending unless the auto-confirm rules confirm it again.
+func (s *ReservationService) ModifyReservation(ctx context.Context, input servicedto.ModifyReservationInput) (*servicedto.Reservation, error) {
+	if input.UserID == 0 || input.ReservationID == 0 || input.People < 0 {
+		return nil, ErrInvalidInput
+	}
+	res, err := s.reservationClient.GetReservationByID(ctx, input.ReservationID)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, ErrReservationNotFound
+	}
+	if res.UserID != input.UserID {
+		return nil, ErrForbiddenReservation
+	}
+	if res.Status != servicedto.StatusPending && res.Status != servicedto.StatusConfirmed {
+		return nil, ErrInvalidStatus
+	}
+
+	reason := "modified by guest"
+	params := servicedto.ModifyReservationParams{
+		ID:           res.ID,
+		From:         res.Status,
+		Date:         res.Date,
+		Time:         res.Time,
+		People:       res.People,
+		Comment:      res.Comment,
+		Status:       res.Status,
+		ReviewReason: res.ReviewReason,
+		Decision:     res.Decision,
+		Actor:        servicedto.StatusActor{UserID: &input.UserID, Source: servicedto.StatusSourceGuest, Reason: &reason},
+	}
+	if input.Date != "" {
+		if params.Date, err = time.Parse("2006-01-02", input.Date); err != nil {
+			return nil, ErrInvalidInput
+		}
+	}
+	if input.Time != "" {
+		slot, err := time.Parse("15:04", input.Time)
+		if err != nil {
+			return nil, ErrInvalidInput
+		}
+		params.Time = slot.Format("15:04")
+	}
+	if input.People > 0 {
+		params.People = input.People
+	}
+	if input.Comment != nil {
+		params.Comment = input.Comment
+		if *input.Comment == "" {
+			params.Comment = nil
+		}
+	}
+
+	rebook := !sameDate(params.Date, res.Date) || params.Time != res.Time || params.People != res.People
+	if rebook {
+		if err := s.recheckBooking(ctx, *res, &params); err != nil {
+			return nil, err
+		}
+	} else if equalComments(params.Comment, res.Comment) {
+		return res, nil
+	}
+
+	updated, err := s.reservationClient.ModifyReservation(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		// The booking changed status while we were looking at it.
+		return nil, ErrInvalidStatus
+	}
+	return updated, nil
+}
+
+// recheckBooking runs the new-booking checks on a changed slot or party
+// size and decides the status the booking continues in.
+func (s *ReservationService) recheckBooking(ctx context.Context, res servicedto.Reservation, params *servicedto.ModifyReservationParams) error {
+	if _, err := normalizeRequirements(s.catalogue, res.Requirements, params.People); err != nil {
+		return err
+	}
+	reviewReason, err := s.checkOverlap(ctx, res.UserID, params.Date, params.Time, res.ID)
+	if err != nil {
+		return err
+	}
+	restrictionReason, depositRequired, err := s.checkRestriction(ctx, res.UserID)
+	if err != nil {
+		return err
+	}
+	reviewReason = joinReasons(restrictionReason, reviewReason)
+
+	var payment *servicedto.Payment
+	if s.paymentClient != nil {
+		if payment, err = s.paymentClient.GetPaymentByReservation(ctx, res.ID); err != nil {
+			return err
+		}
+	}
+	if payment == nil && s.depositAmount(params.Date, params.People, depositRequired) > 0 {
+		return ErrDepositRequired
+	}
+
+	status, decision, err := s.decideStatus(ctx, bookingRequest{
+		reservationID: res.ID,
+		userID:        res.UserID,
+		date:          params.Date,
+		slotTime:      params.Time,
+		people:        params.People,
+		flagged:       reviewReason != nil,
+		deposit:       payment != nil,
+	})
+	if err != nil {
+		return err
+	}
+	params.Status, params.ReviewReason, params.Decision = status, reviewReason, decision
+	return nil
+}
+
+func equalComments(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestModifyReservation(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	client := newFakeReservationClient()
+	users := newFakeUserClient()
+	users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com"})
+	svc := NewReservationService(client, WithOverlapPolicy(servicedto.OverlapPolicyReject), WithGuestClient(users))
+
+	add := func(slot string, status string) uint {
+		res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: 1, Date: mustDate(t, "2030-01-11"), Time: slot, People: 2, Status: status,
+		})
+		return res.ID
+	}
+	id := add("20:00", servicedto.StatusConfirmed)
+	other := add("13:00", servicedto.StatusConfirmed)
+
+	// Moving by an hour overlaps only the booking itself.
+	res, err := svc.ModifyReservation(ctx, servicedto.ModifyReservationInput{UserID: 1, ReservationID: id, Time: "21:00", People: 3})
+	if err != nil {
+		t.Fatalf("modify: %v", err)
+	}
+	if res.Time != "21:00" || res.People != 3 || res.Status != servicedto.StatusPending {
+		t.Fatalf("expected a changed, pending booking, got %+v", res)
+	}
+	last := client.history[len(client.history)-1]
+	if last.FromStatus != servicedto.StatusConfirmed || last.Source != servicedto.StatusSourceGuest {
+		t.Fatalf("unexpected history entry: %+v", last)
+	}
+	// One modified event covers the change, status included.
+	if e := client.events[len(client.events)-1]; e.Type != servicedto.DomainEventReservationModified ||
+		e.FromStatus != servicedto.StatusConfirmed || e.ToStatus != servicedto.StatusPending {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+	if e := client.events[len(client.events)-2]; e.Type != servicedto.DomainEventReservationCreated {
+		t.Fatalf("expected no separate status change event, got %+v", e)
+	}
+
+	svc.ConfirmReservation(ctx, admin, id)
+	comment := "window seat"
+	res, err = svc.ModifyReservation(ctx, servicedto.ModifyReservationInput{UserID: 1, ReservationID: id, Comment: &comment})
+	if err != nil || res.Status != servicedto.StatusConfirmed || *res.Comment != comment {
+		t.Fatalf("expected a comment change to keep the booking confirmed, got %+v %v", res, err)
+	}
+	events := len(client.events)
+	if _, err := svc.ModifyReservation(ctx, servicedto.ModifyReservationInput{UserID: 1, ReservationID: id, Time: "21:00"}); err != nil || len(client.events) != events {
+		t.Fatalf("expected no change and no event, got %v %d", err, len(client.events)-events)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		input servicedto.ModifyReservationInput
+		want  error
+	}{
+		{"overlap", servicedto.ModifyReservationInput{UserID: 1, ReservationID: id, Time: "14:00"}, ErrOverlappingReservation},
+		{"not owner", servicedto.ModifyReservationInput{UserID: 2, ReservationID: id, People: 4}, ErrForbiddenReservation},
+		{"bad time", servicedto.ModifyReservationInput{UserID: 1, ReservationID: id, Time: "late"}, ErrInvalidInput},
+		{"missing", servicedto.ModifyReservationInput{UserID: 1, ReservationID: 999, People: 4}, ErrReservationNotFound},
+	} {
+		if _, err := svc.ModifyReservation(ctx, tc.input); err != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+
+	svc.AdminCancelReservation(ctx, admin, other)
+	if _, err := svc.ModifyReservation(ctx, servicedto.ModifyReservationInput{UserID: 1, ReservationID: other, People: 4}); err != ErrInvalidStatus {
+		t.Fatalf("expected a cancelled booking to be refused, got %v", err)
+	}
+}
+
+func TestModifyReservationDeposit(t *testing.T) {
+	ctx := context.Background()
+	svc, client, _, _ := newDepositTestService(servicedto.DepositPolicy{MinPeople: 8})
+	res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: mustDate(t, "2025-12-10"), Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+	if _, err := svc.ModifyReservation(ctx, servicedto.ModifyReservationInput{UserID: 1, ReservationID: res.ID, People: 8}); err != ErrDepositRequired {
+		t.Fatalf("expected growing past the deposit threshold to be refused, got %v", err)
+	}
+
+	// Bookings that already hold a deposit go back to staff instead.
+	out, _ := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 2, Date: "2025-12-10", Time: "20:00", People: 8})
+	if err := svc.HandlePaymentWebhook(ctx, []byte("ok:"+out.Reservation.Payment.ProviderRef), "signed"); err != nil {
+		t.Fatalf("webhook: %v", err)
+	}
+	modified, err := svc.ModifyReservation(ctx, servicedto.ModifyReservationInput{UserID: 2, ReservationID: out.Reservation.ID, People: 10})
+	if err != nil || modified.People != 10 || modified.Status != servicedto.StatusPending {
+		t.Fatalf("expected the larger party to be pending, got %+v %v", modified, err)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"vesuvio/internal/dto/service"
+)
+
+// maxStaffNoteLength bounds a single staff note, in characters.
+const maxStaffNoteLength = 2000
+
+// AddStaffNote attaches an internal note, authored by the admin, to a
+// reservation.
+func (s *ReservationService) AddStaffNote(ctx context.Context, admin servicedto.User, reservationID uint, body string) (*servicedto.StaffNote, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	body = strings.TrimSpace(body)
+	if reservationID == 0 || body == "" || utf8.RuneCountInString(body) > maxStaffNoteLength {
+		return nil, ErrInvalidInput
+	}
+	if err := s.ensureReservationExists(ctx, reservationID); err != nil {
+		return nil, err
+	}
+
+	return s.reservationClient.AddStaffNote(ctx, servicedto.CreateStaffNoteParams{
+		ReservationID: reservationID,
+		AuthorID:      admin.ID,
+		Body:          body,
+	})
+}
+
+// SetReservationTags replaces the tags of a reservation and returns the
+// normalized set.
+func (s *ReservationService) SetReservationTags(ctx context.Context, admin servicedto.User, reservationID uint, tags []string) ([]string, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	if reservationID == 0 {
+		return nil, ErrInvalidInput
+	}
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureReservationExists(ctx, reservationID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reservationClient.SetReservationTags(ctx, reservationID, tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (s *ReservationService) ensureReservationExists(ctx context.Context, reservationID uint) error {
+	res, err := s.reservationClient.GetReservationByID(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		return ErrReservationNotFound
+	}
+	return nil
+}
+
+// normalizeTags lower-cases, de-duplicates and sorts tags, rejecting any
+// outside the staff vocabulary.
+func normalizeTags(tags []string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !isValidTag(tag) {
+			return nil, ErrInvalidTag
+		}
+		if !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+func isValidTag(tag string) bool {
+	switch tag {
+	case servicedto.TagVIP, servicedto.TagBirthday, servicedto.TagAllergy, servicedto.TagRegular:
+		return true
+	default:
+		return false
+	}
+}
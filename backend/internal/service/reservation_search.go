@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// Limits for the admin reservation search.
+const (
+	DefaultSearchResults = 20
+	MaxSearchResults     = 100
+	minSearchQueryLength = 2
+	maxSearchQueryLength = 100
+	// searchCandidates is how many rows per side of today are ranked.
+	searchCandidates = 200
+)
+
+// AdminSearchReservations finds reservations by guest name, email, phone,
+// confirmation code or comment, best matches first and, among equally good
+// matches, those nearest to today.
+func (s *ReservationService) AdminSearchReservations(ctx context.Context, admin servicedto.User, input servicedto.SearchReservationsInput) ([]servicedto.Reservation, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	term := strings.ToLower(strings.Join(strings.Fields(input.Query), " "))
+	if len(term) < minSearchQueryLength || len(term) > maxSearchQueryLength {
+		return nil, ErrInvalidInput
+	}
+	limit := input.Limit
+	switch {
+	case limit == 0:
+		limit = DefaultSearchResults
+	case limit < 0 || limit > MaxSearchResults:
+		return nil, ErrInvalidInput
+	}
+
+	now := s.now().In(s.location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	digits := phoneDigits(term)
+
+	candidates, err := s.reservationClient.SearchReservations(ctx, servicedto.ReservationSearch{
+		Term:        term,
+		PhoneDigits: digits,
+		Around:      today,
+		Limit:       searchCandidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type ranked struct {
+		res      servicedto.Reservation
+		score    int
+		distance time.Duration
+	}
+	results := make([]ranked, 0, len(candidates))
+	for _, r := range candidates {
+		score := searchScore(r, term, digits)
+		if score == 0 {
+			continue
+		}
+		distance := r.Date.Sub(today)
+		if distance < 0 {
+			distance = -distance
+		}
+		results = append(results, ranked{res: r, score: score, distance: distance})
+	}
+	slices.SortStableFunc(results, func(a, b ranked) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		if a.distance != b.distance {
+			if a.distance < b.distance {
+				return -1
+			}
+			return 1
+		}
+		if c := strings.Compare(a.res.Time, b.res.Time); c != 0 {
+			return c
+		}
+		return int(a.res.ID) - int(b.res.ID)
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	list := make([]servicedto.Reservation, 0, len(results))
+	for _, r := range results {
+		list = append(list, r.res)
+	}
+	if err := s.attachGuestSummaries(ctx, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// searchScore rates how well a reservation matches a lower-case search term.
+// Zero means no match.
+func searchScore(r servicedto.Reservation, term, digits string) int {
+	best := 0
+	consider := func(score int) {
+		if score > best {
+			best = score
+		}
+	}
+
+	if code := strings.ToLower(r.Code); code != "" {
+		switch {
+		case slices.Contains(confirmationCodeCandidates(term), strings.ToUpper(code)):
+			consider(100)
+		case strings.Contains(strings.TrimPrefix(code, strings.ToLower(servicedto.ConfirmationCodePrefix)), term):
+			consider(60)
+		}
+	}
+	if r.User != nil {
+		name := strings.ToLower(r.User.Name)
+		switch {
+		case name == term:
+			consider(90)
+		case strings.HasPrefix(name, term):
+			consider(80)
+		case slices.ContainsFunc(strings.Fields(name), func(w string) bool { return strings.HasPrefix(w, term) }):
+			consider(70)
+		case strings.Contains(name, term):
+			consider(50)
+		}
+
+		email := strings.ToLower(r.User.Email)
+		switch {
+		case email == term:
+			consider(90)
+		case strings.HasPrefix(email, term):
+			consider(55)
+		case strings.Contains(email, term):
+			consider(40)
+		}
+
+		if digits != "" && r.User.Phone != nil && strings.Contains(*r.User.Phone, digits) {
+			consider(75)
+		}
+	}
+	if r.Comment != nil && strings.Contains(strings.ToLower(*r.Comment), term) {
+		consider(20)
+	}
+	return best
+}
+
+// phoneDigits returns the digits of a search term that looks like part of a
+// phone number, or "" when it does not.
+func phoneDigits(term string) string {
+	var b strings.Builder
+	for _, r := range term {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' || r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return ""
+		}
+	}
+	if b.Len() < 3 {
+		return ""
+	}
+	return b.String()
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"time"
 
 	"vesuvio/internal/dto/service"
@@ -11,20 +13,137 @@ import (
 type ReservationClient interface {
 	CreateReservation(ctx context.Context, params servicedto.CreateReservationParams) (*servicedto.Reservation, error)
 	ListReservationsByUser(ctx context.Context, userID uint, status *string) ([]servicedto.Reservation, error)
+	ListReservationsByUsers(ctx context.Context, userIDs []uint) ([]servicedto.Reservation, error)
+	SummarizeGuests(ctx context.Context, query servicedto.GuestSummaryQuery) (map[uint]servicedto.GuestSummary, error)
 	GetReservationByID(ctx context.Context, id uint) (*servicedto.Reservation, error)
-	UpdateReservationStatus(ctx context.Context, id uint, status string) (*servicedto.Reservation, error)
-	ListReservationsByDate(ctx context.Context, date time.Time, status *string) ([]servicedto.Reservation, error)
+	GetReservationByCode(ctx context.Context, code string) (*servicedto.Reservation, error)
+	UpdateReservationStatus(ctx context.Context, params servicedto.UpdateReservationStatusParams) (*servicedto.Reservation, error)
+	UpdateReservationStatuses(ctx context.Context, changes []servicedto.UpdateReservationStatusParams) ([]servicedto.Reservation, error)
+	ModifyReservation(ctx context.Context, params servicedto.ModifyReservationParams) (*servicedto.Reservation, error)
+	EscalateReservation(ctx context.Context, id uint, reason string, at time.Time) (bool, error)
+	ConfirmAttendance(ctx context.Context, id uint, at time.Time) (bool, error)
+	ListStatusChanges(ctx context.Context, reservationID uint) ([]servicedto.StatusChange, error)
+	ListStatusChangesByReservations(ctx context.Context, reservationIDs []uint) ([]servicedto.StatusChange, error)
+	AddStaffNote(ctx context.Context, params servicedto.CreateStaffNoteParams) (*servicedto.StaffNote, error)
+	SetReservationTags(ctx context.Context, reservationID uint, tags []string) error
+	SeatReservation(ctx context.Context, id uint, tableID *uint, at time.Time, actor servicedto.StatusActor) (*servicedto.Reservation, error)
+	QueryReservations(ctx context.Context, query servicedto.ReservationQuery) (*servicedto.ReservationPage, error)
+	SearchReservations(ctx context.Context, search servicedto.ReservationSearch) ([]servicedto.Reservation, error)
+}
+
+// DefaultReservationDuration is how long a table is expected to be occupied.
+const DefaultReservationDuration = 2 * time.Hour
+
+// Page sizes for the admin reservation listing.
+const (
+	DefaultReservationPageSize = 100
+	MaxReservationPageSize     = 500
+)
+
+// GuestClient abstracts the user lookups needed to book on behalf of
+// existing users and phone or walk-in guests.
+type GuestClient interface {
+	GetUserByID(ctx context.Context, id uint) (*servicedto.User, error)
+	GetUserByPhone(ctx context.Context, phone string) (*servicedto.User, error)
+	CreateUser(ctx context.Context, params servicedto.CreateUserParams) (*servicedto.User, error)
 }
 
 type ReservationService struct {
-	reservationClient ReservationClient
+	reservationClient   ReservationClient
+	guestClient         GuestClient
+	tableClient         TableClient
+	reservationDuration time.Duration
+	overlapPolicy       string
+	location            *time.Location
+	catalogue           servicedto.RequirementsCatalogue
+	restrictionClient   RestrictionClient
+	sanctions           servicedto.SanctionPolicy
+	paymentProvider     PaymentProvider
+	paymentClient       PaymentClient
+	deposits            servicedto.DepositPolicy
+	autoConfirm         *servicedto.AutoConfirmPolicy
+	expiry              servicedto.ExpiryPolicy
+	noShowGrace         time.Duration
+	links               *LinkSigner
+	phones              servicedto.PhoneFormat
+	now                 func() time.Time
+}
+
+// ReservationOption customises a ReservationService.
+type ReservationOption func(*ReservationService)
+
+// WithReservationDuration sets the expected length of a booking.
+func WithReservationDuration(d time.Duration) ReservationOption {
+	return func(s *ReservationService) {
+		if d > 0 {
+			s.reservationDuration = d
+		}
+	}
+}
+
+// WithGuestClient enables booking on behalf of other users and guests.
+func WithGuestClient(guestClient GuestClient) ReservationOption {
+	return func(s *ReservationService) {
+		s.guestClient = guestClient
+	}
+}
+
+// WithTableClient enables table assignment for walk-ins and seating.
+func WithTableClient(tableClient TableClient) ReservationOption {
+	return func(s *ReservationService) {
+		s.tableClient = tableClient
+	}
+}
+
+// WithLocation sets the restaurant time zone used to interpret slots.
+func WithLocation(loc *time.Location) ReservationOption {
+	return func(s *ReservationService) {
+		if loc != nil {
+			s.location = loc
+		}
+	}
+}
+
+// WithOverlapPolicy sets how overlapping bookings by the same guest are handled.
+func WithOverlapPolicy(policy string) ReservationOption {
+	return func(s *ReservationService) {
+		s.overlapPolicy = policy
+	}
+}
+
+// WithPhoneFormat sets how the phone numbers of phone guests are read.
+func WithPhoneFormat(f servicedto.PhoneFormat) ReservationOption {
+	return func(s *ReservationService) {
+		s.phones = f
+	}
 }
 
-func NewReservationService(resClient ReservationClient) *ReservationService {
-	return &ReservationService{reservationClient: resClient}
+func NewReservationService(resClient ReservationClient, opts ...ReservationOption) *ReservationService {
+	s := &ReservationService{
+		reservationClient:   resClient,
+		reservationDuration: DefaultReservationDuration,
+		overlapPolicy:       servicedto.OverlapPolicyFlag,
+		location:            time.UTC,
+		catalogue:           DefaultRequirementsCatalogue,
+		sanctions:           normalizeSanctionPolicy(servicedto.SanctionPolicy{}),
+		now:                 time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
+// CreateReservation books online for the signed-in guest, subject to any
+// restriction from past no-shows.
 func (s *ReservationService) CreateReservation(ctx context.Context, input servicedto.CreateReservationInput) (*servicedto.CreateReservationOutput, error) {
+	return s.createReservation(ctx, input, false)
+}
+
+// createReservation validates and stores a new booking, pending unless the
+// auto-confirm rules confirm it. Guest restrictions only apply to bookings
+// guests make themselves.
+func (s *ReservationService) createReservation(ctx context.Context, input servicedto.CreateReservationInput, byStaff bool) (*servicedto.CreateReservationOutput, error) {
 	if input.UserID == 0 || input.Date == "" || input.Time == "" || input.People <= 0 {
 		return nil, ErrInvalidInput
 	}
@@ -33,22 +152,317 @@ func (s *ReservationService) CreateReservation(ctx context.Context, input servic
 	if err != nil {
 		return nil, ErrInvalidInput
 	}
+	slot, err := time.Parse("15:04", input.Time)
+	if err != nil {
+		return nil, ErrInvalidInput
+	}
+	slotTime := slot.Format("15:04")
+	channel := input.Channel
+	if channel == "" {
+		channel = servicedto.ChannelWeb
+	}
+	if !isValidChannel(channel) {
+		return nil, ErrInvalidInput
+	}
+
+	requirements, err := normalizeRequirements(s.catalogue, input.Requirements, input.People)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewReason, err := s.checkOverlap(ctx, input.UserID, parsedDate, slotTime, 0)
+	if err != nil {
+		return nil, err
+	}
+	var deposit int64
+	if !byStaff {
+		restrictionReason, depositRequired, err := s.checkRestriction(ctx, input.UserID)
+		if err != nil {
+			return nil, err
+		}
+		reviewReason = joinReasons(restrictionReason, reviewReason)
+		deposit = s.depositAmount(parsedDate, input.People, depositRequired)
+	}
+	status, decision, err := s.decideStatus(ctx, bookingRequest{
+		userID:   input.UserID,
+		date:     parsedDate,
+		slotTime: slotTime,
+		people:   input.People,
+		flagged:  reviewReason != nil,
+		deposit:  deposit > 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if deposit > 0 {
+		status = servicedto.StatusAwaitingPayment
+	}
 
 	res, err := s.reservationClient.CreateReservation(ctx, servicedto.CreateReservationParams{
-		UserID:  input.UserID,
-		Date:    parsedDate,
-		Time:    input.Time,
-		People:  input.People,
-		Comment: input.Comment,
-		Status:  servicedto.StatusPending,
+		UserID:       input.UserID,
+		Date:         parsedDate,
+		Time:         slotTime,
+		People:       input.People,
+		Comment:      input.Comment,
+		Requirements: requirements,
+		Status:       status,
+		Channel:      channel,
+		ReviewReason: reviewReason,
+		Decision:     decision,
+		Overlap:      s.overlapGuard(),
 	})
 	if err != nil {
 		return nil, err
 	}
+	if res == nil {
+		// A concurrent booking by the same guest won the race.
+		return nil, ErrOverlappingReservation
+	}
+	if deposit > 0 {
+		if err := s.requestPayment(ctx, res, deposit); err != nil {
+			// Do not hold the slot for a booking the guest cannot pay for.
+			reason := "payment could not be started"
+			_, _ = s.reservationClient.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{
+				ID:     res.ID,
+				Status: servicedto.StatusCancelled,
+				Actor:  servicedto.StatusActor{Source: servicedto.StatusSourceSystem, Reason: &reason},
+			})
+			return nil, err
+		}
+	}
 
 	return &servicedto.CreateReservationOutput{Reservation: *res}, nil
 }
 
+// AdminCreateReservation books on behalf of an existing user or a phone or
+// walk-in guest. Guests are matched by phone number so repeat callers reuse
+// the same record.
+func (s *ReservationService) AdminCreateReservation(ctx context.Context, admin servicedto.User, input servicedto.AdminCreateReservationInput) (*servicedto.CreateReservationOutput, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	if s.guestClient == nil {
+		return nil, errGuestClientMissing
+	}
+	channel := input.Channel
+	if channel == "" {
+		channel = servicedto.ChannelPhone
+	}
+
+	user, err := s.resolveBookingUser(ctx, input.UserID, input.Guest)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.createReservation(ctx, servicedto.CreateReservationInput{
+		UserID:       user.ID,
+		Date:         input.Date,
+		Time:         input.Time,
+		People:       input.People,
+		Comment:      input.Comment,
+		Requirements: input.Requirements,
+		Channel:      channel,
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+	out.Reservation.User = user
+	return out, nil
+}
+
+// WalkIn seats a party that arrived without a booking. The reservation is
+// created for the current time and skips the overlap policy, since the
+// guests are already in the room.
+func (s *ReservationService) WalkIn(ctx context.Context, admin servicedto.User, input servicedto.WalkInInput) (*servicedto.Reservation, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	if s.guestClient == nil {
+		return nil, errGuestClientMissing
+	}
+	if input.People <= 0 {
+		return nil, ErrInvalidInput
+	}
+
+	user, err := s.resolveBookingUser(ctx, input.UserID, input.Guest)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().In(s.location)
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if input.TableID != nil {
+		if err := s.checkTableFree(ctx, *input.TableID, 0, now); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := s.reservationClient.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID:   user.ID,
+		Date:     date,
+		Time:     now.Format("15:04"),
+		People:   input.People,
+		TableID:  input.TableID,
+		Comment:  input.Comment,
+		Status:   servicedto.StatusSeated,
+		Channel:  servicedto.ChannelWalkIn,
+		SeatedAt: &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.User = user
+	return res, nil
+}
+
+// SeatReservation marks a booked party as arrived, optionally at a table.
+func (s *ReservationService) SeatReservation(ctx context.Context, admin servicedto.User, input servicedto.SeatReservationInput) (*servicedto.Reservation, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	if input.ReservationID == 0 {
+		return nil, ErrInvalidInput
+	}
+
+	res, err := s.reservationClient.GetReservationByID(ctx, input.ReservationID)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, ErrReservationNotFound
+	}
+	if res.Status == servicedto.StatusCancelled {
+		return nil, ErrInvalidStatus
+	}
+	now := s.now().In(s.location)
+	if !sameDate(res.Date, now) {
+		return nil, ErrNotToday
+	}
+	if input.TableID != nil {
+		if err := s.checkTableFree(ctx, *input.TableID, res.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.settlePayment(ctx, res.ID, settleArrived); err != nil {
+		return nil, err
+	}
+
+	return s.reservationClient.SeatReservation(ctx, res.ID, input.TableID, now, adminActor(admin))
+}
+
+// checkTableFree fails when the table does not exist or another seated
+// party is expected to still be at it at the given time.
+func (s *ReservationService) checkTableFree(ctx context.Context, tableID, ignoreReservationID uint, at time.Time) error {
+	if s.tableClient == nil {
+		return errTableClientMissing
+	}
+	table, err := s.tableClient.GetTableByID(ctx, tableID)
+	if err != nil {
+		return err
+	}
+	if table == nil {
+		return ErrTableNotFound
+	}
+
+	// Parties seated late yesterday may still be at the table.
+	date := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	seated, err := reservationsBetween(ctx, s.reservationClient, date.AddDate(0, 0, -1), date, servicedto.StatusSeated)
+	if err != nil {
+		return err
+	}
+	for _, r := range seated {
+		if r.ID == ignoreReservationID || r.TableID == nil || *r.TableID != tableID {
+			continue
+		}
+		if isInRoom(r, at, s.location, s.reservationDuration) {
+			return ErrTableOccupied
+		}
+	}
+	return nil
+}
+
+// resolveBookingUser returns the user a staff booking is for: the existing
+// user when userID is set, otherwise the guest with that phone, created on
+// first use.
+func (s *ReservationService) resolveBookingUser(ctx context.Context, userID uint, guest *servicedto.GuestInput) (*servicedto.User, error) {
+	if (userID == 0) == (guest == nil) {
+		return nil, ErrInvalidInput
+	}
+
+	if userID != 0 {
+		user, err := s.guestClient.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrUserNotFound
+		}
+		return user, nil
+	}
+
+	name := strings.TrimSpace(guest.Name)
+	if name == "" {
+		return nil, ErrInvalidInput
+	}
+	phone, ok := s.phones.Normalize(guest.Phone)
+	if !ok {
+		return nil, ErrInvalidPhone
+	}
+
+	existing, err := s.guestClient.GetUserByPhone(ctx, phone)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	return s.guestClient.CreateUser(ctx, servicedto.CreateUserParams{
+		Name:  name,
+		Email: guestEmail(phone),
+		Phone: &phone,
+		// No password hash: guests cannot log in.
+		IsGuest: true,
+	})
+}
+
+// checkOverlap applies the overlap policy to a new booking by userID, or to
+// a changed booking ignoreID. Under the flag policy it returns the review
+// reason to store on the reservation.
+func (s *ReservationService) checkOverlap(ctx context.Context, userID uint, date time.Time, slotTime string, ignoreID uint) (*string, error) {
+	guard := s.overlapGuard()
+	if guard == nil {
+		return nil, nil
+	}
+
+	existing, err := s.reservationClient.ListReservationsByUser(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	conflict := guard.Conflict(existing, date, slotTime, ignoreID)
+	if conflict == nil {
+		return nil, nil
+	}
+	if guard.Reject {
+		return nil, ErrOverlappingReservation
+	}
+	reason := guard.Reason(*conflict)
+	return &reason, nil
+}
+
+// overlapGuard returns the overlap rule the client re-checks at insert time,
+// or nil when the policy is off.
+func (s *ReservationService) overlapGuard() *servicedto.OverlapGuard {
+	if s.overlapPolicy != servicedto.OverlapPolicyReject && s.overlapPolicy != servicedto.OverlapPolicyFlag {
+		return nil
+	}
+	return &servicedto.OverlapGuard{
+		Window: s.reservationDuration,
+		Reject: s.overlapPolicy == servicedto.OverlapPolicyReject,
+	}
+}
+
 func (s *ReservationService) ListUserReservations(ctx context.Context, input servicedto.ListUserReservationsInput) ([]servicedto.Reservation, error) {
 	if input.UserID == 0 {
 		return nil, ErrInvalidInput
@@ -79,44 +493,170 @@ func (s *ReservationService) CancelReservation(ctx context.Context, input servic
 		return res, nil
 	}
 
-	updated, err := s.reservationClient.UpdateReservationStatus(ctx, input.ReservationID, servicedto.StatusCancelled)
+	updated, err := s.reservationClient.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{
+		ID:     input.ReservationID,
+		Status: servicedto.StatusCancelled,
+		From:   res.Status,
+		Actor:  servicedto.StatusActor{UserID: &input.UserID, Source: servicedto.StatusSourceGuest},
+	})
 	if err != nil {
 		return nil, err
 	}
+	if updated == nil {
+		return nil, ErrStatusConflict
+	}
+	s.settleEnded(ctx, res.ID, s.cancelSettlement(*res))
+	if s.isLateCancel(*res) {
+		if err := s.recordIncident(ctx, *updated, servicedto.IncidentLateCancel); err != nil {
+			return nil, err
+		}
+	}
 	return updated, nil
 }
 
-func (s *ReservationService) AdminListReservations(ctx context.Context, input servicedto.AdminListReservationsInput) ([]servicedto.Reservation, error) {
-	if input.Date == "" {
+// AdminListReservations filters, sorts and paginates reservations for staff.
+// At least one of Date, From or To is required so listings stay bounded.
+func (s *ReservationService) AdminListReservations(ctx context.Context, input servicedto.AdminListReservationsInput) (*servicedto.AdminListReservationsOutput, error) {
+	statuses := input.Statuses
+	if input.Status != nil {
+		statuses = append(statuses, *input.Status)
+	}
+	query, err := filterQuery(servicedto.BulkReservationFilter{
+		Date:      input.Date,
+		From:      input.From,
+		To:        input.To,
+		Statuses:  statuses,
+		Tags:      input.Tags,
+		MinPeople: input.MinPeople,
+		MaxPeople: input.MaxPeople,
+	})
+	if err != nil {
+		return nil, err
+	}
+	query.Sort = input.Sort
+	query.Limit = input.Limit
+
+	switch query.Sort {
+	case "":
+		query.Sort = servicedto.SortByDateTime
+	case servicedto.SortByDateTime, servicedto.SortByCreatedAt, servicedto.SortByPeople:
+	default:
 		return nil, ErrInvalidInput
 	}
-	if input.Status != nil && !isValidStatus(*input.Status) {
-		return nil, ErrInvalidStatus
+	switch input.Order {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return nil, ErrInvalidInput
+	}
+
+	switch {
+	case query.Limit == 0:
+		query.Limit = DefaultReservationPageSize
+	case query.Limit < 0 || query.Limit > MaxReservationPageSize:
+		return nil, ErrInvalidInput
+	}
+
+	after, err := decodeCursor(input.Cursor, query.Sort, query.Descending)
+	if err != nil {
+		return nil, err
 	}
+	query.After = after
 
-	date, err := time.Parse("2006-01-02", input.Date)
+	page, err := s.reservationClient.QueryReservations(ctx, query)
 	if err != nil {
+		return nil, err
+	}
+	if err := s.attachGuestSummaries(ctx, page.Reservations); err != nil {
+		return nil, err
+	}
+	return &servicedto.AdminListReservationsOutput{
+		Reservations: page.Reservations,
+		Total:        page.Total,
+		NextCursor:   encodeCursor(query.Sort, query.Descending, page.Next),
+	}, nil
+}
+
+// AdminGetReservationByCode looks up a reservation by its confirmation code.
+// The prefix is optional and matching ignores case and whitespace, so hosts
+// can type whatever the guest reads out.
+func (s *ReservationService) AdminGetReservationByCode(ctx context.Context, admin servicedto.User, code string) (*servicedto.Reservation, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	candidates := confirmationCodeCandidates(code)
+	if len(candidates) == 0 {
 		return nil, ErrInvalidInput
 	}
 
-	return s.reservationClient.ListReservationsByDate(ctx, date, input.Status)
+	var res *servicedto.Reservation
+	for _, candidate := range candidates {
+		var err error
+		if res, err = s.reservationClient.GetReservationByCode(ctx, candidate); err != nil {
+			return nil, err
+		}
+		if res != nil {
+			break
+		}
+	}
+	if res == nil {
+		return nil, ErrReservationNotFound
+	}
+	list := []servicedto.Reservation{*res}
+	if err := s.attachGuestSummaries(ctx, list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
 }
 
 func (s *ReservationService) ConfirmReservation(ctx context.Context, admin servicedto.User, reservationID uint) (*servicedto.Reservation, error) {
 	if !admin.IsAdmin {
 		return nil, ErrUnauthorized
 	}
-	return s.updateReservationStatus(ctx, reservationID, servicedto.StatusConfirmed)
+	return s.updateReservationStatus(ctx, reservationID, servicedto.StatusConfirmed, adminActor(admin))
 }
 
 func (s *ReservationService) AdminCancelReservation(ctx context.Context, admin servicedto.User, reservationID uint) (*servicedto.Reservation, error) {
 	if !admin.IsAdmin {
 		return nil, ErrUnauthorized
 	}
-	return s.updateReservationStatus(ctx, reservationID, servicedto.StatusCancelled)
+	return s.updateReservationStatus(ctx, reservationID, servicedto.StatusCancelled, adminActor(admin))
 }
 
-func (s *ReservationService) updateReservationStatus(ctx context.Context, reservationID uint, status string) (*servicedto.Reservation, error) {
+// MarkNoShow records that a pending or confirmed party never arrived.
+func (s *ReservationService) MarkNoShow(ctx context.Context, admin servicedto.User, reservationID uint) (*servicedto.Reservation, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	if reservationID == 0 {
+		return nil, ErrInvalidInput
+	}
+	res, err := s.reservationClient.GetReservationByID(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, ErrReservationNotFound
+	}
+	switch res.Status {
+	case servicedto.StatusNoShow:
+		return res, nil
+	case servicedto.StatusPending, servicedto.StatusConfirmed:
+	default:
+		return nil, ErrInvalidStatus
+	}
+	updated, err := s.updateReservationStatus(ctx, reservationID, servicedto.StatusNoShow, adminActor(admin))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordIncident(ctx, *updated, servicedto.IncidentNoShow); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *ReservationService) updateReservationStatus(ctx context.Context, reservationID uint, status string, actor servicedto.StatusActor) (*servicedto.Reservation, error) {
 	if reservationID == 0 {
 		return nil, ErrInvalidInput
 	}
@@ -131,13 +671,246 @@ func (s *ReservationService) updateReservationStatus(ctx context.Context, reserv
 	if res == nil {
 		return nil, ErrReservationNotFound
 	}
+	if res.Status == status {
+		return s.reservationClient.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{
+			ID:     reservationID,
+			Status: status,
+			Actor:  actor,
+		})
+	}
+
+	// The change is a compare-and-set from the status read, and the payment
+	// is only settled once it is stored.
+	updated, err := s.reservationClient.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{
+		ID:     reservationID,
+		Status: status,
+		From:   res.Status,
+		Actor:  actor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrStatusConflict
+	}
+	s.settleEnded(ctx, res.ID, settlementFor(status))
+	return updated, nil
+}
+
+// AdminReservationHistory returns the status changes of a reservation,
+// oldest first.
+func (s *ReservationService) AdminReservationHistory(ctx context.Context, admin servicedto.User, reservationID uint) ([]servicedto.StatusChange, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	res, err := s.reservationClient.GetReservationByID(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, ErrReservationNotFound
+	}
+	return s.reservationClient.ListStatusChanges(ctx, reservationID)
+}
+
+func adminActor(admin servicedto.User) servicedto.StatusActor {
+	id := admin.ID
+	return servicedto.StatusActor{UserID: &id, Source: servicedto.StatusSourceAdmin}
+}
+
+// confirmationCodeCandidates lists the codes a host may have meant, most
+// literal first: the code as typed, then with a single prefix added or its
+// dash restored. Random parts can themselves start with the prefix letters,
+// so the exact code must win.
+func confirmationCodeCandidates(code string) []string {
+	code = strings.ToUpper(strings.Join(strings.Fields(code), ""))
+	if code == "" {
+		return nil
+	}
+	prefix := servicedto.ConfirmationCodePrefix
+	var candidates []string
+	for _, c := range []string{
+		code,
+		prefix + strings.TrimPrefix(code, prefix),
+		prefix + strings.TrimPrefix(code, strings.TrimSuffix(prefix, "-")),
+	} {
+		if c != prefix && strings.HasPrefix(c, prefix) && !slices.Contains(candidates, c) {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
+
+func isValidChannel(channel string) bool {
+	switch channel {
+	case servicedto.ChannelWeb, servicedto.ChannelPhone, servicedto.ChannelWalkIn, servicedto.ChannelPartner:
+		return true
+	default:
+		return false
+	}
+}
+
+// isActiveStatus reports whether a reservation still holds its slot.
+func isActiveStatus(status string) bool {
+	switch status {
+	case servicedto.StatusPending, servicedto.StatusConfirmed, servicedto.StatusSeated, servicedto.StatusAwaitingPayment:
+		return true
+	default:
+		return false
+	}
+}
+
+// filterQuery validates a reservation filter and turns it into a query. At
+// least one of Date, From or To is required so results stay bounded.
+func filterQuery(filter servicedto.BulkReservationFilter) (servicedto.ReservationQuery, error) {
+	var query servicedto.ReservationQuery
+	if filter.Date != "" && (filter.From != "" || filter.To != "") {
+		return query, ErrInvalidInput
+	}
+	if filter.Date != "" {
+		filter.From, filter.To = filter.Date, filter.Date
+	}
+	if filter.From == "" && filter.To == "" {
+		return query, ErrInvalidInput
+	}
+
+	for _, bound := range []struct {
+		raw  string
+		dest **time.Time
+	}{{filter.From, &query.From}, {filter.To, &query.To}} {
+		if bound.raw == "" {
+			continue
+		}
+		d, err := time.Parse("2006-01-02", bound.raw)
+		if err != nil {
+			return query, ErrInvalidInput
+		}
+		*bound.dest = &d
+	}
+	if query.From != nil && query.To != nil && query.To.Before(*query.From) {
+		return query, ErrInvalidInput
+	}
+
+	for _, st := range filter.Statuses {
+		if !isValidStatus(st) {
+			return query, ErrInvalidStatus
+		}
+	}
+	query.Statuses = filter.Statuses
+
+	tags, err := normalizeTags(filter.Tags)
+	if err != nil {
+		return query, err
+	}
+	if len(tags) > 0 {
+		query.Tags = tags
+	}
+
+	if filter.MinPeople < 0 || filter.MaxPeople < 0 || (filter.MaxPeople > 0 && filter.MinPeople > filter.MaxPeople) {
+		return query, ErrInvalidInput
+	}
+	query.MinPeople = filter.MinPeople
+	query.MaxPeople = filter.MaxPeople
+	return query, nil
+}
+
+// reservationsOn lists every reservation on a date, optionally limited to
+// some statuses.
+func reservationsOn(ctx context.Context, client ReservationClient, date time.Time, statuses ...string) ([]servicedto.Reservation, error) {
+	return reservationsBetween(ctx, client, date, date, statuses...)
+}
+
+// reservationsBetween returns the reservations dated from through to.
+func reservationsBetween(ctx context.Context, client ReservationClient, from, to time.Time, statuses ...string) ([]servicedto.Reservation, error) {
+	page, err := client.QueryReservations(ctx, servicedto.ReservationQuery{
+		From:     &from,
+		To:       &to,
+		Statuses: statuses,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page.Reservations, nil
+}
+
+// attachGuestSummaries sets the compact guest summary on reservations shown
+// to staff.
+func (s *ReservationService) attachGuestSummaries(ctx context.Context, list []servicedto.Reservation) error {
+	if len(list) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(list))
+	for i, r := range list {
+		ids[i] = r.ID
+	}
+	now := s.now().In(s.location)
+	summaries, err := s.reservationClient.SummarizeGuests(ctx, servicedto.GuestSummaryQuery{
+		ReservationIDs: ids,
+		Today:          time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+		Clock:          now.Format("15:04"),
+	})
+	if err != nil {
+		return err
+	}
+	for i := range list {
+		if summary, ok := summaries[list[i].ID]; ok {
+			list[i].Guest = &summary
+		}
+	}
+	return nil
+}
+
+// slotStart returns when the reservation starts in the restaurant's time zone.
+func slotStart(r servicedto.Reservation, loc *time.Location) (time.Time, bool) {
+	minutes := minutesOfDay(r.Time)
+	if minutes < 0 {
+		return time.Time{}, false
+	}
+	y, m, d := r.Date.Date()
+	return time.Date(y, m, d, minutes/60, minutes%60, 0, 0, loc), true
+}
+
+// seatedSince returns when a seated party sat down, falling back to the slot
+// start for parties moved to seated without going through seating.
+func seatedSince(r servicedto.Reservation, loc *time.Location) (time.Time, bool) {
+	if r.SeatedAt != nil {
+		return *r.SeatedAt, true
+	}
+	return slotStart(r, loc)
+}
+
+// isInRoom reports whether a seated party is expected to still be at its
+// table at the given time.
+func isInRoom(r servicedto.Reservation, at time.Time, loc *time.Location, duration time.Duration) bool {
+	if r.Status != servicedto.StatusSeated {
+		return false
+	}
+	start, ok := seatedSince(r, loc)
+	if !ok {
+		return false
+	}
+	return !at.Before(start) && at.Before(start.Add(duration))
+}
+
+// minutesOfDay converts HH:MM to minutes after midnight, or -1 if malformed.
+func minutesOfDay(hhmm string) int {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return -1
+	}
+	return t.Hour()*60 + t.Minute()
+}
 
-	return s.reservationClient.UpdateReservationStatus(ctx, reservationID, status)
+func sameDate(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
 }
 
 func isValidStatus(status string) bool {
 	switch status {
-	case servicedto.StatusPending, servicedto.StatusConfirmed, servicedto.StatusCancelled:
+	case servicedto.StatusPending, servicedto.StatusConfirmed, servicedto.StatusCancelled, servicedto.StatusSeated, servicedto.StatusNoShow,
+		servicedto.StatusAwaitingPayment:
 		return true
 	default:
 		return false
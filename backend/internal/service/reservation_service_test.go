@@ -1,7 +1,11 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -206,6 +210,22 @@ func TestAdminListReservationsValidation(t *testing.T) {
 	if err != ErrInvalidInput {
 		t.Fatalf("expected ErrInvalidInput for bad date, got %v", err)
 	}
+
+	for name, input := range map[string]servicedto.AdminListReservationsInput{
+		"date with range": {Date: "2025-01-01", From: "2025-01-01"},
+		"reversed range":  {From: "2025-01-05", To: "2025-01-01"},
+		"unknown sort":    {Date: "2025-01-01", Sort: "name"},
+		"unknown order":   {Date: "2025-01-01", Order: "up"},
+		"limit too large": {Date: "2025-01-01", Limit: MaxReservationPageSize + 1},
+		"people inverted": {Date: "2025-01-01", MinPeople: 6, MaxPeople: 2},
+	} {
+		if _, err := svc.AdminListReservations(ctx, input); err != ErrInvalidInput {
+			t.Fatalf("%s: expected ErrInvalidInput, got %v", name, err)
+		}
+	}
+	if _, err := svc.AdminListReservations(ctx, servicedto.AdminListReservationsInput{Date: "2025-01-01", Cursor: "garbage"}); err != ErrInvalidCursor {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
 }
 
 func TestAdminCancelReservationUnauthorized(t *testing.T) {
@@ -339,15 +359,753 @@ func TestAdminListReservationsWithStatus(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(res) != 1 || res[0].Status != servicedto.StatusConfirmed {
+	if len(res.Reservations) != 1 || res.Reservations[0].Status != servicedto.StatusConfirmed || res.Total != 1 {
 		t.Fatalf("unexpected admin list result: %+v", res)
 	}
 }
 
+func TestAdminListReservationsPagination(t *testing.T) {
+	client := newFakeReservationClient()
+	svc := NewReservationService(client)
+	ctx := context.Background()
+
+	for i, day := range []int{3, 1, 2, 1, 5} {
+		client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: uint(i + 1),
+			Date:   time.Date(2025, 12, day, 0, 0, 0, 0, time.UTC),
+			Time:   "20:00",
+			People: i + 1,
+			Status: servicedto.StatusPending,
+		})
+	}
+
+	input := servicedto.AdminListReservationsInput{From: "2025-12-01", To: "2025-12-03", Limit: 2}
+	first, err := svc.AdminListReservations(ctx, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Total != 4 || len(first.Reservations) != 2 || first.NextCursor == "" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	if first.Reservations[0].ID != 2 || first.Reservations[1].ID != 4 {
+		t.Fatalf("expected ids 2,4 on first page, got %d,%d", first.Reservations[0].ID, first.Reservations[1].ID)
+	}
+
+	input.Cursor = first.NextCursor
+	second, err := svc.AdminListReservations(ctx, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.Reservations) != 2 || second.NextCursor != "" {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+	if second.Reservations[0].ID != 3 || second.Reservations[1].ID != 1 {
+		t.Fatalf("expected ids 3,1 on second page, got %d,%d", second.Reservations[0].ID, second.Reservations[1].ID)
+	}
+
+	// A cursor only works with the ordering it was issued for.
+	input.Order = "desc"
+	if _, err := svc.AdminListReservations(ctx, input); err != ErrInvalidCursor {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestAdminListReservationsSortByPeople(t *testing.T) {
+	client := newFakeReservationClient()
+	svc := NewReservationService(client)
+	ctx := context.Background()
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, people := range []int{4, 8, 2} {
+		client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: 1, Date: date, Time: "20:00", People: people, Status: servicedto.StatusConfirmed,
+		})
+	}
+
+	res, err := svc.AdminListReservations(ctx, servicedto.AdminListReservationsInput{
+		Date:      "2025-12-01",
+		Sort:      servicedto.SortByPeople,
+		Order:     "desc",
+		MinPeople: 3,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Reservations) != 2 || res.Reservations[0].People != 8 || res.Reservations[1].People != 4 {
+		t.Fatalf("unexpected sorted result: %+v", res.Reservations)
+	}
+}
+
+func TestAdminSearchReservationsRanking(t *testing.T) {
+	client := newFakeReservationClient()
+	svc := NewReservationService(client)
+	svc.now = func() time.Time { return time.Date(2025, 12, 10, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+
+	phone := "+34911222333"
+	note := "Garcia's anniversary, friend of Maria"
+	seed := []struct {
+		day     int
+		user    servicedto.User
+		comment *string
+	}{
+		{20, servicedto.User{Name: "Maria Garcia", Email: "maria@example.com"}, nil},
+		{11, servicedto.User{Name: "Pablo Garcia", Email: "pablo@example.com", Phone: &phone}, nil},
+		{9, servicedto.User{Name: "Anna Rossi", Email: "anna@example.com"}, &note},
+		{10, servicedto.User{Name: "Luca Bianchi", Email: "luca@example.com"}, nil},
+	}
+	for _, s := range seed {
+		res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID:  1,
+			Date:    time.Date(2025, 12, s.day, 0, 0, 0, 0, time.UTC),
+			Time:    "20:00",
+			People:  2,
+			Comment: s.comment,
+			Status:  servicedto.StatusConfirmed,
+		})
+		user := s.user
+		res.User = &user
+		client.reservations[res.ID] = *res
+	}
+
+	list, err := svc.AdminSearchReservations(ctx, admin, servicedto.SearchReservationsInput{Query: "  GARCIA "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Name matches rank above the comment match; the nearer booking wins the tie.
+	if len(list) != 3 || list[0].ID != 2 || list[1].ID != 1 || list[2].ID != 3 {
+		t.Fatalf("unexpected ranking: %+v", list)
+	}
+
+	list, err = svc.AdminSearchReservations(ctx, admin, servicedto.SearchReservationsInput{Query: "911 222"})
+	if err != nil || len(list) != 1 || list[0].ID != 2 {
+		t.Fatalf("expected phone match, got %+v (%v)", list, err)
+	}
+
+	list, err = svc.AdminSearchReservations(ctx, admin, servicedto.SearchReservationsInput{Query: "vsv-000004"})
+	if err != nil || len(list) != 1 || list[0].ID != 4 {
+		t.Fatalf("expected code match, got %+v (%v)", list, err)
+	}
+
+	if _, err := svc.AdminSearchReservations(ctx, admin, servicedto.SearchReservationsInput{Query: "g"}); err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput for short query, got %v", err)
+	}
+	if _, err := svc.AdminSearchReservations(ctx, servicedto.User{ID: 1}, servicedto.SearchReservationsInput{Query: "garcia"}); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestBulkUpdateStatusPerItem(t *testing.T) {
+	client := newFakeReservationClient()
+	svc := NewReservationService(client)
+	ctx := context.Background()
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	pending, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	confirmed, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 2, Date: date, Time: "21:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+
+	out, err := svc.BulkUpdateStatus(ctx, admin, servicedto.BulkStatusInput{
+		IDs:    []uint{pending.ID, confirmed.ID, 404, pending.ID},
+		Status: servicedto.StatusConfirmed,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Updated != 1 || out.Unchanged != 1 || out.Failed != 1 || len(out.Results) != 3 {
+		t.Fatalf("unexpected summary: %+v", out)
+	}
+	want := []string{servicedto.BulkOutcomeUpdated, servicedto.BulkOutcomeUnchanged, servicedto.BulkOutcomeNotFound}
+	for i, r := range out.Results {
+		if r.Outcome != want[i] {
+			t.Fatalf("result %d: expected %s, got %+v", i, want[i], r)
+		}
+	}
+
+	history, _ := svc.AdminReservationHistory(ctx, admin, pending.ID)
+	if len(history) != 1 || history[0].Source != servicedto.StatusSourceAdminBulk || *history[0].ActorID != admin.ID {
+		t.Fatalf("expected bulk history entry, got %+v", history)
+	}
+}
+
+func TestBulkUpdateStatusAtomic(t *testing.T) {
+	client := newFakeReservationClient()
+	svc := NewReservationService(client)
+	ctx := context.Background()
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+
+	_, err := svc.BulkUpdateStatus(ctx, admin, servicedto.BulkStatusInput{
+		IDs:    []uint{res.ID, 404},
+		Status: servicedto.StatusCancelled,
+		Atomic: true,
+	})
+	if err != ErrReservationNotFound {
+		t.Fatalf("expected ErrReservationNotFound, got %v", err)
+	}
+	if client.reservations[res.ID].Status != servicedto.StatusPending {
+		t.Fatalf("expected no change after failed atomic bulk")
+	}
+
+	out, err := svc.BulkUpdateStatus(ctx, admin, servicedto.BulkStatusInput{
+		Filter: &servicedto.BulkReservationFilter{Date: "2025-12-01", Statuses: []string{servicedto.StatusPending}},
+		Status: servicedto.StatusCancelled,
+		Atomic: true,
+	})
+	if err != nil || out.Updated != 1 || client.reservations[res.ID].Status != servicedto.StatusCancelled {
+		t.Fatalf("unexpected atomic filter result: %+v (%v)", out, err)
+	}
+}
+
+func TestBulkUpdateStatusValidation(t *testing.T) {
+	svc := NewReservationService(newFakeReservationClient())
+	ctx := context.Background()
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+	filter := &servicedto.BulkReservationFilter{Date: "2025-12-01"}
+
+	if _, err := svc.BulkUpdateStatus(ctx, servicedto.User{ID: 1}, servicedto.BulkStatusInput{IDs: []uint{1}, Status: servicedto.StatusConfirmed}); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if _, err := svc.BulkUpdateStatus(ctx, admin, servicedto.BulkStatusInput{IDs: []uint{1}, Status: "weird"}); err != ErrInvalidStatus {
+		t.Fatalf("expected ErrInvalidStatus, got %v", err)
+	}
+	if _, err := svc.BulkUpdateStatus(ctx, admin, servicedto.BulkStatusInput{Status: servicedto.StatusConfirmed}); err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput without selection, got %v", err)
+	}
+	if _, err := svc.BulkUpdateStatus(ctx, admin, servicedto.BulkStatusInput{IDs: []uint{1}, Filter: filter, Status: servicedto.StatusConfirmed}); err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput with ids and filter, got %v", err)
+	}
+	if _, err := svc.BulkUpdateStatus(ctx, admin, servicedto.BulkStatusInput{IDs: make([]uint, MaxBulkReservations+1), Status: servicedto.StatusConfirmed}); err != ErrTooManyReservations {
+		t.Fatalf("expected ErrTooManyReservations, got %v", err)
+	}
+}
+
+func TestAdminConfirmRecordsHistory(t *testing.T) {
+	client := newFakeReservationClient()
+	svc := NewReservationService(client)
+	ctx := context.Background()
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+
+	res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 2, Status: servicedto.StatusPending,
+	})
+	if _, err := svc.ConfirmReservation(ctx, admin, res.ID); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if _, err := svc.CancelReservation(ctx, servicedto.CancelReservationInput{UserID: 1, ReservationID: res.ID}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	history, err := svc.AdminReservationHistory(ctx, admin, res.ID)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(history) != 2 || history[0].Source != servicedto.StatusSourceAdmin || history[1].Source != servicedto.StatusSourceGuest {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+}
+
+func TestStaffNotesAndTags(t *testing.T) {
+	client := newFakeReservationClient()
+	svc := NewReservationService(client)
+	ctx := context.Background()
+	admin := servicedto.User{ID: 99, Name: "Host", IsAdmin: true}
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	vip, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+	client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 2, Date: date, Time: "21:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+
+	note, err := svc.AddStaffNote(ctx, admin, vip.ID, "  allergic to shellfish, seat away from door ")
+	if err != nil {
+		t.Fatalf("add note: %v", err)
+	}
+	if note.AuthorID != admin.ID || note.Body != "allergic to shellfish, seat away from door" {
+		t.Fatalf("unexpected note: %+v", note)
+	}
+	if _, err := svc.AddStaffNote(ctx, admin, vip.ID, "   "); err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput for empty note, got %v", err)
+	}
+	if _, err := svc.AddStaffNote(ctx, admin, 404, "note"); err != ErrReservationNotFound {
+		t.Fatalf("expected ErrReservationNotFound, got %v", err)
+	}
+	if _, err := svc.AddStaffNote(ctx, servicedto.User{ID: 1}, vip.ID, "note"); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+
+	tags, err := svc.SetReservationTags(ctx, admin, vip.ID, []string{"VIP", " allergy", "vip"})
+	if err != nil {
+		t.Fatalf("set tags: %v", err)
+	}
+	if fmt.Sprint(tags) != "[allergy vip]" {
+		t.Fatalf("expected normalized tags, got %v", tags)
+	}
+	if _, err := svc.SetReservationTags(ctx, admin, vip.ID, []string{"celebrity"}); err != ErrInvalidTag {
+		t.Fatalf("expected ErrInvalidTag, got %v", err)
+	}
+
+	list, err := svc.AdminListReservations(ctx, servicedto.AdminListReservationsInput{Date: "2025-12-01", Tags: []string{"Vip"}})
+	if err != nil {
+		t.Fatalf("list by tag: %v", err)
+	}
+	if len(list.Reservations) != 1 || list.Reservations[0].ID != vip.ID || len(list.Reservations[0].Notes) != 1 {
+		t.Fatalf("unexpected tag filter result: %+v", list.Reservations)
+	}
+	if _, err := svc.AdminListReservations(ctx, servicedto.AdminListReservationsInput{Date: "2025-12-01", Tags: []string{"nope"}}); err != ErrInvalidTag {
+		t.Fatalf("expected ErrInvalidTag for list filter, got %v", err)
+	}
+}
+
+func TestCreateReservationRequirements(t *testing.T) {
+	client := newFakeReservationClient()
+	svc := NewReservationService(client, WithRequirementsCatalogue(servicedto.RequirementsCatalogue{
+		DietaryStyles: []string{"vegan", "jain"},
+	}))
+	ctx := context.Background()
+	style := " Jain "
+
+	out, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{
+		UserID: 1, Date: "2025-12-01", Time: "20:00", People: 3,
+		Requirements: servicedto.Requirements{
+			Allergens:     []string{"Peanuts", "gluten", "peanuts"},
+			DietaryStyle:  &style,
+			Accessibility: []string{"wheelchair"},
+			HighChairs:    1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := out.Reservation.Requirements
+	if fmt.Sprint(req.Allergens) != "[gluten peanuts]" || *req.DietaryStyle != "jain" || req.HighChairs != 1 {
+		t.Fatalf("unexpected normalized requirements: %+v", req)
+	}
+
+	for name, r := range map[string]servicedto.Requirements{
+		"unknown allergen":  {Allergens: []string{"pineapple"}},
+		"unknown need":      {Accessibility: []string{"jetpack"}},
+		"default style off": {DietaryStyle: strPtr("vegetarian")},
+		"too many chairs":   {HighChairs: 3},
+		"negative chairs":   {HighChairs: -1},
+	} {
+		_, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{
+			UserID: 1, Date: "2025-12-02", Time: "20:00", People: 3, Requirements: r,
+		})
+		if err != ErrInvalidRequirement {
+			t.Fatalf("%s: expected ErrInvalidRequirement, got %v", name, err)
+		}
+	}
+}
+
+func TestAdminGetReservationByCode(t *testing.T) {
+	client := newFakeReservationClient()
+	svc := NewReservationService(client)
+	ctx := context.Background()
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+
+	res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1,
+		Date:   time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		Time:   "21:00",
+		People: 2,
+		Status: servicedto.StatusPending,
+	})
+
+	for _, code := range []string{res.Code, " vsv-000001 ", "000001", "VSV000001"} {
+		found, err := svc.AdminGetReservationByCode(ctx, admin, code)
+		if err != nil {
+			t.Fatalf("lookup %q: unexpected error: %v", code, err)
+		}
+		if found.ID != res.ID {
+			t.Fatalf("lookup %q: expected reservation %d, got %d", code, res.ID, found.ID)
+		}
+	}
+
+	// Random parts may start with the prefix letters themselves.
+	tricky, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 1, Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 2})
+	stored := client.reservations[tricky.ID]
+	stored.Code = "VSV-VSV3K2"
+	client.reservations[tricky.ID] = stored
+	for _, code := range []string{"VSV-VSV3K2", "vsv3k2", "VSVVSV3K2"} {
+		found, err := svc.AdminGetReservationByCode(ctx, admin, code)
+		if err != nil || found.ID != tricky.ID {
+			t.Fatalf("lookup %q: expected reservation %d, got %+v %v", code, tricky.ID, found, err)
+		}
+	}
+
+	if _, err := svc.AdminGetReservationByCode(ctx, admin, "VSV-ZZZZZZ"); err != ErrReservationNotFound {
+		t.Fatalf("expected ErrReservationNotFound, got %v", err)
+	}
+	if _, err := svc.AdminGetReservationByCode(ctx, admin, "  "); err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+	if _, err := svc.AdminGetReservationByCode(ctx, servicedto.User{ID: 2}, res.Code); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestCreateReservationOverlapPolicies(t *testing.T) {
+	ctx := context.Background()
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	newService := func(policy string) (*ReservationService, *fakeReservationClient) {
+		client := newFakeReservationClient()
+		client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: 1, Date: date, Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+		})
+		client.CreateReservation(ctx, servicedto.CreateReservationParams{
+			UserID: 1, Date: date, Time: "13:00", People: 2, Status: servicedto.StatusCancelled,
+		})
+		return NewReservationService(client, WithOverlapPolicy(policy), WithReservationDuration(2*time.Hour)), client
+	}
+	input := servicedto.CreateReservationInput{UserID: 1, Date: "2025-12-01", Time: "21:30", People: 2}
+
+	svc, _ := newService(servicedto.OverlapPolicyReject)
+	if _, err := svc.CreateReservation(ctx, input); err != ErrOverlappingReservation {
+		t.Fatalf("expected ErrOverlappingReservation, got %v", err)
+	}
+
+	// Windows that only touch do not overlap, and cancelled bookings are ignored.
+	for _, slot := range []string{"22:00", "18:00", "13:30"} {
+		free := input
+		free.Time = slot
+		if _, err := svc.CreateReservation(ctx, free); err != nil {
+			t.Fatalf("slot %s: unexpected error: %v", slot, err)
+		}
+	}
+
+	// Other guests are not affected.
+	other := input
+	other.UserID = 2
+	if _, err := svc.CreateReservation(ctx, other); err != nil {
+		t.Fatalf("unexpected error for other guest: %v", err)
+	}
+
+	svc, _ = newService(servicedto.OverlapPolicyFlag)
+	out, err := svc.CreateReservation(ctx, input)
+	if err != nil {
+		t.Fatalf("unexpected error under flag policy: %v", err)
+	}
+	if out.Reservation.ReviewReason == nil || !strings.Contains(*out.Reservation.ReviewReason, "20:00") {
+		t.Fatalf("expected review reason mentioning the overlapping slot, got %v", out.Reservation.ReviewReason)
+	}
+
+	svc, _ = newService(servicedto.OverlapPolicyOff)
+	out, err = svc.CreateReservation(ctx, input)
+	if err != nil || out.Reservation.ReviewReason != nil {
+		t.Fatalf("expected overlap to be ignored when off, got %+v, %v", out, err)
+	}
+
+	// A late booking overlaps an early one on the next date.
+	svc, _ = newService(servicedto.OverlapPolicyReject)
+	if _, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 1, Date: "2025-12-01", Time: "23:00", People: 2}); err != nil {
+		t.Fatalf("unexpected error for a late slot: %v", err)
+	}
+	if _, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 1, Date: "2025-12-02", Time: "00:30", People: 2}); err != ErrOverlappingReservation {
+		t.Fatalf("expected 00:30 to overlap 23:00 the night before, got %v", err)
+	}
+	if _, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 1, Date: "2025-12-02", Time: "01:00", People: 2}); err != nil {
+		t.Fatalf("unexpected error after the late slot ends: %v", err)
+	}
+}
+
+func TestOverlapPolicyDefault(t *testing.T) {
+	if svc := NewReservationService(newFakeReservationClient()); svc.overlapPolicy != servicedto.OverlapPolicyFlag {
+		t.Fatalf("expected default policy flag, got %q", svc.overlapPolicy)
+	}
+}
+
+func TestCreateReservationInvalidTime(t *testing.T) {
+	svc := NewReservationService(newFakeReservationClient())
+
+	_, err := svc.CreateReservation(context.Background(), servicedto.CreateReservationInput{
+		UserID: 1,
+		Date:   "2025-12-01",
+		Time:   "25:99",
+		People: 2,
+	})
+	if err != ErrInvalidInput {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestAdminCreateReservationForExistingUser(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserClient()
+	user, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com", PasswordHash: "hash"})
+	svc := NewReservationService(newFakeReservationClient(), WithGuestClient(users))
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+
+	out, err := svc.AdminCreateReservation(ctx, admin, servicedto.AdminCreateReservationInput{
+		UserID: user.ID, Date: "2025-12-01", Time: "20:00", People: 3,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Reservation.UserID != user.ID || out.Reservation.Channel != servicedto.ChannelPhone || out.Reservation.User == nil {
+		t.Fatalf("unexpected reservation: %+v", out.Reservation)
+	}
+
+	_, err = svc.AdminCreateReservation(ctx, admin, servicedto.AdminCreateReservationInput{
+		UserID: 404, Date: "2025-12-01", Time: "20:00", People: 3,
+	})
+	if err != ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+
+	_, err = svc.AdminCreateReservation(ctx, servicedto.User{ID: 1}, servicedto.AdminCreateReservationInput{
+		UserID: user.ID, Date: "2025-12-01", Time: "20:00", People: 3,
+	})
+	if err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestAdminCreateReservationForGuest(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserClient()
+	svc := NewReservationService(newFakeReservationClient(), WithGuestClient(users))
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+
+	input := servicedto.AdminCreateReservationInput{
+		Guest:   &servicedto.GuestInput{Name: "Luis Garcia", Phone: "+34 600-123-456"},
+		Date:    "2025-12-01",
+		Time:    "20:00",
+		People:  2,
+		Channel: servicedto.ChannelWalkIn,
+	}
+	first, err := svc.AdminCreateReservation(ctx, admin, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	guest := first.Reservation.User
+	if guest == nil || !guest.IsGuest || guest.Phone == nil || *guest.Phone != "+34600123456" {
+		t.Fatalf("unexpected guest user: %+v", guest)
+	}
+	if first.Reservation.Channel != servicedto.ChannelWalkIn {
+		t.Fatalf("expected walk_in channel, got %s", first.Reservation.Channel)
+	}
+
+	// The same phone number reuses the guest record.
+	input.Guest.Phone = "+34600123456"
+	input.Time = "13:00"
+	second, err := svc.AdminCreateReservation(ctx, admin, input)
+	if err != nil {
+		t.Fatalf("unexpected error on repeat guest: %v", err)
+	}
+	if second.Reservation.UserID != guest.ID {
+		t.Fatalf("expected guest %d to be reused, got %d", guest.ID, second.Reservation.UserID)
+	}
+}
+
+func TestAdminCreateReservationValidation(t *testing.T) {
+	ctx := context.Background()
+	svc := NewReservationService(newFakeReservationClient(), WithGuestClient(newFakeUserClient()))
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+
+	cases := map[string]servicedto.AdminCreateReservationInput{
+		"no user or guest": {Date: "2025-12-01", Time: "20:00", People: 2},
+		"both user and guest": {
+			UserID: 1, Guest: &servicedto.GuestInput{Name: "A", Phone: "+34600123456"},
+			Date: "2025-12-01", Time: "20:00", People: 2,
+		},
+		"unknown channel": {
+			Guest: &servicedto.GuestInput{Name: "A", Phone: "+34600123456"},
+			Date:  "2025-12-01", Time: "20:00", People: 2, Channel: "carrier-pigeon",
+		},
+	}
+	for name, input := range cases {
+		if _, err := svc.AdminCreateReservation(ctx, admin, input); err != ErrInvalidInput {
+			t.Fatalf("%s: expected ErrInvalidInput, got %v", name, err)
+		}
+	}
+	badPhone := servicedto.AdminCreateReservationInput{
+		Guest: &servicedto.GuestInput{Name: "A", Phone: "call me"},
+		Date:  "2025-12-01", Time: "20:00", People: 2,
+	}
+	if _, err := svc.AdminCreateReservation(ctx, admin, badPhone); err != ErrInvalidPhone {
+		t.Fatalf("bad phone: expected ErrInvalidPhone, got %v", err)
+	}
+}
+
+func TestWalkInAndSeat(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserClient()
+	tables := newFakeTableClient()
+	table, _ := tables.CreateTable(ctx, servicedto.CreateTableParams{Name: "T1", Area: "main", Seats: 4})
+	client := newFakeReservationClient()
+	svc := NewReservationService(client, WithGuestClient(users), WithTableClient(tables))
+	svc.now = func() time.Time { return time.Date(2025, 12, 1, 20, 7, 30, 0, time.UTC) }
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+
+	walkIn, err := svc.WalkIn(ctx, admin, servicedto.WalkInInput{
+		Guest:   &servicedto.GuestInput{Name: "Walk In", Phone: "+34600123456"},
+		People:  2,
+		TableID: &table.ID,
+	})
+	if err != nil {
+		t.Fatalf("walk-in: %v", err)
+	}
+	if walkIn.Status != servicedto.StatusSeated || walkIn.Channel != servicedto.ChannelWalkIn || walkIn.Time != "20:07" {
+		t.Fatalf("unexpected walk-in reservation: %+v", walkIn)
+	}
+	if !walkIn.Date.Equal(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected walk-in dated today, got %s", walkIn.Date)
+	}
+
+	// The table is taken until the walk-in is expected to leave.
+	booked, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: walkIn.Date, Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+	_, err = svc.SeatReservation(ctx, admin, servicedto.SeatReservationInput{ReservationID: booked.ID, TableID: &table.ID})
+	if err != ErrTableOccupied {
+		t.Fatalf("expected ErrTableOccupied, got %v", err)
+	}
+
+	seated, err := svc.SeatReservation(ctx, admin, servicedto.SeatReservationInput{ReservationID: booked.ID})
+	if err != nil {
+		t.Fatalf("seat without table: %v", err)
+	}
+	if seated.Status != servicedto.StatusSeated {
+		t.Fatalf("expected seated, got %s", seated.Status)
+	}
+
+	missing := uint(42)
+	_, err = svc.WalkIn(ctx, admin, servicedto.WalkInInput{UserID: 404, People: 2, TableID: &missing})
+	if err != ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound for unknown user, got %v", err)
+	}
+	user, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com"})
+	_, err = svc.WalkIn(ctx, admin, servicedto.WalkInInput{UserID: user.ID, People: 2, TableID: &missing})
+	if err != ErrTableNotFound {
+		t.Fatalf("expected ErrTableNotFound, got %v", err)
+	}
+	if _, err := svc.SeatReservation(ctx, admin, servicedto.SeatReservationInput{ReservationID: 999}); err != ErrReservationNotFound {
+		t.Fatalf("expected ErrReservationNotFound, got %v", err)
+	}
+}
+
+func TestSeatReservationOnlyToday(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeReservationClient()
+	svc := NewReservationService(client, WithTableClient(newFakeTableClient()))
+	now := time.Date(2025, 12, 1, 20, 10, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+
+	tomorrow, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: time.Date(2025, 12, 2, 0, 0, 0, 0, time.UTC), Time: "20:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+	if _, err := svc.SeatReservation(ctx, admin, servicedto.SeatReservationInput{ReservationID: tomorrow.ID}); err != ErrNotToday {
+		t.Fatalf("expected ErrNotToday, got %v", err)
+	}
+
+	tonight, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{
+		UserID: 1, Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Time: "19:00", People: 2, Status: servicedto.StatusConfirmed,
+	})
+	seated, err := svc.SeatReservation(ctx, admin, servicedto.SeatReservationInput{ReservationID: tonight.ID})
+	if err != nil {
+		t.Fatalf("seat: %v", err)
+	}
+	if seated.SeatedAt == nil || !seated.SeatedAt.Equal(now) {
+		t.Fatalf("expected seated at %s, got %v", now, seated.SeatedAt)
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	tests := map[string]string{
+		"+34 600-123-456":  "+34600123456",
+		"0039 (081) 12345": "+3908112345",
+	}
+	for in, want := range tests {
+		got, ok := normalizePhone(in)
+		if !ok || got != want {
+			t.Fatalf("normalizePhone(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"", "+12345", "600123456", "(011) 4444.5555", "+0123456789", "34+600123456", "+34600123456x", "+1234567890123456"} {
+		if _, ok := normalizePhone(in); ok {
+			t.Fatalf("expected %q to be rejected", in)
+		}
+	}
+}
+
+func TestPhoneFormatDefaultCountry(t *testing.T) {
+	italy := servicedto.PhoneFormat{CountryCode: "39"}
+	uk := servicedto.PhoneFormat{CountryCode: "+44", TrunkPrefix: "0"}
+	tests := []struct {
+		format servicedto.PhoneFormat
+		in     string
+		want   string
+	}{
+		{italy, "081 123 4567", "+390811234567"},
+		{italy, "333-123.4567", "+393331234567"},
+		{italy, "+34 600 123 456", "+34600123456"},
+		{italy, "0034600123456", "+34600123456"},
+		{uk, "07700 900123", "+447700900123"},
+		{uk, "(020) 7946 0018", "+442079460018"},
+	}
+	for _, tc := range tests {
+		if got, ok := tc.format.Normalize(tc.in); !ok || got != tc.want {
+			t.Fatalf("%+v.Normalize(%q) = %q, %v; want %q", tc.format, tc.in, got, ok, tc.want)
+		}
+	}
+	for _, in := range []string{"", "12", "call me", "333 123 4567 ext 2"} {
+		if _, ok := italy.Normalize(in); ok {
+			t.Fatalf("expected %q to be rejected", in)
+		}
+	}
+}
+
+func TestAdminCreateReservationLocalPhone(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserClient()
+	svc := NewReservationService(newFakeReservationClient(), WithGuestClient(users), WithPhoneFormat(servicedto.PhoneFormat{CountryCode: "39"}))
+	admin := servicedto.User{ID: 99, IsAdmin: true}
+
+	first, err := svc.AdminCreateReservation(ctx, admin, servicedto.AdminCreateReservationInput{
+		Guest: &servicedto.GuestInput{Name: "Gianni", Phone: "333 123 4567"},
+		Date:  "2025-12-01", Time: "20:00", People: 2,
+	})
+	if err != nil {
+		t.Fatalf("local phone: %v", err)
+	}
+	// The same guest typed in international form is found, not duplicated.
+	second, err := svc.AdminCreateReservation(ctx, admin, servicedto.AdminCreateReservationInput{
+		Guest: &servicedto.GuestInput{Name: "Gianni", Phone: "+39 333 1234567"},
+		Date:  "2025-12-02", Time: "20:00", People: 2,
+	})
+	if err != nil || second.Reservation.UserID != first.Reservation.UserID {
+		t.Fatalf("expected the same guest, got %+v %v", second, err)
+	}
+	guest, _ := users.GetUserByID(ctx, first.Reservation.UserID)
+	if guest.Phone == nil || *guest.Phone != "+393331234567" {
+		t.Fatalf("expected the phone stored in E.164 form, got %+v", guest)
+	}
+}
+
 // fakeReservationClient is an in-memory reservation store for tests.
 type fakeReservationClient struct {
 	reservations map[uint]servicedto.Reservation
+	history      []servicedto.StatusChange
+	events       []servicedto.DomainEvent // what the outbox would hold
 	nextID       uint
+
+	// beforeStatusChange, when set, runs before each status change to
+	// simulate a concurrent writer.
+	beforeStatusChange func()
 }
 
 func newFakeReservationClient() *fakeReservationClient {
@@ -362,17 +1120,25 @@ func (f *fakeReservationClient) CreateReservation(ctx context.Context, params se
 	f.nextID++
 	now := time.Now()
 	res := servicedto.Reservation{
-		ID:        id,
-		UserID:    params.UserID,
-		Date:      params.Date,
-		Time:      params.Time,
-		People:    params.People,
-		Comment:   params.Comment,
-		Status:    params.Status,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:           id,
+		Code:         fmt.Sprintf("VSV-%06d", id),
+		UserID:       params.UserID,
+		Date:         params.Date,
+		Time:         params.Time,
+		People:       params.People,
+		TableID:      params.TableID,
+		Comment:      params.Comment,
+		Requirements: params.Requirements,
+		Status:       params.Status,
+		Channel:      params.Channel,
+		ReviewReason: params.ReviewReason,
+		Decision:     params.Decision,
+		SeatedAt:     params.SeatedAt,
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 	f.reservations[id] = res
+	f.recordEvent(servicedto.DomainEventReservationCreated, res, "")
 	return &res, nil
 }
 
@@ -399,28 +1165,308 @@ func (f *fakeReservationClient) GetReservationByID(ctx context.Context, id uint)
 	return &copy, nil
 }
 
-func (f *fakeReservationClient) UpdateReservationStatus(ctx context.Context, id uint, status string) (*servicedto.Reservation, error) {
+func (f *fakeReservationClient) GetReservationByCode(ctx context.Context, code string) (*servicedto.Reservation, error) {
+	for _, r := range f.reservations {
+		if r.Code == code {
+			copy := r
+			return &copy, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeReservationClient) UpdateReservationStatus(ctx context.Context, params servicedto.UpdateReservationStatusParams) (*servicedto.Reservation, error) {
+	if f.beforeStatusChange != nil {
+		f.beforeStatusChange()
+	}
+	r, ok := f.reservations[params.ID]
+	if !ok || (params.From != "" && r.Status != params.From) {
+		return nil, nil
+	}
+	f.recordStatusChange(r, params.Status, params.Actor)
+	r.Status = params.Status
+	r.UpdatedAt = time.Now()
+	f.reservations[params.ID] = r
+	copy := r
+	return &copy, nil
+}
+
+func (f *fakeReservationClient) UpdateReservationStatuses(ctx context.Context, changes []servicedto.UpdateReservationStatusParams) ([]servicedto.Reservation, error) {
+	for _, params := range changes {
+		if _, ok := f.reservations[params.ID]; !ok {
+			return nil, nil
+		}
+	}
+	updated := make([]servicedto.Reservation, 0, len(changes))
+	for _, params := range changes {
+		r, _ := f.UpdateReservationStatus(ctx, params)
+		updated = append(updated, *r)
+	}
+	return updated, nil
+}
+
+func (f *fakeReservationClient) ModifyReservation(ctx context.Context, params servicedto.ModifyReservationParams) (*servicedto.Reservation, error) {
+	r, ok := f.reservations[params.ID]
+	if !ok || r.Status != params.From {
+		return nil, nil
+	}
+	f.appendHistory(r, params.Status, params.Actor)
+	r.Date, r.Time, r.People, r.Comment = params.Date, params.Time, params.People, params.Comment
+	r.Status, r.ReviewReason, r.Decision = params.Status, params.ReviewReason, params.Decision
+	r.UpdatedAt = time.Now()
+	f.reservations[params.ID] = r
+	f.recordEvent(servicedto.DomainEventReservationModified, r, params.From)
+	copy := r
+	return &copy, nil
+}
+
+func (f *fakeReservationClient) ConfirmAttendance(ctx context.Context, id uint, at time.Time) (bool, error) {
+	r, ok := f.reservations[id]
+	if !ok || r.Status != servicedto.StatusConfirmed {
+		return false, nil
+	}
+	r.AttendingAt = &at
+	f.reservations[id] = r
+	f.recordEvent(servicedto.DomainEventAttendanceConfirmed, r, "")
+	return true, nil
+}
+
+func (f *fakeReservationClient) EscalateReservation(ctx context.Context, id uint, reason string, at time.Time) (bool, error) {
+	r, ok := f.reservations[id]
+	if !ok || r.Status != servicedto.StatusPending || r.EscalatedAt != nil {
+		return false, nil
+	}
+	r.ReviewReason = &reason
+	r.EscalatedAt = &at
+	f.reservations[id] = r
+	f.recordEvent(servicedto.DomainEventEscalated, r, "")
+	return true, nil
+}
+
+func (f *fakeReservationClient) SeatReservation(ctx context.Context, id uint, tableID *uint, at time.Time, actor servicedto.StatusActor) (*servicedto.Reservation, error) {
 	r, ok := f.reservations[id]
 	if !ok {
 		return nil, nil
 	}
-	r.Status = status
-	r.UpdatedAt = time.Now()
+	f.recordStatusChange(r, servicedto.StatusSeated, actor)
+	r.Status = servicedto.StatusSeated
+	if tableID != nil {
+		r.TableID = tableID
+	}
+	if r.SeatedAt == nil {
+		r.SeatedAt = &at
+	}
 	f.reservations[id] = r
 	copy := r
 	return &copy, nil
 }
 
-func (f *fakeReservationClient) ListReservationsByDate(ctx context.Context, date time.Time, status *string) ([]servicedto.Reservation, error) {
+func (f *fakeReservationClient) SearchReservations(ctx context.Context, search servicedto.ReservationSearch) ([]servicedto.Reservation, error) {
+	list := make([]servicedto.Reservation, 0, len(f.reservations))
+	for _, r := range f.reservations {
+		list = append(list, r)
+	}
+	return list, nil
+}
+
+func (f *fakeReservationClient) QueryReservations(ctx context.Context, q servicedto.ReservationQuery) (*servicedto.ReservationPage, error) {
 	var list []servicedto.Reservation
 	for _, r := range f.reservations {
-		if !r.Date.Equal(date) {
+		if q.From != nil && r.Date.Before(*q.From) {
 			continue
 		}
-		if status != nil && r.Status != *status {
+		if q.To != nil && r.Date.After(*q.To) {
+			continue
+		}
+		if len(q.Statuses) > 0 && !slices.Contains(q.Statuses, r.Status) {
+			continue
+		}
+		if len(q.Tags) > 0 && !slices.ContainsFunc(q.Tags, func(tag string) bool { return slices.Contains(r.Tags, tag) }) {
+			continue
+		}
+		if (q.MinPeople > 0 && r.People < q.MinPeople) || (q.MaxPeople > 0 && r.People > q.MaxPeople) {
 			continue
 		}
 		list = append(list, r)
 	}
+	page := &servicedto.ReservationPage{Total: int64(len(list))}
+
+	key := func(r servicedto.Reservation) servicedto.ReservationCursor {
+		return servicedto.ReservationCursor{Date: r.Date, Time: r.Time, CreatedAt: r.CreatedAt, People: r.People, ID: r.ID}
+	}
+	less := func(a, b servicedto.ReservationCursor) int {
+		var c int
+		switch q.Sort {
+		case servicedto.SortByCreatedAt:
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		case servicedto.SortByPeople:
+			c = cmp.Compare(a.People, b.People)
+		default:
+			if c = a.Date.Compare(b.Date); c == 0 {
+				c = strings.Compare(a.Time, b.Time)
+			}
+		}
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if q.Descending {
+			c = -c
+		}
+		return c
+	}
+	slices.SortFunc(list, func(a, b servicedto.Reservation) int { return less(key(a), key(b)) })
+	if q.After != nil {
+		list = slices.DeleteFunc(list, func(r servicedto.Reservation) bool { return less(key(r), *q.After) <= 0 })
+	}
+	if q.Limit > 0 && len(list) > q.Limit {
+		list = list[:q.Limit]
+		next := key(list[len(list)-1])
+		page.Next = &next
+	}
+	page.Reservations = list
+	return page, nil
+}
+
+func (f *fakeReservationClient) ListStatusChanges(ctx context.Context, reservationID uint) ([]servicedto.StatusChange, error) {
+	var list []servicedto.StatusChange
+	for _, ch := range f.history {
+		if ch.ReservationID == reservationID {
+			list = append(list, ch)
+		}
+	}
 	return list, nil
 }
+
+func (f *fakeReservationClient) ListStatusChangesByReservations(ctx context.Context, ids []uint) ([]servicedto.StatusChange, error) {
+	var list []servicedto.StatusChange
+	for _, ch := range f.history {
+		if slices.Contains(ids, ch.ReservationID) {
+			list = append(list, ch)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeReservationClient) ListReservationsByUsers(ctx context.Context, userIDs []uint) ([]servicedto.Reservation, error) {
+	var list []servicedto.Reservation
+	for _, r := range f.reservations {
+		if slices.Contains(userIDs, r.UserID) {
+			list = append(list, r)
+		}
+	}
+	slices.SortFunc(list, func(a, b servicedto.Reservation) int {
+		if c := a.Date.Compare(b.Date); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Time, b.Time); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return list, nil
+}
+
+func (f *fakeReservationClient) SummarizeGuests(ctx context.Context, q servicedto.GuestSummaryQuery) (map[uint]servicedto.GuestSummary, error) {
+	visit := func(r servicedto.Reservation) bool {
+		return r.Status == servicedto.StatusSeated || r.Status == servicedto.StatusConfirmed &&
+			(r.Date.Before(q.Today) || r.Date.Equal(q.Today) && r.Time < q.Clock)
+	}
+	summaries := make(map[uint]servicedto.GuestSummary, len(q.ReservationIDs))
+	for _, id := range q.ReservationIDs {
+		res, ok := f.reservations[id]
+		if !ok {
+			continue
+		}
+		summary := servicedto.GuestSummary{VisitNumber: 1}
+		for _, r := range f.reservations {
+			if r.UserID != res.UserID {
+				continue
+			}
+			if r.Status == servicedto.StatusNoShow {
+				summary.NoShows++
+			}
+			if visit(r) {
+				summary.Visits++
+				if r.ID != res.ID && (r.Date.Before(res.Date) || r.Date.Equal(res.Date) && (r.Time < res.Time || r.Time == res.Time && r.ID < res.ID)) {
+					summary.VisitNumber++
+				}
+			}
+			for _, tag := range r.Tags {
+				if !slices.Contains(summary.Tags, tag) {
+					summary.Tags = append(summary.Tags, tag)
+				}
+			}
+		}
+		slices.Sort(summary.Tags)
+		summaries[id] = summary
+	}
+	return summaries, nil
+}
+
+func (f *fakeReservationClient) recordStatusChange(r servicedto.Reservation, status string, actor servicedto.StatusActor) {
+	if f.appendHistory(r, status, actor) {
+		from := r.Status
+		r.Status = status
+		f.recordEvent(servicedto.DomainEventStatusChanged, r, from)
+	}
+}
+
+// appendHistory records a status change without its event and reports
+// whether the status changed at all.
+func (f *fakeReservationClient) appendHistory(r servicedto.Reservation, status string, actor servicedto.StatusActor) bool {
+	if r.Status == status {
+		return false
+	}
+	f.history = append(f.history, servicedto.StatusChange{
+		ID:            uint(len(f.history) + 1),
+		ReservationID: r.ID,
+		FromStatus:    r.Status,
+		ToStatus:      status,
+		ActorID:       actor.UserID,
+		Source:        actor.Source,
+		Reason:        actor.Reason,
+		CreatedAt:     time.Now(),
+	})
+	return true
+}
+
+func (f *fakeReservationClient) recordEvent(eventType string, r servicedto.Reservation, from string) {
+	f.events = append(f.events, servicedto.DomainEvent{
+		ID:            uint(len(f.events) + 1),
+		Type:          eventType,
+		ReservationID: r.ID,
+		FromStatus:    from,
+		ToStatus:      r.Status,
+		CreatedAt:     time.Now(),
+	})
+}
+
+func (f *fakeReservationClient) AddStaffNote(ctx context.Context, params servicedto.CreateStaffNoteParams) (*servicedto.StaffNote, error) {
+	r := f.reservations[params.ReservationID]
+	note := servicedto.StaffNote{
+		ID:            uint(len(r.Notes) + 1),
+		ReservationID: params.ReservationID,
+		AuthorID:      params.AuthorID,
+		Body:          params.Body,
+		CreatedAt:     time.Now(),
+	}
+	r.Notes = append(r.Notes, note)
+	f.reservations[params.ReservationID] = r
+	f.recordEvent(servicedto.DomainEventStaffNoteAdded, r, "")
+	return &note, nil
+}
+
+func (f *fakeReservationClient) SetReservationTags(ctx context.Context, reservationID uint, tags []string) error {
+	r := f.reservations[reservationID]
+	changed := !slices.Equal(r.Tags, tags)
+	r.Tags = tags
+	f.reservations[reservationID] = r
+	if changed {
+		f.recordEvent(servicedto.DomainEventTagsChanged, r, "")
+	}
+	return nil
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// RestrictionClient abstracts incident and guest restriction persistence.
+type RestrictionClient interface {
+	RecordIncident(ctx context.Context, params servicedto.CreateIncidentParams) (*servicedto.Incident, error)
+	DeleteIncident(ctx context.Context, reservationID uint, kind string) error
+	ListIncidentsByUser(ctx context.Context, userID uint) ([]servicedto.Incident, error)
+	CountIncidents(ctx context.Context, userID uint, kinds []string, since time.Time) (int, error)
+	ListRestrictionsByUser(ctx context.Context, userID uint) ([]servicedto.Restriction, error)
+	ListActiveRestrictions(ctx context.Context) ([]servicedto.Restriction, error)
+	SaveRestriction(ctx context.Context, params servicedto.SaveRestrictionParams) (*servicedto.Restriction, error)
+	LiftRestriction(ctx context.Context, params servicedto.LiftRestrictionParams) (*servicedto.Restriction, error)
+}
+
+// DefaultSanctionWindow is how far back incidents count towards sanctions.
+const DefaultSanctionWindow = 180 * 24 * time.Hour
+
+// DefaultSanctionRules escalate from staff approval to a deposit to a block
+// on online booking.
+var DefaultSanctionRules = []servicedto.SanctionRule{
+	{Incidents: 2, Level: servicedto.RestrictionApproval},
+	{Incidents: 3, Level: servicedto.RestrictionDeposit},
+	{Incidents: 4, Level: servicedto.RestrictionBlock},
+}
+
+// WithSanctions records no-shows and late cancellations and restricts
+// online booking for guests who reach the policy thresholds. A nil Rules
+// slice keeps DefaultSanctionRules; an empty one only records incidents.
+func WithSanctions(restrictionClient RestrictionClient, policy servicedto.SanctionPolicy) ReservationOption {
+	return func(s *ReservationService) {
+		s.restrictionClient = restrictionClient
+		s.sanctions = normalizeSanctionPolicy(policy)
+	}
+}
+
+func normalizeSanctionPolicy(policy servicedto.SanctionPolicy) servicedto.SanctionPolicy {
+	if policy.Rules == nil {
+		policy.Rules = DefaultSanctionRules
+	}
+	rules := make([]servicedto.SanctionRule, 0, len(policy.Rules))
+	for _, r := range policy.Rules {
+		if r.Incidents > 0 && restrictionRank(r.Level) > 0 {
+			rules = append(rules, r)
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].Incidents < rules[j].Incidents })
+	policy.Rules = rules
+	if policy.Window <= 0 {
+		policy.Window = DefaultSanctionWindow
+	}
+	if policy.LateCancelWindow <= 0 {
+		policy.LateCancelWindow = DefaultLateCancelWindow
+	}
+	return policy
+}
+
+// restrictionRank orders restriction levels; 0 means unknown.
+func restrictionRank(level string) int {
+	switch level {
+	case servicedto.RestrictionApproval:
+		return 1
+	case servicedto.RestrictionDeposit:
+		return 2
+	case servicedto.RestrictionBlock:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// sanctionLevel returns the strictest level whose threshold count reaches.
+func sanctionLevel(rules []servicedto.SanctionRule, count int) string {
+	level := ""
+	for _, r := range rules {
+		if count >= r.Incidents && restrictionRank(r.Level) > restrictionRank(level) {
+			level = r.Level
+		}
+	}
+	return level
+}
+
+// recordIncident counts a no-show or late cancellation against the guest
+// and escalates their restriction when a threshold is reached.
+func (s *ReservationService) recordIncident(ctx context.Context, res servicedto.Reservation, kind string) error {
+	if s.restrictionClient == nil {
+		return nil
+	}
+	incident, err := s.restrictionClient.RecordIncident(ctx, servicedto.CreateIncidentParams{
+		UserID:        res.UserID,
+		ReservationID: res.ID,
+		Kind:          kind,
+		OccurredAt:    s.now(),
+	})
+	if err != nil || incident == nil {
+		return err
+	}
+	if kind == servicedto.IncidentLateCancel && !s.sanctions.CountLateCancels {
+		return nil
+	}
+	return s.applySanctions(ctx, res.UserID)
+}
+
+func (s *ReservationService) applySanctions(ctx context.Context, userID uint) error {
+	restrictions, err := s.restrictionClient.ListRestrictionsByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	since := s.now().Add(-s.sanctions.Window)
+	var active *servicedto.Restriction
+	for i, r := range restrictions {
+		if r.Active() {
+			active = &restrictions[i]
+			continue
+		}
+		// Lifting a restriction forgives everything before it.
+		if r.LiftedAt.After(since) {
+			since = *r.LiftedAt
+		}
+		break
+	}
+
+	kinds := []string{servicedto.IncidentNoShow}
+	if s.sanctions.CountLateCancels {
+		kinds = append(kinds, servicedto.IncidentLateCancel)
+	}
+	count, err := s.restrictionClient.CountIncidents(ctx, userID, kinds, since)
+	if err != nil {
+		return err
+	}
+	level := sanctionLevel(s.sanctions.Rules, count)
+	if level == "" || (active != nil && restrictionRank(active.Level) >= restrictionRank(level)) {
+		return nil
+	}
+
+	_, err = s.restrictionClient.SaveRestriction(ctx, servicedto.SaveRestrictionParams{
+		UserID:    userID,
+		Level:     level,
+		Reason:    fmt.Sprintf("%d incidents since %s", count, since.In(s.location).Format("2006-01-02")),
+		Incidents: count,
+	})
+	return err
+}
+
+// checkRestriction applies the guest's active restriction to an online
+// booking. It returns the review reason to store and whether a deposit is
+// required, or ErrGuestRestricted when online booking is blocked.
+func (s *ReservationService) checkRestriction(ctx context.Context, userID uint) (*string, bool, error) {
+	restriction, err := activeRestriction(ctx, s.restrictionClient, userID)
+	if err != nil || restriction == nil {
+		return nil, false, err
+	}
+	var reason string
+	switch restriction.Level {
+	case servicedto.RestrictionBlock:
+		return nil, false, ErrGuestRestricted
+	case servicedto.RestrictionDeposit:
+		reason = "deposit required: " + restriction.Reason
+	default:
+		reason = "approval required: " + restriction.Reason
+	}
+	return &reason, restriction.Level == servicedto.RestrictionDeposit, nil
+}
+
+// isLateCancel reports whether cancelling res now falls inside the
+// late-cancel window before its slot.
+func (s *ReservationService) isLateCancel(res servicedto.Reservation) bool {
+	if res.Status != servicedto.StatusPending && res.Status != servicedto.StatusConfirmed {
+		return false
+	}
+	start, ok := slotStart(res, s.location)
+	return ok && start.Sub(s.now()) < s.sanctions.LateCancelWindow
+}
+
+// activeRestriction returns the guest's restriction that has not been
+// lifted, if any. A nil client means sanctions are disabled.
+func activeRestriction(ctx context.Context, client RestrictionClient, userID uint) (*servicedto.Restriction, error) {
+	if client == nil {
+		return nil, nil
+	}
+	restrictions, err := client.ListRestrictionsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(restrictions) == 0 || !restrictions[0].Active() {
+		return nil, nil
+	}
+	return &restrictions[0], nil
+}
+
+// joinReasons combines review reasons, either of which may be nil.
+func joinReasons(a, b *string) *string {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	}
+	joined := *a + "; " + *b
+	return &joined
+}
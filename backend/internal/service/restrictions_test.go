@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestNoShowsEscalateRestrictions(t *testing.T) {
+	client := newFakeReservationClient()
+	restrictions := newFakeRestrictionClient()
+	// Every booking takes the same slot; only the sanctions are under test.
+	svc := NewReservationService(client,
+		WithSanctions(restrictions, servicedto.SanctionPolicy{}),
+		WithOverlapPolicy(servicedto.OverlapPolicyOff))
+	now := time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	ctx := context.Background()
+
+	book := func() (*servicedto.CreateReservationOutput, error) {
+		return svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 1, Date: "2025-12-20", Time: "20:00", People: 2})
+	}
+	noShow := func() {
+		t.Helper()
+		res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 1, Date: now, Time: "11:00", People: 2, Status: servicedto.StatusConfirmed})
+		if _, err := svc.MarkNoShow(ctx, admin, res.ID); err != nil {
+			t.Fatalf("mark no-show: %v", err)
+		}
+		// Marking the same reservation again is not a second incident.
+		if _, err := svc.MarkNoShow(ctx, admin, res.ID); err != nil {
+			t.Fatalf("mark no-show again: %v", err)
+		}
+	}
+
+	noShow()
+	if out, err := book(); err != nil || out.Reservation.ReviewReason != nil {
+		t.Fatalf("expected unrestricted booking after one no-show, got %+v (%v)", out, err)
+	}
+
+	noShow()
+	out, err := book()
+	if err != nil || out.Reservation.ReviewReason == nil || *out.Reservation.ReviewReason != "approval required: 2 incidents since 2025-06-04" {
+		t.Fatalf("expected approval required, got %+v (%v)", out, err)
+	}
+
+	noShow()
+	out, err = book()
+	if err != nil || out.Reservation.ReviewReason == nil || *out.Reservation.ReviewReason != "deposit required: 3 incidents since 2025-06-04" {
+		t.Fatalf("expected deposit required, got %+v (%v)", out, err)
+	}
+
+	noShow()
+	if _, err := book(); err != ErrGuestRestricted {
+		t.Fatalf("expected ErrGuestRestricted, got %v", err)
+	}
+	if len(restrictions.restrictions) != 1 || restrictions.restrictions[0].Level != servicedto.RestrictionBlock {
+		t.Fatalf("expected one escalated restriction, got %+v", restrictions.restrictions)
+	}
+
+	// Staff can still book for a blocked guest.
+	users := newFakeUserClient()
+	users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Guest", Email: "guest@example.com"})
+	staff := NewReservationService(client, WithSanctions(restrictions, servicedto.SanctionPolicy{}), WithGuestClient(users))
+	if _, err := staff.AdminCreateReservation(ctx, admin, servicedto.AdminCreateReservationInput{UserID: 1, Date: "2025-12-20", Time: "20:00", People: 2}); err != nil {
+		t.Fatalf("expected staff booking to bypass restriction, got %v", err)
+	}
+}
+
+func TestLiftRestrictionResetsCount(t *testing.T) {
+	client := newFakeReservationClient()
+	restrictions := newFakeRestrictionClient()
+	policy := servicedto.SanctionPolicy{Rules: []servicedto.SanctionRule{{Incidents: 1, Level: servicedto.RestrictionBlock}}}
+	svc := NewReservationService(client, WithSanctions(restrictions, policy))
+	now := time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	guests := NewGuestService(newFakeUserClient(), client, restrictions, time.UTC, 0)
+	guests.now = func() time.Time { return now }
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	ctx := context.Background()
+
+	res, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 1, Date: now, Time: "11:00", People: 2, Status: servicedto.StatusConfirmed})
+	if _, err := svc.MarkNoShow(ctx, admin, res.ID); err != nil {
+		t.Fatalf("mark no-show: %v", err)
+	}
+	active, err := guests.ListRestrictions(ctx)
+	if err != nil || len(active) != 1 || active[0].UserID != 1 {
+		t.Fatalf("expected one active restriction, got %+v (%v)", active, err)
+	}
+
+	if _, err := guests.LiftRestriction(ctx, servicedto.User{ID: 1}, servicedto.LiftRestrictionInput{UserID: 1}); err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	note := " paid for the missed table "
+	lifted, err := guests.LiftRestriction(ctx, admin, servicedto.LiftRestrictionInput{UserID: 1, Note: &note})
+	if err != nil || lifted.Active() || *lifted.LiftNote != "paid for the missed table" || *lifted.LiftedBy != admin.ID {
+		t.Fatalf("unexpected lifted restriction: %+v (%v)", lifted, err)
+	}
+	if _, err := guests.LiftRestriction(ctx, admin, servicedto.LiftRestrictionInput{UserID: 1}); err != ErrRestrictionNotFound {
+		t.Fatalf("expected ErrRestrictionNotFound, got %v", err)
+	}
+
+	if _, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: 1, Date: "2025-12-20", Time: "20:00", People: 2}); err != nil {
+		t.Fatalf("expected booking after lift, got %v", err)
+	}
+
+	// Only incidents after the lift count: the next no-show blocks again.
+	now = now.Add(time.Hour)
+	res, _ = client.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 1, Date: now, Time: "12:00", People: 2, Status: servicedto.StatusConfirmed})
+	if _, err := svc.MarkNoShow(ctx, admin, res.ID); err != nil {
+		t.Fatalf("mark no-show: %v", err)
+	}
+	if r, _ := activeRestriction(ctx, restrictions, 1); r == nil || r.Incidents != 1 {
+		t.Fatalf("expected new restriction counting one incident, got %+v", r)
+	}
+}
+
+func TestLateCancelRecordsIncident(t *testing.T) {
+	client := newFakeReservationClient()
+	restrictions := newFakeRestrictionClient()
+	policy := servicedto.SanctionPolicy{
+		Rules:            []servicedto.SanctionRule{{Incidents: 1, Level: servicedto.RestrictionApproval}},
+		CountLateCancels: true,
+	}
+	svc := NewReservationService(client, WithSanctions(restrictions, policy))
+	now := time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	early, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 1, Date: now.AddDate(0, 0, 3), Time: "20:00", People: 2, Status: servicedto.StatusConfirmed})
+	late, _ := client.CreateReservation(ctx, servicedto.CreateReservationParams{UserID: 1, Date: now, Time: "20:00", People: 2, Status: servicedto.StatusConfirmed})
+
+	if _, err := svc.CancelReservation(ctx, servicedto.CancelReservationInput{UserID: 1, ReservationID: early.ID}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if len(restrictions.incidents) != 0 {
+		t.Fatalf("expected no incident for early cancel, got %+v", restrictions.incidents)
+	}
+	if _, err := svc.CancelReservation(ctx, servicedto.CancelReservationInput{UserID: 1, ReservationID: late.ID}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if len(restrictions.incidents) != 1 || restrictions.incidents[0].Kind != servicedto.IncidentLateCancel {
+		t.Fatalf("expected one late-cancel incident, got %+v", restrictions.incidents)
+	}
+	if r, _ := activeRestriction(ctx, restrictions, 1); r == nil || r.Level != servicedto.RestrictionApproval {
+		t.Fatalf("expected approval restriction, got %+v", r)
+	}
+}
+
+func TestSanctionLevel(t *testing.T) {
+	rules := normalizeSanctionPolicy(servicedto.SanctionPolicy{}).Rules
+	for count, want := range map[int]string{0: "", 1: "", 2: "approval", 3: "deposit", 4: "block", 9: "block"} {
+		if got := sanctionLevel(rules, count); got != want {
+			t.Fatalf("sanctionLevel(%d) = %q, want %q", count, got, want)
+		}
+	}
+	if rules := normalizeSanctionPolicy(servicedto.SanctionPolicy{Rules: []servicedto.SanctionRule{}}).Rules; len(rules) != 0 {
+		t.Fatalf("expected empty rules to disable sanctions, got %+v", rules)
+	}
+}
+
+// fakeRestrictionClient keeps incidents and restrictions in memory.
+type fakeRestrictionClient struct {
+	incidents    []servicedto.Incident
+	restrictions []servicedto.Restriction
+}
+
+func newFakeRestrictionClient() *fakeRestrictionClient {
+	return &fakeRestrictionClient{}
+}
+
+func (f *fakeRestrictionClient) RecordIncident(ctx context.Context, params servicedto.CreateIncidentParams) (*servicedto.Incident, error) {
+	for _, i := range f.incidents {
+		if i.ReservationID == params.ReservationID && i.Kind == params.Kind {
+			return nil, nil
+		}
+	}
+	incident := servicedto.Incident{
+		ID:            uint(len(f.incidents) + 1),
+		UserID:        params.UserID,
+		ReservationID: params.ReservationID,
+		Kind:          params.Kind,
+		OccurredAt:    params.OccurredAt,
+		CreatedAt:     params.OccurredAt,
+	}
+	f.incidents = append(f.incidents, incident)
+	return &incident, nil
+}
+
+func (f *fakeRestrictionClient) DeleteIncident(ctx context.Context, reservationID uint, kind string) error {
+	f.incidents = slices.DeleteFunc(f.incidents, func(i servicedto.Incident) bool {
+		return i.ReservationID == reservationID && i.Kind == kind
+	})
+	return nil
+}
+
+func (f *fakeRestrictionClient) ListIncidentsByUser(ctx context.Context, userID uint) ([]servicedto.Incident, error) {
+	var list []servicedto.Incident
+	for i := len(f.incidents) - 1; i >= 0; i-- {
+		if f.incidents[i].UserID == userID {
+			list = append(list, f.incidents[i])
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeRestrictionClient) CountIncidents(ctx context.Context, userID uint, kinds []string, since time.Time) (int, error) {
+	count := 0
+	for _, i := range f.incidents {
+		if i.UserID == userID && slices.Contains(kinds, i.Kind) && i.OccurredAt.After(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeRestrictionClient) ListRestrictionsByUser(ctx context.Context, userID uint) ([]servicedto.Restriction, error) {
+	var list []servicedto.Restriction
+	for i := len(f.restrictions) - 1; i >= 0; i-- {
+		if f.restrictions[i].UserID == userID {
+			list = append(list, f.restrictions[i])
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeRestrictionClient) ListActiveRestrictions(ctx context.Context) ([]servicedto.Restriction, error) {
+	var list []servicedto.Restriction
+	for _, r := range f.restrictions {
+		if r.Active() {
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeRestrictionClient) SaveRestriction(ctx context.Context, params servicedto.SaveRestrictionParams) (*servicedto.Restriction, error) {
+	for i, r := range f.restrictions {
+		if r.UserID == params.UserID && r.Active() {
+			f.restrictions[i].Level = params.Level
+			f.restrictions[i].Reason = params.Reason
+			f.restrictions[i].Incidents = params.Incidents
+			saved := f.restrictions[i]
+			return &saved, nil
+		}
+	}
+	saved := servicedto.Restriction{
+		ID:        uint(len(f.restrictions) + 1),
+		UserID:    params.UserID,
+		Level:     params.Level,
+		Reason:    params.Reason,
+		Incidents: params.Incidents,
+	}
+	f.restrictions = append(f.restrictions, saved)
+	return &saved, nil
+}
+
+func (f *fakeRestrictionClient) LiftRestriction(ctx context.Context, params servicedto.LiftRestrictionParams) (*servicedto.Restriction, error) {
+	for i, r := range f.restrictions {
+		if r.UserID == params.UserID && r.Active() {
+			at, by := params.At, params.LiftedBy
+			f.restrictions[i].LiftedAt = &at
+			f.restrictions[i].LiftedBy = &by
+			f.restrictions[i].LiftNote = params.Note
+			lifted := f.restrictions[i]
+			return &lifted, nil
+		}
+	}
+	return nil, nil
+}
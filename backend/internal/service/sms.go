@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+
+	"vesuvio/internal/dto/service"
+)
+
+// SMSChannelName identifies text messages in preferences and the reminder
+// log.
+const SMSChannelName = "sms"
+
+// SMSSender delivers text messages through an SMS provider.
+type SMSSender interface {
+	SendSMS(ctx context.Context, to, body string) error
+}
+
+// SMSChannel texts guests at the phone number on their account.
+type SMSChannel struct {
+	sender SMSSender
+}
+
+func NewSMSChannel(sender SMSSender) *SMSChannel {
+	return &SMSChannel{sender: sender}
+}
+
+func (c *SMSChannel) Name() string { return SMSChannelName }
+
+func (c *SMSChannel) Address(user servicedto.User) (string, bool) {
+	if user.Phone == nil || *user.Phone == "" {
+		return "", false
+	}
+	return *user.Phone, true
+}
+
+// Send texts the short version of the message, or the body if there is none.
+func (c *SMSChannel) Send(ctx context.Context, n servicedto.Notification) error {
+	text := n.Text
+	if text == "" {
+		text = n.Body
+	}
+	return c.sender.SendSMS(ctx, n.To, text)
+}
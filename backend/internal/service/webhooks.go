@@ -0,0 +1,515 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"vesuvio/internal/dto/service"
+)
+
+// WebhookClient abstracts webhook subscriptions and their delivery log.
+type WebhookClient interface {
+	CreateWebhook(ctx context.Context, params servicedto.CreateWebhookParams) (*servicedto.Webhook, error)
+	GetWebhookByID(ctx context.Context, id uint) (*servicedto.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]servicedto.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook servicedto.Webhook) (*servicedto.Webhook, error)
+	DeleteWebhook(ctx context.Context, id uint) (bool, error)
+	// CreateWebhookDeliveries skips deliveries of an event a webhook already
+	// has.
+	CreateWebhookDeliveries(ctx context.Context, params []servicedto.CreateWebhookDeliveryParams) error
+	ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]servicedto.WebhookDelivery, error)
+	RecordWebhookAttempt(ctx context.Context, params servicedto.WebhookAttemptParams) error
+	ListWebhookDeliveries(ctx context.Context, webhookID uint, status *string, limit int) ([]servicedto.WebhookDelivery, error)
+	ReplayWebhookDeliveries(ctx context.Context, webhookID uint, ids []uint, at time.Time) (int, error)
+}
+
+// WebhookSender posts a payload to a subscriber and returns the HTTP status
+// it answered with.
+type WebhookSender interface {
+	Post(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
+
+// Headers sent with every delivery. The signature is
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+const (
+	WebhookEventHeader     = "X-Vesuvio-Event"
+	WebhookDeliveryHeader  = "X-Vesuvio-Delivery"
+	WebhookTimestampHeader = "X-Vesuvio-Timestamp"
+	WebhookSignatureHeader = "X-Vesuvio-Signature"
+)
+
+// DefaultWebhookPolicy retries for about an hour before giving up.
+var DefaultWebhookPolicy = servicedto.WebhookPolicy{MaxAttempts: 8, Backoff: 30 * time.Second, MaxBackoff: 30 * time.Minute}
+
+// Delivery log limits.
+const (
+	webhookBatchSize           = 100
+	DefaultWebhookDeliveryPage = 50
+	MaxWebhookDeliveryPage     = 500
+)
+
+var webhookEvents = []string{
+	servicedto.WebhookReservationCreated,
+	servicedto.WebhookReservationConfirmed,
+	servicedto.WebhookReservationCancelled,
+	servicedto.WebhookReservationUpdated,
+}
+
+// webhookEventFor maps lifecycle events to webhook event types.
+func webhookEventFor(kind string) string {
+	switch kind {
+	case servicedto.EventReservationCreated:
+		return servicedto.WebhookReservationCreated
+	case servicedto.EventReservationConfirmed:
+		return servicedto.WebhookReservationConfirmed
+	case servicedto.EventReservationCancelled:
+		return servicedto.WebhookReservationCancelled
+	case servicedto.EventReservationModified, servicedto.EventReservationStatusChanged:
+		return servicedto.WebhookReservationUpdated
+	default:
+		return ""
+	}
+}
+
+// WebhookService manages webhook subscriptions and delivers reservation
+// events to them. Events are queued in the delivery log and sent by
+// DeliverDueWebhooks, which retries failures with exponential backoff.
+type WebhookService struct {
+	client     WebhookClient
+	sender     WebhookSender
+	policy     servicedto.WebhookPolicy
+	lookupHost func(ctx context.Context, host string) ([]netip.Addr, error)
+	now        func() time.Time
+}
+
+// NewWebhookService fills in unset policy fields from DefaultWebhookPolicy.
+func NewWebhookService(client WebhookClient, sender WebhookSender, policy servicedto.WebhookPolicy) *WebhookService {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultWebhookPolicy.MaxAttempts
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = DefaultWebhookPolicy.Backoff
+	}
+	if policy.MaxBackoff < policy.Backoff {
+		policy.MaxBackoff = max(DefaultWebhookPolicy.MaxBackoff, policy.Backoff)
+	}
+	return &WebhookService{
+		client: client,
+		sender: sender,
+		policy: policy,
+		lookupHost: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		},
+		now: time.Now,
+	}
+}
+
+// CreateWebhook subscribes a URL to events. The returned webhook carries the
+// signing secret, which is not shown again.
+func (s *WebhookService) CreateWebhook(ctx context.Context, admin servicedto.User, input servicedto.CreateWebhookInput) (*servicedto.Webhook, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	target, err := normalizeWebhookURL(input.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWebhookTarget(ctx, target); err != nil {
+		return nil, err
+	}
+	events, err := normalizeWebhookEvents(input.Events)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.CreateWebhook(ctx, servicedto.CreateWebhookParams{
+		URL:         target,
+		Secret:      "whsec_" + secret,
+		Events:      events,
+		Description: trimOptional(input.Description),
+	})
+}
+
+// ListWebhooks returns every subscription without its secret.
+func (s *WebhookService) ListWebhooks(ctx context.Context, admin servicedto.User) ([]servicedto.Webhook, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	webhooks, err := s.client.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook changes a subscription. Deliveries queued for an inactive
+// webhook wait until it is active again.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, admin servicedto.User, input servicedto.UpdateWebhookInput) (*servicedto.Webhook, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	webhook, err := s.getWebhook(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if input.URL != nil {
+		if webhook.URL, err = normalizeWebhookURL(*input.URL); err != nil {
+			return nil, err
+		}
+		if err := s.checkWebhookTarget(ctx, webhook.URL); err != nil {
+			return nil, err
+		}
+	}
+	if input.Events != nil {
+		if webhook.Events, err = normalizeWebhookEvents(input.Events); err != nil {
+			return nil, err
+		}
+	}
+	if input.Active != nil {
+		webhook.Active = *input.Active
+	}
+	if input.Description != nil {
+		webhook.Description = trimOptional(input.Description)
+	}
+	updated, err := s.client.UpdateWebhook(ctx, *webhook)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrWebhookNotFound
+	}
+	updated.Secret = ""
+	return updated, nil
+}
+
+// DeleteWebhook removes a subscription and its delivery log.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, admin servicedto.User, id uint) error {
+	if !admin.IsAdmin {
+		return ErrUnauthorized
+	}
+	deleted, err := s.client.DeleteWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// ListDeliveries returns a webhook's delivery log, newest first, optionally
+// only deliveries with status.
+func (s *WebhookService) ListDeliveries(ctx context.Context, admin servicedto.User, webhookID uint, status *string, limit int) ([]servicedto.WebhookDelivery, error) {
+	if !admin.IsAdmin {
+		return nil, ErrUnauthorized
+	}
+	if status != nil && *status != servicedto.WebhookDeliveryPending && *status != servicedto.WebhookDeliveryDelivered && *status != servicedto.WebhookDeliveryFailed {
+		return nil, ErrInvalidStatus
+	}
+	if limit <= 0 {
+		limit = DefaultWebhookDeliveryPage
+	}
+	limit = min(limit, MaxWebhookDeliveryPage)
+	if _, err := s.getWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return s.client.ListWebhookDeliveries(ctx, webhookID, status, limit)
+}
+
+// ReplayDeliveries gives failed deliveries a fresh round of attempts,
+// starting with the next delivery run. It returns how many were queued.
+func (s *WebhookService) ReplayDeliveries(ctx context.Context, admin servicedto.User, input servicedto.ReplayWebhookInput) (int, error) {
+	if !admin.IsAdmin {
+		return 0, ErrUnauthorized
+	}
+	if _, err := s.getWebhook(ctx, input.WebhookID); err != nil {
+		return 0, err
+	}
+	return s.client.ReplayWebhookDeliveries(ctx, input.WebhookID, input.DeliveryIDs, s.now())
+}
+
+func (s *WebhookService) getWebhook(ctx context.Context, id uint) (*servicedto.Webhook, error) {
+	webhook, err := s.client.GetWebhookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// Publish queues the event for every active webhook subscribed to it. All
+// subscribers receive the same payload and event id. The id comes from the
+// outbox event, so publishing a redelivered event again queues nothing new.
+func (s *WebhookService) Publish(ctx context.Context, event servicedto.ReservationEvent) error {
+	eventType := webhookEventFor(event.Kind)
+	if eventType == "" {
+		return nil
+	}
+	webhooks, err := s.client.ListWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	var subscribed []servicedto.Webhook
+	for _, w := range webhooks {
+		if w.Active && slices.Contains(w.Events, eventType) {
+			subscribed = append(subscribed, w)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	eventID, err := webhookEventID(event)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	payload, err := json.Marshal(webhookPayload{
+		ID:        eventID,
+		Type:      eventType,
+		CreatedAt: now.UTC(),
+		Data:      toWebhookReservation(event.Reservation),
+	})
+	if err != nil {
+		return err
+	}
+	deliveries := make([]servicedto.CreateWebhookDeliveryParams, 0, len(subscribed))
+	for _, w := range subscribed {
+		deliveries = append(deliveries, servicedto.CreateWebhookDeliveryParams{
+			WebhookID:     w.ID,
+			EventID:       eventID,
+			Event:         eventType,
+			Payload:       payload,
+			NextAttemptAt: now,
+		})
+	}
+	return s.client.CreateWebhookDeliveries(ctx, deliveries)
+}
+
+// DeliverDueWebhooks attempts the deliveries that are due. A delivery that
+// fails is retried after an exponentially growing wait until it runs out of
+// attempts and is marked failed.
+func (s *WebhookService) DeliverDueWebhooks(ctx context.Context) (*servicedto.WebhookResult, error) {
+	result := &servicedto.WebhookResult{}
+	due, err := s.client.ListDueWebhookDeliveries(ctx, s.now(), webhookBatchSize)
+	if err != nil {
+		return result, err
+	}
+	webhooks := make(map[uint]*servicedto.Webhook)
+	for _, d := range due {
+		webhook, ok := webhooks[d.WebhookID]
+		if !ok {
+			if webhook, err = s.client.GetWebhookByID(ctx, d.WebhookID); err != nil {
+				return result, err
+			}
+			webhooks[d.WebhookID] = webhook
+		}
+		if webhook == nil {
+			continue
+		}
+		if err := s.deliver(ctx, *webhook, d, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (s *WebhookService) deliver(ctx context.Context, webhook servicedto.Webhook, d servicedto.WebhookDelivery, result *servicedto.WebhookResult) error {
+	timestamp := s.now().Unix()
+	headers := map[string]string{
+		"Content-Type":         "application/json",
+		WebhookEventHeader:     d.Event,
+		WebhookDeliveryHeader:  strconv.FormatUint(uint64(d.ID), 10),
+		WebhookTimestampHeader: strconv.FormatInt(timestamp, 10),
+		WebhookSignatureHeader: SignWebhook(webhook.Secret, timestamp, d.Payload),
+	}
+	// The host may have been pointed elsewhere since the webhook was saved.
+	code, err := 0, s.checkWebhookTarget(ctx, webhook.URL)
+	if err == nil {
+		code, err = s.sender.Post(ctx, webhook.URL, headers, d.Payload)
+	}
+	if err == nil && (code < 200 || code > 299) {
+		err = fmt.Errorf("subscriber answered %d", code)
+	}
+
+	now := s.now()
+	attempt := servicedto.WebhookAttemptParams{ID: d.ID, At: now}
+	if code != 0 {
+		attempt.StatusCode = &code
+	}
+	switch {
+	case err == nil:
+		attempt.Status = servicedto.WebhookDeliveryDelivered
+		result.Delivered++
+	case d.Attempts+1 >= s.policy.MaxAttempts:
+		msg := err.Error()
+		attempt.Status, attempt.Error = servicedto.WebhookDeliveryFailed, &msg
+		result.Failed++
+	default:
+		msg := err.Error()
+		next := now.Add(exponentialBackoff(s.policy.Backoff, s.policy.MaxBackoff, d.Attempts+1))
+		attempt.Status, attempt.Error, attempt.NextAttemptAt = servicedto.WebhookDeliveryPending, &msg, &next
+		result.Retrying++
+	}
+	return s.client.RecordWebhookAttempt(ctx, attempt)
+}
+
+// SignWebhook returns the signature header value for a delivery, for
+// subscribers to compare against.
+func SignWebhook(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookPayload is the JSON body of a delivery.
+type webhookPayload struct {
+	ID        string             `json:"id"`
+	Type      string             `json:"type"`
+	CreatedAt time.Time          `json:"created_at"`
+	Data      webhookReservation `json:"data"`
+}
+
+type webhookReservation struct {
+	ID      uint          `json:"id"`
+	Code    string        `json:"code"`
+	Date    string        `json:"date"`
+	Time    string        `json:"time"`
+	People  int           `json:"people"`
+	Status  string        `json:"status"`
+	Channel string        `json:"channel"`
+	TableID *uint         `json:"table_id"`
+	Comment *string       `json:"comment"`
+	Guest   *webhookGuest `json:"guest,omitempty"`
+}
+
+type webhookGuest struct {
+	ID    uint    `json:"id"`
+	Name  string  `json:"name"`
+	Email string  `json:"email,omitempty"`
+	Phone *string `json:"phone,omitempty"`
+}
+
+func toWebhookReservation(r servicedto.Reservation) webhookReservation {
+	out := webhookReservation{
+		ID:      r.ID,
+		Code:    r.Code,
+		Date:    r.Date.Format("2006-01-02"),
+		Time:    r.Time,
+		People:  r.People,
+		Status:  r.Status,
+		Channel: r.Channel,
+		TableID: r.TableID,
+		Comment: r.Comment,
+	}
+	if u := r.User; u != nil {
+		out.Guest = &webhookGuest{ID: u.ID, Name: u.Name, Phone: u.Phone}
+		if !u.IsGuest {
+			out.Guest.Email = u.Email
+		}
+	}
+	return out
+}
+
+func normalizeWebhookURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(raw) > 2048 {
+		return "", ErrInvalidWebhookURL
+	}
+	return raw, nil
+}
+
+// checkWebhookTarget rejects URLs whose host is, or resolves to, an address
+// that is not PublicAddress. Hosts that do not resolve are let through; the
+// sender refuses to connect to such addresses whatever they resolve to later.
+func (s *WebhookService) checkWebhookTarget(ctx context.Context, target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return ErrInvalidWebhookURL
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateWebhookURL
+	}
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else if addrs, err = s.lookupHost(ctx, host); err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !servicedto.PublicAddress(addr) {
+			return ErrPrivateWebhookURL
+		}
+	}
+	return nil
+}
+
+// normalizeWebhookEvents lowercases and deduplicates event types, rejecting
+// unknown ones and empty lists.
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	var out []string
+	for _, e := range events {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if !slices.Contains(webhookEvents, e) {
+			return nil, ErrInvalidWebhookEvent
+		}
+		if !slices.Contains(out, e) {
+			out = append(out, e)
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrInvalidWebhookEvent
+	}
+	return out, nil
+}
+
+func trimOptional(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// webhookEventID names the event subscribers receive after the outbox event
+// it came from; other events get a random id.
+func webhookEventID(event servicedto.ReservationEvent) (string, error) {
+	if event.ID == 0 {
+		return randomHex(16)
+	}
+	return fmt.Sprintf("evt_%d", event.ID), nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,435 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	servicedto "vesuvio/internal/dto/service"
+)
+
+func TestWebhookSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	svc := NewWebhookService(newFakeWebhookClient(), &fakeWebhookSender{}, servicedto.WebhookPolicy{})
+
+	webhook, err := svc.CreateWebhook(ctx, admin, servicedto.CreateWebhookInput{
+		URL:    " https://crm.example/hooks ",
+		Events: []string{"Reservation.Created", servicedto.WebhookReservationCreated, servicedto.WebhookReservationCancelled},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if webhook.URL != "https://crm.example/hooks" || len(webhook.Events) != 2 || len(webhook.Secret) < 32 {
+		t.Fatalf("unexpected webhook: %+v", webhook)
+	}
+	list, _ := svc.ListWebhooks(ctx, admin)
+	if len(list) != 1 || list[0].Secret != "" {
+		t.Fatalf("expected the secret to be hidden, got %+v", list)
+	}
+
+	off := false
+	updated, err := svc.UpdateWebhook(ctx, admin, servicedto.UpdateWebhookInput{ID: webhook.ID, Active: &off})
+	if err != nil || updated.Active || len(updated.Events) != 2 {
+		t.Fatalf("expected only the active flag to change, got %+v %v", updated, err)
+	}
+
+	ftp := "ftp://crm.example"
+	for _, tc := range []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"bad url", func() error {
+			_, err := svc.CreateWebhook(ctx, admin, servicedto.CreateWebhookInput{URL: "crm.example", Events: []string{servicedto.WebhookReservationCreated}})
+			return err
+		}, ErrInvalidWebhookURL},
+		{"bad scheme", func() error {
+			_, err := svc.UpdateWebhook(ctx, admin, servicedto.UpdateWebhookInput{ID: webhook.ID, URL: &ftp})
+			return err
+		}, ErrInvalidWebhookURL},
+		{"unknown event", func() error {
+			_, err := svc.CreateWebhook(ctx, admin, servicedto.CreateWebhookInput{URL: "https://x.example", Events: []string{"reservation.seated"}})
+			return err
+		}, ErrInvalidWebhookEvent},
+		{"no events", func() error {
+			_, err := svc.CreateWebhook(ctx, admin, servicedto.CreateWebhookInput{URL: "https://x.example"})
+			return err
+		}, ErrInvalidWebhookEvent},
+		{"not admin", func() error {
+			_, err := svc.ListWebhooks(ctx, servicedto.User{ID: 1})
+			return err
+		}, ErrUnauthorized},
+		{"missing", func() error { return svc.DeleteWebhook(ctx, admin, 999) }, ErrWebhookNotFound},
+	} {
+		if err := tc.call(); err != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+	if err := svc.DeleteWebhook(ctx, admin, webhook.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	client := newFakeWebhookClient()
+	sender := &fakeWebhookSender{codes: map[string]int{"https://down.example": 503}}
+	svc := NewWebhookService(client, sender, servicedto.WebhookPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: time.Hour})
+	now := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	kitchen, _ := svc.CreateWebhook(ctx, admin, servicedto.CreateWebhookInput{URL: "https://kitchen.example", Events: []string{servicedto.WebhookReservationCreated}})
+	down, _ := svc.CreateWebhook(ctx, admin, servicedto.CreateWebhookInput{URL: "https://down.example", Events: []string{servicedto.WebhookReservationCreated}})
+	svc.CreateWebhook(ctx, admin, servicedto.CreateWebhookInput{URL: "https://other.example", Events: []string{servicedto.WebhookReservationCancelled}})
+
+	res := servicedto.Reservation{ID: 7, Code: "VSV-ABC123", Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 2, Status: servicedto.StatusPending}
+	if err := svc.Publish(ctx, servicedto.ReservationEvent{Kind: servicedto.EventReservationCreated, Reservation: res}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(client.deliveries) != 2 {
+		t.Fatalf("expected a delivery per subscribed webhook, got %d", len(client.deliveries))
+	}
+
+	result, err := svc.DeliverDueWebhooks(ctx)
+	if err != nil || result.Delivered != 1 || result.Retrying != 1 {
+		t.Fatalf("expected one delivered and one retrying, got %+v %v", result, err)
+	}
+	req := sender.requests[0]
+	if req.url != "https://kitchen.example" || req.headers[WebhookEventHeader] != servicedto.WebhookReservationCreated {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	timestamp, _ := strconv.ParseInt(req.headers[WebhookTimestampHeader], 10, 64)
+	if req.headers[WebhookSignatureHeader] != SignWebhook(kitchen.Secret, timestamp, req.body) {
+		t.Fatalf("signature does not verify: %q", req.headers[WebhookSignatureHeader])
+	}
+	var payload struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Code string `json:"code"`
+			Date string `json:"date"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(req.body, &payload); err != nil || payload.ID == "" || payload.Data.Code != "VSV-ABC123" || payload.Data.Date != "2030-01-11" {
+		t.Fatalf("unexpected payload %s: %v", req.body, err)
+	}
+
+	// Retries wait 1m, then 2m, then give up.
+	if result, _ := svc.DeliverDueWebhooks(ctx); result.Retrying+result.Failed != 0 {
+		t.Fatalf("expected nothing due before the backoff, got %+v", result)
+	}
+	for _, wait := range []time.Duration{time.Minute, 2 * time.Minute} {
+		now = now.Add(wait)
+		if _, err := svc.DeliverDueWebhooks(ctx); err != nil {
+			t.Fatalf("deliver: %v", err)
+		}
+	}
+	failed := client.deliveries[1]
+	if failed.Status != servicedto.WebhookDeliveryFailed || failed.Attempts != 3 || *failed.Error != "subscriber answered 503" {
+		t.Fatalf("unexpected failed delivery: %+v", failed)
+	}
+
+	// Once the subscriber is back, replaying sends the same event again.
+	delete(sender.codes, "https://down.example")
+	replayed, err := svc.ReplayDeliveries(ctx, admin, servicedto.ReplayWebhookInput{WebhookID: down.ID})
+	if err != nil || replayed != 1 {
+		t.Fatalf("expected one replay, got %d %v", replayed, err)
+	}
+	if result, _ := svc.DeliverDueWebhooks(ctx); result.Delivered != 1 {
+		t.Fatalf("expected the replay to be delivered, got %+v", result)
+	}
+	last := sender.requests[len(sender.requests)-1]
+	if string(last.body) != string(req.body) {
+		t.Fatalf("expected the replay to resend the original payload")
+	}
+	log, err := svc.ListDeliveries(ctx, admin, down.ID, nil, 0)
+	if err != nil || len(log) != 1 || log[0].Status != servicedto.WebhookDeliveryDelivered {
+		t.Fatalf("unexpected delivery log: %+v %v", log, err)
+	}
+}
+
+func TestWebhookPublishOncePerOutboxEvent(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	client := newFakeWebhookClient()
+	svc := NewWebhookService(client, &fakeWebhookSender{}, servicedto.WebhookPolicy{})
+	svc.CreateWebhook(ctx, admin, servicedto.CreateWebhookInput{URL: "https://kitchen.example", Events: []string{servicedto.WebhookReservationCreated}})
+	svc.CreateWebhook(ctx, admin, servicedto.CreateWebhookInput{URL: "https://crm.example", Events: []string{servicedto.WebhookReservationCreated}})
+
+	res := servicedto.Reservation{ID: 7, Code: "VSV-ABC123", Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 2, Status: servicedto.StatusPending}
+	event := servicedto.ReservationEvent{ID: 42, Kind: servicedto.EventReservationCreated, Reservation: res}
+	for i := 0; i < 2; i++ { // the outbox redelivers the event
+		if err := svc.Publish(ctx, event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	if len(client.deliveries) != 2 {
+		t.Fatalf("expected one delivery per webhook, got %d", len(client.deliveries))
+	}
+	for _, d := range client.deliveries {
+		if d.EventID != "evt_42" || !strings.Contains(string(d.Payload), `"id":"evt_42"`) {
+			t.Fatalf("expected the outbox event id, got %s %s", d.EventID, d.Payload)
+		}
+	}
+
+	event.ID = 43
+	if err := svc.Publish(ctx, event); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(client.deliveries) != 4 {
+		t.Fatalf("expected a new event to be queued, got %d deliveries", len(client.deliveries))
+	}
+}
+
+func TestWebhookURLsMustBePublic(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	client := newFakeWebhookClient()
+	sender := &fakeWebhookSender{}
+	svc := NewWebhookService(client, sender, servicedto.WebhookPolicy{MaxAttempts: 1})
+	dns := map[string][]netip.Addr{
+		"crm.example":      {netip.MustParseAddr("93.184.216.34")},
+		"intranet.example": {netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.5")},
+	}
+	svc.lookupHost = func(ctx context.Context, host string) ([]netip.Addr, error) {
+		if addrs, ok := dns[host]; ok {
+			return addrs, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	create := func(url string) (*servicedto.Webhook, error) {
+		return svc.CreateWebhook(ctx, admin, servicedto.CreateWebhookInput{URL: url, Events: []string{servicedto.WebhookReservationCreated}})
+	}
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://api.localhost./hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.1.2.3/hook",
+		"http://192.168.1.10/hook",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://0.0.0.0/hook",
+		"https://intranet.example/hook",
+	} {
+		if _, err := create(url); err != ErrPrivateWebhookURL {
+			t.Fatalf("%s: expected ErrPrivateWebhookURL, got %v", url, err)
+		}
+	}
+	webhook, err := create("https://crm.example/hook")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	private := "http://10.0.0.5/hook"
+	if _, err := svc.UpdateWebhook(ctx, admin, servicedto.UpdateWebhookInput{ID: webhook.ID, URL: &private}); err != ErrPrivateWebhookURL {
+		t.Fatalf("update: expected ErrPrivateWebhookURL, got %v", err)
+	}
+
+	// The host is re-pointed at the internal network after registration.
+	dns["crm.example"] = []netip.Addr{netip.MustParseAddr("192.168.0.2")}
+	res := servicedto.Reservation{ID: 7, Code: "VSV-ABC123", Date: mustDate(t, "2030-01-11"), Time: "20:00", People: 2, Status: servicedto.StatusPending}
+	if err := svc.Publish(ctx, servicedto.ReservationEvent{Kind: servicedto.EventReservationCreated, Reservation: res}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	result, err := svc.DeliverDueWebhooks(ctx)
+	if err != nil || result.Failed != 1 || len(sender.requests) != 0 {
+		t.Fatalf("expected the delivery refused without a request, got %+v %d %v", result, len(sender.requests), err)
+	}
+	if d := client.deliveries[0]; d.Error == nil || *d.Error != ErrPrivateWebhookURL.Error() {
+		t.Fatalf("unexpected delivery: %+v", d)
+	}
+}
+
+func TestReservationWebhooks(t *testing.T) {
+	ctx := context.Background()
+	admin := servicedto.User{ID: 100, IsAdmin: true}
+	users := newFakeUserClient()
+	user, _ := users.CreateUser(ctx, servicedto.CreateUserParams{Name: "Ana", Email: "ana@example.com"})
+	client := newFakeReservationClient()
+	svc := NewReservationService(client, WithGuestClient(users))
+
+	out, err := svc.CreateReservation(ctx, servicedto.CreateReservationInput{UserID: user.ID, Date: "2030-01-11", Time: "20:00", People: 2})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	svc.ModifyReservation(ctx, servicedto.ModifyReservationInput{UserID: user.ID, ReservationID: out.Reservation.ID, People: 3})
+	if _, err := svc.WalkIn(ctx, admin, servicedto.WalkInInput{UserID: user.ID, People: 2}); err != nil {
+		t.Fatalf("walk-in: %v", err)
+	}
+	client.UpdateReservationStatus(ctx, servicedto.UpdateReservationStatusParams{ID: out.Reservation.ID, Status: servicedto.StatusNoShow})
+
+	// Subscribers hear about walk-ins and every status change; guests do not.
+	webhooks := &recordingPublisher{}
+	dispatchLifecycle(t, client, users, webhooks.Publish, true)
+	want := []string{servicedto.EventReservationCreated, servicedto.EventReservationModified, servicedto.EventReservationCreated, servicedto.EventReservationStatusChanged}
+	if !slices.Equal(webhooks.kinds, want) {
+		t.Fatalf("expected webhook events %v, got %v", want, webhooks.kinds)
+	}
+	if got := webhookEventFor(servicedto.EventReservationStatusChanged); got != servicedto.WebhookReservationUpdated {
+		t.Fatalf("expected status changes sent as %s, got %q", servicedto.WebhookReservationUpdated, got)
+	}
+	notifier := &recordingNotifier{}
+	dispatchLifecycle(t, client, users, notifier.Notify, false)
+	if len(notifier.events) != 3 {
+		t.Fatalf("expected the walk-in guest not to be messaged, got %v", notifier.kinds())
+	}
+}
+
+// recordingPublisher records the kinds of published events.
+type recordingPublisher struct {
+	kinds []string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event servicedto.ReservationEvent) error {
+	p.kinds = append(p.kinds, event.Kind)
+	return nil
+}
+
+type webhookRequest struct {
+	url     string
+	headers map[string]string
+	body    []byte
+}
+
+// fakeWebhookSender records requests and answers with codes[url], or 200.
+type fakeWebhookSender struct {
+	codes    map[string]int
+	requests []webhookRequest
+}
+
+func (s *fakeWebhookSender) Post(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	s.requests = append(s.requests, webhookRequest{url: url, headers: headers, body: body})
+	if code, ok := s.codes[url]; ok {
+		return code, nil
+	}
+	return 200, nil
+}
+
+// fakeWebhookClient keeps webhooks and deliveries in memory; IDs are
+// positions plus one.
+type fakeWebhookClient struct {
+	webhooks   []*servicedto.Webhook
+	deliveries []servicedto.WebhookDelivery
+}
+
+func newFakeWebhookClient() *fakeWebhookClient {
+	return &fakeWebhookClient{}
+}
+
+func (f *fakeWebhookClient) CreateWebhook(ctx context.Context, params servicedto.CreateWebhookParams) (*servicedto.Webhook, error) {
+	w := &servicedto.Webhook{
+		ID: uint(len(f.webhooks) + 1), URL: params.URL, Secret: params.Secret,
+		Events: params.Events, Active: true, Description: params.Description,
+	}
+	f.webhooks = append(f.webhooks, w)
+	copy := *w
+	return &copy, nil
+}
+
+func (f *fakeWebhookClient) GetWebhookByID(ctx context.Context, id uint) (*servicedto.Webhook, error) {
+	if id == 0 || int(id) > len(f.webhooks) || f.webhooks[id-1] == nil {
+		return nil, nil
+	}
+	copy := *f.webhooks[id-1]
+	return &copy, nil
+}
+
+func (f *fakeWebhookClient) ListWebhooks(ctx context.Context) ([]servicedto.Webhook, error) {
+	var list []servicedto.Webhook
+	for _, w := range f.webhooks {
+		if w != nil {
+			list = append(list, *w)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeWebhookClient) UpdateWebhook(ctx context.Context, webhook servicedto.Webhook) (*servicedto.Webhook, error) {
+	existing, _ := f.GetWebhookByID(ctx, webhook.ID)
+	if existing == nil {
+		return nil, nil
+	}
+	f.webhooks[webhook.ID-1] = &webhook
+	return &webhook, nil
+}
+
+func (f *fakeWebhookClient) DeleteWebhook(ctx context.Context, id uint) (bool, error) {
+	existing, _ := f.GetWebhookByID(ctx, id)
+	if existing == nil {
+		return false, nil
+	}
+	f.webhooks[id-1] = nil
+	return true, nil
+}
+
+func (f *fakeWebhookClient) CreateWebhookDeliveries(ctx context.Context, params []servicedto.CreateWebhookDeliveryParams) error {
+	for _, p := range params {
+		if slices.ContainsFunc(f.deliveries, func(d servicedto.WebhookDelivery) bool {
+			return d.WebhookID == p.WebhookID && d.EventID == p.EventID
+		}) {
+			continue
+		}
+		next := p.NextAttemptAt
+		f.deliveries = append(f.deliveries, servicedto.WebhookDelivery{
+			ID: uint(len(f.deliveries) + 1), WebhookID: p.WebhookID, EventID: p.EventID, Event: p.Event,
+			Payload: p.Payload, Status: servicedto.WebhookDeliveryPending, NextAttemptAt: &next,
+		})
+	}
+	return nil
+}
+
+func (f *fakeWebhookClient) ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]servicedto.WebhookDelivery, error) {
+	var due []servicedto.WebhookDelivery
+	for _, d := range f.deliveries {
+		w, _ := f.GetWebhookByID(ctx, d.WebhookID)
+		if w != nil && w.Active && d.Status == servicedto.WebhookDeliveryPending && !d.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeWebhookClient) RecordWebhookAttempt(ctx context.Context, params servicedto.WebhookAttemptParams) error {
+	d := &f.deliveries[params.ID-1]
+	d.Status, d.NextAttemptAt, d.StatusCode, d.Error = params.Status, params.NextAttemptAt, params.StatusCode, params.Error
+	d.Attempts++
+	if params.Status == servicedto.WebhookDeliveryDelivered {
+		d.DeliveredAt = &params.At
+	}
+	return nil
+}
+
+func (f *fakeWebhookClient) ListWebhookDeliveries(ctx context.Context, webhookID uint, status *string, limit int) ([]servicedto.WebhookDelivery, error) {
+	var list []servicedto.WebhookDelivery
+	for i := len(f.deliveries) - 1; i >= 0 && len(list) < limit; i-- {
+		d := f.deliveries[i]
+		if d.WebhookID == webhookID && (status == nil || d.Status == *status) {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeWebhookClient) ReplayWebhookDeliveries(ctx context.Context, webhookID uint, ids []uint, at time.Time) (int, error) {
+	n := 0
+	for i := range f.deliveries {
+		d := &f.deliveries[i]
+		if d.WebhookID != webhookID || d.Status != servicedto.WebhookDeliveryFailed || (len(ids) > 0 && !slices.Contains(ids, d.ID)) {
+			continue
+		}
+		d.Status, d.Attempts, d.NextAttemptAt = servicedto.WebhookDeliveryPending, 0, &at
+		n++
+	}
+	return n, nil
+}
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"fmt"
 	"log"
 	"net/url"
+	"os"
+	_ "time/tzdata" // RESTAURANT_TIMEZONE must resolve in minimal containers
 
 	"github.com/gin-gonic/gin"
 
 	"vesuvio/internal/client"
 	"vesuvio/internal/config"
 	"vesuvio/internal/controller"
+	servicedto "vesuvio/internal/dto/service"
 	"vesuvio/internal/middleware"
 	"vesuvio/internal/service"
 )
@@ -27,41 +33,326 @@ func main() {
 		log.Fatalf("failed to connect database: %v", err)
 	}
 
+	phones := servicedto.PhoneFormat{CountryCode: cfg.PhoneCountryCode, TrunkPrefix: cfg.PhoneTrunkPrefix}
+	updated, skipped, err := client.NormalizeStoredPhones(context.Background(), db, phones)
+	if err != nil {
+		log.Fatalf("failed to normalize phone numbers: %v", err)
+	}
+	if updated > 0 || skipped > 0 {
+		log.Printf("Normalized %d stored phone numbers; %d could not be read", updated, skipped)
+	}
+
 	userClient := client.NewUserClient(db)
 	reservationClient := client.NewReservationClient(db)
+	idempotencyClient := client.NewIdempotencyClient(db)
+	tableClient := client.NewTableClient(db)
+	restrictionClient := client.NewRestrictionClient(db)
+	paymentClient := client.NewPaymentClient(db)
+	jobLeaseClient := client.NewJobLeaseClient(db)
+	reminderClient := client.NewReminderClient(db)
+	webhookClient := client.NewWebhookClient(db)
+
+	links := service.NewLinkSigner(linkSecret(cfg.LinkSecret))
+	channels := notificationChannels(cfg)
+
+	reservationOptions := []service.ReservationOption{
+		service.WithReservationDuration(cfg.ReservationDuration),
+		service.WithOverlapPolicy(cfg.OverlapPolicy),
+		service.WithGuestClient(userClient),
+		service.WithTableClient(tableClient),
+		service.WithLocation(cfg.Location),
+		service.WithPhoneFormat(phones),
+		service.WithRequirementsCatalogue(servicedto.RequirementsCatalogue{
+			Allergens:     cfg.Allergens,
+			DietaryStyles: cfg.DietaryStyles,
+			Accessibility: cfg.AccessibilityNeeds,
+			MaxHighChairs: cfg.MaxHighChairs,
+		}),
+		service.WithSanctions(restrictionClient, servicedto.SanctionPolicy{
+			Rules:            sanctionRules(cfg.Sanctions),
+			Window:           cfg.SanctionWindow,
+			LateCancelWindow: cfg.LateCancelWindow,
+			CountLateCancels: cfg.SanctionCountLateCancels,
+		}),
+		service.WithNoShowGrace(cfg.NoShowGrace),
+		service.WithExpiry(servicedto.ExpiryPolicy{
+			PendingMaxAge:  cfg.PendingMaxAge,
+			PendingMinLead: cfg.PendingMinLead,
+			Action:         cfg.PendingExpiryAction,
+			PaymentTimeout: cfg.PaymentTimeout,
+		}),
+		service.WithReservationLinks(links),
+	}
+	if cfg.PaymentProvider == "fake" {
+		log.Printf("Using the fake payment provider; deposits complete without charging anyone")
+		provider := client.NewFakePaymentProvider(cfg.PaymentWebhookSecret)
+		provider.AutoComplete = true
+		reservationOptions = append(reservationOptions, service.WithPayments(provider, paymentClient, servicedto.DepositPolicy{
+			Kind:              cfg.DepositKind,
+			AmountPerPerson:   int64(cfg.DepositPerPerson),
+			Currency:          cfg.DepositCurrency,
+			MinPeople:         cfg.DepositMinPeople,
+			PeakDays:          cfg.DepositPeakDays,
+			PeakDates:         cfg.DepositPeakDates,
+			ChargeLateCancels: cfg.DepositChargeLateCancels,
+		}))
+	}
+
+	webhookService := service.NewWebhookService(webhookClient, client.NewHTTPWebhookSender(cfg.WebhookTimeout), servicedto.WebhookPolicy{
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		Backoff:     cfg.WebhookBackoff,
+		MaxBackoff:  cfg.WebhookMaxBackoff,
+	})
 
-	authService := service.NewAuthService(userClient)
-	reservationService := service.NewReservationService(reservationClient)
+	// Reservation changes reach webhook subscribers and guests through the
+	// outbox, so none are lost if the process stops right after a commit.
+	lifecycle := service.NewLifecycleEvents(reservationClient, userClient)
+	outboxClient := client.NewOutboxClient(db)
+	outbox := service.NewOutboxDispatcher(outboxClient)
+	outbox.Register("webhooks", lifecycle.Handler(webhookService.Publish, true))
+	liveService := service.NewLiveService(service.NewMemoryBroadcaster(cfg.LiveHistory), outboxClient, reservationClient, userClient, links, cfg.Location)
+	if cfg.LifecycleEmails {
+		notifier, err := service.NewTemplateNotifier(cfg.EmailTemplatesDir, cfg.EmailLocale, channels, userClient)
+		if err != nil {
+			log.Fatalf("failed to load email templates: %v", err)
+		}
+		// One handler per channel, so a failed text is retried without
+		// emailing the guest again.
+		for _, channel := range notifier.Channels() {
+			outbox.Register("guest-messages-"+channel, lifecycle.Handler(notifier.Via(channel), false))
+		}
+	}
+
+	if cfg.AutoConfirm {
+		reservationOptions = append(reservationOptions, service.WithAutoConfirm(servicedto.AutoConfirmPolicy{
+			MaxPeople:      cfg.AutoConfirmMaxPeople,
+			MaxLoadPercent: cfg.AutoConfirmMaxLoad,
+			AllowNoShows:   cfg.AutoConfirmAllowNoShows,
+			BlackoutDates:  cfg.AutoConfirmBlackoutDates,
+		}))
+	}
+
+	authService := service.NewAuthService(userClient, phones)
+	reservationService := service.NewReservationService(reservationClient, reservationOptions...)
+	floorService := service.NewFloorService(tableClient, reservationClient, cfg.ReservationDuration, cfg.Location)
+	kitchenService := service.NewKitchenService(reservationClient, servicePeriods(cfg.ServicePeriods))
+	guestService := service.NewGuestService(userClient, reservationClient, restrictionClient, cfg.Location, cfg.LateCancelWindow)
+	idempotencyService := service.NewIdempotencyService(idempotencyClient, cfg.IdempotencyTTL)
+	preferenceService := service.NewPreferenceService(userClient, phones)
+	reminderService, err := service.NewReminderService(reservationClient, reminderClient, userClient, channels, cfg.EmailTemplatesDir, cfg.EmailLocale, links, cfg.PublicBaseURL, cfg.ReminderOffsets, cfg.Location)
+	if err != nil {
+		log.Fatalf("failed to load reminder templates: %v", err)
+	}
+	calendarService := service.NewCalendarService(reservationClient, client.NewCalendarFeedClient(db), userClient, cfg.PublicBaseURL, cfg.ReservationDuration, cfg.Location)
+
+	jobs := service.NewJobRunner(jobLeaseClient, jobHolder())
+	jobs.Add(service.Job{
+		Name:     "expire-reservations",
+		Interval: cfg.ExpiryInterval,
+		Run: func(ctx context.Context) error {
+			result, err := reservationService.ExpireStaleReservations(ctx)
+			if result != nil && result.Cancelled+result.Escalated > 0 {
+				log.Printf("expired reservations: %d cancelled, %d escalated", result.Cancelled, result.Escalated)
+			}
+			return err
+		},
+	})
+	jobs.Add(service.Job{
+		Name:     "mark-no-shows",
+		Interval: cfg.NoShowInterval,
+		Run: func(ctx context.Context) error {
+			result, err := reservationService.MarkOverdueNoShows(ctx)
+			if result != nil && result.Marked > 0 {
+				log.Printf("marked %d reservations as no-shows", result.Marked)
+			}
+			return err
+		},
+	})
+	jobs.Add(service.Job{
+		Name:     "settle-payments",
+		Interval: cfg.SettlementInterval,
+		Run: func(ctx context.Context) error {
+			result, err := reservationService.SettleDuePayments(ctx)
+			if result != nil && result.Settled+result.Failed > 0 {
+				log.Printf("payments: %d settled, %d to retry", result.Settled, result.Failed)
+			}
+			return err
+		},
+	})
+	jobs.Add(service.Job{
+		Name:     "send-reminders",
+		Interval: cfg.ReminderInterval,
+		Run: func(ctx context.Context) error {
+			result, err := reminderService.SendDueReminders(ctx)
+			if result != nil && result.Sent+result.Failed > 0 {
+				log.Printf("sent %d reminders, %d failed", result.Sent, result.Failed)
+			}
+			return err
+		},
+	})
+	jobs.Add(service.Job{
+		Name:     "deliver-webhooks",
+		Interval: cfg.WebhookInterval,
+		Run: func(ctx context.Context) error {
+			result, err := webhookService.DeliverDueWebhooks(ctx)
+			if result != nil && result.Retrying+result.Failed > 0 {
+				log.Printf("webhooks: %d delivered, %d to retry, %d failed", result.Delivered, result.Retrying, result.Failed)
+			}
+			return err
+		},
+	})
+	jobs.Add(service.Job{
+		Name:     "dispatch-outbox",
+		Interval: cfg.OutboxInterval,
+		Run: func(ctx context.Context) error {
+			result, err := outbox.Dispatch(ctx)
+			if result != nil && result.Retrying+result.Failed > 0 {
+				log.Printf("outbox: %d dispatched, %d to retry, %d failed", result.Dispatched, result.Retrying, result.Failed)
+			}
+			return err
+		},
+	})
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	jobs.Start(jobCtx)
+	// Live updates follow the outbox on every instance, each for its own
+	// subscribers, rather than on the one holding the dispatch lease.
+	if cfg.LiveInterval > 0 {
+		go liveService.Run(jobCtx, cfg.LiveInterval)
+	}
 
 	authController := controller.NewAuthController(authService)
 	reservationController := controller.NewReservationController(reservationService)
 	adminController := controller.NewAdminController(reservationService)
+	floorController := controller.NewFloorController(floorService)
+	kitchenController := controller.NewKitchenController(kitchenService)
+	guestController := controller.NewGuestController(guestService)
+	paymentController := controller.NewPaymentController(reservationService)
+	reminderController := controller.NewReminderController(reminderService)
+	notificationController := controller.NewNotificationController(preferenceService)
+	webhookController := controller.NewWebhookController(webhookService)
+	liveController := controller.NewLiveController(liveService, cfg.LiveHeartbeat, cfg.AllowedOrigins)
+	calendarController := controller.NewCalendarController(calendarService)
 
 	r := gin.Default()
 	r.Use(middleware.CORSMiddleware())
 
 	r.POST("/auth/register", authController.Register)
 	r.POST("/auth/login", authController.Login)
+	r.GET("/requirements-catalogue", reservationController.RequirementsCatalogue)
+	r.POST("/webhooks/payments", paymentController.Webhook)
+	r.GET("/links/:token", reservationController.ShowLink)
+	r.POST("/links", reservationController.FollowLink)
+	r.GET("/feeds/:token", calendarController.Feed)
 
 	authRequired := r.Group("/")
 	authRequired.Use(middleware.AuthMiddleware(authService))
 	{
 		authRequired.GET("/my/reservations", reservationController.ListMyReservations)
-		authRequired.POST("/reservations", reservationController.CreateReservation)
+		authRequired.POST("/my/stream-token", liveController.IssueStreamToken)
+		authRequired.GET("/my/calendar-feed", calendarController.MyFeed)
+		authRequired.POST("/my/calendar-feed/reset", calendarController.ResetMyFeed)
+		authRequired.GET("/my/notification-preferences", notificationController.GetPreferences)
+		authRequired.PUT("/my/notification-preferences", notificationController.UpdatePreferences)
+		authRequired.POST("/reservations", middleware.Idempotency(idempotencyService), reservationController.CreateReservation)
+		authRequired.PATCH("/reservations/:id", reservationController.ModifyReservation)
 		authRequired.PATCH("/reservations/:id/cancel", reservationController.CancelReservation)
+		authRequired.GET("/reservations/:id/calendar.ics", calendarController.ReservationCalendar)
 	}
 
+	// EventSource and WebSocket cannot send X-User-ID; live streams take a
+	// token from POST /my/stream-token instead.
+	r.GET("/my/reservations/live", middleware.StreamTokenAuth(liveService), liveController.FollowReservations)
+	r.GET("/admin/reservations/stream", middleware.StreamTokenAuth(liveService), middleware.AdminOnly(), liveController.StreamReservations)
+
 	adminRequired := r.Group("/admin")
 	adminRequired.Use(middleware.AuthMiddleware(authService), middleware.AdminOnly())
 	{
 		adminRequired.GET("/reservations", adminController.ListReservations)
+		adminRequired.GET("/reservations/export", adminController.ExportReservations)
+		adminRequired.POST("/reservations", adminController.CreateReservation)
+		adminRequired.GET("/reservations/search", adminController.SearchReservations)
+		adminRequired.GET("/calendar-feed", calendarController.StaffFeed)
+		adminRequired.POST("/calendar-feed/reset", calendarController.ResetStaffFeed)
+		adminRequired.GET("/reservations/by-code/:code", adminController.GetReservationByCode)
+		adminRequired.GET("/reservations/:id/history", adminController.ReservationHistory)
+		adminRequired.GET("/reservations/:id/reminders", reminderController.ListReminders)
+		adminRequired.POST("/reservations/:id/notes", adminController.AddStaffNote)
+		adminRequired.PUT("/reservations/:id/tags", adminController.SetTags)
+		adminRequired.POST("/reservations/bulk/confirm", adminController.BulkConfirm)
+		adminRequired.POST("/reservations/bulk/cancel", adminController.BulkCancel)
+		adminRequired.POST("/reservations/bulk/status", adminController.BulkUpdateStatus)
 		adminRequired.PATCH("/reservations/:id/confirm", adminController.ConfirmReservation)
 		adminRequired.PATCH("/reservations/:id/cancel", adminController.CancelReservation)
+		adminRequired.PATCH("/reservations/:id/seat", adminController.SeatReservation)
+		adminRequired.PATCH("/reservations/:id/no-show", adminController.MarkNoShow)
+		adminRequired.POST("/reservations/:id/no-show/revert", adminController.RevertNoShow)
+		adminRequired.GET("/no-shows", adminController.NoShowSummary)
+		adminRequired.POST("/walk-ins", adminController.WalkIn)
+		adminRequired.GET("/floor", floorController.FloorStatus)
+		adminRequired.GET("/kitchen", kitchenController.Summary)
+		adminRequired.GET("/guests/:id", guestController.Profile)
+		adminRequired.POST("/guests/:id/restriction/lift", guestController.LiftRestriction)
+		adminRequired.GET("/restrictions", guestController.ListRestrictions)
+		adminRequired.GET("/tables", floorController.ListTables)
+		adminRequired.POST("/tables", floorController.CreateTable)
+		adminRequired.GET("/webhooks", webhookController.ListWebhooks)
+		adminRequired.POST("/webhooks", webhookController.CreateWebhook)
+		adminRequired.PATCH("/webhooks/:id", webhookController.UpdateWebhook)
+		adminRequired.DELETE("/webhooks/:id", webhookController.DeleteWebhook)
+		adminRequired.GET("/webhooks/:id/deliveries", webhookController.ListDeliveries)
+		adminRequired.POST("/webhooks/:id/replay", webhookController.ReplayDeliveries)
 	}
 
 	if err := startHTTP(r, cfg.Port); err != nil {
 		log.Fatalf("failed to start server: %v", err)
 	}
+	stopJobs()
+	jobs.Wait()
+}
+
+// jobHolder identifies this instance in background job leases.
+func jobHolder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// linkSecret returns the configured secret for guest links, or a random one
+// that only lasts until restart.
+func linkSecret(secret string) []byte {
+	if secret != "" {
+		return []byte(secret)
+	}
+	log.Printf("LINK_SECRET is not set; links in guest messages stop working on restart")
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		log.Fatalf("failed to generate link secret: %v", err)
+	}
+	return random
+}
+
+// notificationChannels builds the configured channels for guest messages.
+func notificationChannels(cfg config.Config) []service.NotificationChannel {
+	var channels []service.NotificationChannel
+	for _, name := range cfg.NotificationChannels {
+		switch name {
+		case "email":
+			channels = append(channels, client.NewSMTPChannel(cfg.SMTPAddr, cfg.SMTPFrom, cfg.SMTPUsername, cfg.SMTPPassword))
+		case "sms":
+			if cfg.SMSGatewayURL == "" {
+				log.Printf("SMS_GATEWAY_URL is not set; not sending SMS")
+				continue
+			}
+			channels = append(channels, service.NewSMSChannel(client.NewHTTPSMSSender(cfg.SMSGatewayURL, cfg.SMSGatewayToken)))
+		case "file":
+			channels = append(channels, client.NewFileSink(cfg.NotificationFile))
+		case "memory":
+			channels = append(channels, client.NewMemorySink())
+		}
+	}
+	return channels
 }
 
 // redactDSN masks the password in the DSN for logging.
@@ -74,3 +365,24 @@ func redactDSN(dsn string) string {
 	u.User = url.UserPassword(username, "****")
 	return u.String()
 }
+
+// servicePeriods converts configured sittings for the kitchen service.
+func servicePeriods(periods []config.ServicePeriod) []servicedto.ServicePeriod {
+	out := make([]servicedto.ServicePeriod, 0, len(periods))
+	for _, p := range periods {
+		out = append(out, servicedto.ServicePeriod{Name: p.Name, Start: p.Start, End: p.End})
+	}
+	return out
+}
+
+// sanctionRules converts configured sanctions; nil keeps the service defaults.
+func sanctionRules(rules []config.SanctionRule) []servicedto.SanctionRule {
+	if rules == nil {
+		return nil
+	}
+	out := make([]servicedto.SanctionRule, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, servicedto.SanctionRule{Incidents: r.Incidents, Level: r.Level})
+	}
+	return out
+}
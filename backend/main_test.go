@@ -1,6 +1,8 @@
 package main
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -55,11 +57,16 @@ func TestMainWithStubbedDependencies(t *testing.T) {
 	}
 
 	var started bool
+	var engine *gin.Engine
+	routes := make(map[string]bool)
 	startHTTP = func(r *gin.Engine, port string) error {
-		started = true
+		started, engine = true, r
 		if port == "" {
 			t.Fatalf("expected port to be set")
 		}
+		for _, route := range r.Routes() {
+			routes[route.Method+" "+route.Path] = true
+		}
 		return nil
 	}
 
@@ -71,4 +78,24 @@ func TestMainWithStubbedDependencies(t *testing.T) {
 	if !started {
 		t.Fatalf("expected startHTTP to be called")
 	}
+	for _, route := range []string{
+		"GET /reservations/:id/calendar.ics",
+		"GET /feeds/:token",
+		"POST /my/stream-token",
+		"GET /my/reservations/live",
+		"GET /admin/reservations/stream",
+		"GET /links/:token",
+		"POST /links",
+	} {
+		if !routes[route] {
+			t.Fatalf("expected route %s to be registered", route)
+		}
+	}
+
+	// The calendar download is behind authentication rather than missing.
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reservations/1/calendar.ics", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an anonymous calendar download, got %d", w.Code)
+	}
 }